@@ -0,0 +1,141 @@
+package animation
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"image"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// AsepriteのJSONエクスポート（Array形式・Hash形式の両方に対応）
+type AsepriteSheet struct {
+	Frames []AsepriteFrame
+	Meta   AsepriteMeta
+}
+
+type AsepriteRect struct {
+	X int `json:"x"`
+	Y int `json:"y"`
+	W int `json:"w"`
+	H int `json:"h"`
+}
+
+type AsepriteFrame struct {
+	Filename string       `json:"filename"`
+	Frame    AsepriteRect `json:"frame"`
+	Duration int          `json:"duration"` // ミリ秒
+}
+
+type AsepriteTag struct {
+	Name      string `json:"name"`
+	From      int    `json:"from"`
+	To        int    `json:"to"`
+	Direction string `json:"direction"` // forward, reverse, pingpong, pingpong_reverse
+	Repeat    string `json:"repeat"`
+}
+
+type AsepriteMeta struct {
+	Image     string        `json:"image"`
+	FrameTags []AsepriteTag `json:"frameTags"`
+}
+
+// AsepriteのJSONを解析
+func ParseAsepriteSheet(data []byte) (*AsepriteSheet, error) {
+	var raw struct {
+		Frames json.RawMessage `json:"frames"`
+		Meta   AsepriteMeta    `json:"meta"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("failed to parse aseprite json: %v", err)
+	}
+
+	frames, err := parseAsepriteFrames(raw.Frames)
+	if err != nil {
+		return nil, err
+	}
+
+	return &AsepriteSheet{
+		Frames: frames,
+		Meta:   raw.Meta,
+	}, nil
+}
+
+// Hash形式はキーの順序がフレーム順なので、順序を保ったまま読み込む
+func parseAsepriteFrames(data json.RawMessage) ([]AsepriteFrame, error) {
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) == 0 {
+		return nil, fmt.Errorf("aseprite json has no frames")
+	}
+
+	if trimmed[0] == '[' {
+		var frames []AsepriteFrame
+		if err := json.Unmarshal(trimmed, &frames); err != nil {
+			return nil, fmt.Errorf("failed to parse aseprite frames: %v", err)
+		}
+		return frames, nil
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(trimmed))
+	if _, err := dec.Token(); err != nil { // '{'
+		return nil, err
+	}
+	var frames []AsepriteFrame
+	for dec.More() {
+		key, err := dec.Token()
+		if err != nil {
+			return nil, err
+		}
+		var frame AsepriteFrame
+		if err := dec.Decode(&frame); err != nil {
+			return nil, fmt.Errorf("failed to parse aseprite frame %v: %v", key, err)
+		}
+		frame.Filename, _ = key.(string)
+		frames = append(frames, frame)
+	}
+	return frames, nil
+}
+
+// タグごとにクリップを作成
+// タグが無い場合は全フレームを"default"クリップにまとめる
+func (s *AsepriteSheet) BuildClips(sheet *ebiten.Image) (map[string]*Clip, error) {
+	images := make([]*ebiten.Image, len(s.Frames))
+	for i, f := range s.Frames {
+		rect := image.Rect(f.Frame.X, f.Frame.Y, f.Frame.X+f.Frame.W, f.Frame.Y+f.Frame.H)
+		images[i] = sheet.SubImage(rect).(*ebiten.Image)
+	}
+
+	tags := s.Meta.FrameTags
+	if len(tags) == 0 {
+		tags = []AsepriteTag{{Name: "default", From: 0, To: len(s.Frames) - 1}}
+	}
+
+	clips := make(map[string]*Clip, len(tags))
+	for _, tag := range tags {
+		if tag.From < 0 || tag.To >= len(s.Frames) || tag.From > tag.To {
+			return nil, fmt.Errorf("invalid frame range in tag %s: %d-%d", tag.Name, tag.From, tag.To)
+		}
+
+		mode := PlayLoop
+		if tag.Direction == "pingpong" || tag.Direction == "pingpong_reverse" {
+			mode = PlayPingPong
+		} else if tag.Repeat != "" && tag.Repeat != "0" {
+			// 回数指定のあるタグは1回再生として扱う
+			mode = PlayOnce
+		}
+
+		clip := NewClip(tag.Name, mode)
+		reverse := tag.Direction == "reverse" || tag.Direction == "pingpong_reverse"
+		for i := tag.From; i <= tag.To; i++ {
+			index := i
+			if reverse {
+				index = tag.To - (i - tag.From)
+			}
+			clip.AddFrame(images[index], float64(s.Frames[index].Duration)/1000.0)
+		}
+		clips[tag.Name] = clip
+	}
+
+	return clips, nil
+}
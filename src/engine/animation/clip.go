@@ -0,0 +1,85 @@
+package animation
+
+import (
+	"fmt"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// 再生モード
+type PlayMode int
+
+const (
+	PlayOnce PlayMode = iota
+	PlayLoop
+	PlayPingPong
+)
+
+// 文字列から再生モードを取得
+func ParsePlayMode(name string) (PlayMode, error) {
+	switch name {
+	case "", "loop", "forward":
+		return PlayLoop, nil
+	case "once":
+		return PlayOnce, nil
+	case "pingpong", "ping_pong":
+		return PlayPingPong, nil
+	default:
+		return PlayLoop, fmt.Errorf("unknown play mode: %s", name)
+	}
+}
+
+// クリップの1フレーム
+type Frame struct {
+	Image    *ebiten.Image
+	Duration float64 // 表示時間（秒）
+	Event    string  // このフレームに入った時に発火するイベント名
+}
+
+// 名前付きのアニメーションクリップ
+type Clip struct {
+	Name   string
+	Frames []Frame
+	Mode   PlayMode
+}
+
+func NewClip(name string, mode PlayMode) *Clip {
+	return &Clip{
+		Name:   name,
+		Frames: make([]Frame, 0),
+		Mode:   mode,
+	}
+}
+
+func (c *Clip) AddFrame(image *ebiten.Image, duration float64) *Clip {
+	c.Frames = append(c.Frames, Frame{
+		Image:    image,
+		Duration: duration,
+	})
+	return c
+}
+
+// 指定フレームにイベントを設定
+func (c *Clip) SetEvent(index int, event string) error {
+	if index < 0 || index >= len(c.Frames) {
+		return fmt.Errorf("frame index out of range: %d", index)
+	}
+	c.Frames[index].Event = event
+	return nil
+}
+
+// フレームを含めた複製（イベントを変えても元のクリップに影響しない）
+func (c *Clip) Clone() *Clip {
+	clone := *c
+	clone.Frames = append([]Frame(nil), c.Frames...)
+	return &clone
+}
+
+// クリップの総再生時間
+func (c *Clip) Duration() float64 {
+	total := 0.0
+	for _, f := range c.Frames {
+		total += f.Duration
+	}
+	return total
+}
@@ -0,0 +1,64 @@
+package animation
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// JSONによるクリップ定義
+//
+//	{"clips": {"walk": {"frames": ["player_walk_1", "player_walk_2"], "duration": 0.1, "mode": "loop", "events": {"1": "footstep"}}}}
+type ClipDef struct {
+	Frames    []string       `json:"frames"`    // 画像アセットID
+	Duration  float64        `json:"duration"`  // 全フレーム共通の表示時間（秒）
+	Durations []float64      `json:"durations"` // フレームごとの表示時間（秒）
+	Mode      string         `json:"mode"`      // once, loop, pingpong
+	Events    map[int]string `json:"events"`    // フレーム番号 → イベント名
+}
+
+type clipDefFile struct {
+	Clips map[string]ClipDef `json:"clips"`
+}
+
+// クリップ定義ファイルを解析
+func ParseClipDefs(data []byte) (map[string]ClipDef, error) {
+	var file clipDefFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse clip definitions: %v", err)
+	}
+	return file.Clips, nil
+}
+
+// 画像IDを解決してクリップを作成
+func (d ClipDef) Build(name string, resolve func(id string) (*ebiten.Image, error)) (*Clip, error) {
+	mode, err := ParsePlayMode(d.Mode)
+	if err != nil {
+		return nil, err
+	}
+	if len(d.Frames) == 0 {
+		return nil, fmt.Errorf("clip %s has no frames", name)
+	}
+
+	clip := NewClip(name, mode)
+	for i, id := range d.Frames {
+		img, err := resolve(id)
+		if err != nil {
+			return nil, fmt.Errorf("clip %s frame %d: %v", name, i, err)
+		}
+		duration := d.Duration
+		if i < len(d.Durations) {
+			duration = d.Durations[i]
+		}
+		clip.AddFrame(img, duration)
+	}
+
+	for index, event := range d.Events {
+		if err := clip.SetEvent(index, event); err != nil {
+			return nil, fmt.Errorf("clip %s: %v", name, err)
+		}
+	}
+
+	return clip, nil
+}
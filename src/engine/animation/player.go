@@ -0,0 +1,141 @@
+package animation
+
+// クリップ再生器（エンジンのdtで進める）
+type ClipPlayer struct {
+	clip      *Clip
+	frame     int
+	elapsed   float64
+	direction int
+	playing   bool
+	finished  bool
+	entered   bool // 現在のフレームのイベントを発火済みか
+	Speed     float64
+}
+
+func NewClipPlayer() *ClipPlayer {
+	return &ClipPlayer{
+		direction: 1,
+		Speed:     1.0,
+	}
+}
+
+// クリップを先頭から再生
+func (p *ClipPlayer) Play(clip *Clip) {
+	p.clip = clip
+	p.frame = 0
+	p.elapsed = 0
+	p.direction = 1
+	p.playing = clip != nil && len(clip.Frames) > 0
+	p.finished = false
+	p.entered = false
+}
+
+func (p *ClipPlayer) Stop() {
+	p.playing = false
+	p.frame = 0
+	p.elapsed = 0
+}
+
+func (p *ClipPlayer) Pause() {
+	p.playing = false
+}
+
+func (p *ClipPlayer) Resume() {
+	if p.clip != nil && !p.finished {
+		p.playing = true
+	}
+}
+
+// 再生を進める。フレームに入るたびにonEventを呼ぶ
+func (p *ClipPlayer) Update(dt float64, onEvent func(event string)) {
+	if !p.playing || p.clip == nil {
+		return
+	}
+
+	p.enterFrame(onEvent)
+	p.elapsed += dt * p.Speed
+
+	frames := p.clip.Frames
+	for p.playing {
+		duration := frames[p.frame].Duration
+		if p.elapsed < duration {
+			break
+		}
+		p.elapsed -= duration
+		if !p.advance() {
+			p.elapsed = 0
+			break
+		}
+		p.enterFrame(onEvent)
+		if duration <= 0 {
+			// 表示時間0のフレームで無限ループしないように1フレームずつ進める
+			break
+		}
+	}
+}
+
+func (p *ClipPlayer) enterFrame(onEvent func(event string)) {
+	if p.entered {
+		return
+	}
+	p.entered = true
+	if event := p.clip.Frames[p.frame].Event; event != "" && onEvent != nil {
+		onEvent(event)
+	}
+}
+
+// 次のフレームへ。再生が終了した場合はfalse
+func (p *ClipPlayer) advance() bool {
+	last := len(p.clip.Frames) - 1
+
+	switch p.clip.Mode {
+	case PlayLoop:
+		p.frame++
+		if p.frame > last {
+			p.frame = 0
+		}
+	case PlayPingPong:
+		if last == 0 {
+			return true
+		}
+		next := p.frame + p.direction
+		if next > last || next < 0 {
+			p.direction = -p.direction
+			next = p.frame + p.direction
+		}
+		p.frame = next
+	default:
+		if p.frame >= last {
+			p.playing = false
+			p.finished = true
+			return false
+		}
+		p.frame++
+	}
+
+	p.entered = false
+	return true
+}
+
+func (p *ClipPlayer) Clip() *Clip {
+	return p.clip
+}
+
+func (p *ClipPlayer) CurrentFrame() *Frame {
+	if p.clip == nil || len(p.clip.Frames) == 0 {
+		return nil
+	}
+	return &p.clip.Frames[p.frame]
+}
+
+func (p *ClipPlayer) FrameIndex() int {
+	return p.frame
+}
+
+func (p *ClipPlayer) IsPlaying() bool {
+	return p.playing
+}
+
+func (p *ClipPlayer) IsFinished() bool {
+	return p.finished
+}
@@ -0,0 +1,48 @@
+package asset
+
+import (
+	"errors"
+	"fmt"
+
+	enginefont "gameengine/src/engine/font"
+)
+
+// マニフェストのフォントをFontManagerに登録する
+// "main"があればデフォルトのフォントにする
+// 読み込めないフォントは飛ばし、まとめてエラーを返す
+func RegisterFonts(fm *enginefont.FontManager, manifest *AssetManifest, loader *AssetLoader) error {
+	var errs []error
+	for id, info := range manifest.Fonts {
+		if info.IsBitmap() {
+			f, err := loader.LoadBitmapFont(info)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("font %s: %v", id, err))
+				continue
+			}
+			fm.RegisterBitmapFont(id, f)
+			continue
+		}
+
+		data, err := loader.ReadFile(info.Path)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("font %s: %v", id, err))
+			continue
+		}
+		if err := fm.RegisterFont(id, data); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	// 代替フォントは全部登録してから設定する
+	for id, info := range manifest.Fonts {
+		if len(info.Fallback) > 0 {
+			fm.SetFallback(id, info.Fallback...)
+		}
+	}
+	if fm.HasFont("main") {
+		if err := fm.SetDefault("main"); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -2,9 +2,14 @@ package asset
 
 import (
 	"bytes"
+	"fmt"
+	"gameengine/src/engine/animation"
+	enginefont "gameengine/src/engine/font"
+	"gameengine/src/engine/tilemap"
 	"image"
 	_ "image/png"
 	"io/fs"
+	"path"
 
 	"github.com/hajimehoshi/ebiten/v2"
 	"golang.org/x/image/font"
@@ -23,12 +28,7 @@ func NewAssetLoader(filesystem fs.FS) *AssetLoader {
 
 // 画像のロード
 func (l *AssetLoader) LoadImage(path string) (*ebiten.Image, error) {
-	data, err := fs.ReadFile(l.fs, path)
-	if err != nil {
-		return nil, err
-	}
-
-	img, _, err := image.Decode(bytes.NewReader(data))
+	img, err := l.decodeImage(path)
 	if err != nil {
 		return nil, err
 	}
@@ -55,6 +55,56 @@ func (l *AssetLoader) LoadFont(path string, size float64) (font.Face, error) {
 	})
 }
 
+// ビットマップフォント（BMFont・グリッド）のロード
+func (l *AssetLoader) LoadBitmapFont(info FontAssetInfo) (*enginefont.BitmapFont, error) {
+	switch info.Type {
+	case "bmfont":
+		data, err := fs.ReadFile(l.fs, info.Path)
+		if err != nil {
+			return nil, err
+		}
+		// ページ画像は.fntからの相対パス
+		dir := path.Dir(info.Path)
+		return enginefont.ParseBMFont(data, func(file string) (image.Image, error) {
+			return l.decodeImage(path.Join(dir, file))
+		})
+	case "grid":
+		if info.CellWidth <= 0 || info.CellHeight <= 0 {
+			return nil, fmt.Errorf("grid font needs cell_width and cell_height: %s", info.Path)
+		}
+		img, err := l.decodeImage(info.Path)
+		if err != nil {
+			return nil, err
+		}
+		f := enginefont.NewGridFont(img, info.CellWidth, info.CellHeight, info.Chars)
+		if info.Baseline > 0 {
+			f.Base = info.Baseline
+		}
+		return f, nil
+	default:
+		return nil, fmt.Errorf("unknown bitmap font type: %s", info.Type)
+	}
+}
+
+func (l *AssetLoader) decodeImage(path string) (image.Image, error) {
+	data, err := fs.ReadFile(l.fs, path)
+	if err != nil {
+		return nil, err
+	}
+	img, _, err := image.Decode(bytes.NewReader(data))
+	return img, err
+}
+
+// ファイルの読み込み
+func (l *AssetLoader) ReadFile(path string) ([]byte, error) {
+	return fs.ReadFile(l.fs, path)
+}
+
+// タイルマップのロード
+func (l *AssetLoader) LoadTilemap(path string) (*tilemap.Map, error) {
+	return tilemap.Load(l, path)
+}
+
 // スクリプトのロード
 func (l *AssetLoader) LoadScript(path string) (string, error) {
 	data, err := fs.ReadFile(l.fs, path)
@@ -64,3 +114,54 @@ func (l *AssetLoader) LoadScript(path string) (string, error) {
 
 	return string(data), nil
 }
+
+// Aseprite形式のアニメーションのロード
+// imagePathが空の場合はJSON内のmeta.imageをJSONからの相対パスとして使用
+func (l *AssetLoader) LoadAsepriteClips(jsonPath, imagePath string) (map[string]*animation.Clip, error) {
+	data, err := fs.ReadFile(l.fs, jsonPath)
+	if err != nil {
+		return nil, err
+	}
+
+	sheet, err := animation.ParseAsepriteSheet(data)
+	if err != nil {
+		return nil, err
+	}
+
+	if imagePath == "" {
+		if sheet.Meta.Image == "" {
+			return nil, fmt.Errorf("aseprite json has no image: %s", jsonPath)
+		}
+		imagePath = path.Join(path.Dir(jsonPath), sheet.Meta.Image)
+	}
+
+	img, err := l.LoadImage(imagePath)
+	if err != nil {
+		return nil, err
+	}
+
+	return sheet.BuildClips(img)
+}
+
+// JSONクリップ定義のロード（フレーム画像はresolveで解決）
+func (l *AssetLoader) LoadClipDefs(jsonPath string, resolve func(id string) (*ebiten.Image, error)) (map[string]*animation.Clip, error) {
+	data, err := fs.ReadFile(l.fs, jsonPath)
+	if err != nil {
+		return nil, err
+	}
+
+	defs, err := animation.ParseClipDefs(data)
+	if err != nil {
+		return nil, err
+	}
+
+	clips := make(map[string]*animation.Clip, len(defs))
+	for name, def := range defs {
+		clip, err := def.Build(name, resolve)
+		if err != nil {
+			return nil, err
+		}
+		clips[name] = clip
+	}
+	return clips, nil
+}
@@ -2,7 +2,9 @@ package asset
 
 import (
 	"fmt"
+	"gameengine/src/engine/animation"
 	"gameengine/src/engine/audio"
+	"gameengine/src/engine/tilemap"
 	"sync"
 
 	"github.com/hajimehoshi/ebiten/v2"
@@ -17,6 +19,8 @@ const (
 	AssetTypeAudio
 	AssetTypeFont
 	AssetTypeScript
+	AssetTypeAnimation
+	AssetTypeTilemap
 )
 
 // アセット情報
@@ -58,6 +62,26 @@ func (m *AssetManager) LoadAsync(ids ...string) {
 	}
 }
 
+// アセットの同期ロード（ロード済みのものはスキップ）
+func (m *AssetManager) Load(ids ...string) error {
+	for _, id := range ids {
+		if m.IsLoaded(id) {
+			continue
+		}
+		if err := m.loadAsset(id); err != nil {
+			return fmt.Errorf("failed to load asset %s: %v", id, err)
+		}
+	}
+	return nil
+}
+
+func (m *AssetManager) IsLoaded(id string) bool {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+	_, exists := m.assets[id]
+	return exists
+}
+
 // アセットのロード状態を更新
 func (m *AssetManager) Update() error {
 	select {
@@ -73,20 +97,22 @@ func (m *AssetManager) Update() error {
 
 // アセットのロード
 func (m *AssetManager) loadAsset(id string) error {
-	m.mutex.Lock()
-	defer m.mutex.Unlock()
-
+	m.mutex.RLock()
 	info, exists := m.assetInfo[id]
+	m.mutex.RUnlock()
 	if !exists {
 		return fmt.Errorf("asset not registered: %s", id)
 	}
 
+	// LoadFuncが他のアセットを参照できるようにロック外で実行
 	asset, err := info.LoadFunc()
 	if err != nil {
 		return err
 	}
 
+	m.mutex.Lock()
 	m.assets[id] = asset
+	m.mutex.Unlock()
 	return nil
 }
 
@@ -144,6 +170,42 @@ func (m *AssetManager) GetScript(id string) (string, error) {
 	return script, nil
 }
 
+// アニメーションクリップの取得
+func (m *AssetManager) GetAnimation(id string) (map[string]*animation.Clip, error) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	asset, exists := m.assets[id]
+	if !exists {
+		return nil, fmt.Errorf("animation asset not loaded: %s", id)
+	}
+
+	clips, ok := asset.(map[string]*animation.Clip)
+	if !ok {
+		return nil, fmt.Errorf("asset is not an animation: %s", id)
+	}
+
+	return clips, nil
+}
+
+// タイルマップの取得
+func (m *AssetManager) GetTilemap(id string) (*tilemap.Map, error) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	asset, exists := m.assets[id]
+	if !exists {
+		return nil, fmt.Errorf("tilemap asset not loaded: %s", id)
+	}
+
+	tm, ok := asset.(*tilemap.Map)
+	if !ok {
+		return nil, fmt.Errorf("asset is not a tilemap: %s", id)
+	}
+
+	return tm, nil
+}
+
 // アセットの解放
 func (m *AssetManager) UnloadAsset(id string) {
 	m.mutex.Lock()
@@ -157,3 +219,87 @@ func (m *AssetManager) UnloadAll() {
 	defer m.mutex.Unlock()
 	m.assets = make(map[string]interface{})
 }
+
+// マニフェストの内容をアセットとして登録
+func (m *AssetManager) RegisterManifest(manifest *AssetManifest, loader *AssetLoader) {
+	for id, info := range manifest.Images {
+		path := info.Path
+		m.RegisterAsset(id, AssetInfo{
+			Type: AssetTypeImage,
+			Path: path,
+			LoadFunc: func() (interface{}, error) {
+				return loader.LoadImage(path)
+			},
+		})
+	}
+
+	for id, info := range manifest.Fonts {
+		info := info
+		m.RegisterAsset(id, AssetInfo{
+			Type: AssetTypeFont,
+			Path: info.Path,
+			LoadFunc: func() (interface{}, error) {
+				if info.IsBitmap() {
+					f, err := loader.LoadBitmapFont(info)
+					if err != nil {
+						return nil, err
+					}
+					return f.Face(info.Size), nil
+				}
+				return loader.LoadFont(info.Path, info.Size)
+			},
+		})
+	}
+
+	for id, info := range manifest.Scripts {
+		path := info.Path
+		m.RegisterAsset(id, AssetInfo{
+			Type: AssetTypeScript,
+			Path: path,
+			LoadFunc: func() (interface{}, error) {
+				return loader.LoadScript(path)
+			},
+		})
+	}
+
+	for id, info := range manifest.Animations {
+		info := info
+		m.RegisterAsset(id, AssetInfo{
+			Type: AssetTypeAnimation,
+			Path: info.Path,
+			LoadFunc: func() (interface{}, error) {
+				switch info.Format {
+				case "", "aseprite":
+					imagePath := info.Image
+					if img, ok := manifest.Images[imagePath]; ok {
+						imagePath = img.Path
+					}
+					return loader.LoadAsepriteClips(info.Path, imagePath)
+				case "clips":
+					return loader.LoadClipDefs(info.Path, m.ResolveImage)
+				default:
+					return nil, fmt.Errorf("unknown animation format: %s", info.Format)
+				}
+			},
+		})
+	}
+
+	for id, info := range manifest.Tilemaps {
+		path := info.Path
+		m.RegisterAsset(id, AssetInfo{
+			Type: AssetTypeTilemap,
+			Path: path,
+			LoadFunc: func() (interface{}, error) {
+				return loader.LoadTilemap(path)
+			},
+		})
+	}
+}
+
+// 画像IDからロード済みの画像を取得（未ロードならロードする）
+func (m *AssetManager) ResolveImage(id string) (*ebiten.Image, error) {
+	if err := m.Load(id); err != nil {
+		return nil, err
+	}
+	return m.GetImage(id)
+}
@@ -4,10 +4,16 @@ import "encoding/json"
 
 // アセットマニフェスト
 type AssetManifest struct {
-	Images   map[string]ImageAssetInfo   `json:"images"`
-	Audio    map[string]AudioAssetInfo   `json:"audio"`
-	Fonts    map[string]FontAssetInfo    `json:"fonts"`
-	Scripts  map[string]ScriptAssetInfo  `json:"scripts"`
+	Images     map[string]ImageAssetInfo     `json:"images"`
+	Audio      map[string]AudioAssetInfo     `json:"audio"`
+	Fonts      map[string]FontAssetInfo      `json:"fonts"`
+	Scripts    map[string]ScriptAssetInfo    `json:"scripts"`
+	Animations map[string]AnimationAssetInfo `json:"animations"`
+	Tilemaps   map[string]TilemapAssetInfo   `json:"tilemaps"`
+	Strings    map[string]StringsAssetInfo   `json:"strings"`
+	Language   string                        `json:"language"` // 最初に使う言語（省略時は"ja"）
+	Themes     map[string]ThemeAssetInfo     `json:"themes"`
+	Theme      string                        `json:"theme"` // 最初に使うテーマ（省略時はテーマなし）
 }
 
 type ImageAssetInfo struct {
@@ -20,14 +26,48 @@ type AudioAssetInfo struct {
 }
 
 type FontAssetInfo struct {
-	Path string  `json:"path"`
-	Size float64 `json:"size"`
+	Path     string   `json:"path"`
+	Size     float64  `json:"size"`
+	Type     string   `json:"type"`     // "ttf"（省略時）、"bmfont"、"grid"
+	Fallback []string `json:"fallback"` // 文字がない場合に探すフォントID（順番どおり）
+
+	// グリッドのビットマップフォント（pathは文字を升目に並べた画像）
+	CellWidth  int    `json:"cell_width"`
+	CellHeight int    `json:"cell_height"`
+	Chars      string `json:"chars"`    // 並んでいる文字（省略時はASCIIの空白から「~」まで）
+	Baseline   int    `json:"baseline"` // 升目の上端からベースラインまで（省略時は升目の高さ）
+}
+
+// ビットマップフォントか
+func (info FontAssetInfo) IsBitmap() bool {
+	return info.Type == "bmfont" || info.Type == "grid"
 }
 
 type ScriptAssetInfo struct {
 	Path string `json:"path"`
 }
 
+type AnimationAssetInfo struct {
+	Path   string `json:"path"`
+	Format string `json:"format"` // "aseprite" or "clips"
+	Image  string `json:"image"`  // asepriteのシート画像（省略時はmeta.imageを使用）
+}
+
+type TilemapAssetInfo struct {
+	Path string `json:"path"` // Tiledのマップ（.tmx / .json / .tmj）
+}
+
+// 文字列テーブル（.json / .csv / .po）
+type StringsAssetInfo struct {
+	Path string `json:"path"`
+	Lang string `json:"lang"` // JSON・POの言語（省略時はIDを言語とする）。CSVは1行目で指定
+}
+
+// UIのテーマ（ウィジェットの種類ごとのスキンを書いたJSON）
+type ThemeAssetInfo struct {
+	Path string `json:"path"`
+}
+
 // マニフェストのロード
 func LoadManifest(data []byte) (*AssetManifest, error) {
 	var manifest AssetManifest
@@ -35,4 +75,4 @@ func LoadManifest(data []byte) (*AssetManifest, error) {
 		return nil, err
 	}
 	return &manifest, nil
-} 
\ No newline at end of file
+}
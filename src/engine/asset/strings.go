@@ -0,0 +1,46 @@
+package asset
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+
+	"gameengine/src/engine/i18n"
+)
+
+// マニフェストの文字列テーブルをLocalizerに読み込み、最初の言語を設定する
+// 読み込めないテーブルは飛ばし、まとめてエラーを返す
+func RegisterStrings(loc *i18n.Localizer, manifest *AssetManifest, loader *AssetLoader) error {
+	var errs []error
+	for id, info := range manifest.Strings {
+		data, err := loader.ReadFile(info.Path)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("strings %s: %v", id, err))
+			continue
+		}
+		lang := info.Lang
+		if lang == "" {
+			lang = id
+		}
+
+		switch strings.ToLower(path.Ext(info.Path)) {
+		case ".json":
+			err = loc.LoadJSON(lang, data)
+		case ".csv":
+			err = loc.LoadCSV(data)
+		case ".po":
+			err = loc.LoadPO(lang, data)
+		default:
+			err = fmt.Errorf("unknown string table format: %s", info.Path)
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("strings %s: %v", id, err))
+		}
+	}
+
+	if manifest.Language != "" {
+		loc.SetLanguage(manifest.Language)
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,175 @@
+package asset
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"image/color"
+
+	enginefont "gameengine/src/engine/font"
+	"gameengine/src/engine/render"
+	"gameengine/src/engine/richtext"
+	"gameengine/src/engine/ui"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// テーマファイルのスキン（画像は画像アセットのID）
+//
+//	{
+//	  "default": {"frame": {"image": "window", "border": 8}, "text_color": "white", "font": "main", "size": 18, "padding": 12},
+//	  "menu": {"selected_color": "#ffff00", "cursor": "cursor", "sounds": {"move": "se_cursor", "select": "se_ok"}},
+//	  "message": {"cursor": "wait_cursor"}
+//	}
+type themeSkinJSON struct {
+	Frame *struct {
+		Image  string          `json:"image"`
+		Border json.RawMessage `json:"border"` // 数値、[上下, 左右]、[上, 右, 下, 左]
+	} `json:"frame"`
+	BackgroundColor string            `json:"background_color"`
+	TextColor       string            `json:"text_color"`
+	SelectedColor   string            `json:"selected_color"`
+	DisabledColor   string            `json:"disabled_color"`
+	Font            string            `json:"font"`
+	Size            float64           `json:"size"`
+	Style           string            `json:"style"` // "regular"、"bold"、"italic"
+	Padding         float64           `json:"padding"`
+	LineHeight      float64           `json:"line_height"`
+	Cursor          string            `json:"cursor"`
+	Sounds          map[string]string `json:"sounds"`
+}
+
+// マニフェストのテーマをThemeSetに登録し、最初のテーマに切り替える
+// 画像はimagesで解決し、フォントはfacesで解決する
+// 読み込めないテーマや画像は飛ばし、まとめてエラーを返す
+func RegisterThemes(set *ui.ThemeSet, manifest *AssetManifest, loader *AssetLoader, images func(id string) (*ebiten.Image, error), faces richtext.FaceFunc) error {
+	var errs []error
+	for id, info := range manifest.Themes {
+		data, err := loader.ReadFile(info.Path)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("theme %s: %v", id, err))
+			continue
+		}
+		theme, err := LoadTheme(data, images)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("theme %s: %v", id, err))
+		}
+		if theme == nil {
+			continue
+		}
+		theme.Faces = faces
+		set.Register(id, theme)
+	}
+
+	if manifest.Theme != "" {
+		if err := set.Use(manifest.Theme); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// テーマファイルを読み込む
+// 画像や色が解釈できないスキンはその項目を除いて読み込み、エラーを返す
+func LoadTheme(data []byte, images func(id string) (*ebiten.Image, error)) (*ui.Theme, error) {
+	var skins map[string]themeSkinJSON
+	if err := json.Unmarshal(data, &skins); err != nil {
+		return nil, err
+	}
+
+	var errs []error
+	theme := ui.NewTheme()
+	for name, js := range skins {
+		skin, err := js.toSkin(images)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("skin %s: %v", name, err))
+		}
+		theme.Skins[name] = skin
+	}
+	return theme, errors.Join(errs...)
+}
+
+func (js themeSkinJSON) toSkin(images func(id string) (*ebiten.Image, error)) (*ui.Skin, error) {
+	var errs []error
+	skin := &ui.Skin{
+		FontID:     js.Font,
+		FontSize:   js.Size,
+		Padding:    js.Padding,
+		LineHeight: js.LineHeight,
+		Sounds:     js.Sounds,
+	}
+
+	if js.Frame != nil {
+		border, err := parseInsets(js.Frame.Border)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("frame border: %v", err))
+		}
+		if img, err := images(js.Frame.Image); err != nil {
+			errs = append(errs, fmt.Errorf("frame: %v", err))
+		} else {
+			skin.Frame = &ui.NineSlice{Image: img, Border: border}
+		}
+	}
+	if js.Cursor != "" {
+		if img, err := images(js.Cursor); err != nil {
+			errs = append(errs, fmt.Errorf("cursor: %v", err))
+		} else {
+			skin.Cursor = img
+		}
+	}
+
+	colors := []struct {
+		name  string
+		value string
+		dst   *color.Color
+	}{
+		{"background_color", js.BackgroundColor, &skin.BackgroundColor},
+		{"text_color", js.TextColor, &skin.TextColor},
+		{"selected_color", js.SelectedColor, &skin.SelectedColor},
+		{"disabled_color", js.DisabledColor, &skin.DisabledColor},
+	}
+	for _, c := range colors {
+		if c.value == "" {
+			continue
+		}
+		rgba, err := render.ParseColor(c.value)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %v", c.name, err))
+			continue
+		}
+		*c.dst = rgba
+	}
+
+	switch js.Style {
+	case "", "regular":
+	case "bold":
+		skin.FontStyle = enginefont.StyleBold
+	case "italic":
+		skin.FontStyle = enginefont.StyleItalic
+	default:
+		errs = append(errs, fmt.Errorf("invalid font style: %s", js.Style))
+	}
+	return skin, errors.Join(errs...)
+}
+
+// 8、[上下, 左右]、[上, 右, 下, 左]
+func parseInsets(data json.RawMessage) (ui.Insets, error) {
+	if len(data) == 0 {
+		return ui.Insets{}, nil
+	}
+	var n float64
+	if err := json.Unmarshal(data, &n); err == nil {
+		return ui.UniformInsets(n), nil
+	}
+	var values []float64
+	if err := json.Unmarshal(data, &values); err != nil {
+		return ui.Insets{}, fmt.Errorf("must be number or list of numbers")
+	}
+	switch len(values) {
+	case 2:
+		return ui.Insets{Top: values[0], Right: values[1], Bottom: values[0], Left: values[1]}, nil
+	case 4:
+		return ui.Insets{Top: values[0], Right: values[1], Bottom: values[2], Left: values[3]}, nil
+	}
+	return ui.Insets{}, fmt.Errorf("must have 2 or 4 values, got %d", len(values))
+}
@@ -0,0 +1,51 @@
+package components
+
+import (
+	"fmt"
+	"gameengine/src/engine/animation"
+	core "gameengine/src/engine/ecs/core"
+)
+
+type AnimationComponent struct {
+	entity   *core.Entity
+	Clips    map[string]*animation.Clip
+	Player   *animation.ClipPlayer
+	Current  string
+	OnEvent  func(clip, event string) // フレームイベント発火時
+	OnFinish func(clip string)        // 1回再生のクリップが終了した時
+}
+
+func NewAnimationComponent() *AnimationComponent {
+	return &AnimationComponent{
+		Clips:  make(map[string]*animation.Clip),
+		Player: animation.NewClipPlayer(),
+	}
+}
+
+func (c *AnimationComponent) GetEntity() *core.Entity  { return c.entity }
+func (c *AnimationComponent) SetEntity(e *core.Entity) { c.entity = e }
+func (c *AnimationComponent) GetID() core.ComponentID  { return 6 } // AnimationComponentのID
+func (c *AnimationComponent) OnAdd()                   {}
+func (c *AnimationComponent) OnRemove()                {}
+
+func (c *AnimationComponent) AddClip(clip *animation.Clip) {
+	c.Clips[clip.Name] = clip
+}
+
+// 名前付きクリップを再生（再生中の同じクリップは先頭に戻さない）
+func (c *AnimationComponent) Play(name string, restart bool) error {
+	clip, exists := c.Clips[name]
+	if !exists {
+		return fmt.Errorf("animation clip not found: %s", name)
+	}
+	if !restart && c.Current == name && c.Player.IsPlaying() {
+		return nil
+	}
+	c.Current = name
+	c.Player.Play(clip)
+	return nil
+}
+
+func (c *AnimationComponent) Stop() {
+	c.Player.Stop()
+}
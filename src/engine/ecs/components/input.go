@@ -0,0 +1,31 @@
+package components
+
+import (
+	core "gameengine/src/engine/ecs/core"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// エンティティごとのアクションとキーの対応
+type InputComponent struct {
+	entity *core.Entity
+	Keys   map[string][]ebiten.Key
+}
+
+func NewInputComponent() *InputComponent {
+	return &InputComponent{
+		Keys: make(map[string][]ebiten.Key),
+	}
+}
+
+func (c *InputComponent) GetEntity() *core.Entity  { return c.entity }
+func (c *InputComponent) SetEntity(e *core.Entity) { c.entity = e }
+func (c *InputComponent) GetID() core.ComponentID  { return 10 } // InputComponentのID
+func (c *InputComponent) OnAdd()                   {}
+func (c *InputComponent) OnRemove()                {}
+
+// アクションにキーが割り当てられているか
+func (c *InputComponent) HasAction(action string) bool {
+	_, exists := c.Keys[action]
+	return exists
+}
@@ -0,0 +1,96 @@
+package components
+
+import (
+	core "gameengine/src/engine/ecs/core"
+	"gameengine/src/engine/i18n"
+	"gameengine/src/engine/ui"
+)
+
+// メッセージ送りの状態
+type MessageState int
+
+const (
+	MessageIdle      MessageState = iota // 表示するメッセージがない
+	MessageRevealing                     // 1文字ずつ表示中
+	MessageWaiting                       // 入力待ち
+	MessageChoosing                      // 選択肢を選択中
+	MessageFinished                      // すべて表示し終えた
+)
+
+func (s MessageState) String() string {
+	switch s {
+	case MessageRevealing:
+		return "revealing"
+	case MessageWaiting:
+		return "waiting"
+	case MessageChoosing:
+		return "choosing"
+	case MessageFinished:
+		return "finished"
+	}
+	return "idle"
+}
+
+// 選択肢の1項目
+type MessageChoice struct {
+	Text    string
+	Key     string // 文字列テーブルのキー（指定するとTextより優先）
+	Enabled bool
+}
+
+// 1つのメッセージ（Choicesがあれば表示後に選択肢を出す）
+type Message struct {
+	Text     string
+	Key      string    // 文字列テーブルのキー（指定するとTextより優先）
+	Args     i18n.Args // キーのプレースホルダーの値
+	Choices  []MessageChoice
+	OnChoice func(index int, option string) // このメッセージの選択肢が選ばれた時
+}
+
+// メッセージを順に表示し、選択肢の結果を受け取る
+type MessageSystemComponent struct {
+	entity       *core.Entity
+	Messages     []Message
+	Current      int           // 表示中のメッセージ
+	WindowID     core.EntityID // 表示先のMessageWindowComponentを持つエンティティ
+	CharDelay    float64       // 1文字の表示間隔（秒、0以下なら一度に表示）
+	HideOnFinish bool          // すべて表示し終えたらウィンドウを隠す
+
+	OnChoice func(index int, option string) // 選択肢が選ばれた時
+	OnFinish func()                         // すべて表示し終えた時
+
+	State         MessageState
+	SkipRequested bool    // 次の更新で残りの文字を一度に表示する
+	Revealed      float64 // 表示済みの文字数
+	WaitTime      float64 // 早送り中の入力待ちの経過時間
+	Menu          *ui.MenuWindow
+	Choice        int // 最後に選ばれた選択肢（未選択なら-1）
+	ChoiceText    string
+}
+
+func NewMessageSystemComponent() *MessageSystemComponent {
+	return &MessageSystemComponent{
+		CharDelay:    0.05,
+		HideOnFinish: true,
+		Choice:       -1,
+	}
+}
+
+func (c *MessageSystemComponent) GetEntity() *core.Entity  { return c.entity }
+func (c *MessageSystemComponent) SetEntity(e *core.Entity) { c.entity = e }
+func (c *MessageSystemComponent) GetID() core.ComponentID  { return 9 } // MessageSystemComponentのID
+func (c *MessageSystemComponent) OnAdd()                   {}
+func (c *MessageSystemComponent) OnRemove()                {}
+
+// メッセージを追加する（表示し終えていれば続きから再開する）
+func (c *MessageSystemComponent) Enqueue(messages ...Message) {
+	c.Messages = append(c.Messages, messages...)
+	if c.State == MessageFinished {
+		c.State = MessageIdle
+	}
+}
+
+// 表示中または表示待ちのメッセージがあるか
+func (c *MessageSystemComponent) IsActive() bool {
+	return c.State != MessageFinished && c.Current < len(c.Messages)
+}
@@ -0,0 +1,24 @@
+package components
+
+import (
+	core "gameengine/src/engine/ecs/core"
+	"gameengine/src/engine/ui"
+)
+
+// メッセージウィンドウ（TransformComponentがあればその位置に表示する）
+type MessageWindowComponent struct {
+	entity *core.Entity
+	Window *ui.MessageWindow
+}
+
+func NewMessageWindowComponent() *MessageWindowComponent {
+	return &MessageWindowComponent{
+		Window: ui.NewMessageWindow(nil),
+	}
+}
+
+func (c *MessageWindowComponent) GetEntity() *core.Entity  { return c.entity }
+func (c *MessageWindowComponent) SetEntity(e *core.Entity) { c.entity = e }
+func (c *MessageWindowComponent) GetID() core.ComponentID  { return 8 } // MessageWindowComponentのID
+func (c *MessageWindowComponent) OnAdd()                   {}
+func (c *MessageWindowComponent) OnRemove()                {}
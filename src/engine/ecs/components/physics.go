@@ -10,6 +10,12 @@ type PhysicsComponent struct {
 	VelocityY float64
 	Gravity   float64
 	Speed     float64
+
+	// タイルマップとの衝突判定（Transformの位置を左上とする矩形。幅か高さが0なら判定しない）
+	Width    float64
+	Height   float64
+	Solid    bool // falseなら通行不可タイルをすり抜ける
+	OnGround bool // 前の更新で下向きに通行不可タイルに当たった
 }
 
 func NewPhysicsComponent() *PhysicsComponent {
@@ -19,6 +25,7 @@ func NewPhysicsComponent() *PhysicsComponent {
 		VelocityY:     0,
 		Gravity:       0,
 		Speed:         3.0,
+		Solid:         true,
 	}
 }
 
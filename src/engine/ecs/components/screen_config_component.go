@@ -1,11 +1,17 @@
 package components
 
-import "gameengine/src/engine/ecs/core"
+import (
+	"gameengine/src/engine/ecs/core"
+	"gameengine/src/engine/render"
+	"image/color"
+)
 
 type ScreenConfigComponent struct {
 	*core.BaseComponent
-	Width  int
-	Height int
+	Width          int
+	Height         int
+	ScaleMode      render.ScaleMode // ウィンドウへの拡大方法
+	LetterboxColor color.RGBA       // 余白の色
 }
 
 // プリセット解像度の定義
@@ -22,9 +28,11 @@ var ScreenResolutions = map[string]struct {
 
 func NewScreenConfigComponent() *ScreenConfigComponent {
 	return &ScreenConfigComponent{
-		BaseComponent: core.NewBaseComponent(4), // Screen Config ID = 4
-		Width:         1280,                     // デフォルトはHD
-		Height:        720,
+		BaseComponent:  core.NewBaseComponent(4), // Screen Config ID = 4
+		Width:          1280,                     // デフォルトはHD
+		Height:         720,
+		ScaleMode:      render.ScaleFit,
+		LetterboxColor: color.RGBA{0, 0, 0, 255},
 	}
 }
 
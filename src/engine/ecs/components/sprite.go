@@ -8,13 +8,16 @@ import (
 )
 
 type SpriteComponent struct {
-	entity *core.Entity
-	Image  string
-	Sprite *ebiten.Image
-	Width  int
-	Height int
-	Layer  int
-	Color  color.Color
+	entity     *core.Entity
+	Image      string
+	Sprite     *ebiten.Image
+	Width      int
+	Height     int
+	Layer      int // 描画レイヤー（大きいほど手前）
+	Z          int // 同じレイヤー内の順番
+	Visible    bool
+	Color      color.Color       // 描画時に画像に掛ける色（白ならそのまま）
+	ColorScale ebiten.ColorScale // 描画時の色の乗算（不透明度など）
 }
 
 func (c *SpriteComponent) GetEntity() *core.Entity {
@@ -38,16 +41,16 @@ func NewSpriteComponent() *SpriteComponent {
 	img.Fill(color.White)
 
 	return &SpriteComponent{
-		Layer:  0,
-		Sprite: img,
-		Width:  32,
-		Height: 32,
-		Color:  color.White,
+		Layer:   0,
+		Sprite:  img,
+		Width:   32,
+		Height:  32,
+		Visible: true,
+		Color:   color.White,
 	}
 }
 
-// 色を設定するメソッド
+// 色を設定するメソッド（白い四角形ならその色になる）
 func (c *SpriteComponent) SetColor(col color.Color) {
 	c.Color = col
-	c.Sprite.Fill(col)
 }
@@ -1,17 +1,57 @@
 package components
 
-import core "gameengine/src/engine/ecs/core"
+import (
+	core "gameengine/src/engine/ecs/core"
+	"gameengine/src/engine/font"
+	"gameengine/src/engine/i18n"
+	"gameengine/src/engine/textlayout"
+	"image/color"
+)
 
 type TextComponent struct {
 	entity  *core.Entity
 	Text    string
 	X, Y    float64
 	Visible bool
+
+	FontID string // 空ならデフォルトフォント
+	Size   float64
+	Style  font.FontStyle
+	Color  color.Color
+
+	OutlineColor  color.Color
+	OutlineWidth  float64 // 0なら縁取りなし
+	ShadowColor   color.Color
+	ShadowOffsetX float64 // 両方0なら影なし
+	ShadowOffsetY float64
+
+	Align       textlayout.Align         // X座標に対する揃え
+	VAlign      textlayout.VerticalAlign // Y座標に対する揃え
+	MaxWidth    float64                  // 0なら折り返さない
+	LineSpacing float64                  // 行の高さの倍率
+	Anchor      textlayout.Anchor        // 指定すると画面の端・中央からのずれとしてX, Yを使う
+
+	Dynamic bool // trueなら文字列ごとの画像を作らず、常にアトラスから1文字ずつ描く（毎フレーム変わるテキスト用）
+
+	TextKey  string    // 文字列テーブルのキー（指定するとTextは現在の言語の訳になる）
+	TextArgs i18n.Args // キーのプレースホルダーの値
 }
 
 func NewTextComponent() *TextComponent {
 	return &TextComponent{
-		Visible: true,
+		Visible:      true,
+		Size:         16,
+		Color:        color.White,
+		OutlineColor: color.Black,
+		ShadowColor:  color.RGBA{0, 0, 0, 160},
+		LineSpacing:  1.0,
+	}
+}
+
+// キーがあれば現在の言語でTextを訳し直す
+func (c *TextComponent) Localize(loc *i18n.Localizer) {
+	if c.TextKey != "" {
+		c.Text = loc.Tr(c.TextKey, c.TextArgs)
 	}
 }
 
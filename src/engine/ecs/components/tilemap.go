@@ -0,0 +1,70 @@
+package components
+
+import (
+	"fmt"
+
+	"gameengine/src/engine/collision"
+	core "gameengine/src/engine/ecs/core"
+	"gameengine/src/engine/tilemap"
+)
+
+type TilemapComponent struct {
+	entity   *core.Entity
+	MapID    string
+	Map      *tilemap.Map
+	Renderer *tilemap.Renderer
+	X, Y     float64 // マップ左上のワールド座標
+	Visible  bool
+
+	colliders []*collision.CollisionObject // 通行不可タイルの矩形（ワールド座標、nilなら作り直す）
+	colliderX float64
+	colliderY float64
+}
+
+// アセットのマップは共有されているので、タイルを書き換えられるように複製して持つ
+func NewTilemapComponent(mapID string, m *tilemap.Map) *TilemapComponent {
+	m = m.Clone()
+	return &TilemapComponent{
+		MapID:    mapID,
+		Map:      m,
+		Renderer: tilemap.NewRenderer(m),
+		Visible:  true,
+	}
+}
+
+func (c *TilemapComponent) GetEntity() *core.Entity  { return c.entity }
+func (c *TilemapComponent) SetEntity(e *core.Entity) { c.entity = e }
+func (c *TilemapComponent) GetID() core.ComponentID  { return 7 } // TilemapComponentのID
+func (c *TilemapComponent) OnAdd()                   {}
+func (c *TilemapComponent) OnRemove()                {}
+
+// ワールド座標のタイル座標
+func (c *TilemapComponent) WorldToTile(x, y float64) (int, int) {
+	return c.Map.WorldToTile(x-c.X, y-c.Y)
+}
+
+// タイルを書き換えてキャッシュを破棄
+func (c *TilemapComponent) SetTile(layerName string, tx, ty int, gid uint32) {
+	for i, layer := range c.Map.Layers {
+		if layer.Name == layerName {
+			layer.SetTile(tx, ty, gid)
+			c.Renderer.Invalidate(i, tx, ty)
+			c.colliders = nil
+			return
+		}
+	}
+}
+
+// 通行不可タイル（プロパティ solid・collision）を連結した衝突オブジェクト（ワールド座標）
+// タイルを書き換えるか、マップを動かすと作り直す
+func (c *TilemapComponent) Colliders() []*collision.CollisionObject {
+	if c.colliders == nil || c.colliderX != c.X || c.colliderY != c.Y {
+		c.colliders = append([]*collision.CollisionObject{}, c.Map.CollisionObjects(fmt.Sprintf("tilemap_%s", c.MapID), 1, ^uint32(0))...)
+		for _, obj := range c.colliders {
+			bounds := obj.Shape.GetBounds()
+			obj.Shape.SetPosition(bounds.Min.X+c.X, bounds.Min.Y+c.Y)
+		}
+		c.colliderX, c.colliderY = c.X, c.Y
+	}
+	return c.colliders
+}
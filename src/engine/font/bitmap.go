@@ -0,0 +1,225 @@
+package font
+
+import (
+	"image"
+	"image/color"
+	"math"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/math/fixed"
+)
+
+// ビットマップフォントの1文字
+type BitmapGlyph struct {
+	X, Y          int // ページ画像上の位置
+	Width, Height int
+	XOffset       int // 描画位置のずれ（行の上端から）
+	YOffset       int
+	XAdvance      int
+	Page          int
+}
+
+// ドット絵用のビットマップフォント（BMFont・グリッド）
+// 指定された大きさに近い整数倍で拡大して描く
+type BitmapFont struct {
+	Size       int // 等倍の大きさ（ピクセル）
+	LineHeight int
+	Base       int // 行の上端からベースラインまで
+	glyphs     map[rune]BitmapGlyph
+	kerning    map[[2]rune]int
+	pages      []*image.Alpha
+	scaled     map[int][]*image.Alpha
+	faces      map[int]*bitmapFace
+}
+
+func newBitmapFont() *BitmapFont {
+	return &BitmapFont{
+		glyphs:  make(map[rune]BitmapGlyph),
+		kerning: make(map[[2]rune]int),
+		scaled:  make(map[int][]*image.Alpha),
+		faces:   make(map[int]*bitmapFace),
+	}
+}
+
+// 等幅の升目に並んだ文字画像からフォントを作る
+// charsは左上から右へ、行を下へ並んだ文字（空ならASCIIの空白から「~」まで）
+func NewGridFont(img image.Image, cellWidth, cellHeight int, chars string) *BitmapFont {
+	if chars == "" {
+		for r := rune(' '); r <= '~'; r++ {
+			chars += string(r)
+		}
+	}
+
+	f := newBitmapFont()
+	f.Size = cellHeight
+	f.LineHeight = cellHeight
+	f.Base = cellHeight
+	f.pages = []*image.Alpha{toAlpha(img)}
+
+	b := img.Bounds()
+	columns := b.Dx() / cellWidth
+	if columns <= 0 {
+		return f
+	}
+	i := 0
+	for _, r := range chars {
+		x, y := (i%columns)*cellWidth, (i/columns)*cellHeight
+		if y+cellHeight > b.Dy() {
+			break
+		}
+		f.glyphs[r] = BitmapGlyph{
+			X: x, Y: y,
+			Width: cellWidth, Height: cellHeight,
+			XAdvance: cellWidth,
+		}
+		i++
+	}
+	return f
+}
+
+func (f *BitmapFont) HasGlyph(r rune) bool {
+	_, ok := f.glyphs[r]
+	return ok
+}
+
+// 文字の組の詰め幅（等倍のピクセル）
+func (f *BitmapFont) SetKerning(first, second rune, amount int) {
+	f.kerning[[2]rune{first, second}] = amount
+}
+
+// sizeに近い整数倍（1倍以上）で描くフェイス
+func (f *BitmapFont) Face(size float64) font.Face {
+	scale := 1
+	if f.Size > 0 {
+		scale = int(math.Max(1, math.Round(size/float64(f.Size))))
+	}
+	face, ok := f.faces[scale]
+	if !ok {
+		face = &bitmapFace{font: f, scale: scale}
+		f.faces[scale] = face
+	}
+	return face
+}
+
+// scale倍に拡大したページ（ドットのまま拡大する）
+func (f *BitmapFont) page(index, scale int) *image.Alpha {
+	if index < 0 || index >= len(f.pages) {
+		return nil
+	}
+	if scale == 1 {
+		return f.pages[index]
+	}
+	pages, ok := f.scaled[scale]
+	if !ok {
+		pages = make([]*image.Alpha, len(f.pages))
+		f.scaled[scale] = pages
+	}
+	if pages[index] == nil {
+		src := f.pages[index]
+		b := src.Bounds()
+		dst := image.NewAlpha(image.Rect(0, 0, b.Dx()*scale, b.Dy()*scale))
+		for y := 0; y < dst.Rect.Dy(); y++ {
+			for x := 0; x < dst.Rect.Dx(); x++ {
+				dst.Pix[dst.PixOffset(x, y)] = src.AlphaAt(b.Min.X+x/scale, b.Min.Y+y/scale).A
+			}
+		}
+		pages[index] = dst
+	}
+	return pages[index]
+}
+
+// 文字の形をアルファ値にする
+// 透明な部分がない画像（黒地に白文字など）は明るさを使う
+func toAlpha(img image.Image) *image.Alpha {
+	b := img.Bounds()
+	opaque := true
+	for y := b.Min.Y; y < b.Max.Y && opaque; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			if _, _, _, a := img.At(x, y).RGBA(); a != 0xffff {
+				opaque = false
+				break
+			}
+		}
+	}
+
+	dst := image.NewAlpha(image.Rect(0, 0, b.Dx(), b.Dy()))
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			c := img.At(x, y)
+			var a uint8
+			if opaque {
+				a = color.GrayModel.Convert(c).(color.Gray).Y
+			} else {
+				_, _, _, a32 := c.RGBA()
+				a = uint8(a32 >> 8)
+			}
+			dst.Pix[dst.PixOffset(x-b.Min.X, y-b.Min.Y)] = a
+		}
+	}
+	return dst
+}
+
+type bitmapFace struct {
+	font  *BitmapFont
+	scale int
+}
+
+func (f *bitmapFace) Close() error {
+	return nil
+}
+
+func (f *bitmapFace) Glyph(dot fixed.Point26_6, r rune) (image.Rectangle, image.Image, image.Point, fixed.Int26_6, bool) {
+	g, ok := f.font.glyphs[r]
+	if !ok {
+		return image.Rectangle{}, nil, image.Point{}, 0, false
+	}
+	s := f.scale
+	x := dot.X.Round() + g.XOffset*s
+	y := dot.Y.Round() + (g.YOffset-f.font.Base)*s
+	dr := image.Rect(x, y, x+g.Width*s, y+g.Height*s)
+	mask := f.font.page(g.Page, s)
+	if mask == nil {
+		return image.Rectangle{}, nil, image.Point{}, fixed.I(g.XAdvance * s), true
+	}
+	return dr, mask, image.Pt(g.X*s, g.Y*s), fixed.I(g.XAdvance * s), true
+}
+
+func (f *bitmapFace) GlyphBounds(r rune) (fixed.Rectangle26_6, fixed.Int26_6, bool) {
+	g, ok := f.font.glyphs[r]
+	if !ok {
+		return fixed.Rectangle26_6{}, 0, false
+	}
+	s := f.scale
+	top := (g.YOffset - f.font.Base) * s
+	bounds := fixed.R(g.XOffset*s, top, (g.XOffset+g.Width)*s, top+g.Height*s)
+	return bounds, fixed.I(g.XAdvance * s), true
+}
+
+func (f *bitmapFace) GlyphAdvance(r rune) (fixed.Int26_6, bool) {
+	g, ok := f.font.glyphs[r]
+	if !ok {
+		return 0, false
+	}
+	return fixed.I(g.XAdvance * f.scale), true
+}
+
+func (f *bitmapFace) Kern(r0, r1 rune) fixed.Int26_6 {
+	return fixed.I(f.font.kerning[[2]rune{r0, r1}] * f.scale)
+}
+
+func (f *bitmapFace) Metrics() font.Metrics {
+	s := f.scale
+	m := font.Metrics{
+		Height:     fixed.I(f.font.LineHeight * s),
+		Ascent:     fixed.I(f.font.Base * s),
+		Descent:    fixed.I((f.font.LineHeight - f.font.Base) * s),
+		CaretSlope: image.Pt(0, 1),
+	}
+	if g, ok := f.font.glyphs['x']; ok {
+		m.XHeight = fixed.I((f.font.Base - g.YOffset) * s)
+	}
+	if g, ok := f.font.glyphs['H']; ok {
+		m.CapHeight = fixed.I((f.font.Base - g.YOffset) * s)
+	}
+	return m
+}
@@ -0,0 +1,141 @@
+package font
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"image"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// BMFontの1行（テキスト形式）または1要素（XML形式）
+type bmfontRecord struct {
+	tag   string
+	attrs map[string]string
+}
+
+// AngelCode BMFont（.fnt のテキスト形式・XML形式）を読み込む
+// loadPageはpageのfile（.fntからの相対パス）の画像を返す
+func ParseBMFont(data []byte, loadPage func(file string) (image.Image, error)) (*BitmapFont, error) {
+	var records []bmfontRecord
+	var err error
+	trimmed := bytes.TrimSpace(data)
+	switch {
+	case bytes.HasPrefix(trimmed, []byte("BMF")):
+		return nil, fmt.Errorf("binary BMFont is not supported")
+	case bytes.HasPrefix(trimmed, []byte("<")):
+		records, err = parseBMFontXML(trimmed)
+	default:
+		records, err = parseBMFontText(string(trimmed))
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	f := newBitmapFont()
+	for _, rec := range records {
+		num := func(key string) int {
+			v, convErr := strconv.Atoi(rec.attrs[key])
+			if convErr != nil && rec.attrs[key] != "" && err == nil {
+				err = fmt.Errorf("invalid %s %s: %q", rec.tag, key, rec.attrs[key])
+			}
+			return v
+		}
+
+		switch rec.tag {
+		case "info":
+			f.Size = num("size")
+			if f.Size < 0 {
+				f.Size = -f.Size // 負の値は文字の高さで指定したもの
+			}
+		case "common":
+			f.LineHeight = num("lineHeight")
+			f.Base = num("base")
+		case "page":
+			id := num("id")
+			img, loadErr := loadPage(rec.attrs["file"])
+			if loadErr != nil {
+				return nil, fmt.Errorf("failed to load page %s: %v", rec.attrs["file"], loadErr)
+			}
+			for len(f.pages) <= id {
+				f.pages = append(f.pages, nil)
+			}
+			f.pages[id] = toAlpha(img)
+		case "char":
+			f.glyphs[rune(num("id"))] = BitmapGlyph{
+				X: num("x"), Y: num("y"),
+				Width: num("width"), Height: num("height"),
+				XOffset: num("xoffset"), YOffset: num("yoffset"),
+				XAdvance: num("xadvance"),
+				Page:     num("page"),
+			}
+		case "kerning":
+			f.SetKerning(rune(num("first")), rune(num("second")), num("amount"))
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if f.Size == 0 {
+		f.Size = f.LineHeight
+	}
+	return f, nil
+}
+
+// tag key=value key="quoted value" ...
+func parseBMFontText(text string) ([]bmfontRecord, error) {
+	var records []bmfontRecord
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		tag, rest, _ := strings.Cut(line, " ")
+		rec := bmfontRecord{tag: tag, attrs: make(map[string]string)}
+		for rest = strings.TrimSpace(rest); rest != ""; rest = strings.TrimSpace(rest) {
+			key, after, ok := strings.Cut(rest, "=")
+			if !ok {
+				return nil, fmt.Errorf("invalid BMFont line: %s", line)
+			}
+			var value string
+			if strings.HasPrefix(after, `"`) {
+				end := strings.Index(after[1:], `"`)
+				if end < 0 {
+					return nil, fmt.Errorf("unterminated string in BMFont line: %s", line)
+				}
+				value, rest = after[1:end+1], after[end+2:]
+			} else {
+				value, rest, _ = strings.Cut(after, " ")
+			}
+			rec.attrs[strings.TrimSpace(key)] = value
+		}
+		records = append(records, rec)
+	}
+	return records, nil
+}
+
+func parseBMFontXML(data []byte) ([]bmfontRecord, error) {
+	var records []bmfontRecord
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			return records, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid BMFont XML: %v", err)
+		}
+		start, ok := token.(xml.StartElement)
+		if !ok {
+			continue
+		}
+		rec := bmfontRecord{tag: start.Name.Local, attrs: make(map[string]string)}
+		for _, attr := range start.Attr {
+			rec.attrs[attr.Name.Local] = attr.Value
+		}
+		records = append(records, rec)
+	}
+}
@@ -0,0 +1,122 @@
+package font
+
+import (
+	"image"
+	"unicode"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/sfnt"
+	"golang.org/x/image/math/fixed"
+)
+
+// 文字ごとに、その文字を持つ最初のフォントで描くフェイス
+// 行の高さなどは先頭のフォントのものを使う
+type fallbackFace struct {
+	faces  []font.Face
+	ids    []string
+	covers func(fontID string, r rune) bool
+	index  map[rune]int
+}
+
+func newFallbackFace(faces []font.Face, ids []string, covers func(string, rune) bool) *fallbackFace {
+	return &fallbackFace{
+		faces:  faces,
+		ids:    ids,
+		covers: covers,
+		index:  make(map[rune]int),
+	}
+}
+
+// rを持つフェイス（どれにもなければ先頭）
+func (f *fallbackFace) pick(r rune) font.Face {
+	i, ok := f.index[r]
+	if !ok {
+		i = 0
+		for j, id := range f.ids {
+			if f.covers(id, r) {
+				i = j
+				break
+			}
+		}
+		f.index[r] = i
+	}
+	return f.faces[i]
+}
+
+func (f *fallbackFace) Close() error {
+	return nil
+}
+
+func (f *fallbackFace) Glyph(dot fixed.Point26_6, r rune) (image.Rectangle, image.Image, image.Point, fixed.Int26_6, bool) {
+	return f.pick(r).Glyph(dot, r)
+}
+
+func (f *fallbackFace) GlyphBounds(r rune) (fixed.Rectangle26_6, fixed.Int26_6, bool) {
+	return f.pick(r).GlyphBounds(r)
+}
+
+func (f *fallbackFace) GlyphAdvance(r rune) (fixed.Int26_6, bool) {
+	return f.pick(r).GlyphAdvance(r)
+}
+
+// 別のフォントの文字の間ではカーニングしない
+func (f *fallbackFace) Kern(r0, r1 rune) fixed.Int26_6 {
+	face := f.pick(r0)
+	if face != f.pick(r1) {
+		return 0
+	}
+	return face.Kern(r0, r1)
+}
+
+func (f *fallbackFace) Metrics() font.Metrics {
+	return f.faces[0].Metrics()
+}
+
+func hasGlyph(ft *sfnt.Font, buf *sfnt.Buffer, r rune) bool {
+	index, err := ft.GlyphIndex(buf, r)
+	return err == nil && index != 0
+}
+
+// fontIDのフォントそのものにrがあるか
+func (fm *FontManager) FontHasGlyph(fontID string, r rune) bool {
+	return fm.covers(fontID, r)
+}
+
+func (fm *FontManager) covers(fontID string, r rune) bool {
+	if bitmap, exists := fm.bitmaps[fontID]; exists {
+		return bitmap.HasGlyph(r)
+	}
+	ft, exists := fm.fonts[fontID]
+	return exists && hasGlyph(ft, &fm.buf, r)
+}
+
+// 代替フォントを含めてrを描けるか
+func (fm *FontManager) HasGlyph(fontID string, r rune) bool {
+	return fm.FontFor(fontID, r) != ""
+}
+
+// rを描くのに使われるフォントのID（どれにもなければ空）
+func (fm *FontManager) FontFor(fontID string, r rune) string {
+	for _, id := range fm.chain(fontID) {
+		if fm.covers(id, r) {
+			return id
+		}
+	}
+	return ""
+}
+
+// 代替フォントを含めても描けない文字（重複なし、制御文字と空白は除く）
+func (fm *FontManager) MissingGlyphs(fontID string, text string) []rune {
+	var missing []rune
+	seen := make(map[rune]bool)
+	for _, r := range text {
+		if seen[r] || unicode.IsControl(r) || unicode.IsSpace(r) {
+			continue
+		}
+		seen[r] = true
+		if !fm.HasGlyph(fontID, r) {
+			missing = append(missing, r)
+		}
+	}
+	return missing
+}
@@ -11,7 +11,7 @@ import (
 	"golang.org/x/image/font/sfnt"
 )
 
-//go:embed assets\mplus1p.ttf
+//go:embed assets/mplus1p.ttf
 var fontFS embed.FS
 
 // フォントサイズのプリセット
@@ -29,13 +29,19 @@ const (
 	StyleRegular FontStyle = iota
 	StyleBold
 	StyleItalic
+	StyleBoldItalic = StyleBold | StyleItalic
 )
 
 // フォントマネージャー
 type FontManager struct {
 	fonts     map[string]*sfnt.Font
+	bitmaps   map[string]*BitmapFont
 	faces     map[fontKey]font.Face
+	baseFaces map[fontKey]font.Face // 代替・スタイルを適用する前のフェイス
+	fallbacks map[string][]string
+	embedded  []string // 最後の代替として使う組み込みフォント
 	defaultID string
+	buf       sfnt.Buffer
 }
 
 // フォントフェイスのキャッシュキー
@@ -47,8 +53,11 @@ type fontKey struct {
 
 func NewFontManager() (*FontManager, error) {
 	fm := &FontManager{
-		fonts: make(map[string]*sfnt.Font),
-		faces: make(map[fontKey]font.Face),
+		fonts:     make(map[string]*sfnt.Font),
+		bitmaps:   make(map[string]*BitmapFont),
+		faces:     make(map[fontKey]font.Face),
+		baseFaces: make(map[fontKey]font.Face),
+		fallbacks: make(map[string][]string),
 	}
 
 	// 組み込みフォントの読み込み
@@ -82,6 +91,7 @@ func (fm *FontManager) loadEmbeddedFonts() error {
 
 		fontID := entry.Name()[:len(entry.Name())-4] // 拡張子を除去
 		fm.fonts[fontID] = font
+		fm.embedded = append(fm.embedded, fontID)
 
 		// 最初に読み込んだフォントをデフォルトとして設定
 		if fm.defaultID == "" {
@@ -92,10 +102,68 @@ func (fm *FontManager) loadEmbeddedFonts() error {
 	return nil
 }
 
+// フォントデータ（TTF/OTF）をIDで登録する
+func (fm *FontManager) RegisterFont(fontID string, data []byte) error {
+	font, err := opentype.Parse(data)
+	if err != nil {
+		return fmt.Errorf("failed to parse font %s: %v", fontID, err)
+	}
+	delete(fm.bitmaps, fontID)
+	fm.fonts[fontID] = font
+	fm.forget(fontID)
+	return nil
+}
+
+// ビットマップフォント（BMFont・グリッド）をIDで登録する
+func (fm *FontManager) RegisterBitmapFont(fontID string, font *BitmapFont) {
+	delete(fm.fonts, fontID)
+	fm.bitmaps[fontID] = font
+	fm.forget(fontID)
+}
+
+// 登録し直したフォントのフェイスを捨てる
+func (fm *FontManager) forget(fontID string) {
+	for key := range fm.baseFaces {
+		if key.fontID == fontID {
+			delete(fm.baseFaces, key)
+		}
+	}
+	fm.clearFaces()
+}
+
+// IDを指定しない場合に使うフォント
+func (fm *FontManager) SetDefault(fontID string) error {
+	if !fm.HasFont(fontID) {
+		return fmt.Errorf("font not found: %s", fontID)
+	}
+	fm.defaultID = fontID
+	fm.clearFaces()
+	return nil
+}
+
+func (fm *FontManager) DefaultID() string {
+	return fm.defaultID
+}
+
+func (fm *FontManager) HasFont(fontID string) bool {
+	_, outline := fm.fonts[fontID]
+	_, bitmap := fm.bitmaps[fontID]
+	return outline || bitmap
+}
+
+// fontIDにない文字を探すフォントを順に設定する（例: 日本語 → 英字 → 絵文字・記号）
+// 組み込みフォントは常に最後に探す
+func (fm *FontManager) SetFallback(fontID string, fallbacks ...string) {
+	fm.fallbacks[fontID] = append([]string(nil), fallbacks...)
+	fm.clearFaces()
+}
+
 func (fm *FontManager) GetFace(size float64, style FontStyle) (font.Face, error) {
 	return fm.GetFontFace(fm.defaultID, size, style)
 }
 
+// 未登録のIDはデフォルトのフォントで代用する
+// 文字がないフォントは代替フォントの順に探し、太字・斜体はフォントから合成する
 func (fm *FontManager) GetFontFace(fontID string, size float64, style FontStyle) (font.Face, error) {
 	key := fontKey{fontID, size, style}
 
@@ -104,26 +172,84 @@ func (fm *FontManager) GetFontFace(fontID string, size float64, style FontStyle)
 		return face, nil
 	}
 
-	// フォントの取得
-	font, exists := fm.fonts[fontID]
-	if !exists {
+	chain := fm.chain(fontID)
+	if len(chain) == 0 {
 		return nil, fmt.Errorf("font not found: %s", fontID)
 	}
 
+	faces := make([]font.Face, 0, len(chain))
+	for _, id := range chain {
+		face, err := fm.baseFace(id, size)
+		if err != nil {
+			return nil, err
+		}
+		faces = append(faces, face)
+	}
+
+	var face font.Face = faces[0]
+	if len(faces) > 1 {
+		face = newFallbackFace(faces, chain, fm.covers)
+	}
+	face = newSyntheticFace(face, size, style)
+
+	// キャッシュに保存
+	fm.faces[key] = face
+	return face, nil
+}
+
+// 代替を含めて文字を探すフォントの順番
+func (fm *FontManager) chain(fontID string) []string {
+	if !fm.HasFont(fontID) {
+		fontID = fm.defaultID
+	}
+	var chain []string
+	seen := make(map[string]bool)
+	add := func(id string) {
+		if fm.HasFont(id) && !seen[id] {
+			seen[id] = true
+			chain = append(chain, id)
+		}
+	}
+	add(fontID)
+	for _, id := range fm.fallbacks[fontID] {
+		add(id)
+	}
+	for _, id := range fm.embedded {
+		add(id)
+	}
+	return chain
+}
+
+func (fm *FontManager) baseFace(fontID string, size float64) (font.Face, error) {
+	key := fontKey{fontID, size, StyleRegular}
+	if face, exists := fm.baseFaces[key]; exists {
+		return face, nil
+	}
+
+	// ビットマップフォントは整数倍に拡大する
+	if bitmap, exists := fm.bitmaps[fontID]; exists {
+		face := bitmap.Face(size)
+		fm.baseFaces[key] = face
+		return face, nil
+	}
+
 	// フォントフェイスの生成
-	face, err := opentype.NewFace(font, &opentype.FaceOptions{
+	face, err := opentype.NewFace(fm.fonts[fontID], &opentype.FaceOptions{
 		Size: size,
 		DPI:  72,
 	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to create font face: %v", err)
 	}
-
-	// キャッシュに保存
-	fm.faces[key] = face
+	fm.baseFaces[key] = face
 	return face, nil
 }
 
+// 登録や代替の変更で作り直す
+func (fm *FontManager) clearFaces() {
+	fm.faces = make(map[fontKey]font.Face)
+}
+
 // テキスト描画用のヘルパー関数
 type TextStyle struct {
 	FontID      string
@@ -0,0 +1,112 @@
+package font
+
+import (
+	"image"
+	"math"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/math/fixed"
+)
+
+// 斜体の傾き（高さに対する横のずれ）
+const italicSlant = 0.2
+
+// 太字・斜体のフォントがない場合に、通常のグリフを太らせたり傾けたりして描くフェイス
+type syntheticFace struct {
+	font.Face
+	bold  int     // 横に重ねる幅（ピクセル）
+	slant float64 // 斜体の傾き
+	mask  *image.Alpha
+}
+
+// StyleRegularならそのまま返す
+func newSyntheticFace(face font.Face, size float64, style FontStyle) font.Face {
+	if style == StyleRegular {
+		return face
+	}
+	f := &syntheticFace{Face: face}
+	if style&StyleBold != 0 {
+		// 18pxで1ピクセル程度
+		f.bold = int(math.Max(1, math.Round(size/18)))
+	}
+	if style&StyleItalic != 0 {
+		f.slant = italicSlant
+	}
+	return f
+}
+
+func (f *syntheticFace) GlyphBounds(r rune) (fixed.Rectangle26_6, fixed.Int26_6, bool) {
+	bounds, advance, ok := f.Face.GlyphBounds(r)
+	if !ok {
+		return bounds, advance, ok
+	}
+	bounds.Max.X += fixed.I(f.bold)
+	advance += fixed.I(f.bold)
+	if f.slant != 0 {
+		// 上端ほど右、下端ほど左にずれる
+		bounds.Min.X -= fixed.Int26_6(float64(bounds.Max.Y) * f.slant)
+		bounds.Max.X -= fixed.Int26_6(float64(bounds.Min.Y) * f.slant)
+		bounds.Min.X = fixed.I(bounds.Min.X.Floor())
+		bounds.Max.X = fixed.I(bounds.Max.X.Ceil() + 1) // 按分したはみ出し
+	}
+	return bounds, advance, ok
+}
+
+func (f *syntheticFace) GlyphAdvance(r rune) (fixed.Int26_6, bool) {
+	advance, ok := f.Face.GlyphAdvance(r)
+	return advance + fixed.I(f.bold), ok
+}
+
+func (f *syntheticFace) Glyph(dot fixed.Point26_6, r rune) (image.Rectangle, image.Image, image.Point, fixed.Int26_6, bool) {
+	dr, mask, maskp, advance, ok := f.Face.Glyph(dot, r)
+	if !ok || dr.Empty() {
+		return dr, mask, maskp, advance + fixed.I(f.bold), ok
+	}
+
+	// ベースラインからの高さでずらす量（ピクセルの中心で測る）
+	baseline := float64(dot.Y) / 64
+	shift := func(y int) float64 {
+		return (baseline - (float64(y) + 0.5)) * f.slant
+	}
+	left := int(math.Floor(math.Min(shift(dr.Min.Y), shift(dr.Max.Y-1))))
+	right := int(math.Ceil(math.Max(shift(dr.Min.Y), shift(dr.Max.Y-1)))) + f.bold + 1
+
+	out := image.Rect(dr.Min.X+left, dr.Min.Y, dr.Max.X+right, dr.Max.Y)
+	if f.mask == nil || f.mask.Rect != out {
+		f.mask = image.NewAlpha(out)
+	} else {
+		for i := range f.mask.Pix {
+			f.mask.Pix[i] = 0
+		}
+	}
+
+	for y := dr.Min.Y; y < dr.Max.Y; y++ {
+		s := shift(y)
+		whole := int(math.Floor(s))
+		frac := s - float64(whole)
+		for x := dr.Min.X; x < dr.Max.X; x++ {
+			_, _, _, a := mask.At(maskp.X+x-dr.Min.X, maskp.Y+y-dr.Min.Y).RGBA()
+			if a == 0 {
+				continue
+			}
+			v := float64(a >> 8)
+			// 斜体は隣のピクセルに按分し、太字は横に重ねる
+			for k := 0; k <= f.bold; k++ {
+				f.blend(x+whole+k, y, v*(1-frac))
+				f.blend(x+whole+k+1, y, v*frac)
+			}
+		}
+	}
+	return out, f.mask, out.Min, advance + fixed.I(f.bold), ok
+}
+
+// 濃い方を残す
+func (f *syntheticFace) blend(x, y int, v float64) {
+	if !(image.Point{x, y}).In(f.mask.Rect) {
+		return
+	}
+	i := f.mask.PixOffset(x, y)
+	if a := uint8(math.Min(255, math.Round(v))); a > f.mask.Pix[i] {
+		f.mask.Pix[i] = a
+	}
+}
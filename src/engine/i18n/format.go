@@ -0,0 +1,55 @@
+package i18n
+
+import (
+	"fmt"
+	"strings"
+)
+
+// {name}をargsの値で置き換える（{{ と }} は括弧そのもの、argsにない名前はそのまま残す）
+func Format(text string, args Args) string {
+	if !strings.ContainsAny(text, "{}") {
+		return text
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(text); i++ {
+		c := text[i]
+		switch {
+		case c == '{' && i+1 < len(text) && text[i+1] == '{':
+			b.WriteByte('{')
+			i++
+		case c == '}' && i+1 < len(text) && text[i+1] == '}':
+			b.WriteByte('}')
+			i++
+		case c == '{':
+			end := strings.IndexByte(text[i:], '}')
+			if end < 0 {
+				b.WriteString(text[i:])
+				return b.String()
+			}
+			name := text[i+1 : i+end]
+			if v, ok := args[name]; ok {
+				b.WriteString(fmt.Sprint(v))
+			} else {
+				b.WriteString(text[i : i+end+1])
+			}
+			i += end
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return b.String()
+}
+
+// 複数形の選択に使う"count"の値
+func countOf(args Args) (float64, bool) {
+	switch v := args["count"].(type) {
+	case int:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case float64:
+		return v, true
+	}
+	return 0, false
+}
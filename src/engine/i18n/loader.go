@@ -0,0 +1,229 @@
+package i18n
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+var pluralCategories = map[string]bool{
+	"zero": true, "one": true, "two": true, "few": true, "many": true, "other": true,
+}
+
+// JSONの文字列テーブルを読み込む
+// 入れ子のオブジェクトは「.」でつないだキーになり、複数形の種類だけを持つオブジェクトは複数形として扱う
+//
+//	{"menu": {"start": "ゲーム開始"}, "items": {"one": "{count} item", "other": "{count} items"}}
+func (l *Localizer) LoadJSON(lang string, data []byte) error {
+	var table map[string]interface{}
+	if err := json.Unmarshal(data, &table); err != nil {
+		return fmt.Errorf("invalid string table (%s): %v", lang, err)
+	}
+	return l.addJSON(lang, "", table)
+}
+
+func (l *Localizer) addJSON(lang, prefix string, table map[string]interface{}) error {
+	for name, value := range table {
+		key := prefix + name
+		switch v := value.(type) {
+		case string:
+			l.AddString(lang, key, v)
+		case map[string]interface{}:
+			if forms, ok := pluralJSON(v); ok {
+				if err := l.AddPlural(lang, key, forms); err != nil {
+					return err
+				}
+				continue
+			}
+			if err := l.addJSON(lang, key+".", v); err != nil {
+				return err
+			}
+		default:
+			return fmt.Errorf("string table (%s): %s must be string or object", lang, key)
+		}
+	}
+	return nil
+}
+
+// 複数形の種類だけを持つオブジェクトか
+func pluralJSON(obj map[string]interface{}) (map[string]string, bool) {
+	if len(obj) == 0 {
+		return nil, false
+	}
+	forms := make(map[string]string, len(obj))
+	for category, value := range obj {
+		s, ok := value.(string)
+		if !ok || !pluralCategories[category] {
+			return nil, false
+		}
+		forms[category] = s
+	}
+	return forms, true
+}
+
+// CSVの文字列テーブルを読み込む（1行目は "key" と言語コード、空欄は未翻訳）
+// 複数形は "items#one"、"items#other" のようにキーの後に種類を付ける
+func (l *Localizer) LoadCSV(data []byte) error {
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.FieldsPerRecord = -1
+	records, err := reader.ReadAll()
+	if err != nil {
+		return fmt.Errorf("invalid string table: %v", err)
+	}
+	if len(records) == 0 {
+		return nil
+	}
+	header := records[0]
+	if len(header) < 2 || strings.TrimSpace(strings.TrimPrefix(header[0], "\ufeff")) != "key" {
+		return fmt.Errorf("string table header must start with \"key\"")
+	}
+
+	plurals := make(map[[2]string]map[string]string)
+	for _, record := range records[1:] {
+		if len(record) == 0 || strings.TrimSpace(record[0]) == "" {
+			continue
+		}
+		key, category, isPlural := strings.Cut(strings.TrimSpace(record[0]), "#")
+		for i := 1; i < len(record) && i < len(header); i++ {
+			lang := strings.TrimSpace(header[i])
+			if record[i] == "" {
+				continue
+			}
+			if !isPlural {
+				l.AddString(lang, key, record[i])
+				continue
+			}
+			if !pluralCategories[category] {
+				return fmt.Errorf("unknown plural category: %s", record[0])
+			}
+			id := [2]string{lang, key}
+			if plurals[id] == nil {
+				plurals[id] = make(map[string]string)
+			}
+			plurals[id][category] = record[i]
+		}
+	}
+	for id, forms := range plurals {
+		if err := l.AddPlural(id[0], id[1], forms); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// gettextのPOファイルを読み込む
+// キーはmsgctxtがあればmsgctxt、なければmsgid。fuzzyと未翻訳の項目は飛ばす
+func (l *Localizer) LoadPO(lang string, data []byte) error {
+	var (
+		entry   poEntry
+		current *string // 続きの行を足す文字列
+		entries []poEntry
+	)
+	flush := func() {
+		if entry.msgid != "" || entry.msgctxt != "" {
+			entries = append(entries, entry)
+		}
+		entry = poEntry{}
+		current = nil
+	}
+
+	for n, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		switch {
+		case line == "":
+			flush()
+			continue
+		case strings.HasPrefix(line, "#,"):
+			entry.fuzzy = strings.Contains(line, "fuzzy")
+			continue
+		case strings.HasPrefix(line, "#"):
+			continue
+		case strings.HasPrefix(line, `"`):
+			if current == nil {
+				return fmt.Errorf("po line %d: unexpected string", n+1)
+			}
+			s, err := strconv.Unquote(line)
+			if err != nil {
+				return fmt.Errorf("po line %d: %v", n+1, err)
+			}
+			*current += s
+			continue
+		}
+
+		keyword, rest, _ := strings.Cut(line, " ")
+		s, err := strconv.Unquote(strings.TrimSpace(rest))
+		if err != nil {
+			return fmt.Errorf("po line %d: %v", n+1, err)
+		}
+		switch {
+		case keyword == "msgctxt":
+			if entry.msgid != "" {
+				flush()
+			}
+			entry.msgctxt = s
+			current = &entry.msgctxt
+		case keyword == "msgid":
+			if entry.msgid != "" {
+				flush()
+			}
+			entry.msgid = s
+			current = &entry.msgid
+		case keyword == "msgid_plural":
+			entry.plural = true
+			current = nil
+		case keyword == "msgstr":
+			entry.msgstr = []string{s}
+			current = &entry.msgstr[0]
+		case strings.HasPrefix(keyword, "msgstr["):
+			index, err := strconv.Atoi(strings.TrimSuffix(strings.TrimPrefix(keyword, "msgstr["), "]"))
+			if err != nil || index < 0 {
+				return fmt.Errorf("po line %d: invalid %s", n+1, keyword)
+			}
+			for len(entry.msgstr) <= index {
+				entry.msgstr = append(entry.msgstr, "")
+			}
+			entry.msgstr[index] = s
+			current = &entry.msgstr[index]
+		default:
+			return fmt.Errorf("po line %d: unknown keyword %s", n+1, keyword)
+		}
+	}
+	flush()
+
+	forms := PluralForms(lang)
+	for _, e := range entries {
+		key := e.msgid
+		if e.msgctxt != "" {
+			key = e.msgctxt
+		}
+		if e.fuzzy || key == "" || len(e.msgstr) == 0 || e.msgstr[0] == "" {
+			continue
+		}
+		if !e.plural {
+			l.AddString(lang, key, e.msgstr[0])
+			continue
+		}
+		plural := make(map[string]string)
+		for i, s := range e.msgstr {
+			if i < len(forms) && s != "" {
+				plural[forms[i]] = s
+			}
+		}
+		if _, ok := plural["other"]; !ok {
+			plural["other"] = e.msgstr[len(e.msgstr)-1] // "other"がない言語は最後の形で代用
+		}
+		l.AddEntry(lang, key, Entry{Forms: plural})
+	}
+	return nil
+}
+
+type poEntry struct {
+	msgctxt string
+	msgid   string
+	plural  bool
+	msgstr  []string
+	fuzzy   bool
+}
@@ -0,0 +1,215 @@
+package i18n
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// プレースホルダー（{name}）に入れる値
+// "count"は複数形の選択にも使う
+type Args map[string]interface{}
+
+// 1つのキーの訳（複数形がなければOtherだけ）
+type Entry struct {
+	Forms map[string]string // 複数形の種類（"zero"、"one"、"two"、"few"、"many"、"other"）ごとの文
+}
+
+// 見つからなかったキー
+type MissingKey struct {
+	Lang  string
+	Key   string
+	Count int // 参照された回数
+}
+
+// 言語ごとの文字列テーブルと現在の言語
+type Localizer struct {
+	mutex     sync.RWMutex
+	lang      string
+	fallback  string // 現在の言語にないキーを探す言語
+	tables    map[string]map[string]Entry
+	listeners []func(lang string)
+
+	reportMissing bool
+	missing       map[[2]string]int
+}
+
+// 既定のLocalizer（Goのコードからはi18n.Trで使う）
+var Default = NewLocalizer("ja")
+
+func NewLocalizer(lang string) *Localizer {
+	return &Localizer{
+		lang:     lang,
+		fallback: lang,
+		tables:   make(map[string]map[string]Entry),
+		missing:  make(map[[2]string]int),
+	}
+}
+
+func (l *Localizer) Language() string {
+	l.mutex.RLock()
+	defer l.mutex.RUnlock()
+	return l.lang
+}
+
+// 言語を切り替え、変更を通知する
+func (l *Localizer) SetLanguage(lang string) {
+	l.mutex.Lock()
+	changed := l.lang != lang
+	l.lang = lang
+	listeners := append([]func(string){}, l.listeners...)
+	l.mutex.Unlock()
+
+	if changed {
+		for _, fn := range listeners {
+			fn(lang)
+		}
+	}
+}
+
+// 現在の言語にないキーを探す言語
+func (l *Localizer) SetFallback(lang string) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	l.fallback = lang
+}
+
+// テーブルのある言語
+func (l *Localizer) Languages() []string {
+	l.mutex.RLock()
+	defer l.mutex.RUnlock()
+	langs := make([]string, 0, len(l.tables))
+	for lang := range l.tables {
+		langs = append(langs, lang)
+	}
+	sort.Strings(langs)
+	return langs
+}
+
+// 言語が切り替わったときに呼ぶ関数を登録する（テキストの再配置など）
+func (l *Localizer) OnLanguageChange(fn func(lang string)) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	l.listeners = append(l.listeners, fn)
+}
+
+func (l *Localizer) AddString(lang, key, text string) {
+	l.AddEntry(lang, key, Entry{Forms: map[string]string{"other": text}})
+}
+
+// 複数形の種類ごとの文を登録する（"other"は必須）
+func (l *Localizer) AddPlural(lang, key string, forms map[string]string) error {
+	if _, ok := forms["other"]; !ok {
+		return fmt.Errorf("plural %s (%s) has no \"other\" form", key, lang)
+	}
+	l.AddEntry(lang, key, Entry{Forms: forms})
+	return nil
+}
+
+func (l *Localizer) AddEntry(lang, key string, entry Entry) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	table, ok := l.tables[lang]
+	if !ok {
+		table = make(map[string]Entry)
+		l.tables[lang] = table
+	}
+	table[key] = entry
+}
+
+// 現在の言語（または代わりの言語）にキーがあるか
+func (l *Localizer) Has(key string) bool {
+	l.mutex.RLock()
+	defer l.mutex.RUnlock()
+	_, _, ok := l.lookup(key)
+	return ok
+}
+
+// キーの訳にプレースホルダーを埋めて返す
+// 見つからなければキーをそのまま返す（見つからないキーの記録中は「!キー!」）
+func (l *Localizer) Tr(key string, args ...Args) string {
+	var merged Args
+	if len(args) == 1 {
+		merged = args[0]
+	} else if len(args) > 1 {
+		merged = make(Args)
+		for _, a := range args {
+			for k, v := range a {
+				merged[k] = v
+			}
+		}
+	}
+
+	l.mutex.RLock()
+	entry, lang, ok := l.lookup(key)
+	report := l.reportMissing
+	current := l.lang
+	l.mutex.RUnlock()
+
+	if !ok {
+		if report {
+			l.recordMissing(current, key)
+			return "!" + key + "!"
+		}
+		return key
+	}
+	if report && lang != current {
+		l.recordMissing(current, key) // 代わりの言語で表示したものも翻訳漏れ
+	}
+
+	text := entry.Forms["other"]
+	if count, ok := countOf(merged); ok {
+		if form, ok := entry.Forms[PluralCategory(lang, count)]; ok {
+			text = form
+		}
+	}
+	return Format(text, merged)
+}
+
+func (l *Localizer) lookup(key string) (Entry, string, bool) {
+	for _, lang := range []string{l.lang, l.fallback} {
+		if entry, ok := l.tables[lang][key]; ok {
+			return entry, lang, true
+		}
+	}
+	return Entry{}, "", false
+}
+
+// 見つからないキーを記録し、画面上で分かるようにする（翻訳作業用）
+func (l *Localizer) SetReportMissing(enabled bool) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	l.reportMissing = enabled
+}
+
+func (l *Localizer) recordMissing(lang, key string) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	id := [2]string{lang, key}
+	if l.missing[id] == 0 {
+		fmt.Printf("Missing translation: %s (%s)\n", key, lang)
+	}
+	l.missing[id]++
+}
+
+// 記録した見つからないキー（言語・キー順）
+func (l *Localizer) MissingKeys() []MissingKey {
+	l.mutex.RLock()
+	defer l.mutex.RUnlock()
+	keys := make([]MissingKey, 0, len(l.missing))
+	for id, count := range l.missing {
+		keys = append(keys, MissingKey{Lang: id[0], Key: id[1], Count: count})
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].Lang != keys[j].Lang {
+			return keys[i].Lang < keys[j].Lang
+		}
+		return keys[i].Key < keys[j].Key
+	})
+	return keys
+}
+
+// 既定のLocalizerで訳す
+func Tr(key string, args ...Args) string {
+	return Default.Tr(key, args...)
+}
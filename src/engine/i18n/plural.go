@@ -0,0 +1,80 @@
+package i18n
+
+import (
+	"math"
+	"strings"
+)
+
+// 言語ごとの複数形の種類（POファイルのmsgstr[n]の順番）
+func PluralForms(lang string) []string {
+	switch baseLanguage(lang) {
+	case "ja", "zh", "ko", "th", "vi", "id", "ms":
+		return []string{"other"}
+	case "ru", "uk", "be", "pl", "hr", "sr":
+		return []string{"one", "few", "many"}
+	case "cs", "sk":
+		return []string{"one", "few", "other"}
+	}
+	return []string{"one", "other"}
+}
+
+// nに使う複数形の種類（CLDRの規則を整数向けに簡略化したもの）
+// POファイルの形に合わせるため、PluralFormsにない種類は返さない（小数はPOファイルの最後の形）
+func PluralCategory(lang string, n float64) string {
+	integer := n == math.Trunc(n)
+	i := int64(math.Abs(n))
+	switch baseLanguage(lang) {
+	case "ja", "zh", "ko", "th", "vi", "id", "ms":
+		return "other"
+	case "fr", "pt":
+		if n >= 0 && n < 2 {
+			return "one"
+		}
+		return "other"
+	case "ru", "uk", "be", "hr", "sr":
+		if !integer {
+			return "many"
+		}
+		switch {
+		case i%10 == 1 && i%100 != 11:
+			return "one"
+		case i%10 >= 2 && i%10 <= 4 && (i%100 < 12 || i%100 > 14):
+			return "few"
+		}
+		return "many"
+	case "pl":
+		if !integer {
+			return "many"
+		}
+		switch {
+		case i == 1:
+			return "one"
+		case i%10 >= 2 && i%10 <= 4 && (i%100 < 12 || i%100 > 14):
+			return "few"
+		}
+		return "many"
+	case "cs", "sk":
+		switch {
+		case !integer:
+			return "other"
+		case i == 1:
+			return "one"
+		case i >= 2 && i <= 4:
+			return "few"
+		}
+		return "other"
+	}
+	if integer && i == 1 {
+		return "one"
+	}
+	return "other"
+}
+
+// "en-US"、"pt_BR"などの言語部分
+func baseLanguage(lang string) string {
+	lang = strings.ToLower(lang)
+	if i := strings.IndexAny(lang, "-_"); i >= 0 {
+		return lang[:i]
+	}
+	return lang
+}
@@ -0,0 +1,211 @@
+package input
+
+import (
+	"math"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// 軸アクション（移動の向きや量のように連続した値を返すアクション）
+const (
+	AxisMove Action = "move" // 矢印キー・WASD・十字キー・左スティック（2軸）
+	AxisLook Action = "look" // 右スティック（2軸）
+)
+
+// 軸の入力元の種類
+type AxisSourceType int
+
+const (
+	ButtonAxis     AxisSourceType = iota // 負・正の向きのボタン（押すと-1か1）
+	StickAxis                            // ゲームパッドのスティック
+	TriggerAxis                          // ゲームパッドのトリガー（0〜1、1軸）
+	MouseDeltaAxis                       // マウスの移動量（ゲーム内座標のピクセル×Scale）
+	WheelAxis                            // ホイールの回転量×Scale（1軸）
+)
+
+// 軸アクションの入力元
+type AxisSource struct {
+	Type AxisSourceType
+
+	// ButtonAxis: 横軸の負（左）・正（右）、縦軸の負（上）・正（下）の向きのボタン
+	// 縦軸のボタンがなければ1軸
+	Negative  []InputBinding
+	Positive  []InputBinding
+	NegativeY []InputBinding
+	PositiveY []InputBinding
+
+	Stick   Stick
+	Trigger Trigger
+	Player  int     // ゲームパッドのプレイヤー番号（0ならつながっているすべてのパッド）
+	Scale   float64 // MouseDeltaAxis・WheelAxisの倍率（0なら1）
+}
+
+// 2つのキーで-1か1になる1軸
+func KeyAxis(negative, positive ebiten.Key) AxisSource {
+	return AxisSource{Type: ButtonAxis, Negative: []InputBinding{KeyBinding(negative)}, Positive: []InputBinding{KeyBinding(positive)}}
+}
+
+// 上下左右のボタンで向きが決まる2軸（斜めは長さ1にする）
+func DirectionAxis(up, down, left, right InputBinding) AxisSource {
+	return AxisSource{
+		Type:      ButtonAxis,
+		Negative:  []InputBinding{left},
+		Positive:  []InputBinding{right},
+		NegativeY: []InputBinding{up},
+		PositiveY: []InputBinding{down},
+	}
+}
+
+func StickSource(stick Stick) AxisSource {
+	return AxisSource{Type: StickAxis, Stick: stick}
+}
+
+func TriggerSource(trigger Trigger) AxisSource {
+	return AxisSource{Type: TriggerAxis, Trigger: trigger}
+}
+
+func MouseDeltaSource(scale float64) AxisSource {
+	return AxisSource{Type: MouseDeltaAxis, Scale: scale}
+}
+
+func WheelSource(scale float64) AxisSource {
+	return AxisSource{Type: WheelAxis, Scale: scale}
+}
+
+// 2軸の値を返すか
+func (s AxisSource) is2D() bool {
+	switch s.Type {
+	case ButtonAxis:
+		return len(s.NegativeY) > 0 || len(s.PositiveY) > 0
+	case StickAxis, MouseDeltaAxis:
+		return true
+	}
+	return false
+}
+
+func (s AxisSource) scale() float64 {
+	if s.Scale == 0 {
+		return 1
+	}
+	return s.Scale
+}
+
+func (im *InputManager) setDefaultAxes() {
+	pad := func(button ebiten.StandardGamepadButton) InputBinding { return PadBinding(button) }
+	im.BindAxis(AxisMove, DirectionAxis(KeyBinding(ebiten.KeyUp), KeyBinding(ebiten.KeyDown), KeyBinding(ebiten.KeyLeft), KeyBinding(ebiten.KeyRight)))
+	im.BindAxis(AxisMove, DirectionAxis(KeyBinding(ebiten.KeyW), KeyBinding(ebiten.KeyS), KeyBinding(ebiten.KeyA), KeyBinding(ebiten.KeyD)))
+	im.BindAxis(AxisMove, DirectionAxis(pad(ebiten.StandardGamepadButtonLeftTop), pad(ebiten.StandardGamepadButtonLeftBottom), pad(ebiten.StandardGamepadButtonLeftLeft), pad(ebiten.StandardGamepadButtonLeftRight)))
+	im.BindAxis(AxisMove, StickSource(LeftStick))
+	im.BindAxis(AxisLook, StickSource(RightStick))
+}
+
+// 軸アクションに入力元を追加する
+// 入力元が複数あれば、一番大きく倒されているものの値になる
+func (im *InputManager) BindAxis(action Action, source AxisSource) {
+	im.mutex.Lock()
+	defer im.mutex.Unlock()
+	im.axisBindings[action] = append(im.axisBindings[action], source)
+}
+
+// 軸アクションの入力元をすべて外す
+func (im *InputManager) UnbindAxis(action Action) {
+	im.mutex.Lock()
+	defer im.mutex.Unlock()
+	delete(im.axisBindings, action)
+	delete(im.axes, action)
+}
+
+// 軸アクションが2軸か（登録されていなければfalse）
+func (im *InputManager) IsAxis2D(action Action) bool {
+	im.mutex.RLock()
+	defer im.mutex.RUnlock()
+	for _, source := range im.axisBindings[action] {
+		if source.is2D() {
+			return true
+		}
+	}
+	return false
+}
+
+// 軸アクションの横軸の値（1軸ならその値）
+// 軸を扱う一番上のコンテキストまで届いていなければ0
+func (im *InputManager) Axis(action Action) float64 {
+	return im.route(action).Axis(action)
+}
+
+// 2軸の軸アクションの値（右・下が正）
+func (im *InputManager) Axis2D(action Action) (x, y float64) {
+	return im.route(action).Axis2D(action)
+}
+
+// 軸アクションの値を更新する（ロックした状態で呼ぶ）
+func (im *InputManager) updateAxes() {
+	for action, sources := range im.axisBindings {
+		if im.listening != nil {
+			im.axes[action] = [2]float64{}
+			continue
+		}
+		var best [2]float64
+		for _, source := range sources {
+			if v := im.axisValue(source); math.Hypot(v[0], v[1]) > math.Hypot(best[0], best[1]) {
+				best = v
+			}
+		}
+		im.axes[action] = best
+	}
+}
+
+func (im *InputManager) axisValue(source AxisSource) [2]float64 {
+	switch source.Type {
+	case ButtonAxis:
+		x := im.buttonDirection(source.Negative, source.Positive)
+		y := im.buttonDirection(source.NegativeY, source.PositiveY)
+		if x != 0 && y != 0 {
+			x, y = x/math.Sqrt2, y/math.Sqrt2
+		}
+		return [2]float64{x, y}
+	case StickAxis:
+		var v [2]float64
+		for _, id := range im.gamepads.devices(source.Player) {
+			if !ebiten.IsStandardGamepadLayoutAvailable(id) {
+				continue
+			}
+			if x, y := im.gamepads.stick(id, source.Stick); math.Hypot(x, y) > math.Hypot(v[0], v[1]) {
+				v = [2]float64{x, y}
+			}
+		}
+		return v
+	case TriggerAxis:
+		value := 0.0
+		for _, id := range im.gamepads.devices(source.Player) {
+			if ebiten.IsStandardGamepadLayoutAvailable(id) {
+				value = math.Max(value, im.gamepads.trigger(id, source.Trigger))
+			}
+		}
+		return [2]float64{value, 0}
+	case MouseDeltaAxis:
+		k := source.scale()
+		return [2]float64{float64(im.mouse.X-im.mouse.PrevX) * k, float64(im.mouse.Y-im.mouse.PrevY) * k}
+	case WheelAxis:
+		return [2]float64{im.mouse.ScrollY * source.scale(), 0}
+	}
+	return [2]float64{}
+}
+
+// 負の向きのボタンで-1、正の向きで1、両方か押されていなければ0
+func (im *InputManager) buttonDirection(negative, positive []InputBinding) float64 {
+	value := 0.0
+	for _, binding := range negative {
+		if im.isBindingActive(binding) {
+			value--
+			break
+		}
+	}
+	for _, binding := range positive {
+		if im.isBindingActive(binding) {
+			value++
+			break
+		}
+	}
+	return value
+}
@@ -0,0 +1,388 @@
+package input
+
+import (
+	"sort"
+	"time"
+)
+
+// 方向（上下左右のアクションから決まる8方向と中立）
+// コマンドの手順に方向の名前を書くと、その向きに入れ直した時だけ一致する（"down"は真下だけで、右下では一致しない）
+const (
+	DirectionNeutral   Action = "neutral"
+	DirectionUp        Action = "up"
+	DirectionDown      Action = "down"
+	DirectionLeft      Action = "left"
+	DirectionRight     Action = "right"
+	DirectionUpLeft    Action = "up_left"
+	DirectionUpRight   Action = "up_right"
+	DirectionDownLeft  Action = "down_left"
+	DirectionDownRight Action = "down_right"
+)
+
+// 横・縦の向き（-1、0、1）と方向
+var directions = [3][3]Action{
+	{DirectionUpLeft, DirectionUp, DirectionUpRight},
+	{DirectionLeft, DirectionNeutral, DirectionRight},
+	{DirectionDownLeft, DirectionDown, DirectionDownRight},
+}
+
+func isDirection(name Action) bool {
+	for _, row := range directions {
+		for _, d := range row {
+			if d == name {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// キーボードとマウスの入力を記録するプレイヤー
+const KeyboardPlayer = 1
+
+// プレイヤーごとに残す入力の数
+const inputBufferSize = 64
+
+// コマンドの受付時間の既定値
+const DefaultSequenceWindow = 500 * time.Millisecond
+
+// 入力の履歴の1件
+type InputEvent struct {
+	Action    Action // アクションか方向
+	Pressed   bool   // 押したか（falseなら離した。方向は入れた時だけ記録する）
+	Direction bool   // 方向を入れた
+	Player    int    // 0ならすべてのプレイヤーをまとめた履歴
+	Frame     int    // 記録したフレーム（Frame()と比べる）
+
+	consumed  bool // 先行入力として使った
+	sequenced bool // コマンドの最後の入力として使った
+}
+
+// 入力の履歴（古いものから上書きするリングバッファ）
+type inputBuffer struct {
+	events    [inputBufferSize]InputEvent
+	next      int // 次に書く位置
+	count     int
+	states    map[Action]bool
+	direction Action
+	matched   map[string]int // コマンドが最後に成立したフレーム（それまでの入力は次の判定に使わない）
+}
+
+func newInputBuffer() *inputBuffer {
+	return &inputBuffer{
+		states:    make(map[Action]bool),
+		direction: DirectionNeutral,
+		matched:   make(map[string]int),
+	}
+}
+
+func (b *inputBuffer) push(e InputEvent) {
+	b.events[b.next] = e
+	b.next = (b.next + 1) % inputBufferSize
+	if b.count < inputBufferSize {
+		b.count++
+	}
+}
+
+// 新しい順に渡す（fnがfalseを返すと止める）
+func (b *inputBuffer) each(fn func(e *InputEvent) bool) {
+	for i := 1; i <= b.count; i++ {
+		if !fn(&b.events[(b.next-i+inputBufferSize)%inputBufferSize]) {
+			return
+		}
+	}
+}
+
+// コマンド（波動拳の↓↘→+Aのように、決まった順に入れる入力）
+type InputSequence struct {
+	Name       string
+	Steps      []Action      // アクションか方向の名前
+	Window     time.Duration // 最初の入力から最後の入力までの時間の上限（0ならDefaultSequenceWindow）
+	StepWindow time.Duration // 入力と入力の間の時間の上限（0なら制限なし）
+	Player     int           // 0ならどのプレイヤーでも（プレイヤーごとに判定する）
+	OnMatch    func(player int)
+}
+
+// コマンドを登録する（同じ名前があれば置き換える）
+// 同じフレームに最後の入力が同じコマンドがいくつも成立したら、手順の長いものだけを成立とする
+func (im *InputManager) AddSequence(sequence InputSequence) {
+	im.mutex.Lock()
+	defer im.mutex.Unlock()
+	sequence.Steps = append([]Action{}, sequence.Steps...)
+	for i, s := range im.sequences {
+		if s.Name == sequence.Name {
+			im.sequences[i] = sequence
+			return
+		}
+	}
+	im.sequences = append(im.sequences, sequence)
+}
+
+func (im *InputManager) RemoveSequence(name string) {
+	im.mutex.Lock()
+	defer im.mutex.Unlock()
+	for i, s := range im.sequences {
+		if s.Name == name {
+			im.sequences = append(im.sequences[:i], im.sequences[i+1:]...)
+			break
+		}
+	}
+	delete(im.matchedSequences, name)
+}
+
+// このフレームでコマンドが成立したか（成立したプレイヤーを返す）
+func (im *InputManager) SequenceMatched(name string) (player int, ok bool) {
+	im.mutex.RLock()
+	defer im.mutex.RUnlock()
+	player, ok = im.matchedSequences[name]
+	return player, ok
+}
+
+// Updateを呼んだ回数
+func (im *InputManager) Frame() int {
+	im.mutex.RLock()
+	defer im.mutex.RUnlock()
+	return im.frame
+}
+
+// 記録してからの時間
+func (im *InputManager) Age(event InputEvent) time.Duration {
+	return framesToDuration(im.Frame() - event.Frame)
+}
+
+// within以内にアクションを押したか（先行入力。Consumeしたものと、ConsumeBufferedしたものは数えない）
+// playerが0ならすべてのプレイヤー
+func (im *InputManager) WasPressed(action Action, within time.Duration, player int) bool {
+	return im.findEvent(action, true, within, player)
+}
+
+// within以内にアクションを離したか
+func (im *InputManager) WasReleased(action Action, within time.Duration, player int) bool {
+	return im.findEvent(action, false, within, player)
+}
+
+func (im *InputManager) findEvent(action Action, pressed bool, within time.Duration, player int) bool {
+	im.mutex.RLock()
+	defer im.mutex.RUnlock()
+	b := im.buffers[player]
+	if b == nil {
+		return false
+	}
+	found := false
+	b.each(func(e *InputEvent) bool {
+		if framesToDuration(im.frame-e.Frame) > within {
+			return false
+		}
+		if !e.Direction && e.Action == action && e.Pressed == pressed && !e.consumed {
+			found = true
+			return false
+		}
+		return true
+	})
+	return found
+}
+
+// 記録されている押した入力を先行入力として使ったことにする（playerが0ならすべてのプレイヤー）
+func (im *InputManager) ConsumeBuffered(action Action, player int) {
+	im.mutex.Lock()
+	defer im.mutex.Unlock()
+	im.consumeBuffered(action, player)
+}
+
+func (im *InputManager) consumeBuffered(action Action, player int) {
+	for p, b := range im.buffers {
+		if player != 0 && p != player && p != 0 {
+			continue
+		}
+		b.each(func(e *InputEvent) bool {
+			if !e.Direction && e.Action == action && e.Pressed {
+				e.consumed = true
+			}
+			return true
+		})
+	}
+}
+
+// within以内の入力の履歴（古い順。playerが0ならすべてのプレイヤーをまとめたもの）
+func (im *InputManager) History(player int, within time.Duration) []InputEvent {
+	im.mutex.RLock()
+	defer im.mutex.RUnlock()
+	b := im.buffers[player]
+	if b == nil {
+		return nil
+	}
+	var events []InputEvent
+	b.each(func(e *InputEvent) bool {
+		if framesToDuration(im.frame-e.Frame) > within {
+			return false
+		}
+		events = append(events, *e)
+		return true
+	})
+	for i, j := 0, len(events)-1; i < j; i, j = i+1, j-1 {
+		events[i], events[j] = events[j], events[i]
+	}
+	return events
+}
+
+// ゲームプレイのコンテキストに届いた入力を記録し、コマンドを判定する（ロックした状態で呼ぶ）
+// 成立したコマンドのOnMatchを返す
+func (im *InputManager) updateBuffers() []func() {
+	im.frame++
+	players := im.gamepads.config.MaxPlayers
+	if players < KeyboardPlayer {
+		players = KeyboardPlayer
+	}
+	for player := 0; player <= players; player++ {
+		if im.buffers[player] == nil {
+			im.buffers[player] = newInputBuffer()
+		}
+		im.recordInputs(player, im.buffers[player])
+	}
+	return im.matchSequences(players)
+}
+
+func (im *InputManager) recordInputs(player int, b *inputBuffer) {
+	gameplay := im.contexts[0]
+	states := make(map[Action]bool)
+	var actions []Action
+	gameplay.eachBinding(im.bindings, func(action Action, bindings []InputBinding) {
+		pressed := gameplay.states[action]
+		if pressed && player != 0 {
+			pressed = false
+			for _, binding := range bindings {
+				if im.isBindingActiveFor(binding, player) {
+					pressed = true
+					break
+				}
+			}
+		}
+		states[action] = pressed
+		actions = append(actions, action)
+	})
+	sort.Slice(actions, func(i, j int) bool { return actions[i] < actions[j] })
+
+	// 方向を先に記録する（同じフレームに入れた方向とボタンは方向、ボタンの順になる）
+	x, y := 1, 1
+	if states[ActionLeft] {
+		x--
+	}
+	if states[ActionRight] {
+		x++
+	}
+	if states[ActionUp] {
+		y--
+	}
+	if states[ActionDown] {
+		y++
+	}
+	if direction := directions[y][x]; direction != b.direction {
+		b.direction = direction
+		b.push(InputEvent{Action: direction, Pressed: true, Direction: true, Player: player, Frame: im.frame})
+	}
+	for _, action := range actions {
+		if states[action] != b.states[action] {
+			b.push(InputEvent{Action: action, Pressed: states[action], Player: player, Frame: im.frame})
+		}
+	}
+	b.states = states
+}
+
+// プレイヤーの機器で押されているか（キーボードとマウスはKeyboardPlayer）
+func (im *InputManager) isBindingActiveFor(binding InputBinding, player int) bool {
+	if !im.isBindingActive(binding) {
+		return false
+	}
+	switch binding.Type {
+	case KeyboardInput, MouseInput:
+		return player == KeyboardPlayer
+	}
+	if binding.Player != 0 {
+		return binding.Player == player
+	}
+	binding.Player = player
+	return im.gamepads.isButtonPressed(binding)
+}
+
+func (im *InputManager) matchSequences(players int) []func() {
+	im.matchedSequences = make(map[string]int)
+	sequences := append([]InputSequence{}, im.sequences...)
+	sort.SliceStable(sequences, func(i, j int) bool { return len(sequences[i].Steps) > len(sequences[j].Steps) })
+
+	var fired []func()
+	for _, s := range sequences {
+		if len(s.Steps) == 0 {
+			continue
+		}
+		from, to := 1, players
+		if s.Player != 0 {
+			from, to = s.Player, s.Player
+		}
+		for player := from; player <= to; player++ {
+			b := im.buffers[player]
+			if b == nil || !im.matchSequence(s, b) {
+				continue
+			}
+			im.matchedSequences[s.Name] = player
+			if s.OnMatch != nil {
+				onMatch, player := s.OnMatch, player
+				fired = append(fired, func() { onMatch(player) })
+			}
+			break
+		}
+	}
+	return fired
+}
+
+// このフレームの入力で手順の最後まで入ったか
+// 最後の入力から遡って、手順を新しい方から順に探す
+func (im *InputManager) matchSequence(s InputSequence, b *inputBuffer) bool {
+	window := s.Window
+	if window <= 0 {
+		window = DefaultSequenceWindow
+	}
+	since := b.matched[s.Name]
+	step := len(s.Steps) - 1
+	var last, later *InputEvent
+	b.each(func(e *InputEvent) bool {
+		if e.Frame <= since {
+			return false
+		}
+		if last == nil {
+			// 最後の入力はこのフレームのもの
+			if e.Frame != im.frame {
+				return false
+			}
+			if e.sequenced || !stepMatches(s.Steps[step], e) {
+				return true
+			}
+			last, later = e, e
+			step--
+			return step >= 0
+		}
+		if framesToDuration(last.Frame-e.Frame) > window {
+			return false
+		}
+		if s.StepWindow > 0 && framesToDuration(later.Frame-e.Frame) > s.StepWindow {
+			return false
+		}
+		if stepMatches(s.Steps[step], e) {
+			later = e
+			step--
+		}
+		return step >= 0
+	})
+	if last == nil || step >= 0 {
+		return false
+	}
+	last.sequenced = true
+	b.matched[s.Name] = im.frame
+	return true
+}
+
+func stepMatches(step Action, e *InputEvent) bool {
+	if !e.Pressed || e.Action != step {
+		return false
+	}
+	return e.Direction == isDirection(step)
+}
@@ -2,11 +2,25 @@ package input
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
 	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/hajimehoshi/ebiten/v2"
 )
 
+// 入力の設定ファイル（割り当ては名前で書く）
+//
+//	{
+//	  "key_bindings": {"ok": ["KeyZ", "KeyEnter", "GamepadA"], "save": ["Ctrl+KeyS"]},
+//	  "gamepad": {"stick_deadzone": 0.25, "vibration": false}
+//	}
 type InputConfig struct {
 	KeyBindings map[Action][]InputBinding `json:"key_bindings"`
+	Gamepad     *GamepadConfig            `json:"gamepad,omitempty"`
 }
 
 func NewInputConfig() *InputConfig {
@@ -15,22 +29,182 @@ func NewInputConfig() *InputConfig {
 	}
 }
 
+// マネージャーの今の割り当てと設定
+func (im *InputManager) Config() *InputConfig {
+	im.mutex.RLock()
+	defer im.mutex.RUnlock()
+
+	c := NewInputConfig()
+	for action, bindings := range im.bindings {
+		c.KeyBindings[action] = append([]InputBinding{}, bindings...)
+	}
+	gamepad := im.gamepads.config
+	c.Gamepad = &gamepad
+	return c
+}
+
+// 割り当てを既定に戻す（軸とゲームパッドの設定はそのまま）
+func (im *InputManager) ResetBindings() {
+	im.mutex.Lock()
+	im.bindings = make(map[Action][]InputBinding)
+	im.mutex.Unlock()
+	im.SetDefaultBindings()
+}
+
 func (c *InputConfig) Save(filename string) error {
 	data, err := json.MarshalIndent(c, "", "  ")
 	if err != nil {
 		return err
 	}
+	if dir := filepath.Dir(filename); dir != "." {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return err
+		}
+	}
 	return os.WriteFile(filename, data, 0644)
 }
 
+// ファイルを読み込む（以前の数値の形式も読める）
 func (c *InputConfig) Load(filename string) error {
 	data, err := os.ReadFile(filename)
 	if err != nil {
 		return err
 	}
-	return json.Unmarshal(data, c)
+	if err := json.Unmarshal(data, c); err != nil {
+		return fmt.Errorf("%s: %v", filename, err)
+	}
+	return nil
+}
+
+// 割り当ての誤りをまとめて返す
+func (c *InputConfig) Validate() error {
+	var errs []error
+	for _, action := range c.Actions() {
+		bindings := c.KeyBindings[action]
+		if action == "" {
+			errs = append(errs, fmt.Errorf("empty action name"))
+		}
+		for i, b := range bindings {
+			if err := b.Validate(); err != nil {
+				errs = append(errs, fmt.Errorf("%s[%d]: %v", action, i, err))
+			}
+			for _, prev := range bindings[:i] {
+				if prev.Equal(b) {
+					errs = append(errs, fmt.Errorf("%s: duplicate binding %s", action, b))
+					break
+				}
+			}
+		}
+	}
+	if c.Gamepad != nil {
+		g := c.Gamepad
+		for _, v := range []struct {
+			name  string
+			value float64
+		}{
+			{"stick_deadzone", g.StickDeadzone},
+			{"trigger_deadzone", g.TriggerDeadzone},
+			{"axis_threshold", g.AxisThreshold},
+			{"trigger_threshold", g.TriggerThreshold},
+		} {
+			if v.value < 0 || v.value >= 1 {
+				errs = append(errs, fmt.Errorf("gamepad %s must be in [0, 1), got %g", v.name, v.value))
+			}
+		}
+		if g.MaxPlayers < 0 {
+			errs = append(errs, fmt.Errorf("gamepad max_players must not be negative, got %d", g.MaxPlayers))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// 割り当ての値が範囲内か
+func (b InputBinding) Validate() error {
+	switch b.Type {
+	case KeyboardInput:
+		if b.Key < 0 || b.Key > ebiten.KeyMax {
+			return fmt.Errorf("invalid key: %d", b.Key)
+		}
+	case MouseInput:
+		if b.MouseBtn < 0 || b.MouseBtn > ebiten.MouseButtonMax {
+			return fmt.Errorf("invalid mouse button: %d", b.MouseBtn)
+		}
+	case GamepadInput:
+		if b.Button < 0 || b.Button > ebiten.GamepadButtonMax {
+			return fmt.Errorf("invalid gamepad button: %d", b.Button)
+		}
+	case StandardGamepadInput:
+		if b.StandardButton < 0 || b.StandardButton > ebiten.StandardGamepadButtonMax {
+			return fmt.Errorf("invalid gamepad button: %d", b.StandardButton)
+		}
+	case GamepadAxisInput:
+		if b.Axis < 0 || b.Axis > ebiten.StandardGamepadAxisMax {
+			return fmt.Errorf("invalid gamepad axis: %d", b.Axis)
+		}
+		if b.AxisDirection != -1 && b.AxisDirection != 1 {
+			return fmt.Errorf("axis direction must be -1 or 1, got %g", b.AxisDirection)
+		}
+	default:
+		return fmt.Errorf("invalid input type: %d", b.Type)
+	}
+	if b.Player < 0 {
+		return fmt.Errorf("player must not be negative, got %d", b.Player)
+	}
+	for _, key := range b.Modifiers {
+		if key < 0 || key > ebiten.KeyMax {
+			return fmt.Errorf("invalid modifier key: %d", key)
+		}
+	}
+	return nil
+}
+
+// 同じ入力が割り当てられた複数のアクション
+type BindingConflict struct {
+	Binding InputBinding
+	Actions []Action
+}
+
+// 複数のアクションに割り当てられている入力（名前の順）
+func (c *InputConfig) Conflicts() []BindingConflict {
+	var conflicts []BindingConflict
+	for _, action := range c.Actions() {
+	bindings:
+		for _, b := range c.KeyBindings[action] {
+			for i := range conflicts {
+				if conflicts[i].Binding.Equal(b) {
+					if conflicts[i].Actions[len(conflicts[i].Actions)-1] != action {
+						conflicts[i].Actions = append(conflicts[i].Actions, action)
+					}
+					continue bindings
+				}
+			}
+			conflicts = append(conflicts, BindingConflict{Binding: b, Actions: []Action{action}})
+		}
+	}
+
+	result := conflicts[:0]
+	for _, conflict := range conflicts {
+		if len(conflict.Actions) > 1 {
+			result = append(result, conflict)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Binding.String() < result[j].Binding.String()
+	})
+	return result
+}
+
+// 割り当てのあるアクション（名前の順）
+func (c *InputConfig) Actions() []Action {
+	actions := make([]Action, 0, len(c.KeyBindings))
+	for action := range c.KeyBindings {
+		actions = append(actions, action)
+	}
+	sort.Slice(actions, func(i, j int) bool { return actions[i] < actions[j] })
+	return actions
 }
 
+// 割り当てをマネージャーに適用する（設定にないアクションの割り当ては外す）
 func (c *InputConfig) ApplyToManager(im *InputManager) {
 	im.mutex.Lock()
 	defer im.mutex.Unlock()
@@ -40,6 +214,70 @@ func (c *InputConfig) ApplyToManager(im *InputManager) {
 
 	// 設定を適用
 	for action, bindings := range c.KeyBindings {
-		im.bindings[action] = bindings
+		im.bindings[action] = append([]InputBinding{}, bindings...)
+	}
+	if c.Gamepad != nil {
+		im.gamepads.config = *c.Gamepad
 	}
-} 
\ No newline at end of file
+}
+
+// プロファイルの設定ファイルを置くディレクトリを設定する
+func (im *InputManager) SetProfileDir(dir string) {
+	im.mutex.Lock()
+	defer im.mutex.Unlock()
+	im.profileDir = dir
+}
+
+func (im *InputManager) profilePath(name string) (string, error) {
+	im.mutex.RLock()
+	defer im.mutex.RUnlock()
+	if name == "" || strings.ContainsAny(name, `/\.`) {
+		return "", fmt.Errorf("invalid input profile name: %q", name)
+	}
+	return filepath.Join(im.profileDir, "input_"+name+".json"), nil
+}
+
+// 今の割り当てをプロファイルとして保存する
+func (im *InputManager) SaveProfile(name string) error {
+	path, err := im.profilePath(name)
+	if err != nil {
+		return err
+	}
+	return im.Config().Save(path)
+}
+
+// プロファイルを読み込んで適用する（誤りがあれば適用しない）
+func (im *InputManager) LoadProfile(name string) error {
+	path, err := im.profilePath(name)
+	if err != nil {
+		return err
+	}
+	c := NewInputConfig()
+	if err := c.Load(path); err != nil {
+		return err
+	}
+	if err := c.Validate(); err != nil {
+		return fmt.Errorf("%s: %v", path, err)
+	}
+	c.ApplyToManager(im)
+	return nil
+}
+
+// 保存されているプロファイルの名前（名前の順）
+func (im *InputManager) Profiles() ([]string, error) {
+	im.mutex.RLock()
+	dir := im.profileDir
+	im.mutex.RUnlock()
+
+	matches, err := filepath.Glob(filepath.Join(dir, "input_*.json"))
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(matches))
+	for _, path := range matches {
+		base := filepath.Base(path)
+		names = append(names, strings.TrimSuffix(strings.TrimPrefix(base, "input_"), ".json"))
+	}
+	sort.Strings(names)
+	return names, nil
+}
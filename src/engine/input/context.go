@@ -0,0 +1,447 @@
+package input
+
+import (
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// 既定のコンテキストの名前
+const (
+	ContextGameplay = "gameplay" // 一番下にある、全体の割り当てをすべて使うコンテキスト
+	ContextMenu     = "menu"
+	ContextDialogue = "dialogue"
+	ContextText     = "text" // 文字入力中（キーボードをすべて止める）
+	ContextDebug    = "debug"
+)
+
+// 入力コンテキスト（ゲームプレイ、メニュー、会話、デバッグコンソールなど）
+// 入力は上のコンテキストから順に渡り、扱った入力は下には渡らない
+// Blockingなら扱わなかった入力も下に渡さない
+type InputContext struct {
+	Name     string
+	Actions  []Action                  // 全体の割り当てから使うアクション（nilならすべて）
+	Bindings map[Action][]InputBinding // このコンテキストだけの割り当て（全体の割り当てより優先）
+	Blocking bool
+
+	manager   *InputManager // 積まれているマネージャー
+	states    map[Action]bool
+	previous  map[Action]bool
+	held      map[Action]int  // 押し続けているフレーム数
+	released  map[Action]bool // 離すまで押していないとみなすアクション
+	callbacks map[Action][]func()
+}
+
+func NewInputContext(name string, blocking bool, actions ...Action) *InputContext {
+	return &InputContext{
+		Name:      name,
+		Actions:   actions,
+		Bindings:  make(map[Action][]InputBinding),
+		Blocking:  blocking,
+		states:    make(map[Action]bool),
+		previous:  make(map[Action]bool),
+		held:      make(map[Action]int),
+		released:  make(map[Action]bool),
+		callbacks: make(map[Action][]func()),
+	}
+}
+
+// 上下左右・決定・キャンセル・メニューだけを扱い、ほかの入力は下に渡さない
+func NewMenuContext() *InputContext {
+	return NewInputContext(ContextMenu, true, ActionUp, ActionDown, ActionLeft, ActionRight, ActionOK, ActionCancel, ActionMenu)
+}
+
+// 会話中（上下・決定・キャンセルだけを扱う）
+func NewDialogueContext() *InputContext {
+	return NewInputContext(ContextDialogue, true, ActionUp, ActionDown, ActionOK, ActionCancel)
+}
+
+// 文字入力中（アクションを何も扱わず、すべて止める）
+func NewTextContext() *InputContext {
+	return NewInputContext(ContextText, true, []Action{}...)
+}
+
+// 全体の割り当てとこのコンテキストだけの割り当てを順に渡す
+func (c *InputContext) eachBinding(global map[Action][]InputBinding, fn func(action Action, bindings []InputBinding)) {
+	if c.Actions == nil {
+		for action, bindings := range global {
+			if _, own := c.Bindings[action]; !own {
+				fn(action, bindings)
+			}
+		}
+	} else {
+		for _, action := range c.Actions {
+			if _, own := c.Bindings[action]; !own {
+				fn(action, global[action])
+			}
+		}
+	}
+	for action, bindings := range c.Bindings {
+		fn(action, bindings)
+	}
+}
+
+// アクションを扱うか
+func (c *InputContext) handles(action Action) bool {
+	if _, own := c.Bindings[action]; own {
+		return true
+	}
+	if c.Actions == nil {
+		return true
+	}
+	for _, a := range c.Actions {
+		if a == action {
+			return true
+		}
+	}
+	return false
+}
+
+// コンテキストを一番上に積む（既に積まれていれば一番上に移す）
+func (im *InputManager) PushContext(c *InputContext) {
+	im.mutex.Lock()
+	defer im.mutex.Unlock()
+	im.removeContext(c)
+	c.manager = im
+	im.contexts = append(im.contexts, c)
+	// 積んだ時に押していた入力は、離すまでこのコンテキストのアクションにしない
+	im.eachContextBinding(c, func(action Action, bindings []InputBinding) {
+		c.released[action] = true
+	})
+}
+
+// 一番上のコンテキストを外す（一番下のゲームプレイは外さない）
+func (im *InputManager) PopContext() *InputContext {
+	im.mutex.Lock()
+	defer im.mutex.Unlock()
+	if len(im.contexts) <= 1 {
+		return nil
+	}
+	top := im.contexts[len(im.contexts)-1]
+	im.removeContext(top)
+	return top
+}
+
+// コンテキストを外す（途中に積まれていてもよい）
+func (im *InputManager) RemoveContext(c *InputContext) {
+	im.mutex.Lock()
+	defer im.mutex.Unlock()
+	im.removeContext(c)
+}
+
+// activeなら積み（積まれていなければ）、そうでなければ外す
+func (im *InputManager) SetContextActive(c *InputContext, active bool) {
+	im.mutex.RLock()
+	pushed := im.indexOfContext(c) >= 0
+	im.mutex.RUnlock()
+	switch {
+	case active && !pushed:
+		im.PushContext(c)
+	case !active && pushed:
+		im.RemoveContext(c)
+	}
+}
+
+func (im *InputManager) removeContext(c *InputContext) {
+	if c == im.contexts[0] {
+		return
+	}
+	if i := im.indexOfContext(c); i >= 0 {
+		im.contexts = append(im.contexts[:i], im.contexts[i+1:]...)
+		// 外したコンテキストで押していたアクションは、下で離すまで押していないとみなす
+		for _, below := range im.contexts {
+			im.eachContextBinding(below, func(action Action, bindings []InputBinding) {
+				if below.states[action] || im.anyActive(bindings) {
+					below.released[action] = true
+				}
+			})
+		}
+		// 外したコンテキストの状態は捨てる（積み直した時に前の入力で押されたことにならないように）
+		c.states = make(map[Action]bool)
+		c.previous = make(map[Action]bool)
+		c.held = make(map[Action]int)
+		c.manager = nil
+	}
+}
+
+func (im *InputManager) indexOfContext(c *InputContext) int {
+	for i, ctx := range im.contexts {
+		if ctx == c {
+			return i
+		}
+	}
+	return -1
+}
+
+func (im *InputManager) eachContextBinding(c *InputContext, fn func(action Action, bindings []InputBinding)) {
+	c.eachBinding(im.bindings, fn)
+}
+
+func (im *InputManager) anyActive(bindings []InputBinding) bool {
+	for _, b := range bindings {
+		if im.isBindingActive(b) {
+			return true
+		}
+	}
+	return false
+}
+
+// 名前のコンテキスト（上から探す）
+func (im *InputManager) Context(name string) *InputContext {
+	im.mutex.RLock()
+	defer im.mutex.RUnlock()
+	for i := len(im.contexts) - 1; i >= 0; i-- {
+		if im.contexts[i].Name == name {
+			return im.contexts[i]
+		}
+	}
+	return nil
+}
+
+// 一番下のゲームプレイのコンテキスト
+func (im *InputManager) Gameplay() *InputContext {
+	return im.contexts[0]
+}
+
+// 積まれているコンテキストの名前（下から）
+func (im *InputManager) ContextNames() []string {
+	im.mutex.RLock()
+	defer im.mutex.RUnlock()
+	names := make([]string, len(im.contexts))
+	for i, c := range im.contexts {
+		names[i] = c.Name
+	}
+	return names
+}
+
+// アクションが届くコンテキスト（アクションを扱う一番上のもの。途中にBlockingがあればそこで止まる）
+func (im *InputManager) routeOf(action Action) *InputContext {
+	for i := len(im.contexts) - 1; i >= 0; i-- {
+		c := im.contexts[i]
+		if c.handles(action) || c.Blocking {
+			return c
+		}
+	}
+	return im.contexts[0]
+}
+
+// コンテキストごとのアクションの状態を更新する（ロックした状態で呼ぶ）
+// 上のコンテキストで扱った入力と、Blockingより下の入力は押していないとみなす
+func (im *InputManager) updateContexts() {
+	handled := make(map[bindingInput]bool)
+	blocked := false
+	for i := len(im.contexts) - 1; i >= 0; i-- {
+		c := im.contexts[i]
+		for action := range c.states {
+			c.previous[action] = c.states[action]
+		}
+		var active []bindingInput
+		c.eachBinding(im.bindings, func(action Action, bindings []InputBinding) {
+			pressed, raw := false, false
+			for _, b := range bindings {
+				if !im.isBindingActive(b) {
+					continue
+				}
+				raw = true
+				active = append(active, b.input())
+				if !blocked && !handled[b.input()] {
+					pressed = true
+				}
+			}
+			if c.released[action] {
+				if !raw {
+					delete(c.released, action)
+				}
+				pressed = false
+			}
+			if im.listening != nil {
+				pressed = false
+			}
+			c.states[action] = pressed
+			if pressed {
+				c.held[action]++
+			} else {
+				c.held[action] = 0
+			}
+		})
+		if !blocked {
+			for _, in := range active {
+				handled[in] = true
+			}
+		}
+		if c.Blocking {
+			blocked = true
+		}
+	}
+}
+
+// 押したアクションを使ったことにする（離すまで押していないとみなす）
+// 同じ入力が割り当てられたほかのコンテキストのアクションも押していないとみなす
+func (c *InputContext) Consume(action Action) {
+	im := c.manager
+	if im == nil {
+		return
+	}
+	im.mutex.Lock()
+	defer im.mutex.Unlock()
+	im.consume(c, action)
+}
+
+func (im *InputManager) consume(c *InputContext, action Action) {
+	// 先行入力としても使えないようにする
+	im.consumeBuffered(action, 0)
+	var inputs []bindingInput
+	c.eachBinding(im.bindings, func(a Action, bindings []InputBinding) {
+		if a != action {
+			return
+		}
+		for _, b := range bindings {
+			if im.isBindingActive(b) {
+				inputs = append(inputs, b.input())
+			}
+		}
+	})
+	for _, ctx := range im.contexts {
+		ctx.eachBinding(im.bindings, func(a Action, bindings []InputBinding) {
+			if ctx == c && a == action {
+				ctx.release(a)
+				return
+			}
+			for _, b := range bindings {
+				for _, in := range inputs {
+					if b.input() == in {
+						ctx.release(a)
+						return
+					}
+				}
+			}
+		})
+	}
+}
+
+// 押していないことにし、離すまでそのままにする（押した瞬間・離した瞬間にもならない）
+func (c *InputContext) release(action Action) {
+	if !c.states[action] {
+		return
+	}
+	c.states[action] = false
+	c.previous[action] = false
+	c.held[action] = 0
+	c.released[action] = true
+}
+
+func (c *InputContext) IsPressed(action Action) bool {
+	if c.manager == nil {
+		return false
+	}
+	c.manager.mutex.RLock()
+	defer c.manager.mutex.RUnlock()
+	return c.states[action]
+}
+
+func (c *InputContext) IsJustPressed(action Action) bool {
+	if c.manager == nil {
+		return false
+	}
+	c.manager.mutex.RLock()
+	defer c.manager.mutex.RUnlock()
+	return c.states[action] && !c.previous[action]
+}
+
+func (c *InputContext) IsJustReleased(action Action) bool {
+	if c.manager == nil {
+		return false
+	}
+	c.manager.mutex.RLock()
+	defer c.manager.mutex.RUnlock()
+	return !c.states[action] && c.previous[action]
+}
+
+// 押し続けているフレーム数（押した最初のフレームで1、押していなければ0）
+func (c *InputContext) HeldFrames(action Action) int {
+	if c.manager == nil {
+		return 0
+	}
+	c.manager.mutex.RLock()
+	defer c.manager.mutex.RUnlock()
+	return c.held[action]
+}
+
+// 押し続けている時間（フレーム数をTPSで割る）
+func (c *InputContext) HeldDuration(action Action) time.Duration {
+	return framesToDuration(c.HeldFrames(action))
+}
+
+func framesToDuration(frames int) time.Duration {
+	tps := ebiten.TPS()
+	if tps <= 0 {
+		tps = ebiten.DefaultTPS
+	}
+	return time.Duration(frames) * time.Second / time.Duration(tps)
+}
+
+// 軸アクションの値（このコンテキストまで届いていなければ0）
+func (c *InputContext) Axis2D(action Action) (x, y float64) {
+	im := c.manager
+	if im == nil {
+		return 0, 0
+	}
+	im.mutex.RLock()
+	defer im.mutex.RUnlock()
+	if !c.handles(action) || im.routeOf(action) != c {
+		return 0, 0
+	}
+	v := im.axes[action]
+	return v[0], v[1]
+}
+
+func (c *InputContext) Axis(action Action) float64 {
+	x, _ := c.Axis2D(action)
+	return x
+}
+
+// アクションを押した時に呼ばれる関数を登録する
+// 上のコンテキストから順に呼ばれ、Consumeすると下のコンテキストの関数は呼ばれない
+func (c *InputContext) OnAction(action Action, callback func()) {
+	if c.manager != nil {
+		c.manager.mutex.Lock()
+		defer c.manager.mutex.Unlock()
+	}
+	c.callbacks[action] = append(c.callbacks[action], callback)
+}
+
+// キーを押しているか（上のコンテキストで扱ったキーと、Blockingより下では押していないとみなす）
+func (c *InputContext) IsKeyPressed(key ebiten.Key) bool {
+	im := c.manager
+	if im == nil || !ebiten.IsKeyPressed(key) {
+		return false
+	}
+	im.mutex.RLock()
+	defer im.mutex.RUnlock()
+	if im.listening != nil {
+		return false
+	}
+	for i := len(im.contexts) - 1; i >= 0; i-- {
+		ctx := im.contexts[i]
+		if ctx == c {
+			return true
+		}
+		if ctx.Blocking || ctx.handlesKey(im.bindings, key) {
+			return false
+		}
+	}
+	return false
+}
+
+// キーがアクションに割り当てられているか
+func (c *InputContext) handlesKey(global map[Action][]InputBinding, key ebiten.Key) bool {
+	found := false
+	c.eachBinding(global, func(action Action, bindings []InputBinding) {
+		for _, b := range bindings {
+			if b.Type == KeyboardInput && b.Key == key {
+				found = true
+			}
+		}
+	})
+	return found
+}
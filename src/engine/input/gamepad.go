@@ -0,0 +1,349 @@
+package input
+
+import (
+	"math"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// アナログスティック
+type Stick int
+
+const (
+	LeftStick Stick = iota
+	RightStick
+)
+
+// アナログトリガー
+type Trigger int
+
+const (
+	LeftTrigger Trigger = iota
+	RightTrigger
+)
+
+// ゲームパッドのデッドゾーンと振動の設定
+type GamepadConfig struct {
+	StickDeadzone    float64 `json:"stick_deadzone"`    // これより小さいスティックの傾きは0にする（0〜1）
+	TriggerDeadzone  float64 `json:"trigger_deadzone"`  // これより小さいトリガーの押し込みは0にする（0〜1）
+	AxisThreshold    float64 `json:"axis_threshold"`    // 軸のバインディングを押したとみなす傾き
+	TriggerThreshold float64 `json:"trigger_threshold"` // トリガーのバインディングを押したとみなす押し込み
+	Vibration        bool    `json:"vibration"`         // falseなら振動させない
+	MaxPlayers       int     `json:"max_players"`       // つながったパッドを自動で割り当てるプレイヤー数
+	AutoAssign       bool    `json:"auto_assign"`       // つながったパッドを空いているプレイヤーに割り当てる
+}
+
+func DefaultGamepadConfig() GamepadConfig {
+	return GamepadConfig{
+		StickDeadzone:    0.2,
+		TriggerDeadzone:  0.1,
+		AxisThreshold:    0.5,
+		TriggerThreshold: 0.5,
+		Vibration:        true,
+		MaxPlayers:       4,
+		AutoAssign:       true,
+	}
+}
+
+// つながっているゲームパッドの情報
+type GamepadInfo struct {
+	ID       ebiten.GamepadID
+	Name     string
+	SDLID    string
+	Standard bool // 標準配置（StandardGamepadButton・StandardGamepadAxis）で読める
+	Player   int  // 割り当てられたプレイヤー番号（1から。0なら未割り当て）
+}
+
+// つながっているゲームパッドとプレイヤーへの割り当て
+type gamepadState struct {
+	connected []ebiten.GamepadID       // つながった順
+	players   map[int]ebiten.GamepadID // プレイヤー番号とパッド
+	config    GamepadConfig
+	onConnect []func(id ebiten.GamepadID, player int)
+	onRemove  []func(id ebiten.GamepadID, player int)
+}
+
+func newGamepadState() *gamepadState {
+	return &gamepadState{
+		players: make(map[int]ebiten.GamepadID),
+		config:  DefaultGamepadConfig(),
+	}
+}
+
+// 抜き差しを反映し、呼ぶコールバックを返す（最初の呼び出しでは既につながっているパッドも加える）
+func (g *gamepadState) update() []func() {
+	var fired []func()
+	current := ebiten.AppendGamepadIDs(nil)
+	for i := 0; i < len(g.connected); {
+		id := g.connected[i]
+		if containsGamepad(current, id) {
+			i++
+			continue
+		}
+		g.connected = append(g.connected[:i], g.connected[i+1:]...)
+		player := g.playerOf(id)
+		if player != 0 {
+			delete(g.players, player)
+		}
+		for _, fn := range g.onRemove {
+			fn := fn
+			fired = append(fired, func() { fn(id, player) })
+		}
+	}
+
+	for _, id := range current {
+		if containsGamepad(g.connected, id) {
+			continue
+		}
+		g.connected = append(g.connected, id)
+		player := 0
+		if g.config.AutoAssign {
+			if player = g.freePlayer(); player != 0 {
+				g.players[player] = id
+			}
+		}
+		for _, fn := range g.onConnect {
+			fn := fn
+			fired = append(fired, func() { fn(id, player) })
+		}
+	}
+	return fired
+}
+
+func containsGamepad(ids []ebiten.GamepadID, id ebiten.GamepadID) bool {
+	for _, v := range ids {
+		if v == id {
+			return true
+		}
+	}
+	return false
+}
+
+// パッドが割り当てられていない一番小さいプレイヤー番号（なければ0）
+func (g *gamepadState) freePlayer() int {
+	for player := 1; player <= g.config.MaxPlayers; player++ {
+		if _, exists := g.players[player]; !exists {
+			return player
+		}
+	}
+	return 0
+}
+
+func (g *gamepadState) playerOf(id ebiten.GamepadID) int {
+	for player, assigned := range g.players {
+		if assigned == id {
+			return player
+		}
+	}
+	return 0
+}
+
+// プレイヤーのパッド（0ならつながっているすべて）
+func (g *gamepadState) devices(player int) []ebiten.GamepadID {
+	if player == 0 {
+		return g.connected
+	}
+	if id, exists := g.players[player]; exists {
+		return []ebiten.GamepadID{id}
+	}
+	return nil
+}
+
+// ボタンのバインディングが押されているか
+func (g *gamepadState) isButtonPressed(binding InputBinding) bool {
+	for _, id := range g.devices(binding.Player) {
+		switch binding.Type {
+		case GamepadInput:
+			if ebiten.IsGamepadButtonPressed(id, binding.Button) {
+				return true
+			}
+		case StandardGamepadInput:
+			if !ebiten.IsStandardGamepadLayoutAvailable(id) {
+				continue
+			}
+			if isTriggerButton(binding.StandardButton) {
+				if ebiten.StandardGamepadButtonValue(id, binding.StandardButton) >= g.config.TriggerThreshold {
+					return true
+				}
+			} else if ebiten.IsStandardGamepadButtonPressed(id, binding.StandardButton) {
+				return true
+			}
+		case GamepadAxisInput:
+			if !ebiten.IsStandardGamepadLayoutAvailable(id) {
+				continue
+			}
+			if g.axis(id, binding.Axis)*binding.AxisDirection >= g.config.AxisThreshold {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func isTriggerButton(button ebiten.StandardGamepadButton) bool {
+	return button == ebiten.StandardGamepadButtonFrontBottomLeft || button == ebiten.StandardGamepadButtonFrontBottomRight
+}
+
+// 軸の値（同じスティックの2軸でデッドゾーンを適用する）
+func (g *gamepadState) axis(id ebiten.GamepadID, axis ebiten.StandardGamepadAxis) float64 {
+	stick := LeftStick
+	if axis == ebiten.StandardGamepadAxisRightStickHorizontal || axis == ebiten.StandardGamepadAxisRightStickVertical {
+		stick = RightStick
+	}
+	x, y := g.stick(id, stick)
+	if axis == ebiten.StandardGamepadAxisLeftStickHorizontal || axis == ebiten.StandardGamepadAxisRightStickHorizontal {
+		return x
+	}
+	return y
+}
+
+// スティックの傾き（-1〜1、下・右が正）
+// デッドゾーンより内側は0にし、外側は0〜1に伸ばす
+func (g *gamepadState) stick(id ebiten.GamepadID, stick Stick) (float64, float64) {
+	h, v := ebiten.StandardGamepadAxisLeftStickHorizontal, ebiten.StandardGamepadAxisLeftStickVertical
+	if stick == RightStick {
+		h, v = ebiten.StandardGamepadAxisRightStickHorizontal, ebiten.StandardGamepadAxisRightStickVertical
+	}
+	x, y := ebiten.StandardGamepadAxisValue(id, h), ebiten.StandardGamepadAxisValue(id, v)
+	length := math.Hypot(x, y)
+	deadzone := g.config.StickDeadzone
+	if length <= deadzone || length == 0 {
+		return 0, 0
+	}
+	scaled := math.Min(1, (length-deadzone)/(1-deadzone))
+	return x / length * scaled, y / length * scaled
+}
+
+// トリガーの押し込み（0〜1）
+func (g *gamepadState) trigger(id ebiten.GamepadID, trigger Trigger) float64 {
+	button := ebiten.StandardGamepadButtonFrontBottomLeft
+	if trigger == RightTrigger {
+		button = ebiten.StandardGamepadButtonFrontBottomRight
+	}
+	value := ebiten.StandardGamepadButtonValue(id, button)
+	deadzone := g.config.TriggerDeadzone
+	if value <= deadzone {
+		return 0
+	}
+	return math.Min(1, (value-deadzone)/(1-deadzone))
+}
+
+// つながっているゲームパッド（つながった順）
+func (im *InputManager) Gamepads() []GamepadInfo {
+	im.mutex.RLock()
+	defer im.mutex.RUnlock()
+
+	infos := make([]GamepadInfo, len(im.gamepads.connected))
+	for i, id := range im.gamepads.connected {
+		infos[i] = GamepadInfo{
+			ID:       id,
+			Name:     ebiten.GamepadName(id),
+			SDLID:    ebiten.GamepadSDLID(id),
+			Standard: ebiten.IsStandardGamepadLayoutAvailable(id),
+			Player:   im.gamepads.playerOf(id),
+		}
+	}
+	return infos
+}
+
+// パッドをプレイヤーに割り当てる（ほかのプレイヤーに割り当て済みなら付け替える）
+func (im *InputManager) AssignGamepad(player int, id ebiten.GamepadID) {
+	im.mutex.Lock()
+	defer im.mutex.Unlock()
+
+	if old := im.gamepads.playerOf(id); old != 0 {
+		delete(im.gamepads.players, old)
+	}
+	im.gamepads.players[player] = id
+}
+
+// プレイヤーのパッドの割り当てを外す
+func (im *InputManager) UnassignGamepad(player int) {
+	im.mutex.Lock()
+	defer im.mutex.Unlock()
+	delete(im.gamepads.players, player)
+}
+
+// プレイヤーに割り当てられたパッド
+func (im *InputManager) GamepadOf(player int) (ebiten.GamepadID, bool) {
+	im.mutex.RLock()
+	defer im.mutex.RUnlock()
+	id, exists := im.gamepads.players[player]
+	return id, exists
+}
+
+// パッドがつながった時・外れた時に呼ばれる関数を登録する（playerは割り当てられたプレイヤー、なければ0）
+func (im *InputManager) OnGamepadConnected(fn func(id ebiten.GamepadID, player int)) {
+	im.mutex.Lock()
+	defer im.mutex.Unlock()
+	im.gamepads.onConnect = append(im.gamepads.onConnect, fn)
+}
+
+func (im *InputManager) OnGamepadDisconnected(fn func(id ebiten.GamepadID, player int)) {
+	im.mutex.Lock()
+	defer im.mutex.Unlock()
+	im.gamepads.onRemove = append(im.gamepads.onRemove, fn)
+}
+
+func (im *InputManager) GamepadConfig() GamepadConfig {
+	im.mutex.RLock()
+	defer im.mutex.RUnlock()
+	return im.gamepads.config
+}
+
+func (im *InputManager) SetGamepadConfig(config GamepadConfig) {
+	im.mutex.Lock()
+	defer im.mutex.Unlock()
+	im.gamepads.config = config
+}
+
+// プレイヤーのスティックの傾き（デッドゾーン適用済み、-1〜1、下・右が正）
+// playerが0ならつながっているパッドで一番大きく傾いているもの
+func (im *InputManager) Stick(player int, stick Stick) (x, y float64) {
+	im.mutex.RLock()
+	defer im.mutex.RUnlock()
+
+	for _, id := range im.gamepads.devices(player) {
+		if !ebiten.IsStandardGamepadLayoutAvailable(id) {
+			continue
+		}
+		if sx, sy := im.gamepads.stick(id, stick); math.Hypot(sx, sy) > math.Hypot(x, y) {
+			x, y = sx, sy
+		}
+	}
+	return x, y
+}
+
+// プレイヤーのトリガーの押し込み（デッドゾーン適用済み、0〜1）
+// playerが0ならつながっているパッドで一番大きいもの
+func (im *InputManager) Trigger(player int, trigger Trigger) float64 {
+	im.mutex.RLock()
+	defer im.mutex.RUnlock()
+
+	value := 0.0
+	for _, id := range im.gamepads.devices(player) {
+		if ebiten.IsStandardGamepadLayoutAvailable(id) {
+			value = math.Max(value, im.gamepads.trigger(id, trigger))
+		}
+	}
+	return value
+}
+
+// プレイヤーのパッドを振動させる（0ならつながっているすべて）
+// strong・weakは低周波・高周波のモーターの強さ（0〜1）。振動できないパッドでは何もしない
+func (im *InputManager) Vibrate(player int, duration time.Duration, strong, weak float64) {
+	im.mutex.RLock()
+	defer im.mutex.RUnlock()
+
+	if !im.gamepads.config.Vibration {
+		return
+	}
+	for _, id := range im.gamepads.devices(player) {
+		ebiten.VibrateGamepad(id, &ebiten.VibrateGamepadOptions{
+			Duration:        duration,
+			StrongMagnitude: strong,
+			WeakMagnitude:   weak,
+		})
+	}
+}
@@ -2,6 +2,7 @@ package input
 
 import (
 	"sync"
+	"time"
 
 	"github.com/hajimehoshi/ebiten/v2"
 )
@@ -12,7 +13,9 @@ type InputType int
 const (
 	KeyboardInput InputType = iota
 	MouseInput
-	GamepadInput
+	GamepadInput         // ゲームパッドのボタン番号（標準配置が使えないパッド用）
+	StandardGamepadInput // 標準配置のボタン
+	GamepadAxisInput     // 標準配置のスティックの軸を片方向に倒す
 )
 
 // 入力アクションの定義
@@ -34,45 +37,141 @@ type InputBinding struct {
 	Key      ebiten.Key
 	Button   ebiten.GamepadButton
 	MouseBtn ebiten.MouseButton
+
+	StandardButton ebiten.StandardGamepadButton
+	Axis           ebiten.StandardGamepadAxis
+	AxisDirection  float64 // 軸を倒す向き（-1か1）
+	Player         int     // ゲームパッドのプレイヤー番号（0ならつながっているすべてのパッド）
+
+	// 一緒に押していなければならないキー（Ctrl+Sなど）
+	// 修飾キー付きの組み合わせが押されている間は、同じ入力の修飾キーなしの割り当ては押されていないとみなす
+	Modifiers []ebiten.Key
+}
+
+// 修飾キーを除いた入力（比較できる値）
+type bindingInput struct {
+	Type           InputType
+	Key            ebiten.Key
+	Button         ebiten.GamepadButton
+	MouseBtn       ebiten.MouseButton
+	StandardButton ebiten.StandardGamepadButton
+	Axis           ebiten.StandardGamepadAxis
+	AxisDirection  float64
+	Player         int
+}
+
+func (b InputBinding) input() bindingInput {
+	return bindingInput{b.Type, b.Key, b.Button, b.MouseBtn, b.StandardButton, b.Axis, b.AxisDirection, b.Player}
 }
 
 // 入力マネージャー
 type InputManager struct {
-	mutex     sync.RWMutex
-	bindings  map[Action][]InputBinding
-	states    map[Action]bool
-	previous  map[Action]bool
-	callbacks map[Action][]func()
+	mutex    sync.RWMutex
+	bindings map[Action][]InputBinding
+	contexts []*InputContext // 積まれているコンテキスト（下から。一番下はゲームプレイ）
+	mouse    *MouseState
+	gamepads *gamepadState
+
+	axisBindings map[Action][]AxisSource
+	axes         map[Action][2]float64
+	chorded      map[bindingInput]bool // 修飾キー付きで押されている入力
+
+	listening *listenState // 割り当てのために待っている入力
+
+	frame            int                  // Updateを呼んだ回数
+	buffers          map[int]*inputBuffer // プレイヤーごとの入力の履歴（0はすべてのプレイヤー）
+	sequences        []InputSequence
+	matchedSequences map[string]int // このフレームで成立したコマンドとプレイヤー
+
+	profileDir string // プロファイルの設定ファイルを置くディレクトリ
 }
 
 func NewInputManager() *InputManager {
 	im := &InputManager{
-		bindings:  make(map[Action][]InputBinding),
-		states:    make(map[Action]bool),
-		previous:  make(map[Action]bool),
-		callbacks: make(map[Action][]func()),
+		bindings: make(map[Action][]InputBinding),
+		mouse:    NewMouseState(),
+		gamepads: newGamepadState(),
+
+		axisBindings: make(map[Action][]AxisSource),
+		axes:         make(map[Action][2]float64),
+		chorded:      make(map[bindingInput]bool),
+
+		buffers:          make(map[int]*inputBuffer),
+		matchedSequences: make(map[string]int),
 	}
+	gameplay := NewInputContext(ContextGameplay, false)
+	gameplay.manager = im
+	im.contexts = []*InputContext{gameplay}
 
-	// デフォルトのキーバインドを設定
+	// デフォルトのキーバインドと軸を設定
 	im.SetDefaultBindings()
+	im.setDefaultAxes()
 	return im
 }
 
 func (im *InputManager) SetDefaultBindings() {
 	// 方向キー
-	im.BindAction(ActionUp, InputBinding{KeyboardInput, ebiten.KeyUp, 0, 0})
-	im.BindAction(ActionDown, InputBinding{KeyboardInput, ebiten.KeyDown, 0, 0})
-	im.BindAction(ActionLeft, InputBinding{KeyboardInput, ebiten.KeyLeft, 0, 0})
-	im.BindAction(ActionRight, InputBinding{KeyboardInput, ebiten.KeyRight, 0, 0})
+	im.BindAction(ActionUp, KeyBinding(ebiten.KeyUp))
+	im.BindAction(ActionDown, KeyBinding(ebiten.KeyDown))
+	im.BindAction(ActionLeft, KeyBinding(ebiten.KeyLeft))
+	im.BindAction(ActionRight, KeyBinding(ebiten.KeyRight))
 
 	// アクションキー
-	im.BindAction(ActionOK, InputBinding{KeyboardInput, ebiten.KeyZ, 0, 0})
-	im.BindAction(ActionCancel, InputBinding{KeyboardInput, ebiten.KeyX, 0, 0})
-	im.BindAction(ActionMenu, InputBinding{KeyboardInput, ebiten.KeyEscape, 0, 0})
+	im.BindAction(ActionOK, KeyBinding(ebiten.KeyZ))
+	im.BindAction(ActionCancel, KeyBinding(ebiten.KeyX))
+	im.BindAction(ActionMenu, KeyBinding(ebiten.KeyEscape))
 
 	// マウス
-	im.BindAction(ActionOK, InputBinding{MouseInput, 0, 0, ebiten.MouseButtonLeft})
-	im.BindAction(ActionCancel, InputBinding{MouseInput, 0, 0, ebiten.MouseButtonRight})
+	im.BindAction(ActionOK, MouseBinding(ebiten.MouseButtonLeft))
+	im.BindAction(ActionCancel, MouseBinding(ebiten.MouseButtonRight))
+
+	// ゲームパッド（標準配置の十字キーと左スティック、右側の下のボタンで決定・右のボタンでキャンセル）
+	im.BindAction(ActionUp, PadBinding(ebiten.StandardGamepadButtonLeftTop))
+	im.BindAction(ActionDown, PadBinding(ebiten.StandardGamepadButtonLeftBottom))
+	im.BindAction(ActionLeft, PadBinding(ebiten.StandardGamepadButtonLeftLeft))
+	im.BindAction(ActionRight, PadBinding(ebiten.StandardGamepadButtonLeftRight))
+	im.BindAction(ActionUp, AxisBinding(ebiten.StandardGamepadAxisLeftStickVertical, -1))
+	im.BindAction(ActionDown, AxisBinding(ebiten.StandardGamepadAxisLeftStickVertical, 1))
+	im.BindAction(ActionLeft, AxisBinding(ebiten.StandardGamepadAxisLeftStickHorizontal, -1))
+	im.BindAction(ActionRight, AxisBinding(ebiten.StandardGamepadAxisLeftStickHorizontal, 1))
+	im.BindAction(ActionOK, PadBinding(ebiten.StandardGamepadButtonRightBottom))
+	im.BindAction(ActionCancel, PadBinding(ebiten.StandardGamepadButtonRightRight))
+	im.BindAction(ActionMenu, PadBinding(ebiten.StandardGamepadButtonCenterRight))
+	im.BindAction(ActionMenu, PadBinding(ebiten.StandardGamepadButtonRightTop))
+}
+
+func KeyBinding(key ebiten.Key) InputBinding {
+	return InputBinding{Type: KeyboardInput, Key: key}
+}
+
+func MouseBinding(button ebiten.MouseButton) InputBinding {
+	return InputBinding{Type: MouseInput, MouseBtn: button}
+}
+
+// 標準配置のボタン（トリガーは押し込みがTriggerThreshold以上で押したとみなす）
+func PadBinding(button ebiten.StandardGamepadButton) InputBinding {
+	return InputBinding{Type: StandardGamepadInput, StandardButton: button}
+}
+
+// 標準配置のスティックの軸をdirection（-1か1）の向きにAxisThreshold以上倒す
+func AxisBinding(axis ebiten.StandardGamepadAxis, direction float64) InputBinding {
+	return InputBinding{Type: GamepadAxisInput, Axis: axis, AxisDirection: direction}
+}
+
+// 標準配置が使えないパッドのボタン番号
+func RawPadBinding(button ebiten.GamepadButton) InputBinding {
+	return InputBinding{Type: GamepadInput, Button: button}
+}
+
+// 修飾キーと一緒に押すキー（ChordBinding(ebiten.KeyS, ebiten.KeyControl)でCtrl+S）
+func ChordBinding(key ebiten.Key, modifiers ...ebiten.Key) InputBinding {
+	return KeyBinding(key).WithModifiers(modifiers...)
+}
+
+// 修飾キーを加えた割り当て（MouseBinding(ebiten.MouseButtonLeft).WithModifiers(ebiten.KeyShift)など）
+func (b InputBinding) WithModifiers(modifiers ...ebiten.Key) InputBinding {
+	b.Modifiers = append(append([]ebiten.Key{}, b.Modifiers...), modifiers...)
+	return b
 }
 
 func (im *InputManager) BindAction(action Action, binding InputBinding) {
@@ -85,77 +184,149 @@ func (im *InputManager) BindAction(action Action, binding InputBinding) {
 	im.bindings[action] = append(im.bindings[action], binding)
 }
 
+// アクションの割り当て
+func (im *InputManager) Bindings(action Action) []InputBinding {
+	im.mutex.RLock()
+	defer im.mutex.RUnlock()
+	return append([]InputBinding{}, im.bindings[action]...)
+}
+
+// アクションの割り当てを置き換える（空なら外す）
+func (im *InputManager) SetBindings(action Action, bindings []InputBinding) {
+	im.mutex.Lock()
+	defer im.mutex.Unlock()
+	if len(bindings) == 0 {
+		delete(im.bindings, action)
+		return
+	}
+	im.bindings[action] = append([]InputBinding{}, bindings...)
+}
+
 func (im *InputManager) Update() error {
 	im.mutex.Lock()
 	defer im.mutex.Unlock()
 
-	// 前のフレームの状態を保存
-	for action := range im.states {
-		im.previous[action] = im.states[action]
-	}
+	im.mouse.Update()
+	fired := im.gamepads.update()
 
-	// 現在の入力状態を更新
-	for action, bindings := range im.bindings {
-		im.states[action] = false
-		for _, binding := range bindings {
-			if im.isBindingActive(binding) {
-				im.states[action] = true
-				break
+	// 修飾キー付きで押されている入力（同じ入力の修飾キーなしの割り当てを抑える）
+	im.chorded = make(map[bindingInput]bool)
+	for _, c := range im.contexts {
+		c.eachBinding(im.bindings, func(action Action, bindings []InputBinding) {
+			for _, binding := range bindings {
+				if len(binding.Modifiers) > 0 && im.isBindingActive(binding) {
+					im.chorded[binding.input()] = true
+				}
 			}
+		})
+	}
+
+	// 割り当てのために入力を待っている
+	if im.listening != nil {
+		if fn := im.pollListen(); fn != nil {
+			fired = append(fired, fn)
 		}
 	}
 
-	// コールバックの実行
-	for action, callbacks := range im.callbacks {
-		if im.IsJustPressed(action) {
+	// 現在の入力状態を更新
+	im.updateContexts()
+	im.updateAxes()
+	// 入力の履歴とコマンド（コマンドのOnMatchはアクションのコールバックより先に呼ぶ）
+	fired = append(fired, im.updateBuffers()...)
+
+	// コールバックの実行（ロックを外してから呼ぶので、コールバック内で状態を参照できる）
+	// 上のコンテキストから順に呼び、Consumeされたら下のコンテキストの関数は呼ばない
+	for i := len(im.contexts) - 1; i >= 0; i-- {
+		c := im.contexts[i]
+		for action, callbacks := range c.callbacks {
+			if !c.states[action] || c.previous[action] {
+				continue
+			}
 			for _, callback := range callbacks {
-				callback()
+				c, action, callback := c, action, callback
+				fired = append(fired, func() {
+					if c.IsJustPressed(action) {
+						callback()
+					}
+				})
 			}
 		}
 	}
+	im.mutex.Unlock()
+	for _, callback := range fired {
+		callback()
+	}
+	im.mutex.Lock()
 
 	return nil
 }
 
 func (im *InputManager) isBindingActive(binding InputBinding) bool {
+	if len(binding.Modifiers) == 0 {
+		if im.chorded[binding.input()] {
+			return false
+		}
+	} else {
+		for _, key := range binding.Modifiers {
+			if !ebiten.IsKeyPressed(key) {
+				return false
+			}
+		}
+	}
 	switch binding.Type {
 	case KeyboardInput:
 		return ebiten.IsKeyPressed(binding.Key)
 	case MouseInput:
 		return ebiten.IsMouseButtonPressed(binding.MouseBtn)
-	case GamepadInput:
-		// TODO: ゲームパッドの実装
-		return false
+	case GamepadInput, StandardGamepadInput, GamepadAxisInput:
+		return im.gamepads.isButtonPressed(binding)
 	default:
 		return false
 	}
 }
 
+// カーソル位置とホイール（Updateで更新される）
+// ゲーム内座標に変換する場合はMouse().Converterを設定する
+func (im *InputManager) Mouse() *MouseState {
+	return im.mouse
+}
+
+// 以下の問い合わせは、アクションを扱う一番上のコンテキストの状態を返す
+// （メニューが開いていればメニューの、会話中なら会話の状態。コンテキストを決めて問い合わせる時はContextを使う）
 func (im *InputManager) IsPressed(action Action) bool {
-	im.mutex.RLock()
-	defer im.mutex.RUnlock()
-	return im.states[action]
+	return im.route(action).IsPressed(action)
 }
 
 func (im *InputManager) IsJustPressed(action Action) bool {
-	im.mutex.RLock()
-	defer im.mutex.RUnlock()
-	return im.states[action] && !im.previous[action]
+	return im.route(action).IsJustPressed(action)
 }
 
 func (im *InputManager) IsJustReleased(action Action) bool {
+	return im.route(action).IsJustReleased(action)
+}
+
+// 押し続けているフレーム数（押した最初のフレームで1、押していなければ0）
+func (im *InputManager) HeldFrames(action Action) int {
+	return im.route(action).HeldFrames(action)
+}
+
+// 押し続けている時間（フレーム数をTPSで割る）
+func (im *InputManager) HeldDuration(action Action) time.Duration {
+	return im.route(action).HeldDuration(action)
+}
+
+// アクションを使ったことにする（離すまでどのコンテキストでも押していないとみなす）
+func (im *InputManager) Consume(action Action) {
+	im.route(action).Consume(action)
+}
+
+func (im *InputManager) route(action Action) *InputContext {
 	im.mutex.RLock()
 	defer im.mutex.RUnlock()
-	return !im.states[action] && im.previous[action]
+	return im.routeOf(action)
 }
 
-// コールバックの登録
+// ゲームプレイのコンテキストにコールバックを登録する（メニューや会話で扱っている間は呼ばれない）
 func (im *InputManager) OnAction(action Action, callback func()) {
-	im.mutex.Lock()
-	defer im.mutex.Unlock()
-
-	if _, exists := im.callbacks[action]; !exists {
-		im.callbacks[action] = make([]func(), 0)
-	}
-	im.callbacks[action] = append(im.callbacks[action], callback)
+	im.Gameplay().OnAction(action, callback)
 }
@@ -1,12 +1,22 @@
 package input
 
 import (
+	"math"
+
 	"github.com/hajimehoshi/ebiten/v2"
 )
 
+// 画面座標をゲーム内座標に変換する
+type CoordinateConverter interface {
+	ScreenToWorld(x, y float64) (float64, float64)
+}
+
 type MouseState struct {
-	X, Y          int
+	X, Y          int // ゲーム内座標
 	PrevX, PrevY  int
+	ScreenX       int // ウィンドウ上の座標
+	ScreenY       int
+	Converter     CoordinateConverter
 	ScrollX       float64
 	ScrollY       float64
 	DragStartX    int
@@ -24,7 +34,12 @@ func NewMouseState() *MouseState {
 func (m *MouseState) Update() {
 	m.PrevX = m.X
 	m.PrevY = m.Y
-	m.X, m.Y = ebiten.CursorPosition()
+	m.ScreenX, m.ScreenY = ebiten.CursorPosition()
+	m.X, m.Y = m.ScreenX, m.ScreenY
+	if m.Converter != nil {
+		x, y := m.Converter.ScreenToWorld(float64(m.ScreenX), float64(m.ScreenY))
+		m.X, m.Y = int(math.Floor(x)), int(math.Floor(y))
+	}
 	m.ScrollX, m.ScrollY = ebiten.Wheel()
 
 	// ドラッグ処理
@@ -48,4 +63,4 @@ func (m *MouseState) GetDragDelta() (dx, dy int) {
 		return m.X - m.PrevX, m.Y - m.PrevY
 	}
 	return 0, 0
-} 
\ No newline at end of file
+}
@@ -0,0 +1,270 @@
+package input
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// 割り当ての名前
+//
+//	キー:           "KeyZ"、"KeySpace"、"KeyArrowUp"、"KeyDigit1"
+//	修飾キー付き:   "Ctrl+KeyS"、"Shift+MouseLeft"（"Ctrl"、"Shift"、"Alt"、"Meta"か"Key..."を"+"でつなぐ）
+//	マウス:         "MouseLeft"、"MouseRight"、"MouseMiddle"、"MouseBack"、"MouseForward"
+//	パッド:         "GamepadA"、"GamepadStart"、"GamepadUp"（標準配置、Xboxの名前）
+//	スティック:     "GamepadLeftStickUp"、"GamepadRightStickLeft"
+//	ボタン番号:     "GamepadButton3"（標準配置が使えないパッド）
+//	プレイヤー指定: "GamepadA@2"
+var (
+	mouseButtonNames = map[ebiten.MouseButton]string{
+		ebiten.MouseButtonLeft:   "MouseLeft",
+		ebiten.MouseButtonRight:  "MouseRight",
+		ebiten.MouseButtonMiddle: "MouseMiddle",
+		ebiten.MouseButton3:      "MouseBack",
+		ebiten.MouseButton4:      "MouseForward",
+	}
+	padButtonNames = map[ebiten.StandardGamepadButton]string{
+		ebiten.StandardGamepadButtonRightBottom:      "GamepadA",
+		ebiten.StandardGamepadButtonRightRight:       "GamepadB",
+		ebiten.StandardGamepadButtonRightLeft:        "GamepadX",
+		ebiten.StandardGamepadButtonRightTop:         "GamepadY",
+		ebiten.StandardGamepadButtonFrontTopLeft:     "GamepadLB",
+		ebiten.StandardGamepadButtonFrontTopRight:    "GamepadRB",
+		ebiten.StandardGamepadButtonFrontBottomLeft:  "GamepadLT",
+		ebiten.StandardGamepadButtonFrontBottomRight: "GamepadRT",
+		ebiten.StandardGamepadButtonCenterLeft:       "GamepadBack",
+		ebiten.StandardGamepadButtonCenterRight:      "GamepadStart",
+		ebiten.StandardGamepadButtonCenterCenter:     "GamepadGuide",
+		ebiten.StandardGamepadButtonLeftStick:        "GamepadLS",
+		ebiten.StandardGamepadButtonRightStick:       "GamepadRS",
+		ebiten.StandardGamepadButtonLeftTop:          "GamepadUp",
+		ebiten.StandardGamepadButtonLeftBottom:       "GamepadDown",
+		ebiten.StandardGamepadButtonLeftLeft:         "GamepadLeft",
+		ebiten.StandardGamepadButtonLeftRight:        "GamepadRight",
+	}
+	modifierNames = map[ebiten.Key]string{
+		ebiten.KeyControl: "Ctrl",
+		ebiten.KeyShift:   "Shift",
+		ebiten.KeyAlt:     "Alt",
+		ebiten.KeyMeta:    "Meta",
+	}
+)
+
+// 割り当ての名前（"KeyZ"、"Ctrl+KeyS"、"GamepadA@2"など）
+func (b InputBinding) String() string {
+	var name string
+	switch b.Type {
+	case KeyboardInput:
+		name = keyName(b.Key)
+	case MouseInput:
+		var ok bool
+		if name, ok = mouseButtonNames[b.MouseBtn]; !ok {
+			name = fmt.Sprintf("MouseButton%d", b.MouseBtn)
+		}
+	case StandardGamepadInput:
+		var ok bool
+		if name, ok = padButtonNames[b.StandardButton]; !ok {
+			name = fmt.Sprintf("GamepadStandard%d", b.StandardButton)
+		}
+	case GamepadAxisInput:
+		name = axisName(b.Axis, b.AxisDirection)
+	case GamepadInput:
+		name = fmt.Sprintf("GamepadButton%d", b.Button)
+	default:
+		return fmt.Sprintf("Unknown(%d)", b.Type)
+	}
+
+	parts := make([]string, 0, len(b.Modifiers)+1)
+	for _, key := range b.Modifiers {
+		if mod, ok := modifierNames[key]; ok {
+			parts = append(parts, mod)
+		} else {
+			parts = append(parts, keyName(key))
+		}
+	}
+	name = strings.Join(append(parts, name), "+")
+	if b.Player != 0 {
+		name += "@" + strconv.Itoa(b.Player)
+	}
+	return name
+}
+
+func keyName(key ebiten.Key) string {
+	return "Key" + key.String()
+}
+
+func axisName(axis ebiten.StandardGamepadAxis, direction float64) string {
+	stick := "LeftStick"
+	if axis == ebiten.StandardGamepadAxisRightStickHorizontal || axis == ebiten.StandardGamepadAxisRightStickVertical {
+		stick = "RightStick"
+	}
+	horizontal := axis == ebiten.StandardGamepadAxisLeftStickHorizontal || axis == ebiten.StandardGamepadAxisRightStickHorizontal
+	switch {
+	case horizontal && direction < 0:
+		return "Gamepad" + stick + "Left"
+	case horizontal:
+		return "Gamepad" + stick + "Right"
+	case direction < 0:
+		return "Gamepad" + stick + "Up"
+	}
+	return "Gamepad" + stick + "Down"
+}
+
+// 名前から割り当てを作る（大文字小文字は区別しない）
+func ParseBinding(name string) (InputBinding, error) {
+	s := strings.TrimSpace(name)
+	player := 0
+	if i := strings.LastIndex(s, "@"); i >= 0 {
+		n, err := strconv.Atoi(s[i+1:])
+		if err != nil || n < 0 {
+			return InputBinding{}, fmt.Errorf("invalid player in binding: %s", name)
+		}
+		s, player = s[:i], n
+	}
+
+	parts := strings.Split(s, "+")
+	binding, err := parseInput(strings.TrimSpace(parts[len(parts)-1]))
+	if err != nil {
+		return InputBinding{}, err
+	}
+	for _, part := range parts[:len(parts)-1] {
+		key, err := parseModifier(strings.TrimSpace(part))
+		if err != nil {
+			return InputBinding{}, fmt.Errorf("%v in binding: %s", err, name)
+		}
+		binding.Modifiers = append(binding.Modifiers, key)
+	}
+	if player != 0 {
+		if binding.Type == KeyboardInput || binding.Type == MouseInput {
+			return InputBinding{}, fmt.Errorf("player can only be set for gamepad bindings: %s", name)
+		}
+		binding.Player = player
+	}
+	return binding, nil
+}
+
+// 名前から割り当てを作る（作れなければpanic）
+func MustParseBinding(name string) InputBinding {
+	b, err := ParseBinding(name)
+	if err != nil {
+		panic(err)
+	}
+	return b
+}
+
+func parseInput(name string) (InputBinding, error) {
+	lower := strings.ToLower(name)
+	switch {
+	case strings.HasPrefix(lower, "key"):
+		if key, ok := parseKey(name[len("key"):]); ok {
+			return KeyBinding(key), nil
+		}
+	case strings.HasPrefix(lower, "mousebutton"):
+		if n, err := strconv.Atoi(name[len("mousebutton"):]); err == nil && n >= 0 && n <= int(ebiten.MouseButtonMax) {
+			return MouseBinding(ebiten.MouseButton(n)), nil
+		}
+	case strings.HasPrefix(lower, "mouse"):
+		for button, n := range mouseButtonNames {
+			if strings.EqualFold(n, name) {
+				return MouseBinding(button), nil
+			}
+		}
+	case strings.HasPrefix(lower, "gamepadbutton"):
+		if n, err := strconv.Atoi(name[len("gamepadbutton"):]); err == nil && n >= 0 && n <= int(ebiten.GamepadButtonMax) {
+			return RawPadBinding(ebiten.GamepadButton(n)), nil
+		}
+	case strings.HasPrefix(lower, "gamepadstandard"):
+		if n, err := strconv.Atoi(name[len("gamepadstandard"):]); err == nil && n >= 0 && n <= int(ebiten.StandardGamepadButtonMax) {
+			return PadBinding(ebiten.StandardGamepadButton(n)), nil
+		}
+	case strings.HasPrefix(lower, "gamepad"):
+		for button, n := range padButtonNames {
+			if strings.EqualFold(n, name) {
+				return PadBinding(button), nil
+			}
+		}
+		for _, axis := range []ebiten.StandardGamepadAxis{
+			ebiten.StandardGamepadAxisLeftStickHorizontal, ebiten.StandardGamepadAxisLeftStickVertical,
+			ebiten.StandardGamepadAxisRightStickHorizontal, ebiten.StandardGamepadAxisRightStickVertical,
+		} {
+			for _, direction := range []float64{-1, 1} {
+				if strings.EqualFold(axisName(axis, direction), name) {
+					return AxisBinding(axis, direction), nil
+				}
+			}
+		}
+	}
+	return InputBinding{}, fmt.Errorf("unknown input: %s", name)
+}
+
+// "Ctrl"、"Shift"、"Alt"、"Meta"か"Key..."
+func parseModifier(name string) (ebiten.Key, error) {
+	for key, n := range modifierNames {
+		if strings.EqualFold(n, name) {
+			return key, nil
+		}
+	}
+	if strings.EqualFold(name, "Control") {
+		return ebiten.KeyControl, nil
+	}
+	if len(name) > len("key") && strings.EqualFold(name[:len("key")], "key") {
+		if key, ok := parseKey(name[len("key"):]); ok {
+			return key, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown modifier %q", name)
+}
+
+// Ebitenのキー名（"Z"、"Space"、"Digit1"など）
+func parseKey(name string) (ebiten.Key, bool) {
+	for key := ebiten.Key(0); key <= ebiten.KeyMax; key++ {
+		if strings.EqualFold(key.String(), name) {
+			return key, true
+		}
+	}
+	var key ebiten.Key
+	if err := key.UnmarshalText([]byte(name)); err != nil {
+		return 0, false
+	}
+	return key, true
+}
+
+// 設定ファイルには名前で書く
+func (b InputBinding) MarshalJSON() ([]byte, error) {
+	return json.Marshal(b.String())
+}
+
+// 名前のほか、以前の形式（{"Type": 0, "Key": 25, ...}）も読める
+func (b *InputBinding) UnmarshalJSON(data []byte) error {
+	var name string
+	if err := json.Unmarshal(data, &name); err == nil {
+		parsed, err := ParseBinding(name)
+		if err != nil {
+			return err
+		}
+		*b = parsed
+		return nil
+	}
+	var old struct {
+		Type     InputType
+		Key      json.RawMessage // 数値かEbitenのキー名
+		Button   ebiten.GamepadButton
+		MouseBtn ebiten.MouseButton
+	}
+	if err := json.Unmarshal(data, &old); err != nil {
+		return fmt.Errorf("binding must be a name like \"KeyZ\": %v", err)
+	}
+	*b = InputBinding{Type: old.Type, Button: old.Button, MouseBtn: old.MouseBtn}
+	if len(old.Key) > 0 {
+		var code int
+		if err := json.Unmarshal(old.Key, &code); err == nil {
+			b.Key = ebiten.Key(code)
+		} else if err := json.Unmarshal(old.Key, &b.Key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,299 @@
+package input
+
+import (
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+)
+
+// 次の入力を待つ時に受け付ける機器
+type DeviceFilter int
+
+const (
+	AnyDevice DeviceFilter = iota
+	KeyboardMouseDevice
+	GamepadDevice
+)
+
+// 次の入力を待つ時の設定
+type ListenOptions struct {
+	Devices    DeviceFilter
+	CancelKeys []ebiten.Key // 押すと取り消すキー（nilならEsc。割り当てたい時は空のスライスにする）
+	NoChords   bool         // trueなら修飾キーを押しても組み合わせにせず、すぐに修飾キー（"Ctrl"など）を割り当てる
+	NoAxes     bool         // trueならスティックを倒しても割り当てない
+}
+
+// 待っている入力
+type listenState struct {
+	options   ListenOptions
+	callback  func(binding InputBinding, ok bool)
+	modifiers []ebiten.Key // 押している修飾キー（押した順）
+	lone      bool         // 修飾キーだけを押して離したら、その修飾キーを割り当てる
+	axes      map[bindingInput]bool
+}
+
+// 修飾キーとして扱うキーと、組み合わせの名前に使うキー
+// Ebitenは左右のキーと一緒に左右をまとめたキー（KeyControlなど）も押したと報告するので、まとめたキーも含める
+var modifierKeys = map[ebiten.Key]ebiten.Key{
+	ebiten.KeyControl:      ebiten.KeyControl,
+	ebiten.KeyShift:        ebiten.KeyShift,
+	ebiten.KeyAlt:          ebiten.KeyAlt,
+	ebiten.KeyMeta:         ebiten.KeyMeta,
+	ebiten.KeyControlLeft:  ebiten.KeyControl,
+	ebiten.KeyControlRight: ebiten.KeyControl,
+	ebiten.KeyShiftLeft:    ebiten.KeyShift,
+	ebiten.KeyShiftRight:   ebiten.KeyShift,
+	ebiten.KeyAltLeft:      ebiten.KeyAlt,
+	ebiten.KeyAltRight:     ebiten.KeyAlt,
+	ebiten.KeyMetaLeft:     ebiten.KeyMeta,
+	ebiten.KeyMetaRight:    ebiten.KeyMeta,
+}
+
+// 次に押された入力を割り当てとしてcallbackに渡す（取り消されたらokがfalse）
+// 待っている間はアクションを押していないとみなし、終わった後は押している入力を離すまでアクションにしない
+// 既に待っていれば前の待ちを取り消す
+func (im *InputManager) ListenForBinding(options ListenOptions, callback func(binding InputBinding, ok bool)) {
+	im.mutex.Lock()
+	previous := im.listening
+	im.listening = &listenState{options: options, callback: callback}
+	im.mutex.Unlock()
+
+	if previous != nil && previous.callback != nil {
+		previous.callback(InputBinding{}, false)
+	}
+}
+
+// 入力を待つのをやめる（callbackにはokがfalseで渡す）
+func (im *InputManager) CancelListen() {
+	im.mutex.Lock()
+	listening := im.listening
+	im.listening = nil
+	im.holdUntilReleased()
+	im.mutex.Unlock()
+
+	if listening != nil && listening.callback != nil {
+		listening.callback(InputBinding{}, false)
+	}
+}
+
+func (im *InputManager) IsListening() bool {
+	im.mutex.RLock()
+	defer im.mutex.RUnlock()
+	return im.listening != nil
+}
+
+// 押されたアクションを離すまで押していないとみなす（ロックした状態で呼ぶ）
+func (im *InputManager) holdUntilReleased() {
+	for _, c := range im.contexts {
+		c.eachBinding(im.bindings, func(action Action, bindings []InputBinding) {
+			c.released[action] = true
+		})
+	}
+}
+
+// 待っている入力を探し、見つかれば呼ぶ関数を返す（ロックした状態で呼ぶ）
+func (im *InputManager) pollListen() func() {
+	l := im.listening
+	binding, done, ok := l.poll(im)
+	if !done {
+		return nil
+	}
+	im.listening = nil
+	im.holdUntilReleased()
+	if l.callback == nil {
+		return nil
+	}
+	return func() { l.callback(binding, ok) }
+}
+
+// 入力を探す（見つかればdoneがtrue、取り消されたらokがfalse）
+func (l *listenState) poll(im *InputManager) (binding InputBinding, done, ok bool) {
+	cancelKeys := l.options.CancelKeys
+	if cancelKeys == nil {
+		cancelKeys = []ebiten.Key{ebiten.KeyEscape}
+	}
+	keyboard := l.options.Devices != GamepadDevice
+	gamepad := l.options.Devices != KeyboardMouseDevice
+
+	if keyboard {
+		keys := inpututil.AppendJustPressedKeys(nil)
+		for _, key := range keys {
+			for _, cancel := range cancelKeys {
+				if key == cancel {
+					return InputBinding{}, true, false
+				}
+			}
+		}
+		// 同じフレームに押した修飾キーを先に加える（Ctrl+Sを同時に押してもCtrl+KeySになる）
+		for _, key := range keys {
+			if mod, isModifier := modifierKeys[key]; isModifier {
+				if l.options.NoChords {
+					return KeyBinding(mod), true, true
+				}
+				if !containsKey(l.modifiers, mod) {
+					l.modifiers = append(l.modifiers, mod)
+				}
+				l.lone = true
+			}
+		}
+		for _, key := range keys {
+			if _, isModifier := modifierKeys[key]; !isModifier {
+				return l.withModifiers(KeyBinding(key)), true, true
+			}
+		}
+
+		// 修飾キーだけを押して離した
+		for i := 0; i < len(l.modifiers); {
+			if ebiten.IsKeyPressed(l.modifiers[i]) {
+				i++
+				continue
+			}
+			mod := l.modifiers[i]
+			l.modifiers = append(l.modifiers[:i], l.modifiers[i+1:]...)
+			if l.lone {
+				return KeyBinding(mod), true, true
+			}
+		}
+
+		for button := range mouseButtonNames {
+			if inpututil.IsMouseButtonJustPressed(button) {
+				return l.withModifiers(MouseBinding(button)), true, true
+			}
+		}
+	}
+
+	if gamepad {
+		for _, id := range im.gamepads.connected {
+			if ebiten.IsStandardGamepadLayoutAvailable(id) {
+				for button := ebiten.StandardGamepadButton(0); button <= ebiten.StandardGamepadButtonMax; button++ {
+					if inpututil.IsStandardGamepadButtonJustPressed(id, button) {
+						return PadBinding(button), true, true
+					}
+				}
+				if binding, found := l.pollAxes(im, id); found {
+					return binding, true, true
+				}
+				continue
+			}
+			for button := ebiten.GamepadButton(0); button <= ebiten.GamepadButtonMax; button++ {
+				if inpututil.IsGamepadButtonJustPressed(id, button) {
+					return RawPadBinding(button), true, true
+				}
+			}
+		}
+	}
+	return InputBinding{}, false, false
+}
+
+// 倒し始めたスティックの軸（待ち始めた時に倒していた軸は一度戻すまで受け付けない）
+func (l *listenState) pollAxes(im *InputManager, id ebiten.GamepadID) (InputBinding, bool) {
+	if l.options.NoAxes {
+		return InputBinding{}, false
+	}
+	first := l.axes == nil
+	if first {
+		l.axes = make(map[bindingInput]bool)
+	}
+	for _, axis := range []ebiten.StandardGamepadAxis{
+		ebiten.StandardGamepadAxisLeftStickHorizontal, ebiten.StandardGamepadAxisLeftStickVertical,
+		ebiten.StandardGamepadAxisRightStickHorizontal, ebiten.StandardGamepadAxisRightStickVertical,
+	} {
+		for _, direction := range []float64{-1, 1} {
+			binding := AxisBinding(axis, direction)
+			tilted := im.gamepads.axis(id, axis)*direction >= im.gamepads.config.AxisThreshold
+			was := l.axes[binding.input()]
+			l.axes[binding.input()] = tilted
+			if tilted && !was && !first {
+				return binding, true
+			}
+		}
+	}
+	return InputBinding{}, false
+}
+
+// 押している修飾キーを付ける（修飾キー以外を押したので、修飾キーだけの割り当てにはしない）
+func (l *listenState) withModifiers(binding InputBinding) InputBinding {
+	l.lone = false
+	if len(l.modifiers) == 0 {
+		return binding
+	}
+	return binding.WithModifiers(l.modifiers...)
+}
+
+func containsKey(keys []ebiten.Key, key ebiten.Key) bool {
+	for _, k := range keys {
+		if k == key {
+			return true
+		}
+	}
+	return false
+}
+
+// actionのindex番目の割り当てをbindingに置き換える（indexが範囲外なら追加する）
+// 同じ入力がほかのアクションに割り当てられていて、swapがtrueなら置き換えられた割り当てと入れ替え、falseならそちらから外す
+// 割り当てが変わったほかのアクションを返す
+func (im *InputManager) Rebind(action Action, index int, binding InputBinding, swap bool) []Action {
+	im.mutex.Lock()
+	defer im.mutex.Unlock()
+
+	var old *InputBinding
+	current := im.bindings[action]
+	for i, b := range current {
+		if i != index && b.Equal(binding) {
+			// 既に割り当て済み
+			return nil
+		}
+	}
+	updated := append([]InputBinding{}, current...)
+	if index >= 0 && index < len(updated) {
+		replaced := updated[index]
+		old = &replaced
+		updated[index] = binding
+	} else {
+		updated = append(updated, binding)
+	}
+	im.bindings[action] = updated
+
+	var changed []Action
+	for other, bindings := range im.bindings {
+		if other == action {
+			continue
+		}
+		for i, b := range bindings {
+			if !b.Equal(binding) {
+				continue
+			}
+			replaced := append([]InputBinding{}, bindings...)
+			if swap && old != nil && !containsBinding(replaced, *old) {
+				replaced[i] = *old
+			} else {
+				replaced = append(replaced[:i], replaced[i+1:]...)
+			}
+			im.bindings[other] = replaced
+			changed = append(changed, other)
+			break
+		}
+	}
+	return changed
+}
+
+// 同じ入力と修飾キーか
+func (b InputBinding) Equal(other InputBinding) bool {
+	if b.input() != other.input() || len(b.Modifiers) != len(other.Modifiers) {
+		return false
+	}
+	for _, key := range b.Modifiers {
+		if !containsKey(other.Modifiers, key) {
+			return false
+		}
+	}
+	return true
+}
+
+func containsBinding(bindings []InputBinding, binding InputBinding) bool {
+	for _, b := range bindings {
+		if b.Equal(binding) {
+			return true
+		}
+	}
+	return false
+}
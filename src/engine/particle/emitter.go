@@ -5,6 +5,8 @@ import (
 	"math"
 	"math/rand"
 
+	"gameengine/src/engine/render"
+
 	"github.com/hajimehoshi/ebiten/v2"
 )
 
@@ -74,6 +76,16 @@ func (e *Emitter) Draw(screen *ebiten.Image) {
 	}
 }
 
+// 生きているパーティクルを描画キューに積む（同じ画像はまとめて描画される）
+func (e *Emitter) Collect(q *render.RenderQueue, key render.SortKey) {
+	for _, p := range e.particles {
+		if !p.Active || p.Image == nil {
+			continue
+		}
+		q.PushImage(key, p.Image, p.geoM(), p.colorScale())
+	}
+}
+
 func (e *Emitter) spawnParticle() {
 	// 非アクティブなパーティクルを探す
 	var particle *Particle
@@ -114,4 +126,4 @@ func (e *Emitter) SetPosition(x, y float64) {
 
 func (e *Emitter) SetActive(active bool) {
 	e.active = active
-} 
\ No newline at end of file
+}
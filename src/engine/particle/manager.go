@@ -3,6 +3,8 @@ package particle
 import (
 	"sync"
 
+	"gameengine/src/engine/render"
+
 	"github.com/hajimehoshi/ebiten/v2"
 )
 
@@ -52,4 +54,14 @@ func (m *ParticleManager) Draw(screen *ebiten.Image) {
 	for _, emitter := range m.emitters {
 		emitter.Draw(screen)
 	}
-} 
\ No newline at end of file
+}
+
+// 全エミッターを描画キューに積む
+func (m *ParticleManager) Collect(q *render.RenderQueue, key render.SortKey) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	for _, emitter := range m.emitters {
+		emitter.Collect(q, key)
+	}
+}
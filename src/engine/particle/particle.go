@@ -9,20 +9,20 @@ import (
 
 // パーティクル1つの情報
 type Particle struct {
-	Position    Vector2D
-	Velocity    Vector2D
+	Position     Vector2D
+	Velocity     Vector2D
 	Acceleration Vector2D
-	Scale       float64
-	ScaleVel    float64
-	Rotation    float64
-	RotationVel float64
-	Color       color.RGBA
-	Alpha       float64
-	AlphaVel    float64
-	Life        float64
-	MaxLife     float64
-	Active      bool
-	Image       *ebiten.Image
+	Scale        float64
+	ScaleVel     float64
+	Rotation     float64
+	RotationVel  float64
+	Color        color.RGBA
+	Alpha        float64
+	AlphaVel     float64
+	Life         float64
+	MaxLife      float64
+	Active       bool
+	Image        *ebiten.Image
 }
 
 // 2D座標/ベクトル
@@ -72,25 +72,36 @@ func (p *Particle) Draw(screen *ebiten.Image) {
 	}
 
 	op := &ebiten.DrawImageOptions{}
+	op.GeoM = p.geoM()
+	op.ColorScale = p.colorScale()
+	screen.DrawImage(p.Image, op)
+}
+
+func (p *Particle) geoM() ebiten.GeoM {
+	var geoM ebiten.GeoM
 
 	// スケーリング
-	op.GeoM.Scale(p.Scale, p.Scale)
+	geoM.Scale(p.Scale, p.Scale)
 
 	// 回転
-	op.GeoM.Rotate(p.Rotation)
+	geoM.Rotate(p.Rotation)
 
 	// 移動
 	w, h := p.Image.Bounds().Dx(), p.Image.Bounds().Dy()
-	op.GeoM.Translate(-float64(w)/2, -float64(h)/2)
-	op.GeoM.Translate(p.Position.X, p.Position.Y)
-
-	// カラー/アルファ値
-	op.ColorM.Scale(
-		float64(p.Color.R)/255,
-		float64(p.Color.G)/255,
-		float64(p.Color.B)/255,
-		float64(p.Color.A)/255*p.Alpha,
-	)
+	geoM.Translate(-float64(w)/2, -float64(h)/2)
+	geoM.Translate(p.Position.X, p.Position.Y)
+	return geoM
+}
 
-	screen.DrawImage(p.Image, op)
-} 
\ No newline at end of file
+// カラー/アルファ値（乗算済みアルファ）
+func (p *Particle) colorScale() ebiten.ColorScale {
+	a := float32(p.Color.A) / 255 * float32(p.Alpha)
+	var cs ebiten.ColorScale
+	cs.Scale(
+		float32(p.Color.R)/255*a,
+		float32(p.Color.G)/255*a,
+		float32(p.Color.B)/255*a,
+		a,
+	)
+	return cs
+}
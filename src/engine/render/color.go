@@ -0,0 +1,87 @@
+package render
+
+import (
+	"fmt"
+	"image/color"
+	"math"
+	"strconv"
+	"strings"
+)
+
+var namedColors = map[string]color.RGBA{
+	"black":   {0, 0, 0, 255},
+	"white":   {255, 255, 255, 255},
+	"red":     {255, 0, 0, 255},
+	"green":   {0, 255, 0, 255},
+	"blue":    {0, 0, 255, 255},
+	"yellow":  {255, 255, 0, 255},
+	"cyan":    {0, 255, 255, 255},
+	"magenta": {255, 0, 255, 255},
+	"gray":    {128, 128, 128, 255},
+}
+
+// 色の文字列を解釈する
+// "white" などの色名、"#rrggbb" / "#rrggbbaa"、"rgb(r, g, b)" / "rgba(r, g, b, a)" に対応
+// rgbaのaはCSSと同じく0〜1
+func ParseColor(s string) (color.RGBA, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if c, exists := namedColors[s]; exists {
+		return c, nil
+	}
+
+	if strings.HasPrefix(s, "#") && (len(s) == 7 || len(s) == 9) {
+		v, err := strconv.ParseUint(s[1:], 16, 32)
+		if err == nil {
+			if len(s) == 7 {
+				v = v<<8 | 0xff
+			}
+			return NewRGBA(uint8(v>>24), uint8(v>>16), uint8(v>>8), uint8(v)), nil
+		}
+	}
+
+	if c, ok := parseRGBFunc(s); ok {
+		return c, nil
+	}
+
+	return color.RGBA{}, fmt.Errorf("invalid color: %q", s)
+}
+
+// "rgb(255, 0, 0)" / "rgba(0, 0, 0, 0.8)"
+func parseRGBFunc(s string) (color.RGBA, bool) {
+	var args string
+	var withAlpha bool
+	switch {
+	case strings.HasPrefix(s, "rgba(") && strings.HasSuffix(s, ")"):
+		args, withAlpha = s[5:len(s)-1], true
+	case strings.HasPrefix(s, "rgb(") && strings.HasSuffix(s, ")"):
+		args = s[4 : len(s)-1]
+	default:
+		return color.RGBA{}, false
+	}
+
+	parts := strings.Split(args, ",")
+	if (withAlpha && len(parts) != 4) || (!withAlpha && len(parts) != 3) {
+		return color.RGBA{}, false
+	}
+	values := [4]float64{0, 0, 0, 1}
+	for i, p := range parts {
+		v, err := strconv.ParseFloat(strings.TrimSpace(p), 64)
+		if err != nil {
+			return color.RGBA{}, false
+		}
+		values[i] = v
+	}
+
+	channel := func(v, max float64) uint8 {
+		return uint8(math.Round(math.Max(0, math.Min(max, v)) * 255 / max))
+	}
+	return NewRGBA(channel(values[0], 255), channel(values[1], 255), channel(values[2], 255), channel(values[3], 1)), true
+}
+
+// アルファ値を掛けていない成分から色を作る（color.RGBAは乗算済みのため）
+func NewRGBA(r, g, b, a uint8) color.RGBA {
+	mul := func(v uint8) uint8 {
+		return uint8((uint32(v)*uint32(a) + 127) / 255)
+	}
+	return color.RGBA{mul(r), mul(g), mul(b), a}
+}
@@ -0,0 +1,125 @@
+package render
+
+import (
+	"embed"
+	"fmt"
+	"sort"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+//go:embed shaders/*.kage
+var shaderFS embed.FS
+
+// パス間に掛けるポストエフェクト
+type Effect interface {
+	Name() string
+	Enabled() bool
+	SetEnabled(enabled bool)
+	SetParam(name string, value float64) error
+	Param(name string) (float64, bool)
+	Params() []string
+	Update(dt float64)
+	Apply(dst, src *ebiten.Image)
+}
+
+// シェーダーのuniformと、それを構成するパラメータ名
+type uniform struct {
+	name   string
+	params []string
+}
+
+// Kageシェーダー1つで構成するエフェクト
+type ShaderEffect struct {
+	name     string
+	file     string
+	enabled  bool
+	params   map[string]float64
+	uniforms []uniform
+	shader   *ebiten.Shader
+	failed   bool
+}
+
+func newShaderEffect(name, file string, uniforms []uniform, defaults map[string]float64) *ShaderEffect {
+	params := make(map[string]float64, len(defaults))
+	for k, v := range defaults {
+		params[k] = v
+	}
+	return &ShaderEffect{
+		name:     name,
+		file:     file,
+		enabled:  true,
+		params:   params,
+		uniforms: uniforms,
+	}
+}
+
+func (e *ShaderEffect) Name() string            { return e.name }
+func (e *ShaderEffect) Enabled() bool           { return e.enabled && !e.failed }
+func (e *ShaderEffect) SetEnabled(enabled bool) { e.enabled = enabled }
+func (e *ShaderEffect) Update(dt float64)       {}
+
+func (e *ShaderEffect) SetParam(name string, value float64) error {
+	if _, exists := e.params[name]; !exists {
+		return fmt.Errorf("effect %s has no parameter: %s", e.name, name)
+	}
+	e.params[name] = value
+	return nil
+}
+
+func (e *ShaderEffect) Param(name string) (float64, bool) {
+	value, exists := e.params[name]
+	return value, exists
+}
+
+func (e *ShaderEffect) Params() []string {
+	names := make([]string, 0, len(e.params))
+	for name := range e.params {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// シェーダーは初回使用時にコンパイルする
+func (e *ShaderEffect) load() *ebiten.Shader {
+	if e.shader != nil || e.failed {
+		return e.shader
+	}
+	src, err := shaderFS.ReadFile("shaders/" + e.file)
+	if err == nil {
+		e.shader, err = ebiten.NewShader(src)
+	}
+	if err != nil {
+		fmt.Printf("Failed to compile shader %s: %v\n", e.file, err)
+		e.failed = true
+	}
+	return e.shader
+}
+
+func (e *ShaderEffect) Apply(dst, src *ebiten.Image) {
+	shader := e.load()
+	if shader == nil {
+		dst.DrawImage(src, nil)
+		return
+	}
+
+	values := make(map[string]interface{}, len(e.uniforms))
+	for _, u := range e.uniforms {
+		if len(u.params) == 1 {
+			values[u.name] = float32(e.params[u.params[0]])
+			continue
+		}
+		vec := make([]float32, len(u.params))
+		for i, p := range u.params {
+			vec[i] = float32(e.params[p])
+		}
+		values[u.name] = vec
+	}
+
+	bounds := src.Bounds()
+	op := &ebiten.DrawRectShaderOptions{}
+	op.Uniforms = values
+	op.Images[0] = src
+	dst.DrawRectShader(bounds.Dx(), bounds.Dy(), shader, op)
+}
@@ -0,0 +1,191 @@
+package render
+
+import (
+	"fmt"
+	"image/color"
+)
+
+// エフェクトの種類
+const (
+	EffectColorGrade = "color_grade"
+	EffectVignette   = "vignette"
+	EffectCRT        = "crt"
+	EffectFade       = "fade"
+	EffectFlash      = "flash"
+)
+
+// 種類名からエフェクトを作成
+func NewEffect(kind, name string) (Effect, error) {
+	if name == "" {
+		name = kind
+	}
+	switch kind {
+	case EffectColorGrade:
+		return NewColorGradeEffect(name), nil
+	case EffectVignette:
+		return NewVignetteEffect(name), nil
+	case EffectCRT:
+		return NewCRTEffect(name), nil
+	case EffectFade:
+		return NewFadeEffect(name), nil
+	case EffectFlash:
+		return NewFlashEffect(name), nil
+	default:
+		return nil, fmt.Errorf("unknown effect type: %s", kind)
+	}
+}
+
+// 明るさ・コントラスト・彩度・色味の補正
+func NewColorGradeEffect(name string) *ShaderEffect {
+	return newShaderEffect(name, "colorgrade.kage", []uniform{
+		{"Brightness", []string{"brightness"}},
+		{"Contrast", []string{"contrast"}},
+		{"Saturation", []string{"saturation"}},
+		{"Tint", []string{"tint_r", "tint_g", "tint_b"}},
+	}, map[string]float64{
+		"brightness": 0,
+		"contrast":   1,
+		"saturation": 1,
+		"tint_r":     1,
+		"tint_g":     1,
+		"tint_b":     1,
+	})
+}
+
+func NewVignetteEffect(name string) *ShaderEffect {
+	return newShaderEffect(name, "vignette.kage", []uniform{
+		{"Color", []string{"r", "g", "b"}},
+		{"Intensity", []string{"intensity"}},
+		{"Radius", []string{"radius"}},
+		{"Softness", []string{"softness"}},
+	}, map[string]float64{
+		"r":         0,
+		"g":         0,
+		"b":         0,
+		"intensity": 0.5,
+		"radius":    0.75,
+		"softness":  0.45,
+	})
+}
+
+func NewCRTEffect(name string) *ShaderEffect {
+	return newShaderEffect(name, "crt.kage", []uniform{
+		{"Curvature", []string{"curvature"}},
+		{"Scanline", []string{"scanline"}},
+		{"Aberration", []string{"aberration"}},
+	}, map[string]float64{
+		"curvature":  0.04,
+		"scanline":   0.25,
+		"aberration": 1,
+	})
+}
+
+func newBlendEffect(name string) *ShaderEffect {
+	return newShaderEffect(name, "fade.kage", []uniform{
+		{"Color", []string{"r", "g", "b"}},
+		{"Amount", []string{"amount"}},
+	}, map[string]float64{
+		"r":      0,
+		"g":      0,
+		"b":      0,
+		"amount": 0,
+	})
+}
+
+func setEffectColor(e *ShaderEffect, c color.Color) {
+	r, g, b, _ := c.RGBA()
+	e.params["r"] = float64(r) / 0xffff
+	e.params["g"] = float64(g) / 0xffff
+	e.params["b"] = float64(b) / 0xffff
+}
+
+// 単色へのフェード（シーン切り替え用）
+type FadeEffect struct {
+	*ShaderEffect
+	from, to float64
+	duration float64
+	elapsed  float64
+}
+
+func NewFadeEffect(name string) *FadeEffect {
+	return &FadeEffect{ShaderEffect: newBlendEffect(name)}
+}
+
+// 指定色へ向けてフェードアウト
+func (e *FadeEffect) FadeOut(c color.Color, duration float64) {
+	setEffectColor(e.ShaderEffect, c)
+	e.FadeTo(1, duration)
+}
+
+func (e *FadeEffect) FadeIn(duration float64) {
+	e.FadeTo(0, duration)
+}
+
+// 合成率をdurationかけてtargetまで変化させる
+func (e *FadeEffect) FadeTo(target, duration float64) {
+	e.from = e.params["amount"]
+	e.to = target
+	e.duration = duration
+	e.elapsed = 0
+	if duration <= 0 {
+		e.params["amount"] = target
+	}
+}
+
+func (e *FadeEffect) IsFading() bool {
+	return e.elapsed < e.duration
+}
+
+func (e *FadeEffect) Update(dt float64) {
+	if !e.IsFading() {
+		return
+	}
+	e.elapsed += dt
+	t := e.elapsed / e.duration
+	if t > 1 {
+		t = 1
+	}
+	e.params["amount"] = e.from + (e.to-e.from)*t
+}
+
+// 完全に透明な間は描画を省く
+func (e *FadeEffect) Enabled() bool {
+	return e.ShaderEffect.Enabled() && e.params["amount"] > 0
+}
+
+// 一瞬だけ単色を重ねて減衰させる（被弾演出など）
+type FlashEffect struct {
+	*ShaderEffect
+	intensity float64
+	duration  float64
+	elapsed   float64
+}
+
+func NewFlashEffect(name string) *FlashEffect {
+	return &FlashEffect{ShaderEffect: newBlendEffect(name)}
+}
+
+func (e *FlashEffect) Flash(c color.Color, intensity, duration float64) {
+	setEffectColor(e.ShaderEffect, c)
+	e.intensity = intensity
+	e.duration = duration
+	e.elapsed = 0
+	e.params["amount"] = intensity
+}
+
+func (e *FlashEffect) Update(dt float64) {
+	if e.elapsed >= e.duration {
+		e.params["amount"] = 0
+		return
+	}
+	e.elapsed += dt
+	t := e.elapsed / e.duration
+	if t > 1 {
+		t = 1
+	}
+	e.params["amount"] = e.intensity * (1 - t)
+}
+
+func (e *FlashEffect) Enabled() bool {
+	return e.ShaderEffect.Enabled() && e.params["amount"] > 0
+}
@@ -2,27 +2,32 @@ package render
 
 import (
 	"sync"
+
 	"github.com/hajimehoshi/ebiten/v2"
 )
 
+// エンティティを持たない常駐の描画物（背景など）
 type DrawableObject struct {
-	Image     *ebiten.Image
-	X, Y      float64
-	ZIndex    int
-	Visible   bool
-	Options   *ebiten.DrawImageOptions
+	Image   *ebiten.Image
+	X, Y    float64
+	Layer   int
+	ZIndex  int
+	Visible bool
+	Options *ebiten.DrawImageOptions
 }
 
+// 描画キューと常駐の描画物を管理する
+// ECSのシステムやUIは毎フレームQueue()にコマンドを積む
 type RenderManager struct {
 	mutex     sync.RWMutex
 	drawables map[string]*DrawableObject
-	cache     *RenderCache
+	queue     *RenderQueue
 }
 
 func NewRenderManager() *RenderManager {
 	return &RenderManager{
 		drawables: make(map[string]*DrawableObject),
-		cache:     NewRenderCache(),
+		queue:     NewRenderQueue(),
 	}
 }
 
@@ -30,20 +35,57 @@ func (r *RenderManager) RegisterDrawable(name string, obj *DrawableObject) {
 	r.mutex.Lock()
 	defer r.mutex.Unlock()
 	r.drawables[name] = obj
-	r.cache.Invalidate()
 }
 
-func (r *RenderManager) Draw(screen *ebiten.Image) {
+func (r *RenderManager) UnregisterDrawable(name string) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	delete(r.drawables, name)
+}
+
+func (r *RenderManager) GetDrawable(name string) *DrawableObject {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	return r.drawables[name]
+}
+
+func (r *RenderManager) Queue() *RenderQueue {
+	return r.queue
+}
+
+// フレームの開始（キューを空にして常駐の描画物を積む）
+func (r *RenderManager) Begin() {
 	r.mutex.RLock()
 	defer r.mutex.RUnlock()
 
-	// キャッシュされた描画順を使用
-	drawOrder := r.cache.GetDrawOrder(r.drawables)
-	
-	for _, name := range drawOrder {
-		obj := r.drawables[name]
-		if obj.Visible {
-			screen.DrawImage(obj.Image, obj.Options)
+	r.queue.Begin()
+	for _, obj := range r.drawables {
+		if !obj.Visible || obj.Image == nil {
+			continue
+		}
+		cmd := DrawCommand{
+			Key:   SortKey{Layer: obj.Layer, Z: obj.ZIndex, Y: obj.Y},
+			Image: obj.Image,
+		}
+		if obj.Options != nil {
+			cmd.GeoM = obj.Options.GeoM
+			cmd.ColorScale = obj.Options.ColorScale
+			cmd.Blend = obj.Options.Blend
+			cmd.Filter = obj.Options.Filter
 		}
+		cmd.GeoM.Translate(obj.X, obj.Y)
+		r.queue.Push(cmd)
 	}
-} 
\ No newline at end of file
+}
+
+func (r *RenderManager) DrawLayers(screen *ebiten.Image, minLayer, maxLayer int) {
+	r.queue.DrawLayers(screen, minLayer, maxLayer)
+}
+
+func (r *RenderManager) Draw(screen *ebiten.Image) {
+	r.queue.Draw(screen)
+}
+
+func (r *RenderManager) Stats() RenderStats {
+	return r.queue.Stats()
+}
@@ -0,0 +1,177 @@
+package render
+
+import (
+	"fmt"
+	"image/color"
+	"sync"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// 標準のパス名
+const (
+	PassWorld = "world"
+	PassUI    = "ui"
+	PassDebug = "debug"
+)
+
+type PassFunc func(dst *ebiten.Image)
+
+// オフスクリーンに描画する1段分
+// 付けたエフェクトは、このパスまでの合成結果に掛かる
+type RenderPass struct {
+	Name    string
+	Enabled bool
+	draw    PassFunc
+	target  RenderTarget
+	effects []Effect
+}
+
+func (p *RenderPass) AddEffect(effect Effect) {
+	p.effects = append(p.effects, effect)
+}
+
+func (p *RenderPass) RemoveEffect(name string) bool {
+	for i, effect := range p.effects {
+		if effect.Name() == name {
+			p.effects = append(p.effects[:i], p.effects[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+func (p *RenderPass) Effects() []Effect {
+	return p.effects
+}
+
+// 描画パスを順に合成する
+type Pipeline struct {
+	mutex      sync.RWMutex
+	passes     []*RenderPass
+	canvas     RenderTarget
+	work       RenderTarget
+	ClearColor color.Color
+}
+
+func NewPipeline() *Pipeline {
+	return &Pipeline{
+		passes:     make([]*RenderPass, 0),
+		ClearColor: color.RGBA{0, 0, 0, 255},
+	}
+}
+
+// パスを末尾に追加
+func (p *Pipeline) AddPass(name string, draw PassFunc) *RenderPass {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	pass := &RenderPass{Name: name, Enabled: true, draw: draw}
+	p.passes = append(p.passes, pass)
+	return pass
+}
+
+func (p *Pipeline) Pass(name string) *RenderPass {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	for _, pass := range p.passes {
+		if pass.Name == name {
+			return pass
+		}
+	}
+	return nil
+}
+
+func (p *Pipeline) AddEffect(passName string, effect Effect) error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	for _, pass := range p.passes {
+		if pass.Name == passName {
+			pass.AddEffect(effect)
+			return nil
+		}
+	}
+	return fmt.Errorf("render pass not found: %s", passName)
+}
+
+// 全パスからエフェクトを探す
+func (p *Pipeline) Effect(name string) Effect {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	for _, pass := range p.passes {
+		for _, effect := range pass.effects {
+			if effect.Name() == name {
+				return effect
+			}
+		}
+	}
+	return nil
+}
+
+func (p *Pipeline) RemoveEffect(name string) bool {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	for _, pass := range p.passes {
+		if pass.RemoveEffect(name) {
+			return true
+		}
+	}
+	return false
+}
+
+// フェードなど時間で変化するエフェクトを進める（エフェクトの状態を書き換えるので書き込みのロックを取る）
+func (p *Pipeline) Update(dt float64) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	for _, pass := range p.passes {
+		for _, effect := range pass.effects {
+			effect.Update(dt)
+		}
+	}
+}
+
+// 全パスを指定サイズのオフスクリーンに描画して合成結果を返す
+// canvasとworkを入れ替えるので書き込みのロックを取る
+func (p *Pipeline) Render(w, h int) *ebiten.Image {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	canvas := p.canvas.Ensure(w, h)
+	canvas.Fill(p.ClearColor)
+
+	for _, pass := range p.passes {
+		if !pass.Enabled {
+			continue
+		}
+
+		img := pass.target.Ensure(w, h)
+		img.Clear()
+		pass.draw(img)
+		canvas.DrawImage(img, nil)
+
+		for _, effect := range pass.effects {
+			if !effect.Enabled() {
+				continue
+			}
+			work := p.work.Ensure(w, h)
+			work.Clear()
+			effect.Apply(work, canvas)
+			// 結果を次の合成先にする
+			p.canvas, p.work = p.work, p.canvas
+			canvas = work
+		}
+	}
+
+	return canvas
+}
+
+// 画面と同じサイズで描画
+func (p *Pipeline) Draw(screen *ebiten.Image) {
+	bounds := screen.Bounds()
+	screen.DrawImage(p.Render(bounds.Dx(), bounds.Dy()), nil)
+}
@@ -0,0 +1,239 @@
+package render
+
+import (
+	"image"
+	"sort"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// 描画レイヤーの区切り（小さいほど奥）
+const (
+	LayerBackground = -1000 // タイルマップ
+	LayerWorld      = 0     // スプライト・パーティクル
+	LayerUI         = 1000  // UIウィンドウ
+	LayerText       = 1500  // テキスト（UIより手前）
+	LayerDebug      = 2000
+	LayerMax        = 1 << 30
+)
+
+// 1バッチに入るスプライト数（インデックスがuint16に収まる範囲）
+const maxBatchSprites = ebiten.MaxIndicesCount / 6
+
+// 並び順のキー（Layer → Z → Y の順に比較）
+type SortKey struct {
+	Layer int
+	Z     int
+	Y     float64
+}
+
+func (k SortKey) Less(o SortKey) bool {
+	if k.Layer != o.Layer {
+		return k.Layer < o.Layer
+	}
+	if k.Z != o.Z {
+		return k.Z < o.Z
+	}
+	return k.Y < o.Y
+}
+
+// 描画コマンド
+// Imageがあれば画像として描画（同じ画像が続けばまとめて描画）、なければDrawを呼ぶ
+type DrawCommand struct {
+	Key        SortKey
+	Image      *ebiten.Image
+	Source     image.Rectangle // 空でなければImageのこの範囲を描く（アトラス用）
+	GeoM       ebiten.GeoM
+	ColorScale ebiten.ColorScale
+	Blend      ebiten.Blend
+	Filter     ebiten.Filter
+	Draw       func(dst *ebiten.Image)
+	seq        int
+}
+
+// 1フレームの描画統計
+type RenderStats struct {
+	Commands  int // 描画コマンド数
+	DrawCalls int // 実際の描画呼び出し数
+	Batches   int // 複数のスプライトをまとめた描画の数
+	Batched   int // まとめて描画されたスプライトの数
+}
+
+// テキスト描画のキャッシュ統計（起動からの累計と現在の量）
+type TextStats struct {
+	CacheHits    int // 文字列ごとの画像の再利用
+	CacheMisses  int // 文字列ごとの画像の作成
+	Evictions    int // 古い順に破棄した画像
+	CachedImages int
+	CachedPixels int
+	AtlasGlyphs  int // アトラスにある文字
+	AtlasPages   int
+	AtlasHits    int
+	AtlasMisses  int
+	AtlasTexts   int // 直前のフレームでアトラスから描いたテキスト
+}
+
+// 1フレーム分の描画コマンドを集めて、並べ替えて描画する
+type RenderQueue struct {
+	commands []DrawCommand
+	sorted   bool
+	stats    RenderStats
+	last     RenderStats
+	vertices []ebiten.Vertex
+	indices  []uint16
+}
+
+func NewRenderQueue() *RenderQueue {
+	return &RenderQueue{
+		commands: make([]DrawCommand, 0, 256),
+	}
+}
+
+// フレームの開始（前フレームのコマンドを破棄して統計を確定）
+func (q *RenderQueue) Begin() {
+	q.last = q.stats
+	q.stats = RenderStats{}
+	q.commands = q.commands[:0]
+	q.sorted = true
+}
+
+func (q *RenderQueue) Push(cmd DrawCommand) {
+	cmd.seq = len(q.commands)
+	q.commands = append(q.commands, cmd)
+	q.sorted = false
+	q.stats.Commands++
+}
+
+func (q *RenderQueue) PushImage(key SortKey, img *ebiten.Image, geoM ebiten.GeoM, colorScale ebiten.ColorScale) {
+	q.Push(DrawCommand{Key: key, Image: img, GeoM: geoM, ColorScale: colorScale})
+}
+
+// アトラス画像の一部を描画（同じアトラスの範囲はまとめて描画される）
+func (q *RenderQueue) PushImageRect(key SortKey, img *ebiten.Image, src image.Rectangle, geoM ebiten.GeoM, colorScale ebiten.ColorScale) {
+	q.Push(DrawCommand{Key: key, Image: img, Source: src, GeoM: geoM, ColorScale: colorScale})
+}
+
+func (q *RenderQueue) PushFunc(key SortKey, draw func(dst *ebiten.Image)) {
+	q.Push(DrawCommand{Key: key, Draw: draw})
+}
+
+// 直前に完了したフレームの統計
+func (q *RenderQueue) Stats() RenderStats {
+	return q.last
+}
+
+func (q *RenderQueue) sort() {
+	if q.sorted {
+		return
+	}
+	sort.Slice(q.commands, func(i, j int) bool {
+		a, b := &q.commands[i], &q.commands[j]
+		if a.Key != b.Key {
+			return a.Key.Less(b.Key)
+		}
+		return a.seq < b.seq // 同じキーは追加順
+	})
+	q.sorted = true
+}
+
+// minLayer以上maxLayer未満のコマンドを描画
+func (q *RenderQueue) DrawLayers(dst *ebiten.Image, minLayer, maxLayer int) {
+	q.sort()
+
+	start := sort.Search(len(q.commands), func(i int) bool {
+		return q.commands[i].Key.Layer >= minLayer
+	})
+	for i := start; i < len(q.commands) && q.commands[i].Key.Layer < maxLayer; {
+		cmd := &q.commands[i]
+		if cmd.Image == nil {
+			if cmd.Draw != nil {
+				cmd.Draw(dst)
+				q.stats.DrawCalls++
+			}
+			i++
+			continue
+		}
+
+		// 同じ画像・フィルタ・ブレンドが続く範囲をまとめる
+		end := i + 1
+		for end < len(q.commands) && end-i < maxBatchSprites {
+			next := &q.commands[end]
+			if next.Key.Layer >= maxLayer || next.Image != cmd.Image || next.Filter != cmd.Filter || next.Blend != cmd.Blend {
+				break
+			}
+			end++
+		}
+
+		if end-i == 1 {
+			op := &ebiten.DrawImageOptions{
+				GeoM:       cmd.GeoM,
+				ColorScale: cmd.ColorScale,
+				Blend:      cmd.Blend,
+				Filter:     cmd.Filter,
+			}
+			img := cmd.Image
+			if !cmd.Source.Empty() {
+				img = img.SubImage(cmd.Source).(*ebiten.Image)
+			}
+			dst.DrawImage(img, op)
+		} else {
+			q.drawBatch(dst, q.commands[i:end])
+		}
+		q.stats.DrawCalls++
+		i = end
+	}
+}
+
+// 同じ画像のスプライトを1回のDrawTrianglesで描画
+func (q *RenderQueue) drawBatch(dst *ebiten.Image, commands []DrawCommand) {
+	q.vertices = q.vertices[:0]
+	q.indices = q.indices[:0]
+
+	for i := range commands {
+		cmd := &commands[i]
+		bounds := cmd.Source
+		if bounds.Empty() {
+			bounds = cmd.Image.Bounds()
+		}
+		sx0, sy0 := float32(bounds.Min.X), float32(bounds.Min.Y)
+		sx1, sy1 := float32(bounds.Max.X), float32(bounds.Max.Y)
+		w, h := float64(bounds.Dx()), float64(bounds.Dy())
+
+		r, g, b, a := cmd.ColorScale.R(), cmd.ColorScale.G(), cmd.ColorScale.B(), cmd.ColorScale.A()
+		base := uint16(len(q.vertices))
+		corners := [4][4]float32{
+			{0, 0, sx0, sy0},
+			{1, 0, sx1, sy0},
+			{0, 1, sx0, sy1},
+			{1, 1, sx1, sy1},
+		}
+		for _, c := range corners {
+			x, y := cmd.GeoM.Apply(float64(c[0])*w, float64(c[1])*h)
+			q.vertices = append(q.vertices, ebiten.Vertex{
+				DstX:   float32(x),
+				DstY:   float32(y),
+				SrcX:   c[2],
+				SrcY:   c[3],
+				ColorR: r,
+				ColorG: g,
+				ColorB: b,
+				ColorA: a,
+			})
+		}
+		q.indices = append(q.indices, base, base+1, base+2, base+1, base+3, base+2)
+	}
+
+	op := &ebiten.DrawTrianglesOptions{
+		ColorScaleMode: ebiten.ColorScaleModePremultipliedAlpha,
+		Blend:          commands[0].Blend,
+		Filter:         commands[0].Filter,
+	}
+	dst.DrawTriangles(q.vertices, q.indices, commands[0].Image, op)
+	q.stats.Batches++
+	q.stats.Batched += len(commands)
+}
+
+// 全レイヤーを描画
+func (q *RenderQueue) Draw(dst *ebiten.Image) {
+	q.DrawLayers(dst, -LayerMax, LayerMax)
+}
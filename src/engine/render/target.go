@@ -0,0 +1,31 @@
+package render
+
+import "github.com/hajimehoshi/ebiten/v2"
+
+// オフスクリーンの描画先（サイズが変わった時だけ作り直す）
+type RenderTarget struct {
+	image *ebiten.Image
+}
+
+func (t *RenderTarget) Ensure(width, height int) *ebiten.Image {
+	if t.image != nil {
+		bounds := t.image.Bounds()
+		if bounds.Dx() == width && bounds.Dy() == height {
+			return t.image
+		}
+		t.image.Dispose()
+	}
+	t.image = ebiten.NewImage(width, height)
+	return t.image
+}
+
+func (t *RenderTarget) Image() *ebiten.Image {
+	return t.image
+}
+
+func (t *RenderTarget) Dispose() {
+	if t.image != nil {
+		t.image.Dispose()
+		t.image = nil
+	}
+}
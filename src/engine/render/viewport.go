@@ -0,0 +1,204 @@
+package render
+
+import (
+	"fmt"
+	"image/color"
+	"math"
+	"sync"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// 論理解像度をウィンドウに合わせる方法
+type ScaleMode int
+
+const (
+	ScaleFit     ScaleMode = iota // 縦横比を保って拡大し、余白を塗る
+	ScaleInteger                  // 整数倍に拡大（ドット絵向け）
+	ScaleStretch                  // 縦横比を無視してウィンドウ全体に引き伸ばす
+	ScaleExpand                   // 縦横比を保ち、余白の分だけ論理解像度を広げる
+)
+
+var scaleModeNames = map[ScaleMode]string{
+	ScaleFit:     "fit",
+	ScaleInteger: "integer",
+	ScaleStretch: "stretch",
+	ScaleExpand:  "expand",
+}
+
+func (m ScaleMode) String() string {
+	if name, exists := scaleModeNames[m]; exists {
+		return name
+	}
+	return "unknown"
+}
+
+func ParseScaleMode(name string) (ScaleMode, error) {
+	switch name {
+	case "fit", "letterbox":
+		return ScaleFit, nil
+	case "integer", "pixel_perfect":
+		return ScaleInteger, nil
+	case "stretch":
+		return ScaleStretch, nil
+	case "expand":
+		return ScaleExpand, nil
+	default:
+		return ScaleFit, fmt.Errorf("unknown scale mode: %s", name)
+	}
+}
+
+// 論理解像度（ゲーム内座標）と画面（ウィンドウ）座標の対応
+type Viewport struct {
+	mutex          sync.RWMutex
+	mode           ScaleMode
+	letterbox      color.Color
+	baseWidth      int // 設定された論理解像度
+	baseHeight     int
+	outsideWidth   int
+	outsideHeight  int
+	width, height  int // 実際の論理解像度（expandでは広がる）
+	scaleX, scaleY float64
+	offsetX        float64
+	offsetY        float64
+}
+
+func NewViewport(width, height int) *Viewport {
+	v := &Viewport{
+		letterbox:     color.RGBA{0, 0, 0, 255},
+		baseWidth:     width,
+		baseHeight:    height,
+		outsideWidth:  width,
+		outsideHeight: height,
+	}
+	v.relayout()
+	return v
+}
+
+func (v *Viewport) SetBaseSize(width, height int) {
+	v.mutex.Lock()
+	defer v.mutex.Unlock()
+	v.baseWidth = width
+	v.baseHeight = height
+	v.relayout()
+}
+
+func (v *Viewport) SetMode(mode ScaleMode) {
+	v.mutex.Lock()
+	defer v.mutex.Unlock()
+	v.mode = mode
+	v.relayout()
+}
+
+func (v *Viewport) Mode() ScaleMode {
+	v.mutex.RLock()
+	defer v.mutex.RUnlock()
+	return v.mode
+}
+
+func (v *Viewport) SetLetterboxColor(c color.Color) {
+	v.mutex.Lock()
+	defer v.mutex.Unlock()
+	v.letterbox = c
+}
+
+// ウィンドウサイズの変更（Game.Layoutから呼ぶ）
+func (v *Viewport) Layout(outsideWidth, outsideHeight int) {
+	v.mutex.Lock()
+	defer v.mutex.Unlock()
+	if outsideWidth == v.outsideWidth && outsideHeight == v.outsideHeight {
+		return
+	}
+	v.outsideWidth = outsideWidth
+	v.outsideHeight = outsideHeight
+	v.relayout()
+}
+
+func (v *Viewport) relayout() {
+	ow, oh := float64(v.outsideWidth), float64(v.outsideHeight)
+	bw, bh := float64(v.baseWidth), float64(v.baseHeight)
+	if ow <= 0 || oh <= 0 || bw <= 0 || bh <= 0 {
+		v.width, v.height = v.baseWidth, v.baseHeight
+		v.scaleX, v.scaleY = 1, 1
+		v.offsetX, v.offsetY = 0, 0
+		return
+	}
+
+	fit := math.Min(ow/bw, oh/bh)
+	v.width, v.height = v.baseWidth, v.baseHeight
+
+	switch v.mode {
+	case ScaleInteger:
+		scale := math.Floor(fit)
+		if scale < 1 {
+			scale = fit // ウィンドウが小さい時は縮小する
+		}
+		v.scaleX, v.scaleY = scale, scale
+
+	case ScaleStretch:
+		v.scaleX, v.scaleY = ow/bw, oh/bh
+
+	case ScaleExpand:
+		v.width = int(math.Round(ow / fit))
+		v.height = int(math.Round(oh / fit))
+		v.scaleX = ow / float64(v.width)
+		v.scaleY = oh / float64(v.height)
+
+	default:
+		v.scaleX, v.scaleY = fit, fit
+	}
+
+	// 中央寄せ（整数倍ではピクセル境界に合わせる）
+	v.offsetX = (ow - float64(v.width)*v.scaleX) / 2
+	v.offsetY = (oh - float64(v.height)*v.scaleY) / 2
+	if v.mode == ScaleInteger {
+		v.offsetX = math.Floor(v.offsetX)
+		v.offsetY = math.Floor(v.offsetY)
+	}
+}
+
+// 論理解像度
+func (v *Viewport) Size() (int, int) {
+	v.mutex.RLock()
+	defer v.mutex.RUnlock()
+	return v.width, v.height
+}
+
+// 画面座標からゲーム内座標へ
+func (v *Viewport) ScreenToWorld(x, y float64) (float64, float64) {
+	v.mutex.RLock()
+	defer v.mutex.RUnlock()
+	return (x - v.offsetX) / v.scaleX, (y - v.offsetY) / v.scaleY
+}
+
+// ゲーム内座標から画面座標へ
+func (v *Viewport) WorldToScreen(x, y float64) (float64, float64) {
+	v.mutex.RLock()
+	defer v.mutex.RUnlock()
+	return x*v.scaleX + v.offsetX, y*v.scaleY + v.offsetY
+}
+
+// ゲーム内座標が表示範囲に入っているか（余白上のカーソル判定用）
+func (v *Viewport) Contains(x, y float64) bool {
+	v.mutex.RLock()
+	defer v.mutex.RUnlock()
+	return x >= 0 && y >= 0 && x < float64(v.width) && y < float64(v.height)
+}
+
+// 論理解像度の画像を画面に拡大して描画
+func (v *Viewport) Draw(screen, src *ebiten.Image) {
+	v.mutex.RLock()
+	defer v.mutex.RUnlock()
+
+	screen.Fill(v.letterbox)
+
+	op := &ebiten.DrawImageOptions{}
+	op.GeoM.Scale(v.scaleX, v.scaleY)
+	op.GeoM.Translate(v.offsetX, v.offsetY)
+	if v.mode == ScaleInteger {
+		op.Filter = ebiten.FilterNearest
+	} else {
+		op.Filter = ebiten.FilterLinear
+	}
+	screen.DrawImage(src, op)
+}
@@ -0,0 +1,132 @@
+package richtext
+
+import (
+	"image"
+	"image/draw"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"golang.org/x/image/font"
+	"golang.org/x/image/math/fixed"
+)
+
+// アトラスのページの大きさと上限（超えたら作り直す）
+const (
+	atlasPageSize = 1024
+	atlasMaxPages = 4
+	atlasPadding  = 1 // 隣の文字がにじまないように空ける
+)
+
+// 文字ごとの画像を詰め込んだ共有テクスチャ
+// 頻繁に変わるテキストを文字列ごとの画像にせず、文字単位で描くために使う
+type GlyphAtlas struct {
+	pages  []*ebiten.Image
+	glyphs map[atlasKey]AtlasGlyph
+
+	// 現在のページの詰め込み位置（棚詰め）
+	x, y, rowHeight int
+
+	hits, misses, resets int
+}
+
+type atlasKey struct {
+	face font.Face
+	r    rune
+}
+
+// アトラス上の1文字
+type AtlasGlyph struct {
+	Page   *ebiten.Image   // nilなら描く部分がない（空白など）
+	Src    image.Rectangle // ページ上の範囲
+	Offset image.Point     // ベースライン上の描画位置から画像左上まで
+}
+
+// アトラスの利用状況
+type AtlasStats struct {
+	Glyphs int
+	Pages  int
+	Hits   int
+	Misses int
+	Resets int // 満杯で作り直した回数
+}
+
+func NewGlyphAtlas() *GlyphAtlas {
+	return &GlyphAtlas{
+		glyphs: make(map[atlasKey]AtlasGlyph),
+	}
+}
+
+// faceのrの画像（なければラスタライズして追加する）
+func (a *GlyphAtlas) Glyph(face font.Face, r rune) AtlasGlyph {
+	key := atlasKey{face, r}
+	if g, ok := a.glyphs[key]; ok {
+		a.hits++
+		return g
+	}
+	a.misses++
+
+	var g AtlasGlyph
+	dr, mask, maskp, _, ok := face.Glyph(fixed.Point26_6{}, r)
+	if ok && !dr.Empty() && mask != nil {
+		// 白で描いておき、色は描画時に掛ける
+		rgba := image.NewRGBA(image.Rect(0, 0, dr.Dx(), dr.Dy()))
+		draw.DrawMask(rgba, rgba.Bounds(), image.White, image.Point{}, mask, maskp, draw.Src)
+
+		page, src := a.allocate(dr.Dx(), dr.Dy())
+		if page != nil {
+			page.SubImage(src).(*ebiten.Image).WritePixels(rgba.Pix)
+			g = AtlasGlyph{Page: page, Src: src, Offset: dr.Min}
+		}
+	}
+	a.glyphs[key] = g
+	return g
+}
+
+// w×hの場所を確保する
+func (a *GlyphAtlas) allocate(w, h int) (*ebiten.Image, image.Rectangle) {
+	if w+atlasPadding > atlasPageSize || h+atlasPadding > atlasPageSize {
+		return nil, image.Rectangle{}
+	}
+	if len(a.pages) == 0 {
+		a.addPage()
+	}
+	if a.x+w+atlasPadding > atlasPageSize {
+		a.x, a.y = 0, a.y+a.rowHeight
+		a.rowHeight = 0
+	}
+	if a.y+h+atlasPadding > atlasPageSize {
+		if len(a.pages) >= atlasMaxPages {
+			a.reset()
+		}
+		a.addPage()
+	}
+
+	src := image.Rect(a.x, a.y, a.x+w, a.y+h)
+	a.x += w + atlasPadding
+	if h+atlasPadding > a.rowHeight {
+		a.rowHeight = h + atlasPadding
+	}
+	return a.pages[len(a.pages)-1], src
+}
+
+func (a *GlyphAtlas) addPage() {
+	a.pages = append(a.pages, ebiten.NewImage(atlasPageSize, atlasPageSize))
+	a.x, a.y, a.rowHeight = 0, 0, 0
+}
+
+// すべての文字を捨てる
+// 古いページはこのフレームの描画コマンドがまだ参照しているかもしれないので破棄しない
+func (a *GlyphAtlas) reset() {
+	a.pages = nil
+	a.glyphs = make(map[atlasKey]AtlasGlyph)
+	a.resets++
+}
+
+func (a *GlyphAtlas) Stats() AtlasStats {
+	return AtlasStats{
+		Glyphs: len(a.glyphs),
+		Pages:  len(a.pages),
+		Hits:   a.hits,
+		Misses: a.misses,
+		Resets: a.resets,
+	}
+}
@@ -0,0 +1,157 @@
+package richtext
+
+import (
+	"image"
+	"image/color"
+	"math"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/text"
+	"golang.org/x/image/font"
+	"golang.org/x/image/math/fixed"
+)
+
+// 描画の設定
+type DrawOptions struct {
+	Color      color.Color // 色指定のない文字の色（nilなら白）
+	Override   color.Color // 指定するとすべての文字をこの色で描く（影・縁取り用）
+	SkipIcons  bool        // アイコンを描かない（影・縁取り用）
+	LimitChars bool        // trueならMaxChars文字目まで描く（文字送り用）
+	MaxChars   int
+}
+
+// アトラスやアイコンの画像の一部を描く命令
+type Quad struct {
+	Image *ebiten.Image
+	Src   image.Rectangle
+	GeoM  ebiten.GeoM
+	Color color.Color // 白で描いた文字に掛ける色（アイコンはnil）
+}
+
+// ブロック左上を(x, y)として描画する
+func (l *Layout) Draw(dst *ebiten.Image, x, y float64, opts *DrawOptions) {
+	l.walk(x, y, opts, func(item Item, s string, left, baseline float64, clr color.Color) {
+		if item.Icon != nil {
+			dst.DrawImage(item.Icon, iconOptions(item, left, baseline))
+			return
+		}
+		text.Draw(dst, s, item.Face, int(math.Round(left+item.TextX)), int(math.Round(baseline)), clr)
+		if item.Ruby != "" {
+			text.Draw(dst, item.Ruby, item.RubyFace, int(math.Round(left+item.RubyX)), int(math.Round(rubyBaseline(item, baseline))), clr)
+		}
+	})
+}
+
+// Drawと同じ内容を1文字ずつの命令にする（文字はアトラスから描く）
+func (l *Layout) Quads(atlas *GlyphAtlas, x, y float64, opts *DrawOptions, emit func(Quad)) {
+	l.walk(x, y, opts, func(item Item, s string, left, baseline float64, clr color.Color) {
+		if item.Icon != nil {
+			emit(Quad{Image: item.Icon, Src: item.Icon.Bounds(), GeoM: iconOptions(item, left, baseline).GeoM})
+			return
+		}
+		glyphQuads(atlas, item.Face, s, left+item.TextX, baseline, clr, emit)
+		if item.Ruby != "" {
+			glyphQuads(atlas, item.RubyFace, item.Ruby, left+item.RubyX, rubyBaseline(item, baseline), clr, emit)
+		}
+	})
+}
+
+// 表示する部分を順にたどる
+// 文字送りで途中まで表示する場合、sは表示する部分の文字列
+func (l *Layout) walk(x, y float64, opts *DrawOptions, fn func(item Item, s string, left, baseline float64, clr color.Color)) {
+	if opts == nil {
+		opts = &DrawOptions{}
+	}
+	base := opts.Color
+	if base == nil {
+		base = color.White
+	}
+
+	remaining := opts.MaxChars
+	for _, line := range l.Lines {
+		baseline := y + line.Baseline
+		for _, item := range line.Items {
+			if opts.LimitChars && remaining <= 0 {
+				return
+			}
+
+			clr := base
+			if item.Color != nil {
+				clr = item.Color
+			}
+			if opts.Override != nil {
+				clr = opts.Override
+			}
+			left := x + line.X + item.X
+
+			if item.Icon != nil {
+				if !opts.SkipIcons {
+					fn(item, "", left, baseline, clr)
+				}
+				remaining -= item.Chars
+				continue
+			}
+
+			s := item.Text
+			if opts.LimitChars && remaining < item.Chars {
+				s = firstRunes(s, remaining)
+			}
+			// ルビは親文字を表示し始めたら出す
+			fn(item, s, left, baseline, clr)
+			remaining -= item.Chars
+		}
+	}
+}
+
+// ルビのベースライン（親文字の上）
+func rubyBaseline(item Item, baseline float64) float64 {
+	return baseline - item.Ascent - fixedToFloat(item.RubyFace.Metrics().Descent)
+}
+
+// アイコンを文字の高さに合わせて描く
+func iconOptions(item Item, x, baseline float64) *ebiten.DrawImageOptions {
+	b := item.Icon.Bounds()
+	h := item.Ascent + item.Descent
+	scale := h / float64(b.Dy())
+
+	op := &ebiten.DrawImageOptions{}
+	op.GeoM.Scale(scale, scale)
+	op.GeoM.Translate(math.Round(x), math.Round(baseline-item.Ascent))
+	op.Filter = ebiten.FilterLinear
+	return op
+}
+
+// text.Drawと同じ送り幅・カーニングで1文字ずつ並べる
+func glyphQuads(atlas *GlyphAtlas, face font.Face, s string, x, baseline float64, clr color.Color, emit func(Quad)) {
+	ox, oy := int(math.Round(x)), int(math.Round(baseline))
+	var dot fixed.Int26_6
+	prev := rune(-1)
+	for _, r := range s {
+		if prev >= 0 {
+			dot += face.Kern(prev, r)
+		}
+		g := atlas.Glyph(face, r)
+		if g.Page != nil {
+			var geoM ebiten.GeoM
+			geoM.Translate(float64(ox+dot.Round()+g.Offset.X), float64(oy+g.Offset.Y))
+			emit(Quad{Image: g.Page, Src: g.Src, GeoM: geoM, Color: clr})
+		}
+		advance, _ := face.GlyphAdvance(r)
+		dot += advance
+		prev = r
+	}
+}
+
+// 先頭からn文字
+func firstRunes(s string, n int) string {
+	if n <= 0 {
+		return ""
+	}
+	for i := range s {
+		if n == 0 {
+			return s[:i]
+		}
+		n--
+	}
+	return s
+}
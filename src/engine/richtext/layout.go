@@ -0,0 +1,327 @@
+package richtext
+
+import (
+	"image/color"
+	"math"
+	"unicode/utf8"
+
+	enginefont "gameengine/src/engine/font"
+	"gameengine/src/engine/textlayout"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/basicfont"
+	"golang.org/x/image/math/fixed"
+)
+
+// インラインアイコンの代わりに改行判定へ渡す文字
+const iconRune = "￼"
+
+// ルビの大きさ（親文字に対する倍率）
+const rubyScale = 0.5
+
+// フォントIDと大きさ、スタイルからフェイスを得る
+type FaceFunc func(fontID string, size float64, style enginefont.FontStyle) font.Face
+
+// アイコンIDから画像を得る（nilならアイコンは描画しない）
+type IconFunc func(id string) *ebiten.Image
+
+// レイアウトの設定
+type Options struct {
+	FontID string // ベースのフォント
+	Size   float64
+	Style  enginefont.FontStyle
+
+	MaxWidth    float64 // 0以下なら折り返さない
+	LineSpacing float64 // 行の高さの倍率（0なら1.0）
+	Align       textlayout.Align
+
+	Faces FaceFunc // nilなら組み込みのビットマップフォント
+	Icons IconFunc
+}
+
+// 行の中の同じ装飾の部分
+type Item struct {
+	Text    string
+	Face    font.Face
+	Color   color.Color // nilなら描画時の色
+	X       float64     // 行の左端から
+	Width   float64
+	Ascent  float64
+	Descent float64
+	Chars   int // 文字数（送り表示用、アイコンは1文字）
+
+	TextX    float64 // 親文字の位置（ルビの方が長い場合に中央に寄せる）
+	Ruby     string
+	RubyFace font.Face
+	RubyX    float64
+
+	Icon *ebiten.Image
+}
+
+type Line struct {
+	Items      []Item
+	X          float64 // ブロック左端からの位置（揃えを反映）
+	Width      float64
+	Baseline   float64 // ブロック上端からのベースライン
+	Ascent     float64
+	Descent    float64
+	RubyHeight float64 // ルビのために空ける高さ
+}
+
+// 装飾付きテキストの配置結果
+type Layout struct {
+	Lines  []Line
+	Width  float64
+	Height float64
+	chars  int
+}
+
+// 区間ごとの情報
+type segment struct {
+	span       Span
+	start, end int // 改行判定用の文字列上の位置
+	face       font.Face
+	rubyFace   font.Face
+	icon       *ebiten.Image
+	iconW      float64
+	iconH      float64
+	fullWidth  float64 // ルビ・アイコン込みの幅
+	ascent     float64
+	descent    float64
+	rubyHeight float64
+}
+
+// マークアップを解釈して配置する
+func NewFromMarkup(markup string, opts Options) *Layout {
+	return New(Parse(markup), opts)
+}
+
+// 区間を行に分けて配置する
+func New(spans []Span, opts Options) *Layout {
+	spacing := opts.LineSpacing
+	if spacing <= 0 {
+		spacing = 1
+	}
+	baseFace := opts.face(Style{})
+	baseMetrics := baseFace.Metrics()
+	lineHeight := fixedToFloat(baseMetrics.Height) * spacing
+
+	// 改行判定用に1つの文字列にまとめる
+	var flat []byte
+	segments := make([]segment, 0, len(spans))
+	for _, span := range spans {
+		seg := segment{span: span, start: len(flat)}
+		seg.face = opts.face(span.Style)
+		m := seg.face.Metrics()
+		seg.ascent = fixedToFloat(m.Ascent)
+		seg.descent = fixedToFloat(m.Descent)
+
+		switch {
+		case span.Icon != "":
+			flat = append(flat, iconRune...)
+			if opts.Icons != nil {
+				seg.icon = opts.Icons(span.Icon)
+			}
+			// アイコンは文字の高さに合わせる
+			seg.iconH = seg.ascent + seg.descent
+			if seg.icon != nil {
+				b := seg.icon.Bounds()
+				seg.iconW = seg.iconH * float64(b.Dx()) / float64(b.Dy())
+			}
+			seg.fullWidth = seg.iconW
+		default:
+			flat = append(flat, span.Text...)
+			seg.fullWidth = textlayout.Measure(seg.face, span.Text)
+			if span.Ruby != "" {
+				seg.rubyFace = opts.face(Style{
+					FontID: span.Style.FontID,
+					Size:   opts.sizeOf(span.Style) * rubyScale,
+					Style:  span.Style.Style,
+				})
+				rm := seg.rubyFace.Metrics()
+				seg.rubyHeight = fixedToFloat(rm.Ascent + rm.Descent)
+				seg.fullWidth = math.Max(seg.fullWidth, textlayout.Measure(seg.rubyFace, span.Ruby))
+			}
+		}
+		seg.end = len(flat)
+		segments = append(segments, seg)
+	}
+	text := string(flat)
+
+	// ルビの親文字の途中では改行しない
+	var breaks []textlayout.Break
+	for _, b := range textlayout.BreakOpportunities(text) {
+		inside := false
+		for _, seg := range segments {
+			if seg.span.Ruby != "" && b.Pos > seg.start && b.Pos < seg.end {
+				inside = true
+				break
+			}
+		}
+		if !inside {
+			breaks = append(breaks, b)
+		}
+	}
+
+	measure := func(start, end int) float64 {
+		width := 0.0
+		for i := range segments {
+			seg := &segments[i]
+			s, e := maxInt(start, seg.start), minInt(end, seg.end)
+			if s >= e {
+				continue
+			}
+			if s == seg.start && e == seg.end {
+				width += seg.fullWidth
+			} else if seg.span.Icon == "" {
+				width += textlayout.Measure(seg.face, text[s:e])
+			}
+		}
+		return width
+	}
+
+	l := &Layout{}
+	for _, r := range textlayout.WrapRanges(text, breaks, opts.MaxWidth, measure) {
+		line := Line{
+			Ascent:  fixedToFloat(baseMetrics.Ascent),
+			Descent: fixedToFloat(baseMetrics.Descent),
+		}
+		x := 0.0
+		for i := range segments {
+			seg := &segments[i]
+			s, e := maxInt(r.Start, seg.start), minInt(r.End, seg.end)
+			if s >= e {
+				continue
+			}
+
+			item := Item{
+				Face:    seg.face,
+				Color:   seg.span.Style.Color,
+				X:       x,
+				Ascent:  seg.ascent,
+				Descent: seg.descent,
+			}
+			switch {
+			case seg.span.Icon != "":
+				item.Icon = seg.icon
+				item.Width = seg.iconW
+				item.Chars = 1
+			default:
+				item.Text = text[s:e]
+				item.Width = textlayout.Measure(seg.face, item.Text)
+				item.Chars = utf8.RuneCountInString(item.Text)
+				if seg.span.Ruby != "" && s == seg.start && e == seg.end {
+					item.Ruby = seg.span.Ruby
+					item.RubyFace = seg.rubyFace
+					item.TextX = (seg.fullWidth - item.Width) / 2
+					item.RubyX = (seg.fullWidth - textlayout.Measure(seg.rubyFace, item.Ruby)) / 2
+					item.Width = seg.fullWidth
+					line.RubyHeight = math.Max(line.RubyHeight, seg.rubyHeight)
+				}
+			}
+
+			line.Ascent = math.Max(line.Ascent, item.Ascent)
+			line.Descent = math.Max(line.Descent, item.Descent)
+			line.Items = append(line.Items, item)
+			l.chars += item.Chars
+			x += item.Width
+		}
+		line.Width = x
+		l.Width = math.Max(l.Width, x)
+		l.Lines = append(l.Lines, line)
+	}
+
+	// 行の縦位置（大きい文字やルビがある行は広げる）
+	y := 0.0
+	for i := range l.Lines {
+		line := &l.Lines[i]
+		if i == 0 {
+			line.Baseline = line.RubyHeight + line.Ascent
+		} else {
+			prev := l.Lines[i-1]
+			step := math.Max(lineHeight, prev.Descent+line.RubyHeight+line.Ascent)
+			line.Baseline = prev.Baseline + step
+		}
+		y = line.Baseline + line.Descent
+
+		switch opts.Align {
+		case textlayout.AlignCenter:
+			line.X = (l.Width - line.Width) / 2
+		case textlayout.AlignRight:
+			line.X = l.Width - line.Width
+		}
+	}
+	l.Height = y
+	return l
+}
+
+// 表示される文字数（アイコンを含む）
+func (l *Layout) CharCount() int {
+	return l.chars
+}
+
+// 装飾に応じたフェイス
+func (o *Options) face(s Style) font.Face {
+	fontID := o.FontID
+	if s.FontID != "" {
+		fontID = s.FontID
+	}
+	style := o.Style | s.Style // 太字と斜体はそれぞれ別に効く
+	if o.Faces == nil {
+		return basicfont.Face7x13
+	}
+	return o.Faces(fontID, o.sizeOf(s), style)
+}
+
+func (o *Options) sizeOf(s Style) float64 {
+	size := o.Size
+	if s.Size > 0 {
+		size = s.Size
+	}
+	if s.Scale > 0 {
+		size *= s.Scale
+	}
+	return size
+}
+
+func fixedToFloat(v fixed.Int26_6) float64 {
+	return float64(v) / 64
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+// from行目からto行目の手前までを取り出す（縦位置は先頭行が上端に来るように詰める）
+func (l *Layout) Slice(from, to int) *Layout {
+	from = maxInt(0, minInt(from, len(l.Lines)))
+	to = maxInt(from, minInt(to, len(l.Lines)))
+	sub := &Layout{}
+	if from == to {
+		return sub
+	}
+
+	top := l.Lines[from].Baseline - l.Lines[from].RubyHeight - l.Lines[from].Ascent
+	for _, line := range l.Lines[from:to] {
+		line.Baseline -= top
+		for _, item := range line.Items {
+			sub.chars += item.Chars
+		}
+		sub.Width = math.Max(sub.Width, line.X+line.Width)
+		sub.Lines = append(sub.Lines, line)
+	}
+	last := sub.Lines[len(sub.Lines)-1]
+	sub.Height = last.Baseline + last.Descent
+	return sub
+}
@@ -0,0 +1,216 @@
+package richtext
+
+import (
+	"image/color"
+	"strconv"
+	"strings"
+
+	enginefont "gameengine/src/engine/font"
+	"gameengine/src/engine/render"
+)
+
+// 文字列の装飾（ゼロ値はベースの設定のまま）
+type Style struct {
+	FontID string               // 空ならベースのフォント
+	Size   float64              // 0ならベースの大きさ
+	Scale  float64              // 大きさの倍率（0なら1）
+	Style  enginefont.FontStyle // 太字・斜体（[b]と[i]を重ねると両方。ベースのスタイルにも足される）
+	Color  color.Color          // nilならベースの色
+}
+
+// 同じ装飾が続く区間
+type Span struct {
+	Text  string
+	Style Style
+	Ruby  string // 振り仮名（Textが親文字）
+	Icon  string // インラインアイコンの画像ID（Textは空）
+}
+
+// 開いているタグ
+type openTag struct {
+	name  string
+	style Style
+	ruby  string
+	start int // タグ内のspanの開始位置（ruby用）
+}
+
+// マークアップを装飾付きの区間に分ける
+//
+//	[color=red]赤[/color]      色（色名、#rrggbb、#rrggbbaa）
+//	[size=24]大[/size]         大きさ（数値で絶対指定、150% で相対指定）
+//	[b]太字[/b] [i]斜体[/i]
+//	[font=id]別フォント[/font]
+//	[ruby=かんじ]漢字[/ruby]   振り仮名
+//	[icon=button_a]            インラインアイコン
+//	[[                         "[" そのもの
+//
+// 解釈できないタグや対応の取れない閉じタグは文字としてそのまま残す
+func Parse(markup string) []Span {
+	var spans []Span
+	var stack []openTag
+	style := Style{}
+	var buf strings.Builder
+
+	flush := func() {
+		if buf.Len() == 0 {
+			return
+		}
+		spans = append(spans, Span{Text: buf.String(), Style: style})
+		buf.Reset()
+	}
+
+	for i := 0; i < len(markup); {
+		if markup[i] != '[' {
+			j := strings.IndexByte(markup[i:], '[')
+			if j < 0 {
+				j = len(markup) - i
+			}
+			buf.WriteString(markup[i : i+j])
+			i += j
+			continue
+		}
+		if strings.HasPrefix(markup[i:], "[[") {
+			buf.WriteByte('[')
+			i += 2
+			continue
+		}
+
+		end := strings.IndexByte(markup[i:], ']')
+		if end < 0 {
+			buf.WriteString(markup[i:])
+			break
+		}
+		tag := markup[i+1 : i+end]
+		raw := markup[i : i+end+1]
+		i += end + 1
+
+		// 閉じタグ
+		if strings.HasPrefix(tag, "/") {
+			name := strings.TrimSpace(tag[1:])
+			index := -1
+			for k := len(stack) - 1; k >= 0; k-- {
+				if stack[k].name == name {
+					index = k
+					break
+				}
+			}
+			if index < 0 {
+				buf.WriteString(raw)
+				continue
+			}
+			flush()
+			top := stack[index]
+			if top.name == "ruby" {
+				spans = mergeRuby(spans, top.start, top.ruby)
+			}
+			style = top.style
+			stack = stack[:index]
+			continue
+		}
+
+		name, value := tag, ""
+		if k := strings.IndexByte(tag, '='); k >= 0 {
+			name, value = tag[:k], tag[k+1:]
+		}
+		name = strings.TrimSpace(name)
+		value = strings.Trim(strings.TrimSpace(value), "\"'")
+
+		// アイコンは閉じタグを持たない
+		if name == "icon" {
+			value = strings.TrimSuffix(value, "/")
+			if value == "" {
+				buf.WriteString(raw)
+				continue
+			}
+			flush()
+			spans = append(spans, Span{Style: style, Icon: value})
+			continue
+		}
+
+		next, ok := applyTag(style, name, value)
+		if !ok {
+			buf.WriteString(raw)
+			continue
+		}
+		flush()
+		stack = append(stack, openTag{name: name, style: style, ruby: value, start: len(spans)})
+		style = next
+	}
+
+	// 閉じられていないタグは末尾まで有効（ルビは付けない）
+	flush()
+	return spans
+}
+
+// タグを装飾に反映する
+func applyTag(style Style, name, value string) (Style, bool) {
+	switch name {
+	case "color":
+		c, err := render.ParseColor(value)
+		if err != nil {
+			return style, false
+		}
+		style.Color = c
+	case "size":
+		if strings.HasSuffix(value, "%") {
+			v, err := strconv.ParseFloat(strings.TrimSuffix(value, "%"), 64)
+			if err != nil || v <= 0 {
+				return style, false
+			}
+			if style.Scale == 0 {
+				style.Scale = 1
+			}
+			style.Scale *= v / 100
+		} else {
+			v, err := strconv.ParseFloat(value, 64)
+			if err != nil || v <= 0 {
+				return style, false
+			}
+			style.Size = v
+			style.Scale = 0
+		}
+	case "b":
+		style.Style |= enginefont.StyleBold
+	case "i":
+		style.Style |= enginefont.StyleItalic
+	case "font":
+		if value == "" {
+			return style, false
+		}
+		style.FontID = value
+	case "ruby":
+		if value == "" {
+			return style, false
+		}
+	default:
+		return style, false
+	}
+	return style, true
+}
+
+// ruby開始以降の区間を1つの親文字にまとめる
+// 親文字の中で装飾が変わっていても最初の装飾を使う
+func mergeRuby(spans []Span, start int, ruby string) []Span {
+	if start >= len(spans) {
+		return spans
+	}
+	base := spans[start]
+	for _, s := range spans[start+1:] {
+		base.Text += s.Text
+	}
+	if base.Text == "" {
+		return spans[:start]
+	}
+	base.Ruby = ruby
+	base.Icon = ""
+	return append(spans[:start], base)
+}
+
+// タグを取り除いた文字列（ルビは親文字のみ、アイコンは含まない）
+func Plain(markup string) string {
+	var b strings.Builder
+	for _, s := range Parse(markup) {
+		b.WriteString(s.Text)
+	}
+	return b.String()
+}
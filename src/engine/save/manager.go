@@ -97,6 +97,20 @@ func (m *SaveManager) Load(slot int) error {
 	return nil
 }
 
+// 現在のゲーム状態の変数をパスで探す（ロードで状態が入れ替わっても最新を見る）
+func (m *SaveManager) Lookup(path string) (interface{}, bool) {
+	return m.state.Lookup(path)
+}
+
+// パスの変数をStarlarkの値で返す
+func (m *SaveManager) LookupForStarlark(path string) (starlark.Value, bool) {
+	val, exists := m.Lookup(path)
+	if !exists {
+		return starlark.None, false
+	}
+	return convertToStarlarkValue(val), true
+}
+
 // Starlark APIのためのメソッド
 func (m *SaveManager) GetStateForStarlark() map[string]starlark.Value {
 	result := make(map[string]starlark.Value)
@@ -1,6 +1,7 @@
 package save
 
 import (
+	"strings"
 	"sync"
 
 	"go.starlark.net/starlark"
@@ -39,6 +40,31 @@ func (g *GameState) GetVariable(name string) (interface{}, bool) {
 	return val, exists
 }
 
+// "player.hp"のようなパスで変数を探す
+// その名前の変数がなければ、ドットで区切って辞書の中をたどる
+func (g *GameState) Lookup(path string) (interface{}, bool) {
+	g.mutex.RLock()
+	defer g.mutex.RUnlock()
+
+	if val, exists := g.Variables[path]; exists {
+		return val, true
+	}
+	name, rest, found := strings.Cut(path, ".")
+	if !found {
+		return nil, false
+	}
+	val, exists := g.Variables[name]
+	for exists && rest != "" {
+		table, ok := val.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		name, rest, _ = strings.Cut(rest, ".")
+		val, exists = table[name]
+	}
+	return val, exists
+}
+
 // Starlarkの値をGo側の値に変換
 func convertStarlarkValue(v starlark.Value) interface{} {
 	switch v := v.(type) {
@@ -19,6 +19,12 @@ type Scene interface {
 	IsReady() bool
 }
 
+// 入力コンテキストを持つシーン
+// 積んだ時にコンテキストを積み、外した時に外す
+type InputContextScene interface {
+	InputContext() *input.InputContext
+}
+
 // シーン遷移タイプ
 type TransitionType int
 
@@ -99,6 +105,8 @@ func (m *SceneManager) Update() error {
 		return err
 	}
 
+	// 入力状態はゲームループで更新済み（ここで更新するとコールバックからPushなどを呼んだ時に止まる）
+
 	// アクティブなシーンの更新
 	if len(m.scenes) > 0 {
 		currentScene := m.scenes[len(m.scenes)-1]
@@ -131,10 +139,12 @@ func (m *SceneManager) processTransitions() error {
 				return err
 			}
 			m.scenes = append(m.scenes, t.nextScene)
+			m.pushInputContext(t.nextScene)
 
 		case TransitionPop:
 			if len(m.scenes) > 0 {
 				current := m.scenes[len(m.scenes)-1]
+				m.removeInputContext(current)
 				if err := current.OnExit(); err != nil {
 					return err
 				}
@@ -147,6 +157,7 @@ func (m *SceneManager) processTransitions() error {
 		case TransitionReplace:
 			if len(m.scenes) > 0 {
 				current := m.scenes[len(m.scenes)-1]
+				m.removeInputContext(current)
 				if err := current.OnExit(); err != nil {
 					return err
 				}
@@ -162,10 +173,28 @@ func (m *SceneManager) processTransitions() error {
 				return err
 			}
 			m.scenes = append(m.scenes, t.nextScene)
+			m.pushInputContext(t.nextScene)
 		}
 	}
 
 	// 遷移をクリア
 	m.transitions = m.transitions[:0]
 	return nil
-} 
\ No newline at end of file
+} 
+
+// シーンの入力コンテキストを積む
+func (m *SceneManager) pushInputContext(s Scene) {
+	if cs, ok := s.(InputContextScene); ok && m.inputMgr != nil {
+		if c := cs.InputContext(); c != nil {
+			m.inputMgr.PushContext(c)
+		}
+	}
+}
+
+func (m *SceneManager) removeInputContext(s Scene) {
+	if cs, ok := s.(InputContextScene); ok && m.inputMgr != nil {
+		if c := cs.InputContext(); c != nil {
+			m.inputMgr.RemoveContext(c)
+		}
+	}
+}
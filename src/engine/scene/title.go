@@ -2,49 +2,85 @@ package scene
 
 import (
 	"gameengine/src/engine/input"
+	"gameengine/src/engine/textlayout"
 	"gameengine/src/engine/ui"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"golang.org/x/image/font"
 )
 
 // タイトルシーン
 type TitleScene struct {
 	*BaseScene
 	menuWindow *ui.MenuWindow
+	exiting    bool
+	context    *input.InputContext // タイトルにいる間はメニューの操作だけを受け付ける
+
+	Font       font.Face // メニューのフォント（nilなら組み込みのフォント）
+	OnStart    func()    // 「ゲーム開始」が選ばれた時
+	OnSettings func()    // 「設定」が選ばれた時
 }
 
 func NewTitleScene(uiMgr *ui.UIManager, inputMgr *input.InputManager) *TitleScene {
 	return &TitleScene{
 		BaseScene: NewBaseScene(uiMgr, inputMgr),
+		context:   input.NewMenuContext(),
 	}
 }
 
+func (s *TitleScene) InputContext() *input.InputContext {
+	return s.context
+}
+
 func (s *TitleScene) Init() error {
 	if err := s.BaseScene.Init(); err != nil {
 		return err
 	}
 
 	// メニューウィンドウの初期化
-	s.menuWindow = ui.NewMenuWindow(nil) // フォントは適切に設定する必要があります
-	s.menuWindow.SetPosition(100, 100)
-	s.menuWindow.SetSize(200, 300)
+	s.menuWindow = ui.NewMenuWindow(s.Font)
+	s.menuWindow.Input = s.inputMgr
 
 	// メニュー項目の追加
-	s.menuWindow.AddItem("ゲーム開始", true, s.onStartGame)
-	s.menuWindow.AddItem("設定", true, s.onOpenSettings)
-	s.menuWindow.AddItem("終了", true, s.onExit)
+	s.menuWindow.AddLocalizedItem("menu.start", true, s.onStartGame)
+	s.menuWindow.AddLocalizedItem("menu.settings", true, s.onOpenSettings)
+	s.menuWindow.AddLocalizedItem("menu.exit", true, s.onExit)
+	s.menuWindow.FitToItems()
 
 	s.uiManager.AddComponent("title_menu", s.menuWindow)
 
+	// 画面の中央やや下に置く（解像度が変わっても置き直される）
+	s.uiManager.SetLayoutParams("title_menu", ui.LayoutParams{
+		Anchor:  textlayout.Anchor{Enabled: true, H: textlayout.AlignCenter, V: textlayout.AlignMiddle},
+		OffsetY: 100,
+	})
+
+	return nil
+}
+
+// 「終了」が選ばれたらゲームを終える
+func (s *TitleScene) Update() error {
+	if err := s.BaseScene.Update(); err != nil {
+		return err
+	}
+	if s.exiting {
+		return ebiten.Termination
+	}
 	return nil
 }
 
 func (s *TitleScene) onStartGame() {
-	// ゲーム開始処理
+	if s.OnStart != nil {
+		s.OnStart()
+	}
 }
 
 func (s *TitleScene) onOpenSettings() {
-	// 設定画面を開く処理
+	if s.OnSettings != nil {
+		s.OnSettings()
+	}
 }
 
 func (s *TitleScene) onExit() {
-	// ゲーム終了処理
+	s.exiting = true
 } 
\ No newline at end of file
@@ -0,0 +1,253 @@
+package script
+
+import (
+	"fmt"
+
+	"gameengine/src/engine/animation"
+	"gameengine/src/engine/ecs/components"
+	"gameengine/src/engine/ecs/core"
+
+	"go.starlark.net/starlark"
+)
+
+// アニメーションコンポーネントの作成
+//
+//	add_component(id, "animation", {"clips": "player", "play": "walk"})
+//	add_component(id, "animation", {"clips": {"walk": {"frames": [...], "duration": 0.1, "mode": "loop"}}})
+func (e *ScriptEngine) newAnimationComponent(properties *starlark.Dict) (*components.AnimationComponent, error) {
+	component := components.NewAnimationComponent()
+
+	clipsVal, _, err := properties.Get(starlark.String("clips"))
+	if err != nil {
+		return nil, err
+	}
+
+	switch v := clipsVal.(type) {
+	case starlark.String:
+		// マニフェストのanimationsに登録されたアセット
+		if e.assetManager == nil {
+			return nil, fmt.Errorf("asset manager is not available")
+		}
+		if err := e.assetManager.Load(string(v)); err != nil {
+			return nil, err
+		}
+		clips, err := e.assetManager.GetAnimation(string(v))
+		if err != nil {
+			return nil, err
+		}
+		// アセットのクリップは共有されているので、エンティティごとに複製する
+		for _, clip := range clips {
+			component.AddClip(clip.Clone())
+		}
+	case *starlark.Dict:
+		// スクリプト内でのクリップ定義
+		if e.assetManager == nil {
+			return nil, fmt.Errorf("asset manager is not available")
+		}
+		for _, item := range v.Items() {
+			name, ok := starlark.AsString(item[0])
+			if !ok {
+				return nil, fmt.Errorf("clip name must be string, got %s", item[0].Type())
+			}
+			defDict, ok := item[1].(*starlark.Dict)
+			if !ok {
+				return nil, fmt.Errorf("clip %s must be dict, got %s", name, item[1].Type())
+			}
+			def, err := toClipDef(defDict)
+			if err != nil {
+				return nil, fmt.Errorf("clip %s: %v", name, err)
+			}
+			clip, err := def.Build(name, e.assetManager.ResolveImage)
+			if err != nil {
+				return nil, err
+			}
+			component.AddClip(clip)
+		}
+	case nil:
+	default:
+		return nil, fmt.Errorf("clips must be string or dict, got %s", clipsVal.Type())
+	}
+
+	// 既存クリップへのイベント追加 {"walk": {1: "footstep"}}
+	if eventsVal, _, err := properties.Get(starlark.String("events")); err == nil && eventsVal != nil {
+		events, ok := eventsVal.(*starlark.Dict)
+		if !ok {
+			return nil, fmt.Errorf("events must be dict, got %s", eventsVal.Type())
+		}
+		for _, item := range events.Items() {
+			name, _ := starlark.AsString(item[0])
+			clip, exists := component.Clips[name]
+			if !exists {
+				return nil, fmt.Errorf("animation clip not found: %s", name)
+			}
+			frameEvents, err := toFrameEvents(item[1])
+			if err != nil {
+				return nil, err
+			}
+			for index, event := range frameEvents {
+				if err := clip.SetEvent(index, event); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+
+	if speedVal, _, err := properties.Get(starlark.String("speed")); err == nil && speedVal != nil {
+		if speed, ok := starlark.AsFloat(speedVal); ok {
+			component.Player.Speed = speed
+		}
+	}
+
+	if playVal, _, err := properties.Get(starlark.String("play")); err == nil && playVal != nil {
+		if name, ok := starlark.AsString(playVal); ok && name != "" {
+			if err := component.Play(name, true); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return component, nil
+}
+
+func toClipDef(dict *starlark.Dict) (animation.ClipDef, error) {
+	var def animation.ClipDef
+
+	if framesVal, _, _ := dict.Get(starlark.String("frames")); framesVal != nil {
+		frames, ok := framesVal.(*starlark.List)
+		if !ok {
+			return def, fmt.Errorf("frames must be list, got %s", framesVal.Type())
+		}
+		for i := 0; i < frames.Len(); i++ {
+			id, ok := starlark.AsString(frames.Index(i))
+			if !ok {
+				return def, fmt.Errorf("frame id must be string, got %s", frames.Index(i).Type())
+			}
+			def.Frames = append(def.Frames, id)
+		}
+	}
+
+	if durationVal, _, _ := dict.Get(starlark.String("duration")); durationVal != nil {
+		duration, ok := starlark.AsFloat(durationVal)
+		if !ok {
+			return def, fmt.Errorf("duration must be number, got %s", durationVal.Type())
+		}
+		def.Duration = duration
+	}
+
+	if durationsVal, _, _ := dict.Get(starlark.String("durations")); durationsVal != nil {
+		durations, ok := durationsVal.(*starlark.List)
+		if !ok {
+			return def, fmt.Errorf("durations must be list, got %s", durationsVal.Type())
+		}
+		for i := 0; i < durations.Len(); i++ {
+			d, ok := starlark.AsFloat(durations.Index(i))
+			if !ok {
+				return def, fmt.Errorf("duration must be number, got %s", durations.Index(i).Type())
+			}
+			def.Durations = append(def.Durations, d)
+		}
+	}
+
+	if modeVal, _, _ := dict.Get(starlark.String("mode")); modeVal != nil {
+		def.Mode, _ = starlark.AsString(modeVal)
+	}
+
+	if eventsVal, _, _ := dict.Get(starlark.String("events")); eventsVal != nil {
+		events, err := toFrameEvents(eventsVal)
+		if err != nil {
+			return def, err
+		}
+		def.Events = events
+	}
+
+	return def, nil
+}
+
+// {フレーム番号: イベント名} を変換
+func toFrameEvents(v starlark.Value) (map[int]string, error) {
+	dict, ok := v.(*starlark.Dict)
+	if !ok {
+		return nil, fmt.Errorf("frame events must be dict, got %s", v.Type())
+	}
+	events := make(map[int]string, dict.Len())
+	for _, item := range dict.Items() {
+		index, err := starlark.AsInt32(item[0])
+		if err != nil {
+			return nil, fmt.Errorf("frame index must be int: %v", err)
+		}
+		event, ok := starlark.AsString(item[1])
+		if !ok {
+			return nil, fmt.Errorf("event name must be string, got %s", item[1].Type())
+		}
+		events[index] = event
+	}
+	return events, nil
+}
+
+func (e *ScriptEngine) getAnimationComponent(entityID int64) (*components.AnimationComponent, error) {
+	entity := e.world.GetEntity(core.EntityID(entityID))
+	if entity == nil {
+		return nil, fmt.Errorf("entity not found: %d", entityID)
+	}
+	component, ok := entity.GetComponent(6).(*components.AnimationComponent)
+	if !ok || component == nil {
+		return nil, fmt.Errorf("entity %d has no animation component", entityID)
+	}
+	return component, nil
+}
+
+// アニメーションの再生
+func (e *ScriptEngine) playAnimation(thread *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var entityID int64
+	var name string
+	var restart bool
+	if err := starlark.UnpackArgs(b.Name(), args, kwargs, "entity_id", &entityID, "name", &name, "restart?", &restart); err != nil {
+		return nil, err
+	}
+
+	component, err := e.getAnimationComponent(entityID)
+	if err != nil {
+		return nil, err
+	}
+	if err := component.Play(name, restart); err != nil {
+		return nil, err
+	}
+	return starlark.None, nil
+}
+
+// アニメーションの停止
+func (e *ScriptEngine) stopAnimation(thread *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var entityID int64
+	if err := starlark.UnpackPositionalArgs(b.Name(), args, kwargs, 1, &entityID); err != nil {
+		return nil, err
+	}
+
+	component, err := e.getAnimationComponent(entityID)
+	if err != nil {
+		return nil, err
+	}
+	component.Stop()
+	return starlark.None, nil
+}
+
+// フレームイベントのコールバック登録
+// callback(entity_id, clip, event) の形で呼ばれる
+func (e *ScriptEngine) onAnimationEvent(thread *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var entityID int64
+	var callback starlark.Callable
+	if err := starlark.UnpackPositionalArgs(b.Name(), args, kwargs, 2, &entityID, &callback); err != nil {
+		return nil, err
+	}
+
+	component, err := e.getAnimationComponent(entityID)
+	if err != nil {
+		return nil, err
+	}
+	component.OnEvent = func(clip, event string) {
+		callArgs := starlark.Tuple{starlark.MakeInt64(entityID), starlark.String(clip), starlark.String(event)}
+		if _, err := starlark.Call(e.thread, callback, callArgs, nil); err != nil {
+			fmt.Printf("error in animation event callback: %v\n", err)
+		}
+	}
+	return starlark.None, nil
+}
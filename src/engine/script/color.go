@@ -0,0 +1,45 @@
+package script
+
+import (
+	"fmt"
+	"image/color"
+
+	"gameengine/src/engine/render"
+
+	"go.starlark.net/starlark"
+)
+
+// 色の指定を解釈する
+// "white" などの色名、"#rrggbb" / "#rrggbbaa"、[r, g, b] / [r, g, b, a]（0〜255）に対応
+func parseColor(v starlark.Value) (color.RGBA, error) {
+	switch v := v.(type) {
+	case starlark.String:
+		return render.ParseColor(string(v))
+	case starlark.Indexable:
+		n := v.Len()
+		if n != 3 && n != 4 {
+			return color.RGBA{}, fmt.Errorf("color must have 3 or 4 components, got %d", n)
+		}
+		c := [4]uint8{0, 0, 0, 255}
+		for i := 0; i < n; i++ {
+			f, ok := starlark.AsFloat(v.Index(i))
+			if !ok {
+				return color.RGBA{}, fmt.Errorf("color component must be number, got %s", v.Index(i).Type())
+			}
+			c[i] = clampColor(f)
+		}
+		return render.NewRGBA(c[0], c[1], c[2], c[3]), nil
+	default:
+		return color.RGBA{}, fmt.Errorf("invalid color: %s", v.String())
+	}
+}
+
+func clampColor(f float64) uint8 {
+	if f < 0 {
+		return 0
+	}
+	if f > 255 {
+		return 255
+	}
+	return uint8(f)
+}
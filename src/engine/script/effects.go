@@ -0,0 +1,271 @@
+package script
+
+import (
+	"fmt"
+	"image/color"
+
+	"gameengine/src/engine/render"
+
+	"go.starlark.net/starlark"
+)
+
+func (e *ScriptEngine) effect(name string) (render.Effect, error) {
+	if e.pipeline == nil {
+		return nil, fmt.Errorf("render pipeline is not available")
+	}
+	effect := e.pipeline.Effect(name)
+	if effect == nil {
+		return nil, fmt.Errorf("effect not found: %s", name)
+	}
+	return effect, nil
+}
+
+// 辞書のパラメータをエフェクトに反映（"enabled" は有効・無効の切り替え）
+func setEffectParams(effect render.Effect, params *starlark.Dict) error {
+	if params == nil {
+		return nil
+	}
+	for _, item := range params.Items() {
+		key, ok := starlark.AsString(item[0])
+		if !ok {
+			return fmt.Errorf("effect parameter name must be string")
+		}
+		if key == "enabled" {
+			effect.SetEnabled(bool(item[1].Truth()))
+			continue
+		}
+		value, ok := starlark.AsFloat(item[1])
+		if !ok {
+			return fmt.Errorf("effect parameter %s must be number", key)
+		}
+		if err := effect.SetParam(key, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// エフェクトをパスに追加
+func (e *ScriptEngine) addEffect(thread *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var passName, kind, name string
+	var params *starlark.Dict
+	if err := starlark.UnpackArgs(b.Name(), args, kwargs, "pass", &passName, "type", &kind, "name?", &name, "params?", &params); err != nil {
+		return nil, err
+	}
+	if e.pipeline == nil {
+		return nil, fmt.Errorf("render pipeline is not available")
+	}
+
+	effect, err := render.NewEffect(kind, name)
+	if err != nil {
+		return nil, err
+	}
+	if e.pipeline.Effect(effect.Name()) != nil {
+		return nil, fmt.Errorf("effect already exists: %s", effect.Name())
+	}
+	if err := setEffectParams(effect, params); err != nil {
+		return nil, err
+	}
+	if err := e.pipeline.AddEffect(passName, effect); err != nil {
+		return nil, err
+	}
+	return starlark.String(effect.Name()), nil
+}
+
+func (e *ScriptEngine) removeEffect(thread *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var name string
+	if err := starlark.UnpackArgs(b.Name(), args, kwargs, "name", &name); err != nil {
+		return nil, err
+	}
+	if e.pipeline == nil {
+		return nil, fmt.Errorf("render pipeline is not available")
+	}
+	return starlark.Bool(e.pipeline.RemoveEffect(name)), nil
+}
+
+func (e *ScriptEngine) enableEffect(thread *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var name string
+	enabled := true
+	if err := starlark.UnpackArgs(b.Name(), args, kwargs, "name", &name, "enabled?", &enabled); err != nil {
+		return nil, err
+	}
+	effect, err := e.effect(name)
+	if err != nil {
+		return nil, err
+	}
+	effect.SetEnabled(enabled)
+	return starlark.None, nil
+}
+
+func (e *ScriptEngine) setEffect(thread *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var name string
+	var params *starlark.Dict
+	if err := starlark.UnpackArgs(b.Name(), args, kwargs, "name", &name, "params", &params); err != nil {
+		return nil, err
+	}
+	effect, err := e.effect(name)
+	if err != nil {
+		return nil, err
+	}
+	return starlark.None, setEffectParams(effect, params)
+}
+
+func (e *ScriptEngine) getEffect(thread *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var name string
+	if err := starlark.UnpackArgs(b.Name(), args, kwargs, "name", &name); err != nil {
+		return nil, err
+	}
+	effect, err := e.effect(name)
+	if err != nil {
+		return nil, err
+	}
+
+	params := effect.Params()
+	dict := starlark.NewDict(len(params) + 1)
+	dict.SetKey(starlark.String("enabled"), starlark.Bool(effect.Enabled()))
+	for _, p := range params {
+		value, _ := effect.Param(p)
+		dict.SetKey(starlark.String(p), starlark.Float(value))
+	}
+	return dict, nil
+}
+
+// パス単位の表示切り替え
+func (e *ScriptEngine) setPassEnabled(thread *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var name string
+	var enabled bool
+	if err := starlark.UnpackArgs(b.Name(), args, kwargs, "pass", &name, "enabled", &enabled); err != nil {
+		return nil, err
+	}
+	if e.pipeline == nil {
+		return nil, fmt.Errorf("render pipeline is not available")
+	}
+	pass := e.pipeline.Pass(name)
+	if pass == nil {
+		return nil, fmt.Errorf("render pass not found: %s", name)
+	}
+	pass.Enabled = enabled
+	return starlark.None, nil
+}
+
+func (e *ScriptEngine) fadeEffect(name string) (*render.FadeEffect, error) {
+	effect, err := e.effect(name)
+	if err != nil {
+		return nil, err
+	}
+	fade, ok := effect.(*render.FadeEffect)
+	if !ok {
+		return nil, fmt.Errorf("effect %s is not a fade effect", name)
+	}
+	return fade, nil
+}
+
+// 画面を指定色へフェードアウト
+func (e *ScriptEngine) fadeOut(thread *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	duration := 0.5
+	var colorVal starlark.Value
+	name := render.EffectFade
+	if err := starlark.UnpackArgs(b.Name(), args, kwargs, "duration?", &duration, "color?", &colorVal, "name?", &name); err != nil {
+		return nil, err
+	}
+	fade, err := e.fadeEffect(name)
+	if err != nil {
+		return nil, err
+	}
+
+	c := color.RGBA{0, 0, 0, 255}
+	if colorVal != nil && colorVal != starlark.None {
+		if c, err = parseColor(colorVal); err != nil {
+			return nil, err
+		}
+	}
+	fade.FadeOut(c, duration)
+	return starlark.None, nil
+}
+
+func (e *ScriptEngine) fadeIn(thread *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	duration := 0.5
+	name := render.EffectFade
+	if err := starlark.UnpackArgs(b.Name(), args, kwargs, "duration?", &duration, "name?", &name); err != nil {
+		return nil, err
+	}
+	fade, err := e.fadeEffect(name)
+	if err != nil {
+		return nil, err
+	}
+	fade.FadeIn(duration)
+	return starlark.None, nil
+}
+
+func (e *ScriptEngine) isFading(thread *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	name := render.EffectFade
+	if err := starlark.UnpackArgs(b.Name(), args, kwargs, "name?", &name); err != nil {
+		return nil, err
+	}
+	fade, err := e.fadeEffect(name)
+	if err != nil {
+		return nil, err
+	}
+	return starlark.Bool(fade.IsFading()), nil
+}
+
+// 画面フラッシュ
+func (e *ScriptEngine) flash(thread *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var colorVal starlark.Value
+	duration := 0.2
+	intensity := 1.0
+	name := render.EffectFlash
+	if err := starlark.UnpackArgs(b.Name(), args, kwargs, "color?", &colorVal, "duration?", &duration, "intensity?", &intensity, "name?", &name); err != nil {
+		return nil, err
+	}
+	effect, err := e.effect(name)
+	if err != nil {
+		return nil, err
+	}
+	flash, ok := effect.(*render.FlashEffect)
+	if !ok {
+		return nil, fmt.Errorf("effect %s is not a flash effect", name)
+	}
+
+	c := color.RGBA{255, 255, 255, 255}
+	if colorVal != nil && colorVal != starlark.None {
+		if c, err = parseColor(colorVal); err != nil {
+			return nil, err
+		}
+	}
+	flash.Flash(c, intensity, duration)
+	return starlark.None, nil
+}
+
+// 直前のフレームの描画統計
+func (e *ScriptEngine) getRenderStats(thread *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	if err := starlark.UnpackArgs(b.Name(), args, kwargs); err != nil {
+		return nil, err
+	}
+	if e.renderer == nil {
+		return nil, fmt.Errorf("render manager is not available")
+	}
+
+	stats := e.renderer.Stats()
+	dict := starlark.NewDict(4)
+	dict.SetKey(starlark.String("commands"), starlark.MakeInt(stats.Commands))
+	dict.SetKey(starlark.String("draw_calls"), starlark.MakeInt(stats.DrawCalls))
+	dict.SetKey(starlark.String("batches"), starlark.MakeInt(stats.Batches))
+	dict.SetKey(starlark.String("batched"), starlark.MakeInt(stats.Batched))
+	if e.textStats != nil {
+		ts := e.textStats()
+		text := starlark.NewDict(10)
+		text.SetKey(starlark.String("cache_hits"), starlark.MakeInt(ts.CacheHits))
+		text.SetKey(starlark.String("cache_misses"), starlark.MakeInt(ts.CacheMisses))
+		text.SetKey(starlark.String("evictions"), starlark.MakeInt(ts.Evictions))
+		text.SetKey(starlark.String("cached_images"), starlark.MakeInt(ts.CachedImages))
+		text.SetKey(starlark.String("cached_pixels"), starlark.MakeInt(ts.CachedPixels))
+		text.SetKey(starlark.String("atlas_glyphs"), starlark.MakeInt(ts.AtlasGlyphs))
+		text.SetKey(starlark.String("atlas_pages"), starlark.MakeInt(ts.AtlasPages))
+		text.SetKey(starlark.String("atlas_hits"), starlark.MakeInt(ts.AtlasHits))
+		text.SetKey(starlark.String("atlas_misses"), starlark.MakeInt(ts.AtlasMisses))
+		text.SetKey(starlark.String("atlas_texts"), starlark.MakeInt(ts.AtlasTexts))
+		dict.SetKey(starlark.String("text"), text)
+	}
+	return dict, nil
+}
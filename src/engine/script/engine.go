@@ -10,8 +10,15 @@ import (
 	"strings"
 	"sync"
 
+	"gameengine/src/engine/asset"
 	"gameengine/src/engine/ecs/components"
 	"gameengine/src/engine/ecs/core"
+	"gameengine/src/engine/i18n"
+	"gameengine/src/engine/input"
+	"gameengine/src/engine/render"
+	"gameengine/src/engine/richtext"
+	"gameengine/src/engine/save"
+	"gameengine/src/engine/ui"
 	"image/color"
 
 	"github.com/hajimehoshi/ebiten/v2"
@@ -26,6 +33,24 @@ type ScriptEngine struct {
 	globals      starlark.StringDict
 	scriptDir    string
 	stateManager *StateManager
+	assetManager *asset.AssetManager
+	pipeline     *render.Pipeline
+	viewport     *render.Viewport
+	renderer     *render.RenderManager
+	textStats    func() render.TextStats
+	textMeasurer func(c *components.TextComponent) (width, height float64, lines int)
+	mouse        *input.MouseState
+	localizer    *i18n.Localizer
+	uiManager    *ui.UIManager
+	inputManager *input.InputManager
+	faces        richtext.FaceFunc
+	saveManager  *save.SaveManager
+	uiNodes      map[string]*uiNode
+	nextUIID     int
+
+	prefabs        map[string]starlark.Callable
+	currentTilemap core.EntityID
+	tilemapLoaded  bool
 }
 
 func NewScriptEngine(world *core.World, scriptDir string) *ScriptEngine {
@@ -35,6 +60,9 @@ func NewScriptEngine(world *core.World, scriptDir string) *ScriptEngine {
 		globals:      make(starlark.StringDict),
 		scriptDir:    scriptDir,
 		stateManager: NewStateManager(world), // StateManagerを初期化
+		prefabs:      make(map[string]starlark.Callable),
+		localizer:    i18n.Default,
+		uiNodes:      make(map[string]*uiNode),
 	}
 
 	// デバッグ用
@@ -46,6 +74,65 @@ func NewScriptEngine(world *core.World, scriptDir string) *ScriptEngine {
 	return engine
 }
 
+// アセットマネージャーの設定
+func (e *ScriptEngine) SetAssetManager(am *asset.AssetManager) {
+	e.assetManager = am
+}
+
+// エフェクト操作用の描画パイプラインを設定
+func (e *ScriptEngine) SetRenderPipeline(p *render.Pipeline) {
+	e.pipeline = p
+}
+
+// 描画統計の取得用
+func (e *ScriptEngine) SetRenderManager(r *render.RenderManager) {
+	e.renderer = r
+}
+
+// テキスト描画のキャッシュ統計の取得用
+func (e *ScriptEngine) SetTextStats(stats func() render.TextStats) {
+	e.textStats = stats
+}
+
+// measure_textで使う測定（TextSystemと同じレイアウト）
+func (e *ScriptEngine) SetTextMeasurer(m func(c *components.TextComponent) (width, height float64, lines int)) {
+	e.textMeasurer = m
+}
+
+// tr、set_languageとキーで指定したテキストの訳し方を設定する
+func (e *ScriptEngine) SetLocalizer(loc *i18n.Localizer) {
+	e.localizer = loc
+}
+
+// ui_windowなどで作ったコンポーネントの登録先
+func (e *ScriptEngine) SetUIManager(m *ui.UIManager) {
+	e.uiManager = m
+}
+
+// ui_menu・ui_listの入力、is_action_pressedやゲームパッドの関数に使う
+func (e *ScriptEngine) SetInputManager(m *input.InputManager) {
+	e.inputManager = m
+}
+
+// UIのfont・size・styleの解決方法を設定
+func (e *ScriptEngine) SetFaceSource(faces richtext.FaceFunc) {
+	e.faces = faces
+}
+
+// get_variable・set_variableとbindで使うゲーム状態
+func (e *ScriptEngine) SetSaveManager(m *save.SaveManager) {
+	e.saveManager = m
+}
+
+// 座標変換用のビューポートを設定
+func (e *ScriptEngine) SetViewport(v *render.Viewport) {
+	e.viewport = v
+}
+
+func (e *ScriptEngine) SetMouseState(m *input.MouseState) {
+	e.mouse = m
+}
+
 // スクリプトの実行
 func (e *ScriptEngine) ExecuteFile(filename string) error {
 	e.mutex.Lock()
@@ -188,6 +275,35 @@ func (e *ScriptEngine) registerBuiltins() {
 	e.globals["find_entities_by_tag"] = starlark.NewBuiltin("find_entities_by_tag", e.findEntitiesByTag)
 	e.globals["add_tag"] = starlark.NewBuiltin("add_tag", e.addTag)
 	e.globals["is_key_pressed"] = starlark.NewBuiltin("is_key_pressed", e.isKeyPressed)
+	e.globals["is_action_pressed"] = starlark.NewBuiltin("is_action_pressed", e.isActionPressed)
+	e.globals["is_action_just_pressed"] = starlark.NewBuiltin("is_action_just_pressed", e.isActionJustPressed)
+	e.globals["is_action_just_released"] = starlark.NewBuiltin("is_action_just_released", e.isActionJustReleased)
+	e.globals["get_action_held_time"] = starlark.NewBuiltin("get_action_held_time", e.getActionHeldTime)
+	e.globals["get_axis"] = starlark.NewBuiltin("get_axis", e.getAxis)
+	e.globals["get_gamepads"] = starlark.NewBuiltin("get_gamepads", e.getGamepads)
+	e.globals["get_stick"] = starlark.NewBuiltin("get_stick", e.getStick)
+	e.globals["get_trigger"] = starlark.NewBuiltin("get_trigger", e.getTrigger)
+	e.globals["vibrate_gamepad"] = starlark.NewBuiltin("vibrate_gamepad", e.vibrateGamepad)
+	e.globals["get_bindings"] = starlark.NewBuiltin("get_bindings", e.getBindings)
+	e.globals["set_bindings"] = starlark.NewBuiltin("set_bindings", e.setBindings)
+	e.globals["rebind_action"] = starlark.NewBuiltin("rebind_action", e.rebindAction)
+	e.globals["cancel_rebind"] = starlark.NewBuiltin("cancel_rebind", e.cancelRebind)
+	e.globals["is_rebinding"] = starlark.NewBuiltin("is_rebinding", e.isRebinding)
+	e.globals["get_binding_conflicts"] = starlark.NewBuiltin("get_binding_conflicts", e.getBindingConflicts)
+	e.globals["reset_input_bindings"] = starlark.NewBuiltin("reset_input_bindings", e.resetInputBindings)
+	e.globals["save_input_profile"] = starlark.NewBuiltin("save_input_profile", e.saveInputProfile)
+	e.globals["load_input_profile"] = starlark.NewBuiltin("load_input_profile", e.loadInputProfile)
+	e.globals["get_input_profiles"] = starlark.NewBuiltin("get_input_profiles", e.getInputProfiles)
+	e.globals["consume_action"] = starlark.NewBuiltin("consume_action", e.consumeAction)
+	e.globals["push_input_context"] = starlark.NewBuiltin("push_input_context", e.pushInputContext)
+	e.globals["pop_input_context"] = starlark.NewBuiltin("pop_input_context", e.popInputContext)
+	e.globals["get_input_contexts"] = starlark.NewBuiltin("get_input_contexts", e.getInputContexts)
+	e.globals["input_sequence"] = starlark.NewBuiltin("input_sequence", e.inputSequence)
+	e.globals["remove_input_sequence"] = starlark.NewBuiltin("remove_input_sequence", e.removeInputSequence)
+	e.globals["is_sequence_matched"] = starlark.NewBuiltin("is_sequence_matched", e.isSequenceMatched)
+	e.globals["was_action_pressed"] = starlark.NewBuiltin("was_action_pressed", e.wasActionPressed)
+	e.globals["was_action_released"] = starlark.NewBuiltin("was_action_released", e.wasActionReleased)
+	e.globals["get_input_history"] = starlark.NewBuiltin("get_input_history", e.getInputHistory)
 	e.globals["print"] = starlark.NewBuiltin("print", e.print)
 	e.globals["set_component"] = starlark.NewBuiltin("set_component", e.setComponent)
 	e.globals["get_total_entities"] = starlark.NewBuiltin("get_total_entities", e.getTotalEntities)
@@ -195,6 +311,68 @@ func (e *ScriptEngine) registerBuiltins() {
 	e.globals["get_state"] = starlark.NewBuiltin("get_state", e.getState)
 	e.globals["set_states"] = starlark.NewBuiltin("set_states", e.setStates)
 	e.globals["get_states"] = starlark.NewBuiltin("get_states", e.getStates)
+	e.globals["play_animation"] = starlark.NewBuiltin("play_animation", e.playAnimation)
+	e.globals["stop_animation"] = starlark.NewBuiltin("stop_animation", e.stopAnimation)
+	e.globals["on_animation_event"] = starlark.NewBuiltin("on_animation_event", e.onAnimationEvent)
+	e.globals["load_tilemap"] = starlark.NewBuiltin("load_tilemap", e.loadTilemap)
+	e.globals["get_tile"] = starlark.NewBuiltin("get_tile", e.getTile)
+	e.globals["is_solid"] = starlark.NewBuiltin("is_solid", e.isSolid)
+	e.globals["get_spawn_points"] = starlark.NewBuiltin("get_spawn_points", e.getSpawnPoints)
+	e.globals["register_prefab"] = starlark.NewBuiltin("register_prefab", e.registerPrefab)
+	e.globals["add_effect"] = starlark.NewBuiltin("add_effect", e.addEffect)
+	e.globals["remove_effect"] = starlark.NewBuiltin("remove_effect", e.removeEffect)
+	e.globals["enable_effect"] = starlark.NewBuiltin("enable_effect", e.enableEffect)
+	e.globals["set_effect"] = starlark.NewBuiltin("set_effect", e.setEffect)
+	e.globals["get_effect"] = starlark.NewBuiltin("get_effect", e.getEffect)
+	e.globals["set_pass_enabled"] = starlark.NewBuiltin("set_pass_enabled", e.setPassEnabled)
+	e.globals["fade_out"] = starlark.NewBuiltin("fade_out", e.fadeOut)
+	e.globals["fade_in"] = starlark.NewBuiltin("fade_in", e.fadeIn)
+	e.globals["is_fading"] = starlark.NewBuiltin("is_fading", e.isFading)
+	e.globals["flash"] = starlark.NewBuiltin("flash", e.flash)
+	e.globals["get_render_stats"] = starlark.NewBuiltin("get_render_stats", e.getRenderStats)
+	e.globals["set_scale_mode"] = starlark.NewBuiltin("set_scale_mode", e.setScaleMode)
+	e.globals["get_screen_size"] = starlark.NewBuiltin("get_screen_size", e.getScreenSize)
+	e.globals["screen_to_world"] = starlark.NewBuiltin("screen_to_world", e.screenToWorld)
+	e.globals["get_mouse_position"] = starlark.NewBuiltin("get_mouse_position", e.getMousePosition)
+	e.globals["measure_text"] = starlark.NewBuiltin("measure_text", e.measureText)
+	e.globals["show_message"] = starlark.NewBuiltin("show_message", e.showMessage)
+	e.globals["get_message_state"] = starlark.NewBuiltin("get_message_state", e.getMessageState)
+	e.globals["get_choice"] = starlark.NewBuiltin("get_choice", e.getChoice)
+	e.globals["skip_message"] = starlark.NewBuiltin("skip_message", e.skipMessage)
+	e.globals["tr"] = starlark.NewBuiltin("tr", e.tr)
+	e.globals["get_language"] = starlark.NewBuiltin("get_language", e.getLanguage)
+	e.globals["set_language"] = starlark.NewBuiltin("set_language", e.setLanguage)
+	e.globals["get_languages"] = starlark.NewBuiltin("get_languages", e.getLanguages)
+	e.globals["set_report_missing"] = starlark.NewBuiltin("set_report_missing", e.setReportMissing)
+	e.globals["get_missing_keys"] = starlark.NewBuiltin("get_missing_keys", e.getMissingKeys)
+
+	// ゲーム状態の変数
+	e.globals["get_variable"] = starlark.NewBuiltin("get_variable", e.getVariable)
+	e.globals["set_variable"] = starlark.NewBuiltin("set_variable", e.setVariable)
+
+	// UI
+	e.globals["ui_window"] = starlark.NewBuiltin("ui_window", e.uiBuilder("window"))
+	e.globals["ui_panel"] = starlark.NewBuiltin("ui_panel", e.uiBuilder("panel"))
+	e.globals["ui_label"] = starlark.NewBuiltin("ui_label", e.uiBuilder("label"))
+	e.globals["ui_button"] = starlark.NewBuiltin("ui_button", e.uiBuilder("button"))
+	e.globals["ui_gauge"] = starlark.NewBuiltin("ui_gauge", e.uiBuilder("gauge"))
+	e.globals["ui_menu"] = starlark.NewBuiltin("ui_menu", e.uiBuilder("menu"))
+	e.globals["ui_list"] = starlark.NewBuiltin("ui_list", e.uiBuilder("list"))
+	e.globals["ui_text_input"] = starlark.NewBuiltin("ui_text_input", e.uiBuilder("text_input"))
+	e.globals["ui_slider"] = starlark.NewBuiltin("ui_slider", e.uiBuilder("slider"))
+	e.globals["ui_toggle"] = starlark.NewBuiltin("ui_toggle", e.uiBuilder("toggle"))
+	e.globals["ui_tabs"] = starlark.NewBuiltin("ui_tabs", e.uiBuilder("tabs"))
+	e.globals["ui_set"] = starlark.NewBuiltin("ui_set", e.uiSet)
+	e.globals["ui_get"] = starlark.NewBuiltin("ui_get", e.uiGet)
+	e.globals["ui_show"] = starlark.NewBuiltin("ui_show", e.uiShow)
+	e.globals["ui_hide"] = starlark.NewBuiltin("ui_hide", e.uiHide)
+	e.globals["ui_remove"] = starlark.NewBuiltin("ui_remove", e.uiRemove)
+	e.globals["ui_add_child"] = starlark.NewBuiltin("ui_add_child", e.uiAddChild)
+	e.globals["ui_on"] = starlark.NewBuiltin("ui_on", e.uiOn)
+	e.globals["ui_focus"] = starlark.NewBuiltin("ui_focus", e.uiFocus)
+	e.globals["set_theme"] = starlark.NewBuiltin("set_theme", e.setTheme)
+	e.globals["get_theme"] = starlark.NewBuiltin("get_theme", e.getTheme)
+	e.globals["get_themes"] = starlark.NewBuiltin("get_themes", e.getThemes)
 
 	// loadコマンドを追加
 	e.thread.Load = func(thread *starlark.Thread, module string) (starlark.StringDict, error) {
@@ -292,12 +470,14 @@ func (e *ScriptEngine) addComponent(thread *starlark.Thread, b *starlark.Builtin
 			if width, _ := starlark.AsInt32(widthVal); width > 0 {
 				component.Width = int(width)
 				component.Sprite = ebiten.NewImage(int(width), component.Height)
+				component.Sprite.Fill(color.White)
 			}
 		}
 		if heightVal, _, err := properties.Get(starlark.String("height")); err == nil {
 			if height, _ := starlark.AsInt32(heightVal); height > 0 {
 				component.Height = int(height)
 				component.Sprite = ebiten.NewImage(component.Width, int(height))
+				component.Sprite.Fill(color.White)
 			}
 		}
 		if colorVal, _, err := properties.Get(starlark.String("color")); err == nil {
@@ -311,27 +491,30 @@ func (e *ScriptEngine) addComponent(thread *starlark.Thread, b *starlark.Builtin
 				}
 			}
 		}
-		entity.AddComponent(component)
-
-	case "text":
-		component := components.NewTextComponent()
-		if textVal, _, err := properties.Get(starlark.String("text")); err == nil {
-			if text, _ := starlark.AsString(textVal); text != "" {
-				component.Text = text
-				fmt.Printf("Setting text to: %s\n", text) // デバッグ出力を追加
+		if layerVal, found, _ := properties.Get(starlark.String("layer")); found {
+			if layer, err := starlark.AsInt32(layerVal); err == nil {
+				component.Layer = layer
 			}
 		}
-		if xVal, _, err := properties.Get(starlark.String("x")); err == nil {
-			if x, _ := starlark.AsFloat(xVal); x != 0 {
-				component.X = x
+		for _, key := range []string{"z", "z_index"} {
+			if zVal, found, _ := properties.Get(starlark.String(key)); found {
+				if z, err := starlark.AsInt32(zVal); err == nil {
+					component.Z = z
+				}
 			}
 		}
-		if yVal, _, err := properties.Get(starlark.String("y")); err == nil {
-			if y, _ := starlark.AsFloat(yVal); y != 0 {
-				component.Y = y
-			}
+		if visibleVal, found, _ := properties.Get(starlark.String("visible")); found {
+			component.Visible = bool(visibleVal.Truth())
 		}
 		entity.AddComponent(component)
+
+	case "text":
+		component := components.NewTextComponent()
+		if err := applyTextProperties(component, properties); err != nil {
+			return nil, err
+		}
+		component.Localize(e.localizer)
+		entity.AddComponent(component)
 		fmt.Printf("Added text component to entity %d\n", entityID) // デバッグ出力を追加
 
 	case "physics":
@@ -351,7 +534,48 @@ func (e *ScriptEngine) addComponent(thread *starlark.Thread, b *starlark.Builtin
 				component.Gravity = gravity
 			}
 		}
+		// タイルマップとの衝突判定の大きさ
+		if widthVal, _, err := properties.Get(starlark.String("width")); err == nil {
+			component.Width, _ = starlark.AsFloat(widthVal)
+		}
+		if heightVal, _, err := properties.Get(starlark.String("height")); err == nil {
+			component.Height, _ = starlark.AsFloat(heightVal)
+		}
+		if solidVal, _, err := properties.Get(starlark.String("solid")); err == nil {
+			component.Solid = bool(solidVal.Truth())
+		}
+		entity.AddComponent(component)
+
+	case "animation":
+		component, err := e.newAnimationComponent(properties)
+		if err != nil {
+			return nil, err
+		}
 		entity.AddComponent(component)
+
+	case "message_window":
+		component := components.NewMessageWindowComponent()
+		if err := applyMessageWindowProperties(component.Window, properties); err != nil {
+			return nil, err
+		}
+		entity.AddComponent(component)
+
+	case "message_system":
+		component := components.NewMessageSystemComponent()
+		if err := e.applyMessageSystemProperties(component, properties); err != nil {
+			return nil, err
+		}
+		entity.AddComponent(component)
+
+	case "input":
+		component := components.NewInputComponent()
+		if err := applyInputProperties(component, properties); err != nil {
+			return nil, err
+		}
+		entity.AddComponent(component)
+
+	default:
+		return nil, fmt.Errorf("unknown component type: %s", componentType)
 	}
 
 	return starlark.None, nil
@@ -381,6 +605,14 @@ func (e *ScriptEngine) getComponent(thread *starlark.Thread, b *starlark.Builtin
 		componentID = 3
 	case "physics":
 		componentID = 5
+	case "animation":
+		componentID = 6
+	case "message_window":
+		componentID = 8
+	case "message_system":
+		componentID = 9
+	case "input":
+		componentID = 10
 	default:
 		return nil, fmt.Errorf("unknown component type: %s", componentType)
 	}
@@ -397,9 +629,27 @@ func (e *ScriptEngine) getComponent(thread *starlark.Thread, b *starlark.Builtin
 	case *components.TransformComponent:
 		dict.SetKey(starlark.String("x"), starlark.Float(c.X))
 		dict.SetKey(starlark.String("y"), starlark.Float(c.Y))
+	case *components.TextComponent:
+		textComponentToDict(c, dict)
 	case *components.PhysicsComponent:
 		dict.SetKey(starlark.String("velocity_x"), starlark.Float(c.VelocityX))
 		dict.SetKey(starlark.String("velocity_y"), starlark.Float(c.VelocityY))
+		dict.SetKey(starlark.String("on_ground"), starlark.Bool(c.OnGround))
+	case *components.AnimationComponent:
+		dict.SetKey(starlark.String("clip"), starlark.String(c.Current))
+		dict.SetKey(starlark.String("frame"), starlark.MakeInt(c.Player.FrameIndex()))
+		dict.SetKey(starlark.String("playing"), starlark.Bool(c.Player.IsPlaying()))
+		dict.SetKey(starlark.String("finished"), starlark.Bool(c.Player.IsFinished()))
+	case *components.MessageWindowComponent:
+		messageWindowToDict(c.Window, dict)
+	case *components.MessageSystemComponent:
+		messageSystemToDict(c, dict)
+	case *components.InputComponent:
+		actions := make([]starlark.Value, 0, len(c.Keys))
+		for action := range c.Keys {
+			actions = append(actions, starlark.String(action))
+		}
+		dict.SetKey(starlark.String("actions"), starlark.NewList(actions))
 	}
 
 	return dict, nil
@@ -446,36 +696,27 @@ func (e *ScriptEngine) addTag(thread *starlark.Thread, b *starlark.Builtin, args
 	return starlark.None, nil
 }
 
-// キー入力の検知
+// キー入力の検知（メニューや会話など上のコンテキストが使っているキーは押していないとみなす）
 func (e *ScriptEngine) isKeyPressed(thread *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
 	var keyName string
-	if err := starlark.UnpackPositionalArgs(b.Name(), args, kwargs, 1, &keyName); err != nil {
+	context := input.ContextGameplay
+	if err := starlark.UnpackArgs(b.Name(), args, kwargs, "key", &keyName, "context?", &context); err != nil {
 		return nil, err
 	}
 
+	// キー名はEbitenの名前（"Space"、"ArrowLeft"、"A"、"Enter"など、大文字小文字は区別しない）
 	var key ebiten.Key
-	switch keyName {
-	case "Space":
-		key = ebiten.KeySpace
-	case "ArrowLeft":
-		key = ebiten.KeyArrowLeft
-	case "ArrowRight":
-		key = ebiten.KeyArrowRight
-	case "ArrowUp":
-		key = ebiten.KeyArrowUp
-	case "ArrowDown":
-		key = ebiten.KeyArrowDown
-	default:
+	if err := key.UnmarshalText([]byte(keyName)); err != nil {
 		return nil, fmt.Errorf("unknown key: %s", keyName)
 	}
-
-	// キー入力の状態を直接チェック
-	isPressed := ebiten.IsKeyPressed(key)
-	// デバッグ出力を追加
-	// if isPressed {
-	// 	fmt.Printf("Key %s is pressed\n", keyName)
-	// }
-	return starlark.Bool(isPressed), nil
+	if e.inputManager == nil {
+		return starlark.Bool(ebiten.IsKeyPressed(key)), nil
+	}
+	c, err := e.inputContext(context)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", b.Name(), err)
+	}
+	return starlark.Bool(c.IsKeyPressed(key)), nil
 }
 
 // update関数の呼び出し
@@ -541,20 +782,25 @@ func (e *ScriptEngine) setComponent(thread *starlark.Thread, b *starlark.Builtin
 			return starlark.None, nil
 		}
 		textComponent := component.(*components.TextComponent)
-		if textVal, _, err := properties.Get(starlark.String("text")); err == nil {
-			if textStr, _ := starlark.AsString(textVal); true {
-				textComponent.Text = textStr
-			}
+		if err := applyTextProperties(textComponent, properties); err != nil {
+			return nil, err
 		}
-		if xVal, _, err := properties.Get(starlark.String("x")); err == nil {
-			if x, _ := starlark.AsFloat(xVal); true {
-				textComponent.X = x
-			}
+		textComponent.Localize(e.localizer)
+	case "message_window":
+		component, ok := entity.GetComponent(8).(*components.MessageWindowComponent)
+		if !ok {
+			return starlark.None, nil
 		}
-		if yVal, _, err := properties.Get(starlark.String("y")); err == nil {
-			if y, _ := starlark.AsFloat(yVal); true {
-				textComponent.Y = y
-			}
+		if err := applyMessageWindowProperties(component.Window, properties); err != nil {
+			return nil, err
+		}
+	case "message_system":
+		component, ok := entity.GetComponent(9).(*components.MessageSystemComponent)
+		if !ok {
+			return starlark.None, nil
+		}
+		if err := e.applyMessageSystemProperties(component, properties); err != nil {
+			return nil, err
 		}
 	}
 
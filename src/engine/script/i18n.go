@@ -0,0 +1,113 @@
+package script
+
+import (
+	"fmt"
+
+	"gameengine/src/engine/i18n"
+
+	"go.starlark.net/starlark"
+)
+
+// 現在の言語でキーを訳す（キーワード引数はプレースホルダーの値）
+// tr("shop.items", count=3, name="薬草")
+func (e *ScriptEngine) tr(thread *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var key string
+	if err := starlark.UnpackPositionalArgs(b.Name(), args, nil, 1, &key); err != nil {
+		return nil, err
+	}
+	values := make(i18n.Args, len(kwargs))
+	for _, kv := range kwargs {
+		name, _ := starlark.AsString(kv[0])
+		values[name] = toArgValue(kv[1])
+	}
+	return starlark.String(e.localizer.Tr(key, values)), nil
+}
+
+func (e *ScriptEngine) getLanguage(thread *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	if err := starlark.UnpackPositionalArgs(b.Name(), args, kwargs, 0); err != nil {
+		return nil, err
+	}
+	return starlark.String(e.localizer.Language()), nil
+}
+
+// 言語を切り替える（キーで指定したテキスト・メッセージ・メニューはすぐに訳し直される）
+func (e *ScriptEngine) setLanguage(thread *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var lang string
+	if err := starlark.UnpackPositionalArgs(b.Name(), args, kwargs, 1, &lang); err != nil {
+		return nil, err
+	}
+	e.localizer.SetLanguage(lang)
+	return starlark.None, nil
+}
+
+// 文字列テーブルが読み込まれている言語の一覧
+func (e *ScriptEngine) getLanguages(thread *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	if err := starlark.UnpackPositionalArgs(b.Name(), args, kwargs, 0); err != nil {
+		return nil, err
+	}
+	langs := e.localizer.Languages()
+	list := make([]starlark.Value, len(langs))
+	for i, lang := range langs {
+		list[i] = starlark.String(lang)
+	}
+	return starlark.NewList(list), nil
+}
+
+// 見つからないキーを"!key!"と表示して記録するか
+func (e *ScriptEngine) setReportMissing(thread *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var enabled bool
+	if err := starlark.UnpackPositionalArgs(b.Name(), args, kwargs, 1, &enabled); err != nil {
+		return nil, err
+	}
+	e.localizer.SetReportMissing(enabled)
+	return starlark.None, nil
+}
+
+// 記録された見つからないキー [{"lang", "key", "count"}]
+func (e *ScriptEngine) getMissingKeys(thread *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	if err := starlark.UnpackPositionalArgs(b.Name(), args, kwargs, 0); err != nil {
+		return nil, err
+	}
+	missing := e.localizer.MissingKeys()
+	list := make([]starlark.Value, len(missing))
+	for i, m := range missing {
+		dict := starlark.NewDict(3)
+		dict.SetKey(starlark.String("lang"), starlark.String(m.Lang))
+		dict.SetKey(starlark.String("key"), starlark.String(m.Key))
+		dict.SetKey(starlark.String("count"), starlark.MakeInt(m.Count))
+		list[i] = dict
+	}
+	return starlark.NewList(list), nil
+}
+
+// {"name": "勇者", "count": 3} の形のプレースホルダーの値
+func toArgs(key string, v starlark.Value) (i18n.Args, error) {
+	dict, ok := v.(*starlark.Dict)
+	if !ok {
+		return nil, fmt.Errorf("%s must be dict, got %s", key, v.Type())
+	}
+	args := make(i18n.Args, dict.Len())
+	for _, item := range dict.Items() {
+		name, ok := starlark.AsString(item[0])
+		if !ok {
+			return nil, fmt.Errorf("%s keys must be string", key)
+		}
+		args[name] = toArgValue(item[1])
+	}
+	return args, nil
+}
+
+// 数値は複数形の選択に使えるようにGoの数値にする
+func toArgValue(v starlark.Value) interface{} {
+	switch v := v.(type) {
+	case starlark.Int:
+		if n, ok := v.Int64(); ok {
+			return n
+		}
+	case starlark.Float:
+		return float64(v)
+	case starlark.String:
+		return string(v)
+	}
+	return v.String()
+}
@@ -0,0 +1,682 @@
+package script
+
+import (
+	"fmt"
+	"time"
+
+	"gameengine/src/engine/input"
+
+	"go.starlark.net/starlark"
+)
+
+// 入力コンテキスト（"gameplay"なら一番下のゲームプレイ）
+func (e *ScriptEngine) inputContext(name string) (*input.InputContext, error) {
+	if name == input.ContextGameplay {
+		return e.inputManager.Gameplay(), nil
+	}
+	if c := e.inputManager.Context(name); c != nil {
+		return c, nil
+	}
+	return nil, fmt.Errorf("input context not found: %s", name)
+}
+
+// action, context="gameplay" の引数を読む
+func (e *ScriptEngine) unpackActionArgs(b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (*input.InputContext, input.Action, error) {
+	var action string
+	context := input.ContextGameplay
+	if err := starlark.UnpackArgs(b.Name(), args, kwargs, "action", &action, "context?", &context); err != nil {
+		return nil, "", err
+	}
+	if e.inputManager == nil {
+		return nil, "", fmt.Errorf("%s: input is not available", b.Name())
+	}
+	c, err := e.inputContext(context)
+	if err != nil {
+		return nil, "", fmt.Errorf("%s: %v", b.Name(), err)
+	}
+	return c, input.Action(action), nil
+}
+
+// is_action_pressed("ok", context="gameplay")
+// キーボード・マウス・ゲームパッドのどれかで押されているか（メニューや会話で扱っている入力は押されていないとみなす）
+func (e *ScriptEngine) isActionPressed(thread *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	c, action, err := e.unpackActionArgs(b, args, kwargs)
+	if err != nil {
+		return nil, err
+	}
+	return starlark.Bool(c.IsPressed(action)), nil
+}
+
+// is_action_just_pressed("ok", context="gameplay")
+// このフレームで押されたか
+func (e *ScriptEngine) isActionJustPressed(thread *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	c, action, err := e.unpackActionArgs(b, args, kwargs)
+	if err != nil {
+		return nil, err
+	}
+	return starlark.Bool(c.IsJustPressed(action)), nil
+}
+
+// is_action_just_released("ok", context="gameplay")
+// このフレームで離されたか
+func (e *ScriptEngine) isActionJustReleased(thread *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	c, action, err := e.unpackActionArgs(b, args, kwargs)
+	if err != nil {
+		return nil, err
+	}
+	return starlark.Bool(c.IsJustReleased(action)), nil
+}
+
+// get_action_held_time("ok", context="gameplay")
+// 押し続けている秒数（押していなければ0）
+func (e *ScriptEngine) getActionHeldTime(thread *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	c, action, err := e.unpackActionArgs(b, args, kwargs)
+	if err != nil {
+		return nil, err
+	}
+	return starlark.Float(c.HeldDuration(action).Seconds()), nil
+}
+
+// consume_action("ok", context="gameplay")
+// 押したアクションを使ったことにする（離すまでどのコンテキストでも押していないとみなす）
+func (e *ScriptEngine) consumeAction(thread *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	c, action, err := e.unpackActionArgs(b, args, kwargs)
+	if err != nil {
+		return nil, err
+	}
+	c.Consume(action)
+	return starlark.None, nil
+}
+
+// get_axis("move", context="gameplay")
+// 軸アクションの値（2軸なら(x, y)、1軸なら数値）
+func (e *ScriptEngine) getAxis(thread *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	c, action, err := e.unpackActionArgs(b, args, kwargs)
+	if err != nil {
+		return nil, err
+	}
+	if e.inputManager.IsAxis2D(action) {
+		x, y := c.Axis2D(action)
+		return starlark.Tuple{starlark.Float(x), starlark.Float(y)}, nil
+	}
+	return starlark.Float(c.Axis(action)), nil
+}
+
+// push_input_context("pause", actions=["ok", "cancel"], bindings={"resume": ["KeyP"]}, blocking=True)
+// 入力コンテキストを一番上に積む（同じ名前のコンテキストがあれば置き換える）
+// actionsを省くと全体の割り当てをすべて使う
+func (e *ScriptEngine) pushInputContext(thread *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var name string
+	var actionsValue starlark.Value = starlark.None
+	var bindings *starlark.Dict
+	blocking := true
+	if err := starlark.UnpackArgs(b.Name(), args, kwargs, "name", &name, "actions?", &actionsValue, "bindings?", &bindings, "blocking?", &blocking); err != nil {
+		return nil, err
+	}
+	if e.inputManager == nil {
+		return nil, fmt.Errorf("%s: input is not available", b.Name())
+	}
+	if name == "" || name == input.ContextGameplay {
+		return nil, fmt.Errorf("%s: invalid context name: %q", b.Name(), name)
+	}
+
+	c := input.NewInputContext(name, blocking)
+	if actionsValue != starlark.None {
+		names, err := toStringList(actionsValue)
+		if err != nil {
+			return nil, fmt.Errorf("%s: actions: %v", b.Name(), err)
+		}
+		c.Actions = make([]input.Action, len(names))
+		for i, action := range names {
+			c.Actions[i] = input.Action(action)
+		}
+	}
+	if bindings != nil {
+		for _, item := range bindings.Items() {
+			action, ok := starlark.AsString(item[0])
+			if !ok {
+				return nil, fmt.Errorf("%s: bindings key must be string, got %s", b.Name(), item[0].Type())
+			}
+			names, err := toStringList(item[1])
+			if err != nil {
+				return nil, fmt.Errorf("%s: bindings %s: %v", b.Name(), action, err)
+			}
+			for _, n := range names {
+				binding, err := input.ParseBinding(n)
+				if err != nil {
+					return nil, fmt.Errorf("%s: %v", b.Name(), err)
+				}
+				c.Bindings[input.Action(action)] = append(c.Bindings[input.Action(action)], binding)
+			}
+		}
+	}
+
+	if old := e.inputManager.Context(name); old != nil {
+		e.inputManager.RemoveContext(old)
+	}
+	e.inputManager.PushContext(c)
+	return starlark.None, nil
+}
+
+// pop_input_context(name=None)
+// 名前のコンテキスト（省くと一番上）を外す
+func (e *ScriptEngine) popInputContext(thread *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var name starlark.Value = starlark.None
+	if err := starlark.UnpackPositionalArgs(b.Name(), args, kwargs, 0, &name); err != nil {
+		return nil, err
+	}
+	if e.inputManager == nil {
+		return nil, fmt.Errorf("%s: input is not available", b.Name())
+	}
+	if name == starlark.None {
+		e.inputManager.PopContext()
+		return starlark.None, nil
+	}
+	s, ok := starlark.AsString(name)
+	if !ok {
+		return nil, fmt.Errorf("%s: name must be string, got %s", b.Name(), name.Type())
+	}
+	if c := e.inputManager.Context(s); c != nil {
+		e.inputManager.RemoveContext(c)
+	}
+	return starlark.None, nil
+}
+
+// get_input_contexts()
+// 積まれているコンテキストの名前のリスト（下から）
+func (e *ScriptEngine) getInputContexts(thread *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	if err := starlark.UnpackPositionalArgs(b.Name(), args, kwargs, 0); err != nil {
+		return nil, err
+	}
+	if e.inputManager == nil {
+		return nil, fmt.Errorf("%s: input is not available", b.Name())
+	}
+	names := e.inputManager.ContextNames()
+	list := make([]starlark.Value, len(names))
+	for i, name := range names {
+		list[i] = starlark.String(name)
+	}
+	return starlark.NewList(list), nil
+}
+
+// get_gamepads()
+// つながっているゲームパッドの {"id", "name", "standard", "player"} のリスト
+func (e *ScriptEngine) getGamepads(thread *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	if err := starlark.UnpackPositionalArgs(b.Name(), args, kwargs, 0); err != nil {
+		return nil, err
+	}
+	if e.inputManager == nil {
+		return nil, fmt.Errorf("%s: input is not available", b.Name())
+	}
+	gamepads := e.inputManager.Gamepads()
+	list := make([]starlark.Value, len(gamepads))
+	for i, pad := range gamepads {
+		dict := starlark.NewDict(4)
+		dict.SetKey(starlark.String("id"), starlark.MakeInt(int(pad.ID)))
+		dict.SetKey(starlark.String("name"), starlark.String(pad.Name))
+		dict.SetKey(starlark.String("standard"), starlark.Bool(pad.Standard))
+		dict.SetKey(starlark.String("player"), starlark.MakeInt(pad.Player))
+		list[i] = dict
+	}
+	return starlark.NewList(list), nil
+}
+
+// get_stick("left", player=0)
+// スティックの傾き (x, y)（デッドゾーン適用済み、-1〜1、下・右が正）
+func (e *ScriptEngine) getStick(thread *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	name := "left"
+	player := 0
+	if err := starlark.UnpackArgs(b.Name(), args, kwargs, "stick?", &name, "player?", &player); err != nil {
+		return nil, err
+	}
+	if e.inputManager == nil {
+		return nil, fmt.Errorf("%s: input is not available", b.Name())
+	}
+	var stick input.Stick
+	switch name {
+	case "left":
+		stick = input.LeftStick
+	case "right":
+		stick = input.RightStick
+	default:
+		return nil, fmt.Errorf("%s: stick must be left or right, got %s", b.Name(), name)
+	}
+	x, y := e.inputManager.Stick(player, stick)
+	return starlark.Tuple{starlark.Float(x), starlark.Float(y)}, nil
+}
+
+// get_trigger("right", player=0)
+// トリガーの押し込み（デッドゾーン適用済み、0〜1）
+func (e *ScriptEngine) getTrigger(thread *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	name := "right"
+	player := 0
+	if err := starlark.UnpackArgs(b.Name(), args, kwargs, "trigger?", &name, "player?", &player); err != nil {
+		return nil, err
+	}
+	if e.inputManager == nil {
+		return nil, fmt.Errorf("%s: input is not available", b.Name())
+	}
+	var trigger input.Trigger
+	switch name {
+	case "left":
+		trigger = input.LeftTrigger
+	case "right":
+		trigger = input.RightTrigger
+	default:
+		return nil, fmt.Errorf("%s: trigger must be left or right, got %s", b.Name(), name)
+	}
+	return starlark.Float(e.inputManager.Trigger(player, trigger)), nil
+}
+
+// vibrate_gamepad(0.3, strong=1.0, weak=0.5, player=0)
+// 秒数だけ振動させる（振動できないパッドでは何もしない）
+func (e *ScriptEngine) vibrateGamepad(thread *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var seconds starlark.Value
+	var strongValue, weakValue starlark.Value = starlark.Float(1), starlark.Float(1)
+	player := 0
+	if err := starlark.UnpackArgs(b.Name(), args, kwargs, "duration", &seconds, "strong?", &strongValue, "weak?", &weakValue, "player?", &player); err != nil {
+		return nil, err
+	}
+	if e.inputManager == nil {
+		return nil, fmt.Errorf("%s: input is not available", b.Name())
+	}
+	duration, err := toFloat("duration", seconds)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", b.Name(), err)
+	}
+	strong, err := toFloat("strong", strongValue)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", b.Name(), err)
+	}
+	weak, err := toFloat("weak", weakValue)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", b.Name(), err)
+	}
+	e.inputManager.Vibrate(player, time.Duration(duration*float64(time.Second)), strong, weak)
+	return starlark.None, nil
+}
+
+// get_bindings("ok")
+// アクションの割り当ての名前のリスト（"KeyZ"、"GamepadA"など）
+func (e *ScriptEngine) getBindings(thread *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var action string
+	if err := starlark.UnpackPositionalArgs(b.Name(), args, kwargs, 1, &action); err != nil {
+		return nil, err
+	}
+	if e.inputManager == nil {
+		return nil, fmt.Errorf("%s: input is not available", b.Name())
+	}
+	bindings := e.inputManager.Bindings(input.Action(action))
+	names := make([]starlark.Value, len(bindings))
+	for i, binding := range bindings {
+		names[i] = starlark.String(binding.String())
+	}
+	return starlark.NewList(names), nil
+}
+
+// set_bindings("ok", ["KeyZ", "KeyEnter", "GamepadA"])
+// アクションの割り当てを置き換える
+func (e *ScriptEngine) setBindings(thread *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var action string
+	var names starlark.Iterable
+	if err := starlark.UnpackPositionalArgs(b.Name(), args, kwargs, 2, &action, &names); err != nil {
+		return nil, err
+	}
+	if e.inputManager == nil {
+		return nil, fmt.Errorf("%s: input is not available", b.Name())
+	}
+	list, err := toStringList(names)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", b.Name(), err)
+	}
+	var bindings []input.InputBinding
+	for _, name := range list {
+		binding, err := input.ParseBinding(name)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", b.Name(), err)
+		}
+		bindings = append(bindings, binding)
+	}
+	e.inputManager.SetBindings(input.Action(action), bindings)
+	return starlark.None, nil
+}
+
+// rebind_action("ok", on_done=None, index=-1, device="any", swap=True)
+// 次に押された入力をアクションに割り当てる（indexの割り当てを置き換え、範囲外なら追加）
+// on_done(binding, changed)が呼ばれる。Escで取り消すとbindingはNone、changedは割り当てが変わったほかのアクション
+func (e *ScriptEngine) rebindAction(thread *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var action string
+	var onDone starlark.Value = starlark.None
+	index := -1
+	device := "any"
+	swap := true
+	if err := starlark.UnpackArgs(b.Name(), args, kwargs, "action", &action, "on_done?", &onDone, "index?", &index, "device?", &device, "swap?", &swap); err != nil {
+		return nil, err
+	}
+	if e.inputManager == nil {
+		return nil, fmt.Errorf("%s: input is not available", b.Name())
+	}
+	var callback starlark.Callable
+	if onDone != starlark.None {
+		fn, ok := onDone.(starlark.Callable)
+		if !ok {
+			return nil, fmt.Errorf("%s: on_done must be callable, got %s", b.Name(), onDone.Type())
+		}
+		callback = fn
+	}
+	var options input.ListenOptions
+	switch device {
+	case "any":
+		options.Devices = input.AnyDevice
+	case "keyboard":
+		options.Devices = input.KeyboardMouseDevice
+	case "gamepad":
+		options.Devices = input.GamepadDevice
+	default:
+		return nil, fmt.Errorf("%s: device must be any, keyboard or gamepad, got %s", b.Name(), device)
+	}
+
+	im := e.inputManager
+	im.ListenForBinding(options, func(binding input.InputBinding, ok bool) {
+		var name starlark.Value = starlark.None
+		var changed []starlark.Value
+		if ok {
+			name = starlark.String(binding.String())
+			for _, other := range im.Rebind(input.Action(action), index, binding, swap) {
+				changed = append(changed, starlark.String(other))
+			}
+		}
+		if callback == nil {
+			return
+		}
+		if _, err := starlark.Call(e.thread, callback, starlark.Tuple{name, starlark.NewList(changed)}, nil); err != nil {
+			fmt.Printf("error in rebind callback: %v\n", err)
+		}
+	})
+	return starlark.None, nil
+}
+
+// cancel_rebind()
+func (e *ScriptEngine) cancelRebind(thread *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	if err := starlark.UnpackPositionalArgs(b.Name(), args, kwargs, 0); err != nil {
+		return nil, err
+	}
+	if e.inputManager == nil {
+		return nil, fmt.Errorf("%s: input is not available", b.Name())
+	}
+	e.inputManager.CancelListen()
+	return starlark.None, nil
+}
+
+// is_rebinding()
+func (e *ScriptEngine) isRebinding(thread *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	if err := starlark.UnpackPositionalArgs(b.Name(), args, kwargs, 0); err != nil {
+		return nil, err
+	}
+	if e.inputManager == nil {
+		return nil, fmt.Errorf("%s: input is not available", b.Name())
+	}
+	return starlark.Bool(e.inputManager.IsListening()), nil
+}
+
+// get_binding_conflicts()
+// 複数のアクションに割り当てられた入力の {"binding", "actions"} のリスト
+func (e *ScriptEngine) getBindingConflicts(thread *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	if err := starlark.UnpackPositionalArgs(b.Name(), args, kwargs, 0); err != nil {
+		return nil, err
+	}
+	if e.inputManager == nil {
+		return nil, fmt.Errorf("%s: input is not available", b.Name())
+	}
+	conflicts := e.inputManager.Config().Conflicts()
+	list := make([]starlark.Value, len(conflicts))
+	for i, conflict := range conflicts {
+		actions := make([]starlark.Value, len(conflict.Actions))
+		for j, action := range conflict.Actions {
+			actions[j] = starlark.String(action)
+		}
+		dict := starlark.NewDict(2)
+		dict.SetKey(starlark.String("binding"), starlark.String(conflict.Binding.String()))
+		dict.SetKey(starlark.String("actions"), starlark.NewList(actions))
+		list[i] = dict
+	}
+	return starlark.NewList(list), nil
+}
+
+// reset_input_bindings()
+// 割り当てを既定に戻す
+func (e *ScriptEngine) resetInputBindings(thread *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	if err := starlark.UnpackPositionalArgs(b.Name(), args, kwargs, 0); err != nil {
+		return nil, err
+	}
+	if e.inputManager == nil {
+		return nil, fmt.Errorf("%s: input is not available", b.Name())
+	}
+	e.inputManager.ResetBindings()
+	return starlark.None, nil
+}
+
+// save_input_profile("default") / load_input_profile("default")
+func (e *ScriptEngine) saveInputProfile(thread *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	name := "default"
+	if err := starlark.UnpackPositionalArgs(b.Name(), args, kwargs, 0, &name); err != nil {
+		return nil, err
+	}
+	if e.inputManager == nil {
+		return nil, fmt.Errorf("%s: input is not available", b.Name())
+	}
+	if err := e.inputManager.SaveProfile(name); err != nil {
+		return nil, fmt.Errorf("%s: %v", b.Name(), err)
+	}
+	return starlark.None, nil
+}
+
+func (e *ScriptEngine) loadInputProfile(thread *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	name := "default"
+	if err := starlark.UnpackPositionalArgs(b.Name(), args, kwargs, 0, &name); err != nil {
+		return nil, err
+	}
+	if e.inputManager == nil {
+		return nil, fmt.Errorf("%s: input is not available", b.Name())
+	}
+	if err := e.inputManager.LoadProfile(name); err != nil {
+		return nil, fmt.Errorf("%s: %v", b.Name(), err)
+	}
+	return starlark.None, nil
+}
+
+// get_input_profiles()
+// 保存されているプロファイルの名前のリスト
+func (e *ScriptEngine) getInputProfiles(thread *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	if err := starlark.UnpackPositionalArgs(b.Name(), args, kwargs, 0); err != nil {
+		return nil, err
+	}
+	if e.inputManager == nil {
+		return nil, fmt.Errorf("%s: input is not available", b.Name())
+	}
+	names, err := e.inputManager.Profiles()
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", b.Name(), err)
+	}
+	list := make([]starlark.Value, len(names))
+	for i, name := range names {
+		list[i] = starlark.String(name)
+	}
+	return starlark.NewList(list), nil
+}
+
+// 秒数の引数を読む
+func toDuration(name string, v starlark.Value) (time.Duration, error) {
+	seconds, err := toFloat(name, v)
+	if err != nil {
+		return 0, err
+	}
+	return time.Duration(seconds * float64(time.Second)), nil
+}
+
+// input_sequence("hadouken", ["down", "down_right", "right", "ok"], window=0.5, step_window=0, player=0, on_match=None)
+// コマンドを登録する（同じ名前があれば置き換える）。成立するとon_match(player)が呼ばれる
+func (e *ScriptEngine) inputSequence(thread *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var name string
+	var stepsValue starlark.Value
+	var windowValue starlark.Value = starlark.Float(input.DefaultSequenceWindow.Seconds())
+	var stepWindowValue starlark.Value = starlark.Float(0)
+	player := 0
+	var onMatch starlark.Value = starlark.None
+	if err := starlark.UnpackArgs(b.Name(), args, kwargs, "name", &name, "steps", &stepsValue, "window?", &windowValue, "step_window?", &stepWindowValue, "player?", &player, "on_match?", &onMatch); err != nil {
+		return nil, err
+	}
+	if e.inputManager == nil {
+		return nil, fmt.Errorf("%s: input is not available", b.Name())
+	}
+	steps, err := toStringList(stepsValue)
+	if err != nil {
+		return nil, fmt.Errorf("%s: steps: %v", b.Name(), err)
+	}
+	if len(steps) == 0 {
+		return nil, fmt.Errorf("%s: steps must not be empty", b.Name())
+	}
+	window, err := toDuration("window", windowValue)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", b.Name(), err)
+	}
+	stepWindow, err := toDuration("step_window", stepWindowValue)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", b.Name(), err)
+	}
+
+	sequence := input.InputSequence{Name: name, Window: window, StepWindow: stepWindow, Player: player}
+	for _, step := range steps {
+		sequence.Steps = append(sequence.Steps, input.Action(step))
+	}
+	if onMatch != starlark.None {
+		fn, ok := onMatch.(starlark.Callable)
+		if !ok {
+			return nil, fmt.Errorf("%s: on_match must be callable, got %s", b.Name(), onMatch.Type())
+		}
+		sequence.OnMatch = func(player int) {
+			if _, err := starlark.Call(e.thread, fn, starlark.Tuple{starlark.MakeInt(player)}, nil); err != nil {
+				fmt.Printf("error in sequence callback: %v\n", err)
+			}
+		}
+	}
+	e.inputManager.AddSequence(sequence)
+	return starlark.None, nil
+}
+
+// remove_input_sequence("hadouken")
+func (e *ScriptEngine) removeInputSequence(thread *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var name string
+	if err := starlark.UnpackPositionalArgs(b.Name(), args, kwargs, 1, &name); err != nil {
+		return nil, err
+	}
+	if e.inputManager == nil {
+		return nil, fmt.Errorf("%s: input is not available", b.Name())
+	}
+	e.inputManager.RemoveSequence(name)
+	return starlark.None, nil
+}
+
+// is_sequence_matched("hadouken")
+// このフレームでコマンドが成立したか
+func (e *ScriptEngine) isSequenceMatched(thread *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var name string
+	if err := starlark.UnpackPositionalArgs(b.Name(), args, kwargs, 1, &name); err != nil {
+		return nil, err
+	}
+	if e.inputManager == nil {
+		return nil, fmt.Errorf("%s: input is not available", b.Name())
+	}
+	_, ok := e.inputManager.SequenceMatched(name)
+	return starlark.Bool(ok), nil
+}
+
+// was_action_pressed("jump", within=0.15, player=0, consume=False)
+// within秒以内に押したか（先行入力）。consumeがTrueなら、見つけた入力を使ったことにする
+func (e *ScriptEngine) wasActionPressed(thread *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var action string
+	var withinValue starlark.Value = starlark.Float(0.15)
+	player := 0
+	consume := false
+	if err := starlark.UnpackArgs(b.Name(), args, kwargs, "action", &action, "within?", &withinValue, "player?", &player, "consume?", &consume); err != nil {
+		return nil, err
+	}
+	if e.inputManager == nil {
+		return nil, fmt.Errorf("%s: input is not available", b.Name())
+	}
+	within, err := toDuration("within", withinValue)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", b.Name(), err)
+	}
+	pressed := e.inputManager.WasPressed(input.Action(action), within, player)
+	if pressed && consume {
+		e.inputManager.ConsumeBuffered(input.Action(action), player)
+	}
+	return starlark.Bool(pressed), nil
+}
+
+// was_action_released("jump", within=0.15, player=0)
+func (e *ScriptEngine) wasActionReleased(thread *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var action string
+	var withinValue starlark.Value = starlark.Float(0.15)
+	player := 0
+	if err := starlark.UnpackArgs(b.Name(), args, kwargs, "action", &action, "within?", &withinValue, "player?", &player); err != nil {
+		return nil, err
+	}
+	if e.inputManager == nil {
+		return nil, fmt.Errorf("%s: input is not available", b.Name())
+	}
+	within, err := toDuration("within", withinValue)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", b.Name(), err)
+	}
+	return starlark.Bool(e.inputManager.WasReleased(input.Action(action), within, player)), nil
+}
+
+// get_input_history(player=0, within=1.0)
+// within秒以内の入力の {"action", "pressed", "direction", "player", "age"} のリスト（古い順、ageは何秒前か）
+func (e *ScriptEngine) getInputHistory(thread *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	player := 0
+	var withinValue starlark.Value = starlark.Float(1)
+	if err := starlark.UnpackArgs(b.Name(), args, kwargs, "player?", &player, "within?", &withinValue); err != nil {
+		return nil, err
+	}
+	if e.inputManager == nil {
+		return nil, fmt.Errorf("%s: input is not available", b.Name())
+	}
+	within, err := toDuration("within", withinValue)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", b.Name(), err)
+	}
+	var list []starlark.Value
+	for _, event := range e.inputManager.History(player, within) {
+		d := starlark.NewDict(5)
+		d.SetKey(starlark.String("action"), starlark.String(event.Action))
+		d.SetKey(starlark.String("pressed"), starlark.Bool(event.Pressed))
+		d.SetKey(starlark.String("direction"), starlark.Bool(event.Direction))
+		d.SetKey(starlark.String("player"), starlark.MakeInt(event.Player))
+		d.SetKey(starlark.String("age"), starlark.Float(e.inputManager.Age(event).Seconds()))
+		list = append(list, d)
+	}
+	return starlark.NewList(list), nil
+}
+
+// 文字列のリスト（タプルでもよい）を読む
+func toStringList(value starlark.Value) ([]string, error) {
+	iterable, ok := value.(starlark.Iterable)
+	if !ok {
+		return nil, fmt.Errorf("want list of strings, got %s", value.Type())
+	}
+	var list []string
+	iter := iterable.Iterate()
+	defer iter.Done()
+	var v starlark.Value
+	for iter.Next(&v) {
+		s, ok := starlark.AsString(v)
+		if !ok {
+			return nil, fmt.Errorf("want string, got %s", v.Type())
+		}
+		list = append(list, s)
+	}
+	return list, nil
+}
@@ -0,0 +1,438 @@
+package script
+
+import (
+	"fmt"
+	"image/color"
+
+	"gameengine/src/engine/ecs/components"
+	"gameengine/src/engine/ecs/core"
+	"gameengine/src/engine/ui"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"go.starlark.net/starlark"
+)
+
+// メッセージウィンドウに辞書のプロパティを反映（指定されたキーだけ変更）
+// skinは先に反映し、ほかのプロパティでスキンの色などを上書きできるようにする
+func applyMessageWindowProperties(w *ui.MessageWindow, properties *starlark.Dict) error {
+	if value, found, _ := properties.Get(starlark.String("skin")); found {
+		name, err := toString("skin", value)
+		if err != nil {
+			return err
+		}
+		w.SkinName = name
+		w.ApplyTheme(ui.DefaultThemes.Current())
+	}
+
+	for _, item := range properties.Items() {
+		key, ok := starlark.AsString(item[0])
+		if !ok {
+			return fmt.Errorf("message_window property name must be string")
+		}
+		value := item[1]
+
+		var err error
+		switch key {
+		case "text":
+			var text string
+			if text, err = toString(key, value); err == nil {
+				w.SetText(text)
+			}
+		case "x":
+			w.X, err = toFloat(key, value)
+		case "y":
+			w.Y, err = toFloat(key, value)
+		case "width":
+			w.Width, err = toFloat(key, value)
+		case "height":
+			w.Height, err = toFloat(key, value)
+		case "padding":
+			var padding float64
+			if padding, err = toFloat(key, value); err == nil {
+				w.SetPadding(padding)
+			}
+		case "line_height":
+			var height float64
+			if height, err = toFloat(key, value); err == nil {
+				w.SetLineHeight(height)
+			}
+		case "font":
+			w.FontID, err = toString(key, value)
+		case "font_size":
+			w.FontSize, err = toFloat(key, value)
+		case "max_lines":
+			var n int
+			if n, err = starlark.AsInt32(value); err == nil {
+				w.MaxLines = n
+			}
+		case "background_color", "text_color", "cursor_color":
+			var c color.Color
+			if c, err = parseColor(value); err == nil {
+				switch key {
+				case "background_color":
+					w.SetBackgroundColor(c)
+				case "text_color":
+					w.SetTextColor(c)
+				default:
+					w.SetCursorColor(c)
+				}
+			}
+		case "visible":
+			w.Visible = bool(value.Truth())
+		case "z_index":
+			var z int
+			if z, err = starlark.AsInt32(value); err == nil {
+				w.ZIndex = z
+			}
+		case "skin":
+			// 先に反映済み
+		default:
+			return fmt.Errorf("unknown message_window property: %s", key)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	w.Relayout()
+	return nil
+}
+
+// メッセージシステムに辞書のプロパティを反映
+func (e *ScriptEngine) applyMessageSystemProperties(c *components.MessageSystemComponent, properties *starlark.Dict) error {
+	for _, item := range properties.Items() {
+		key, ok := starlark.AsString(item[0])
+		if !ok {
+			return fmt.Errorf("message_system property name must be string")
+		}
+		value := item[1]
+
+		var err error
+		switch key {
+		case "messages":
+			var messages []components.Message
+			if messages, err = e.toMessages(value); err == nil {
+				c.Enqueue(messages...)
+			}
+		case "char_delay":
+			c.CharDelay, err = toFloat(key, value)
+		case "window_id":
+			var id int
+			if id, err = starlark.AsInt32(value); err == nil {
+				c.WindowID = core.EntityID(id)
+			}
+		case "hide_on_finish":
+			c.HideOnFinish = bool(value.Truth())
+		case "on_choice":
+			c.OnChoice, err = e.choiceCallback(value)
+		case "on_finish":
+			callback, ok := value.(starlark.Callable)
+			if !ok {
+				return fmt.Errorf("on_finish must be callable, got %s", value.Type())
+			}
+			c.OnFinish = func() {
+				if _, err := starlark.Call(e.thread, callback, nil, nil); err != nil {
+					fmt.Printf("error in message finish callback: %v\n", err)
+				}
+			}
+		default:
+			return fmt.Errorf("unknown message_system property: %s", key)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// 選択肢のコールバック callback(index, option)
+func (e *ScriptEngine) choiceCallback(v starlark.Value) (func(int, string), error) {
+	if v == starlark.None {
+		return nil, nil
+	}
+	callback, ok := v.(starlark.Callable)
+	if !ok {
+		return nil, fmt.Errorf("on_choice must be callable, got %s", v.Type())
+	}
+	return func(index int, option string) {
+		callArgs := starlark.Tuple{starlark.MakeInt(index), starlark.String(option)}
+		if _, err := starlark.Call(e.thread, callback, callArgs, nil); err != nil {
+			fmt.Printf("error in message choice callback: %v\n", err)
+		}
+	}, nil
+}
+
+// 文字列、辞書、またはそれらのリストをメッセージに変換
+func (e *ScriptEngine) toMessages(v starlark.Value) ([]components.Message, error) {
+	list, ok := v.(*starlark.List)
+	if !ok {
+		msg, err := e.toMessage(v)
+		if err != nil {
+			return nil, err
+		}
+		return []components.Message{msg}, nil
+	}
+
+	messages := make([]components.Message, 0, list.Len())
+	for i := 0; i < list.Len(); i++ {
+		msg, err := e.toMessage(list.Index(i))
+		if err != nil {
+			return nil, fmt.Errorf("messages[%d]: %v", i, err)
+		}
+		messages = append(messages, msg)
+	}
+	return messages, nil
+}
+
+// "テキスト" または {"type": "choice", "text": ..., "options": [...], "on_choice": fn}
+// textの代わりにkey（と args）で文字列テーブルから引ける
+func (e *ScriptEngine) toMessage(v starlark.Value) (components.Message, error) {
+	switch v := v.(type) {
+	case starlark.String:
+		return components.Message{Text: string(v)}, nil
+	case *starlark.Dict:
+		var msg components.Message
+		var msgType string
+		for _, item := range v.Items() {
+			key, ok := starlark.AsString(item[0])
+			if !ok {
+				return msg, fmt.Errorf("message property name must be string")
+			}
+			value := item[1]
+
+			var err error
+			switch key {
+			case "type":
+				msgType, err = toString(key, value)
+			case "text":
+				msg.Text, err = toString(key, value)
+			case "key":
+				msg.Key, err = toString(key, value)
+			case "args":
+				msg.Args, err = toArgs(key, value)
+			case "options", "choices":
+				msg.Choices, err = toChoices(value)
+			case "on_choice":
+				msg.OnChoice, err = e.choiceCallback(value)
+			default:
+				return msg, fmt.Errorf("unknown message property: %s", key)
+			}
+			if err != nil {
+				return msg, err
+			}
+		}
+		switch msgType {
+		case "", "text":
+		case "choice":
+			if len(msg.Choices) == 0 {
+				return msg, fmt.Errorf("choice message must have options")
+			}
+		default:
+			return msg, fmt.Errorf("unknown message type: %s", msgType)
+		}
+		return msg, nil
+	default:
+		return components.Message{}, fmt.Errorf("message must be string or dict, got %s", v.Type())
+	}
+}
+
+// ["はい", {"text": "いいえ", "enabled": False}, {"key": "choice.cancel"}]
+func toChoices(v starlark.Value) ([]components.MessageChoice, error) {
+	list, ok := v.(*starlark.List)
+	if !ok {
+		return nil, fmt.Errorf("options must be list, got %s", v.Type())
+	}
+	choices := make([]components.MessageChoice, 0, list.Len())
+	for i := 0; i < list.Len(); i++ {
+		choice := components.MessageChoice{Enabled: true}
+		switch item := list.Index(i).(type) {
+		case starlark.String:
+			choice.Text = string(item)
+		case *starlark.Dict:
+			if keyVal, found, _ := item.Get(starlark.String("key")); found {
+				key, err := toString("key", keyVal)
+				if err != nil {
+					return nil, err
+				}
+				choice.Key = key
+			}
+			textVal, found, _ := item.Get(starlark.String("text"))
+			if !found && choice.Key == "" {
+				return nil, fmt.Errorf("options[%d] must have text or key", i)
+			}
+			if found {
+				text, err := toString("text", textVal)
+				if err != nil {
+					return nil, err
+				}
+				choice.Text = text
+			}
+			if enabledVal, found, _ := item.Get(starlark.String("enabled")); found {
+				choice.Enabled = bool(enabledVal.Truth())
+			}
+		default:
+			return nil, fmt.Errorf("options[%d] must be string or dict, got %s", i, item.Type())
+		}
+		choices = append(choices, choice)
+	}
+	return choices, nil
+}
+
+// {"keys": {"next": "Space", "select": ["Enter", "Z"]}}
+func applyInputProperties(c *components.InputComponent, properties *starlark.Dict) error {
+	for _, item := range properties.Items() {
+		key, ok := starlark.AsString(item[0])
+		if !ok {
+			return fmt.Errorf("input property name must be string")
+		}
+		if key != "keys" {
+			return fmt.Errorf("unknown input property: %s", key)
+		}
+		keys, ok := item[1].(*starlark.Dict)
+		if !ok {
+			return fmt.Errorf("keys must be dict, got %s", item[1].Type())
+		}
+
+		for _, binding := range keys.Items() {
+			action, ok := starlark.AsString(binding[0])
+			if !ok {
+				return fmt.Errorf("action name must be string")
+			}
+			var names []starlark.Value
+			if list, ok := binding[1].(*starlark.List); ok {
+				for i := 0; i < list.Len(); i++ {
+					names = append(names, list.Index(i))
+				}
+			} else {
+				names = []starlark.Value{binding[1]}
+			}
+
+			c.Keys[action] = nil
+			for _, v := range names {
+				name, ok := starlark.AsString(v)
+				if !ok {
+					return fmt.Errorf("key for %s must be string, got %s", action, v.Type())
+				}
+				var k ebiten.Key
+				if err := k.UnmarshalText([]byte(name)); err != nil {
+					return fmt.Errorf("unknown key for %s: %s", action, name)
+				}
+				c.Keys[action] = append(c.Keys[action], k)
+			}
+		}
+	}
+	return nil
+}
+
+func (e *ScriptEngine) getMessageSystemComponent(entityID int64) (*components.MessageSystemComponent, error) {
+	entity := e.world.GetEntity(core.EntityID(entityID))
+	if entity == nil {
+		return nil, fmt.Errorf("entity not found: %d", entityID)
+	}
+	component, ok := entity.GetComponent(9).(*components.MessageSystemComponent)
+	if !ok || component == nil {
+		return nil, fmt.Errorf("entity %d has no message_system component", entityID)
+	}
+	return component, nil
+}
+
+// メッセージの追加
+// messagesは文字列、辞書、またはそれらのリスト
+func (e *ScriptEngine) showMessage(thread *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var entityID int64
+	var messagesVal starlark.Value
+	var onChoice starlark.Value = starlark.None
+	if err := starlark.UnpackArgs(b.Name(), args, kwargs, "system_id", &entityID, "messages", &messagesVal, "on_choice?", &onChoice); err != nil {
+		return nil, err
+	}
+
+	component, err := e.getMessageSystemComponent(entityID)
+	if err != nil {
+		return nil, err
+	}
+	messages, err := e.toMessages(messagesVal)
+	if err != nil {
+		return nil, err
+	}
+	if onChoice != starlark.None {
+		callback, err := e.choiceCallback(onChoice)
+		if err != nil {
+			return nil, err
+		}
+		for i := range messages {
+			if len(messages[i].Choices) > 0 && messages[i].OnChoice == nil {
+				messages[i].OnChoice = callback
+			}
+		}
+	}
+	component.Enqueue(messages...)
+	return starlark.None, nil
+}
+
+// 表示状態 "idle"、"revealing"、"waiting"、"choosing"、"finished"
+func (e *ScriptEngine) getMessageState(thread *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var entityID int64
+	if err := starlark.UnpackPositionalArgs(b.Name(), args, kwargs, 1, &entityID); err != nil {
+		return nil, err
+	}
+	component, err := e.getMessageSystemComponent(entityID)
+	if err != nil {
+		return nil, err
+	}
+	return starlark.String(component.State.String()), nil
+}
+
+// 最後に選ばれた選択肢（選択中・未選択ならNone）
+// update() の中で待つ場合に使う
+func (e *ScriptEngine) getChoice(thread *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var entityID int64
+	if err := starlark.UnpackPositionalArgs(b.Name(), args, kwargs, 1, &entityID); err != nil {
+		return nil, err
+	}
+	component, err := e.getMessageSystemComponent(entityID)
+	if err != nil {
+		return nil, err
+	}
+	if component.Choice < 0 {
+		return starlark.None, nil
+	}
+	dict := starlark.NewDict(2)
+	dict.SetKey(starlark.String("index"), starlark.MakeInt(component.Choice))
+	dict.SetKey(starlark.String("option"), starlark.String(component.ChoiceText))
+	return dict, nil
+}
+
+// 文字送り中なら全文を表示する
+func (e *ScriptEngine) skipMessage(thread *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var entityID int64
+	if err := starlark.UnpackPositionalArgs(b.Name(), args, kwargs, 1, &entityID); err != nil {
+		return nil, err
+	}
+	component, err := e.getMessageSystemComponent(entityID)
+	if err != nil {
+		return nil, err
+	}
+	component.SkipRequested = true
+	return starlark.None, nil
+}
+
+func messageSystemToDict(c *components.MessageSystemComponent, dict *starlark.Dict) {
+	dict.SetKey(starlark.String("state"), starlark.String(c.State.String()))
+	dict.SetKey(starlark.String("current"), starlark.MakeInt(c.Current))
+	dict.SetKey(starlark.String("count"), starlark.MakeInt(len(c.Messages)))
+	dict.SetKey(starlark.String("char_delay"), starlark.Float(c.CharDelay))
+	dict.SetKey(starlark.String("window_id"), starlark.MakeUint64(uint64(c.WindowID)))
+	if c.Choice >= 0 {
+		dict.SetKey(starlark.String("choice"), starlark.MakeInt(c.Choice))
+		dict.SetKey(starlark.String("choice_text"), starlark.String(c.ChoiceText))
+	}
+}
+
+func messageWindowToDict(w *ui.MessageWindow, dict *starlark.Dict) {
+	dict.SetKey(starlark.String("text"), starlark.String(w.Text))
+	dict.SetKey(starlark.String("x"), starlark.Float(w.X))
+	dict.SetKey(starlark.String("y"), starlark.Float(w.Y))
+	dict.SetKey(starlark.String("width"), starlark.Float(w.Width))
+	dict.SetKey(starlark.String("height"), starlark.Float(w.Height))
+	dict.SetKey(starlark.String("visible"), starlark.Bool(w.Visible))
+	dict.SetKey(starlark.String("fully_shown"), starlark.Bool(w.IsFullyShown()))
+}
@@ -0,0 +1,79 @@
+package script
+
+import (
+	"fmt"
+
+	"gameengine/src/engine/ecs/components"
+	"gameengine/src/engine/render"
+
+	"go.starlark.net/starlark"
+)
+
+// 拡大方法と余白の色を設定
+func (e *ScriptEngine) setScaleMode(thread *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var modeName string
+	var letterbox starlark.Value
+	if err := starlark.UnpackArgs(b.Name(), args, kwargs, "mode", &modeName, "letterbox_color?", &letterbox); err != nil {
+		return nil, err
+	}
+
+	mode, err := render.ParseScaleMode(modeName)
+	if err != nil {
+		return nil, err
+	}
+
+	entities := e.world.FindEntitiesByTag("screen_config")
+	if len(entities) == 0 {
+		return nil, fmt.Errorf("screen config entity not found")
+	}
+	config, ok := entities[0].GetComponent(4).(*components.ScreenConfigComponent)
+	if !ok || config == nil {
+		return nil, fmt.Errorf("screen config component not found")
+	}
+
+	config.ScaleMode = mode
+	if letterbox != nil && letterbox != starlark.None {
+		c, err := parseColor(letterbox)
+		if err != nil {
+			return nil, err
+		}
+		config.LetterboxColor = c
+	}
+	return starlark.None, nil
+}
+
+// 論理解像度（expandではウィンドウに合わせて広がる）
+func (e *ScriptEngine) getScreenSize(thread *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	if err := starlark.UnpackArgs(b.Name(), args, kwargs); err != nil {
+		return nil, err
+	}
+	if e.viewport == nil {
+		return nil, fmt.Errorf("viewport is not available")
+	}
+	w, h := e.viewport.Size()
+	return starlark.Tuple{starlark.MakeInt(w), starlark.MakeInt(h)}, nil
+}
+
+// ウィンドウ上の座標をゲーム内座標に変換
+func (e *ScriptEngine) screenToWorld(thread *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var x, y float64
+	if err := starlark.UnpackArgs(b.Name(), args, kwargs, "x", &x, "y", &y); err != nil {
+		return nil, err
+	}
+	if e.viewport == nil {
+		return starlark.Tuple{starlark.Float(x), starlark.Float(y)}, nil
+	}
+	wx, wy := e.viewport.ScreenToWorld(x, y)
+	return starlark.Tuple{starlark.Float(wx), starlark.Float(wy)}, nil
+}
+
+// ゲーム内座標でのマウス位置
+func (e *ScriptEngine) getMousePosition(thread *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	if err := starlark.UnpackArgs(b.Name(), args, kwargs); err != nil {
+		return nil, err
+	}
+	if e.mouse == nil {
+		return nil, fmt.Errorf("mouse state is not available")
+	}
+	return starlark.Tuple{starlark.MakeInt(e.mouse.X), starlark.MakeInt(e.mouse.Y)}, nil
+}
@@ -0,0 +1,191 @@
+package script
+
+import (
+	"fmt"
+
+	"gameengine/src/engine/ecs/components"
+	"gameengine/src/engine/font"
+	"gameengine/src/engine/textlayout"
+
+	"go.starlark.net/starlark"
+)
+
+// テキストコンポーネントに辞書のプロパティを反映（指定されたキーだけ変更）
+// textだけを指定した場合はキーを外す
+func applyTextProperties(c *components.TextComponent, properties *starlark.Dict) error {
+	textSet, keySet := false, false
+	for _, item := range properties.Items() {
+		key, ok := starlark.AsString(item[0])
+		if !ok {
+			return fmt.Errorf("text property name must be string")
+		}
+		value := item[1]
+
+		var err error
+		switch key {
+		case "text":
+			c.Text, err = toString(key, value)
+			textSet = true
+		case "key":
+			c.TextKey, err = toString(key, value)
+			keySet = true
+		case "args":
+			c.TextArgs, err = toArgs(key, value)
+		case "x":
+			c.X, err = toFloat(key, value)
+		case "y":
+			c.Y, err = toFloat(key, value)
+		case "visible":
+			c.Visible = bool(value.Truth())
+		case "font":
+			c.FontID, err = toString(key, value)
+		case "size":
+			c.Size, err = toFloat(key, value)
+		case "style":
+			var name string
+			if name, err = toString(key, value); err == nil {
+				c.Style, err = parseFontStyle(name)
+			}
+		case "color":
+			c.Color, err = parseColor(value)
+		case "outline_color":
+			c.OutlineColor, err = parseColor(value)
+		case "outline_width":
+			c.OutlineWidth, err = toFloat(key, value)
+		case "shadow_color":
+			c.ShadowColor, err = parseColor(value)
+		case "shadow_offset":
+			c.ShadowOffsetX, c.ShadowOffsetY, err = toPoint(key, value)
+		case "align":
+			var name string
+			if name, err = toString(key, value); err == nil {
+				var ok bool
+				if c.Align, ok = textlayout.ParseAlign(name); !ok {
+					err = fmt.Errorf("invalid align: %s", name)
+				}
+			}
+		case "valign":
+			var name string
+			if name, err = toString(key, value); err == nil {
+				var ok bool
+				if c.VAlign, ok = textlayout.ParseVerticalAlign(name); !ok {
+					err = fmt.Errorf("invalid valign: %s", name)
+				}
+			}
+		case "max_width":
+			c.MaxWidth, err = toFloat(key, value)
+		case "line_spacing":
+			c.LineSpacing, err = toFloat(key, value)
+		case "dynamic":
+			c.Dynamic = bool(value.Truth())
+		case "anchor":
+			var name string
+			if name, err = toString(key, value); err == nil {
+				var ok bool
+				if c.Anchor, ok = textlayout.ParseAnchor(name); !ok {
+					err = fmt.Errorf("invalid anchor: %s", name)
+				}
+			}
+		default:
+			err = fmt.Errorf("unknown text property: %s", key)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	if textSet && !keySet {
+		c.TextKey = ""
+	}
+	return nil
+}
+
+func parseFontStyle(name string) (font.FontStyle, error) {
+	switch name {
+	case "regular", "":
+		return font.StyleRegular, nil
+	case "bold":
+		return font.StyleBold, nil
+	case "italic":
+		return font.StyleItalic, nil
+	default:
+		return font.StyleRegular, fmt.Errorf("invalid font style: %s", name)
+	}
+}
+
+func textComponentToDict(c *components.TextComponent, dict *starlark.Dict) {
+	dict.SetKey(starlark.String("text"), starlark.String(c.Text))
+	dict.SetKey(starlark.String("x"), starlark.Float(c.X))
+	dict.SetKey(starlark.String("y"), starlark.Float(c.Y))
+	dict.SetKey(starlark.String("visible"), starlark.Bool(c.Visible))
+	dict.SetKey(starlark.String("font"), starlark.String(c.FontID))
+	dict.SetKey(starlark.String("size"), starlark.Float(c.Size))
+	dict.SetKey(starlark.String("max_width"), starlark.Float(c.MaxWidth))
+	dict.SetKey(starlark.String("line_spacing"), starlark.Float(c.LineSpacing))
+	dict.SetKey(starlark.String("dynamic"), starlark.Bool(c.Dynamic))
+	dict.SetKey(starlark.String("anchor"), starlark.String(c.Anchor.String()))
+	dict.SetKey(starlark.String("key"), starlark.String(c.TextKey))
+}
+
+// テキストの描画サイズを測る（キーワード引数はtextコンポーネントと同じ、keyを指定すれば文字列は省略できる）
+func (e *ScriptEngine) measureText(thread *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var text string
+	if err := starlark.UnpackPositionalArgs(b.Name(), args, nil, 0, &text); err != nil {
+		return nil, err
+	}
+	if e.textMeasurer == nil {
+		return nil, fmt.Errorf("text system is not available")
+	}
+
+	c := components.NewTextComponent()
+	properties := starlark.NewDict(len(kwargs))
+	for _, kv := range kwargs {
+		properties.SetKey(kv[0], kv[1])
+	}
+	if err := applyTextProperties(c, properties); err != nil {
+		return nil, fmt.Errorf("%s: %v", b.Name(), err)
+	}
+	if len(args) > 0 {
+		c.Text = text
+	}
+	c.Localize(e.localizer)
+
+	width, height, lines := e.textMeasurer(c)
+	dict := starlark.NewDict(3)
+	dict.SetKey(starlark.String("width"), starlark.Float(width))
+	dict.SetKey(starlark.String("height"), starlark.Float(height))
+	dict.SetKey(starlark.String("lines"), starlark.MakeInt(lines))
+	return dict, nil
+}
+
+func toString(key string, v starlark.Value) (string, error) {
+	s, ok := starlark.AsString(v)
+	if !ok {
+		return "", fmt.Errorf("%s must be string, got %s", key, v.Type())
+	}
+	return s, nil
+}
+
+func toFloat(key string, v starlark.Value) (float64, error) {
+	f, ok := starlark.AsFloat(v)
+	if !ok {
+		return 0, fmt.Errorf("%s must be number, got %s", key, v.Type())
+	}
+	return f, nil
+}
+
+// [x, y] の形の値
+func toPoint(key string, v starlark.Value) (float64, float64, error) {
+	seq, ok := v.(starlark.Indexable)
+	if !ok || seq.Len() != 2 {
+		return 0, 0, fmt.Errorf("%s must be [x, y]", key)
+	}
+	x, err := toFloat(key, seq.Index(0))
+	if err != nil {
+		return 0, 0, err
+	}
+	y, err := toFloat(key, seq.Index(1))
+	if err != nil {
+		return 0, 0, err
+	}
+	return x, y, nil
+}
@@ -0,0 +1,239 @@
+package script
+
+import (
+	"fmt"
+
+	"gameengine/src/engine/ecs/components"
+	"gameengine/src/engine/ecs/core"
+	"gameengine/src/engine/tilemap"
+
+	"go.starlark.net/starlark"
+)
+
+// タイルマップの読み込み
+// オブジェクトレイヤーのオブジェクトはプレハブ、またはスポーン地点のエンティティになる
+func (e *ScriptEngine) loadTilemap(thread *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var mapID string
+	var x, y float64
+	spawn := true
+	if err := starlark.UnpackArgs(b.Name(), args, kwargs, "id", &mapID, "x?", &x, "y?", &y, "spawn?", &spawn); err != nil {
+		return nil, err
+	}
+
+	if e.assetManager == nil {
+		return nil, fmt.Errorf("asset manager is not available")
+	}
+	if err := e.assetManager.Load(mapID); err != nil {
+		return nil, err
+	}
+	m, err := e.assetManager.GetTilemap(mapID)
+	if err != nil {
+		return nil, err
+	}
+
+	entity := e.world.CreateEntity()
+	entity.AddTag("tilemap")
+	component := components.NewTilemapComponent(mapID, m)
+	component.X = x
+	component.Y = y
+	entity.AddComponent(component)
+	e.currentTilemap = entity.GetID()
+	e.tilemapLoaded = true
+
+	if spawn {
+		for _, obj := range m.Objects() {
+			if err := e.spawnObject(thread, obj, x, y); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return starlark.MakeInt64(int64(entity.GetID())), nil
+}
+
+// オブジェクトの生成（プレハブが登録されていればそれを呼ぶ）
+func (e *ScriptEngine) spawnObject(thread *starlark.Thread, obj *tilemap.Object, offsetX, offsetY float64) error {
+	x := obj.X + offsetX
+	y := obj.Y + offsetY
+
+	if prefab, exists := e.prefabs[obj.Type]; exists {
+		_, err := starlark.Call(thread, prefab, starlark.Tuple{
+			starlark.Float(x), starlark.Float(y), objectToDict(obj),
+		}, nil)
+		return err
+	}
+
+	entity := e.world.CreateEntity()
+	entity.AddTag("spawn")
+	if obj.Type != "" {
+		entity.AddTag(obj.Type)
+	}
+	transform := components.NewTransformComponent()
+	transform.X = x
+	transform.Y = y
+	entity.AddComponent(transform)
+
+	states := map[string]interface{}{"name": obj.Name}
+	for k, v := range obj.Properties {
+		if i, ok := v.(int); ok {
+			v = int64(i) // get_stateで扱える型に揃える
+		}
+		states[k] = v
+	}
+	e.stateManager.SetStates(entity.GetID(), states)
+	return nil
+}
+
+// プレハブの登録
+// callback(x, y, object) の形で呼ばれる
+func (e *ScriptEngine) registerPrefab(thread *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var objectType string
+	var callback starlark.Callable
+	if err := starlark.UnpackPositionalArgs(b.Name(), args, kwargs, 2, &objectType, &callback); err != nil {
+		return nil, err
+	}
+	e.prefabs[objectType] = callback
+	return starlark.None, nil
+}
+
+// 対象のタイルマップ（省略時は最後に読み込んだもの）
+func (e *ScriptEngine) tilemapComponent(mapEntity starlark.Value) (*components.TilemapComponent, error) {
+	id := e.currentTilemap
+	if mapEntity != nil && mapEntity != starlark.None {
+		v, err := starlark.AsInt32(mapEntity)
+		if err != nil {
+			return nil, fmt.Errorf("map must be entity id: %v", err)
+		}
+		id = core.EntityID(v)
+	} else if !e.tilemapLoaded {
+		return nil, fmt.Errorf("tilemap not loaded")
+	}
+
+	entity := e.world.GetEntity(id)
+	if entity == nil {
+		return nil, fmt.Errorf("tilemap not loaded")
+	}
+	component, ok := entity.GetComponent(7).(*components.TilemapComponent)
+	if !ok || component == nil {
+		return nil, fmt.Errorf("entity %d has no tilemap component", id)
+	}
+	return component, nil
+}
+
+// ワールド座標のタイルを取得
+// layer省略時は一番上の空でないタイル
+func (e *ScriptEngine) getTile(thread *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var x, y float64
+	var layerName string
+	var mapEntity starlark.Value
+	if err := starlark.UnpackArgs(b.Name(), args, kwargs, "x", &x, "y", &y, "layer?", &layerName, "map?", &mapEntity); err != nil {
+		return nil, err
+	}
+
+	tm, err := e.tilemapComponent(mapEntity)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, ty := tm.WorldToTile(x, y)
+	if !tm.Map.InBounds(tx, ty) {
+		return starlark.None, nil
+	}
+
+	for i := len(tm.Map.Layers) - 1; i >= 0; i-- {
+		layer := tm.Map.Layers[i]
+		if layer.Type != tilemap.TileLayer || (layerName != "" && layer.Name != layerName) {
+			continue
+		}
+		gid := layer.TileAt(tx, ty)
+		if gid == 0 {
+			continue
+		}
+
+		dict := starlark.NewDict(6)
+		dict.SetKey(starlark.String("id"), starlark.MakeUint(uint(gid)))
+		dict.SetKey(starlark.String("layer"), starlark.String(layer.Name))
+		dict.SetKey(starlark.String("tile_x"), starlark.MakeInt(tx))
+		dict.SetKey(starlark.String("tile_y"), starlark.MakeInt(ty))
+		dict.SetKey(starlark.String("solid"), starlark.Bool(tm.Map.IsSolid(tx, ty)))
+		dict.SetKey(starlark.String("properties"), propertiesToDict(tm.Map.TileProperties(gid)))
+		return dict, nil
+	}
+
+	return starlark.None, nil
+}
+
+// ワールド座標が通行不可か
+func (e *ScriptEngine) isSolid(thread *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var x, y float64
+	var mapEntity starlark.Value
+	if err := starlark.UnpackArgs(b.Name(), args, kwargs, "x", &x, "y", &y, "map?", &mapEntity); err != nil {
+		return nil, err
+	}
+
+	tm, err := e.tilemapComponent(mapEntity)
+	if err != nil {
+		return nil, err
+	}
+	tx, ty := tm.WorldToTile(x, y)
+	return starlark.Bool(tm.Map.IsSolid(tx, ty)), nil
+}
+
+// オブジェクトレイヤーのスポーン地点一覧
+func (e *ScriptEngine) getSpawnPoints(thread *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var objectType string
+	var mapEntity starlark.Value
+	if err := starlark.UnpackArgs(b.Name(), args, kwargs, "type?", &objectType, "map?", &mapEntity); err != nil {
+		return nil, err
+	}
+
+	tm, err := e.tilemapComponent(mapEntity)
+	if err != nil {
+		return nil, err
+	}
+
+	var result []starlark.Value
+	for _, obj := range tm.Map.Objects() {
+		if objectType != "" && obj.Type != objectType {
+			continue
+		}
+		dict := objectToDict(obj)
+		dict.SetKey(starlark.String("x"), starlark.Float(obj.X+tm.X))
+		dict.SetKey(starlark.String("y"), starlark.Float(obj.Y+tm.Y))
+		result = append(result, dict)
+	}
+	return starlark.NewList(result), nil
+}
+
+func objectToDict(obj *tilemap.Object) *starlark.Dict {
+	dict := starlark.NewDict(7)
+	dict.SetKey(starlark.String("name"), starlark.String(obj.Name))
+	dict.SetKey(starlark.String("type"), starlark.String(obj.Type))
+	dict.SetKey(starlark.String("x"), starlark.Float(obj.X))
+	dict.SetKey(starlark.String("y"), starlark.Float(obj.Y))
+	dict.SetKey(starlark.String("width"), starlark.Float(obj.Width))
+	dict.SetKey(starlark.String("height"), starlark.Float(obj.Height))
+	dict.SetKey(starlark.String("properties"), propertiesToDict(obj.Properties))
+	return dict
+}
+
+func propertiesToDict(props tilemap.Properties) *starlark.Dict {
+	dict := starlark.NewDict(len(props))
+	for k, v := range props {
+		var value starlark.Value
+		switch v := v.(type) {
+		case bool:
+			value = starlark.Bool(v)
+		case int:
+			value = starlark.MakeInt(v)
+		case float64:
+			value = starlark.Float(v)
+		case string:
+			value = starlark.String(v)
+		default:
+			continue
+		}
+		dict.SetKey(starlark.String(k), value)
+	}
+	return dict
+}
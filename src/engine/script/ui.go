@@ -0,0 +1,1436 @@
+package script
+
+import (
+	"fmt"
+	"image/color"
+	"sort"
+
+	"gameengine/src/engine/ecs/core"
+	enginefont "gameengine/src/engine/font"
+	"gameengine/src/engine/i18n"
+	"gameengine/src/engine/textlayout"
+	"gameengine/src/engine/ui"
+
+	"go.starlark.net/starlark"
+	"golang.org/x/image/font"
+)
+
+// スクリプトから作ったUIコンポーネント
+type uiNode struct {
+	id        string
+	kind      string
+	component ui.Component
+	parent    *uiNode // コンテナの中にあればそのノード
+	topLevel  bool    // UIManagerに直接登録されている
+	params    ui.LayoutParams
+	children  []*uiNode
+
+	// フォント（まとめて反映する）
+	fontID   string
+	fontSize float64
+	style    enginefont.FontStyle
+
+	// 値の表示（GameStateの変数、またはエンティティの状態）
+	bind, bindMax string
+	entity        int
+	format        string
+
+	handlers  map[string][]starlark.Callable
+	listening map[string]bool
+}
+
+// スクリプトに返すUIノード（.idでIDを参照できる）
+type uiValue struct {
+	node *uiNode
+}
+
+func (v *uiValue) String() string        { return fmt.Sprintf("<ui %s %q>", v.node.kind, v.node.id) }
+func (v *uiValue) Type() string          { return "ui" }
+func (v *uiValue) Freeze()               {}
+func (v *uiValue) Truth() starlark.Bool  { return starlark.True }
+func (v *uiValue) Hash() (uint32, error) { return starlark.String(v.node.id).Hash() }
+func (v *uiValue) AttrNames() []string   { return []string{"id", "type"} }
+func (v *uiValue) Attr(name string) (starlark.Value, error) {
+	switch name {
+	case "id":
+		return starlark.String(v.node.id), nil
+	case "type":
+		return starlark.String(v.node.kind), nil
+	}
+	return nil, nil
+}
+
+// 埋め込まれたBaseComponent
+func baseOf(c ui.Component) *ui.BaseComponent {
+	switch c := c.(type) {
+	case *ui.Container:
+		return &c.BaseComponent
+	case *ui.Label:
+		return &c.BaseComponent
+	case *ui.Button:
+		return &c.BaseComponent
+	case *ui.Gauge:
+		return &c.BaseComponent
+	case *ui.MenuWindow:
+		return &c.BaseComponent
+	case *ui.ListView:
+		return &c.BaseComponent
+	case *ui.TextInput:
+		return &c.BaseComponent
+	case *ui.Slider:
+		return &c.BaseComponent
+	case *ui.Toggle:
+		return &c.BaseComponent
+	case *ui.TabContainer:
+		return &c.BaseComponent
+	}
+	return nil
+}
+
+func (e *ScriptEngine) uiFace(node *uiNode) font.Face {
+	if e.faces == nil {
+		return nil
+	}
+	return e.faces(node.fontID, node.fontSize, node.style)
+}
+
+// ui_window、ui_labelなどの組み立て関数
+// キーワード引数はui_setと同じプロパティ。childrenで子を並べる
+func (e *ScriptEngine) uiBuilder(kind string) func(*starlark.Thread, *starlark.Builtin, starlark.Tuple, []starlark.Tuple) (starlark.Value, error) {
+	return func(thread *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+		if e.uiManager == nil {
+			return nil, fmt.Errorf("%s: ui is not available", b.Name())
+		}
+
+		// 文字列を持つものは最初の引数で文字列を指定できる
+		var text starlark.Value
+		if kind == "label" || kind == "button" || kind == "toggle" {
+			if err := starlark.UnpackPositionalArgs(b.Name(), args, nil, 0, &text); err != nil {
+				return nil, err
+			}
+		} else if len(args) > 0 {
+			return nil, fmt.Errorf("%s: unexpected positional arguments", b.Name())
+		}
+
+		id := ""
+		var children *starlark.List
+		properties := make([]starlark.Tuple, 0, len(kwargs)+1)
+		if text != nil {
+			properties = append(properties, starlark.Tuple{starlark.String("text"), text})
+		}
+		for _, kv := range kwargs {
+			key, _ := starlark.AsString(kv[0])
+			switch key {
+			case "id":
+				var err error
+				if id, err = toString(key, kv[1]); err != nil {
+					return nil, fmt.Errorf("%s: %v", b.Name(), err)
+				}
+			case "children":
+				list, ok := kv[1].(*starlark.List)
+				if !ok {
+					return nil, fmt.Errorf("%s: children must be list, got %s", b.Name(), kv[1].Type())
+				}
+				children = list
+			default:
+				properties = append(properties, kv)
+			}
+		}
+
+		if id == "" {
+			// 自動のIDは使われていないものを探す（同じ名前のUIを置き換えないように）
+			for id == "" || e.uiIDTaken(id) {
+				e.nextUIID++
+				id = fmt.Sprintf("ui_%d", e.nextUIID)
+			}
+		} else if e.uiIDTaken(id) {
+			return nil, fmt.Errorf("%s: ui id already exists: %s", b.Name(), id)
+		}
+		node := e.newUINode(id, kind)
+
+		if children != nil {
+			for i := 0; i < children.Len(); i++ {
+				child, ok := children.Index(i).(*uiValue)
+				if !ok {
+					return nil, fmt.Errorf("%s: children[%d] must be ui, got %s", b.Name(), i, children.Index(i).Type())
+				}
+				if err := e.attachUI(node, child.node, "", ""); err != nil {
+					return nil, fmt.Errorf("%s: %v", b.Name(), err)
+				}
+			}
+		}
+		if err := e.applyUIProperties(node, properties); err != nil {
+			return nil, fmt.Errorf("%s: %v", b.Name(), err)
+		}
+
+		e.uiNodes[id] = node
+		if kind == "window" {
+			e.showUI(node)
+		}
+		return &uiValue{node}, nil
+	}
+}
+
+// スクリプトのUIか、UIManagerに登録されたコンポーネントが使っているID
+func (e *ScriptEngine) uiIDTaken(id string) bool {
+	if _, exists := e.uiNodes[id]; exists {
+		return true
+	}
+	return e.uiManager != nil && e.uiManager.GetComponent(id) != nil
+}
+
+func (e *ScriptEngine) newUINode(id, kind string) *uiNode {
+	node := &uiNode{
+		id:        id,
+		kind:      kind,
+		fontSize:  16,
+		format:    "{value}",
+		handlers:  make(map[string][]starlark.Callable),
+		listening: make(map[string]bool),
+	}
+	switch kind {
+	case "window":
+		c := ui.NewVStack(4)
+		c.BackgroundColor = color.RGBA{0, 0, 0, 200}
+		c.Padding = ui.UniformInsets(10)
+		c.AutoSize = true
+		c.SkinName = "window"
+		c.ApplyTheme(ui.DefaultThemes.Current())
+		node.component = c
+	case "panel":
+		c := ui.NewVStack(4)
+		c.AutoSize = true
+		node.component = c
+	case "label":
+		node.component = ui.NewLabel(e.uiFace(node), "")
+	case "button":
+		button := ui.NewButton(e.uiFace(node), "", nil)
+		button.OnClick = func() { e.fireUI(node, "click", starlark.String(node.id)) }
+		node.component = button
+	case "gauge":
+		node.component = ui.NewGauge(0, 100)
+	case "menu":
+		menu := ui.NewMenuWindow(e.uiFace(node))
+		menu.Input = e.inputManager
+		menu.OnChange = func(index int) { e.fireUI(node, "change", starlark.MakeInt(index)) }
+		node.component = menu
+	case "list":
+		list := ui.NewListView(e.uiFace(node))
+		list.Input = e.inputManager
+		list.OnChange = func(index int) { e.fireUI(node, "change", starlark.MakeInt(index)) }
+		list.OnSelect = func(index int) {
+			e.fireUI(node, "select", starlark.MakeInt(index), starlark.String(list.Items[index].Text))
+		}
+		node.component = list
+	case "text_input":
+		input := ui.NewTextInput(e.uiFace(node), 200)
+		input.OnChange = func(text string) { e.fireUI(node, "change", starlark.String(text)) }
+		input.OnSubmit = func(text string) { e.fireUI(node, "submit", starlark.String(text)) }
+		node.component = input
+	case "slider":
+		slider := ui.NewSlider(0, 100, 0)
+		slider.OnChange = func(value float64) { e.fireUI(node, "change", starlark.Float(value)) }
+		node.component = slider
+	case "toggle":
+		toggle := ui.NewToggle(e.uiFace(node), "", false)
+		toggle.OnChange = func(checked bool) { e.fireUI(node, "change", starlark.Bool(checked)) }
+		node.component = toggle
+	case "tabs":
+		tabs := ui.NewTabContainer(e.uiFace(node))
+		tabs.OnChange = func(index int) { e.fireUI(node, "change", starlark.MakeInt(index)) }
+		node.component = tabs
+	}
+	return node
+}
+
+// 子をコンテナに入れる（別の場所にあれば移す）
+// タブに入れる場合は見出しをtitle、またはkeyで訳した文字列にする（どちらもなければID）
+func (e *ScriptEngine) attachUI(parent, child *uiNode, title, key string) error {
+	switch parent.component.(type) {
+	case *ui.Container, *ui.TabContainer:
+	default:
+		return fmt.Errorf("%s cannot have children", parent.kind)
+	}
+	for p := parent; p != nil; p = p.parent {
+		if p == child {
+			return fmt.Errorf("cannot add %s to itself", child.id)
+		}
+	}
+	e.detachUI(child)
+	child.parent = parent
+	parent.children = append(parent.children, child)
+	switch c := parent.component.(type) {
+	case *ui.Container:
+		c.AddChild(child.component, child.params)
+	case *ui.TabContainer:
+		tab := c.AddTab(title, child.component)
+		if key != "" {
+			tab.Key = key
+			tab.Title = e.localizer.Tr(key)
+		} else if title == "" {
+			tab.Title = child.id
+		}
+	}
+	return nil
+}
+
+// 親のコンテナや画面から外す
+func (e *ScriptEngine) detachUI(node *uiNode) {
+	if node.topLevel {
+		e.uiManager.RemoveComponent(node.id)
+		node.topLevel = false
+	}
+	if parent := node.parent; parent != nil {
+		switch c := parent.component.(type) {
+		case *ui.Container:
+			c.RemoveChild(node.component)
+		case *ui.TabContainer:
+			c.RemoveTab(node.component)
+		}
+		for i, child := range parent.children {
+			if child == node {
+				parent.children = append(parent.children[:i], parent.children[i+1:]...)
+				break
+			}
+		}
+		node.parent = nil
+	}
+}
+
+// 画面に直接置く（コンテナの中にあればそのまま表示し、タブの中身ならそのタブを選ぶ）
+func (e *ScriptEngine) showUI(node *uiNode) {
+	if node.parent != nil {
+		if tabs, ok := node.parent.component.(*ui.TabContainer); ok {
+			for i, tab := range tabs.Tabs {
+				if tab.Content == node.component {
+					tabs.SetSelectedIndex(i)
+				}
+			}
+			return
+		}
+	}
+	baseOf(node.component).Visible = true
+	if node.parent != nil || node.topLevel {
+		return
+	}
+	e.uiManager.AddComponent(node.id, node.component)
+	node.topLevel = true
+	if hasScreenLayout(node.params) {
+		e.uiManager.SetLayoutParams(node.id, node.params)
+	}
+}
+
+// 画面を基準に置くか（指定がなければx、yの位置のまま）
+func hasScreenLayout(params ui.LayoutParams) bool {
+	return params.Anchor.Enabled || params.HAlign == ui.AlignStretch || params.VAlign == ui.AlignStretch
+}
+
+// 登録されたハンドラーを呼ぶ
+func (e *ScriptEngine) fireUI(node *uiNode, event string, args ...starlark.Value) {
+	for _, fn := range node.handlers[event] {
+		if _, err := starlark.Call(e.thread, fn, starlark.Tuple(args), nil); err != nil {
+			fmt.Printf("error in ui %s handler (%s): %v\n", event, node.id, err)
+		}
+	}
+}
+
+// UIイベントの名前とイベントの種類
+var uiEventTypes = map[string]ui.EventType{
+	"click": ui.EventClick,
+	"focus": ui.EventFocus,
+	"blur":  ui.EventBlur,
+	"enter": ui.EventPointerEnter,
+	"leave": ui.EventPointerLeave,
+}
+
+// 種類ごとのコンポーネント独自のイベント
+var uiComponentEvents = map[string][]string{
+	"menu":       {"select", "change", "cancel"},
+	"list":       {"select", "change", "cancel"},
+	"text_input": {"change", "submit", "cancel"},
+	"slider":     {"change"},
+	"toggle":     {"change"},
+	"tabs":       {"change"},
+}
+
+func hasUIEvent(kind, event string) bool {
+	for _, name := range uiComponentEvents[kind] {
+		if name == event {
+			return true
+		}
+	}
+	return false
+}
+
+// ハンドラーを登録する
+func (e *ScriptEngine) subscribeUI(node *uiNode, event string, v starlark.Value) error {
+	fn, ok := v.(starlark.Callable)
+	if !ok {
+		return fmt.Errorf("on_%s must be callable, got %s", event, v.Type())
+	}
+	switch event {
+	case "select", "change", "submit", "cancel":
+		if !hasUIEvent(node.kind, event) {
+			return fmt.Errorf("%s does not have %s event", node.kind, event)
+		}
+		// キャンセルはハンドラーがある時だけ受け付ける
+		cancel := func() { e.fireUI(node, "cancel") }
+		if event == "cancel" {
+			switch c := node.component.(type) {
+			case *ui.MenuWindow:
+				c.OnCancel = cancel
+			case *ui.ListView:
+				c.OnCancel = cancel
+			case *ui.TextInput:
+				c.OnCancel = cancel
+			}
+		}
+	case "click":
+		if node.kind == "button" {
+			break // ボタンはキー入力でも押せるようにOnClickから呼ぶ
+		}
+		fallthrough
+	default:
+		eventType, ok := uiEventTypes[event]
+		if !ok {
+			return fmt.Errorf("unknown ui event: %s", event)
+		}
+		if !node.listening[event] {
+			node.listening[event] = true
+			baseOf(node.component).On(eventType, func(*ui.Event) {
+				e.fireUI(node, event, starlark.String(node.id))
+			})
+		}
+	}
+	node.handlers[event] = append(node.handlers[event], fn)
+	return nil
+}
+
+// プロパティを反映する（指定されたものだけ変更）
+func (e *ScriptEngine) applyUIProperties(node *uiNode, properties []starlark.Tuple) error {
+	// skin、layoutを先に反映してから色や間隔などを設定する
+	first := func(kv starlark.Tuple) int {
+		switch key, _ := starlark.AsString(kv[0]); key {
+		case "skin":
+			return 0
+		case "layout":
+			return 1
+		}
+		return 2
+	}
+	sort.SliceStable(properties, func(i, j int) bool {
+		return first(properties[i]) < first(properties[j])
+	})
+
+	base := baseOf(node.component)
+	bounds := node.component.GetBounds()
+	layoutChanged, fontChanged, bindChanged := false, false, false
+	for _, kv := range properties {
+		key, ok := starlark.AsString(kv[0])
+		if !ok {
+			return fmt.Errorf("ui property name must be string")
+		}
+		value := kv[1]
+
+		var err error
+		switch key {
+		case "x":
+			var x float64
+			if x, err = toFloat(key, value); err == nil {
+				node.component.SetPosition(x, base.Y)
+			}
+		case "y":
+			var y float64
+			if y, err = toFloat(key, value); err == nil {
+				node.component.SetPosition(base.X, y)
+			}
+		case "width", "height":
+			var size float64
+			if size, err = toFloat(key, value); err == nil {
+				e.setUISize(node, key, size)
+			}
+		case "visible":
+			node.component.SetVisible(bool(value.Truth()))
+		case "z_index":
+			base.ZIndex, err = starlark.AsInt32(value)
+		case "modal":
+			base.Modal = bool(value.Truth())
+		case "focusable":
+			base.Focusable = bool(value.Truth())
+		case "tab_order":
+			base.TabOrder, err = starlark.AsInt32(value)
+		case "tooltip":
+			base.Tooltip, err = toString(key, value)
+
+		// 親の中での置き方
+		case "margin":
+			node.params.Margin, err = toInsets(key, value)
+			layoutChanged = true
+		case "grow":
+			node.params.Grow, err = toFloat(key, value)
+			layoutChanged = true
+		case "halign":
+			node.params.HAlign, err = toAlignment(key, value)
+			layoutChanged = true
+		case "valign":
+			node.params.VAlign, err = toAlignment(key, value)
+			layoutChanged = true
+		case "anchor":
+			var name string
+			if name, err = toString(key, value); err == nil {
+				var ok bool
+				if node.params.Anchor, ok = textlayout.ParseAnchor(name); !ok {
+					err = fmt.Errorf("invalid anchor: %s", name)
+				}
+			}
+			layoutChanged = true
+		case "offset":
+			node.params.OffsetX, node.params.OffsetY, err = toPoint(key, value)
+			layoutChanged = true
+
+		case "font":
+			node.fontID, err = toString(key, value)
+			fontChanged = true
+		case "size":
+			node.fontSize, err = toFloat(key, value)
+			fontChanged = true
+		case "style":
+			var name string
+			if name, err = toString(key, value); err == nil {
+				node.style, err = parseFontStyle(name)
+			}
+			fontChanged = true
+
+		case "bind":
+			node.bind, err = toString(key, value)
+			bindChanged = true
+		case "bind_max":
+			node.bindMax, err = toString(key, value)
+			bindChanged = true
+		case "entity":
+			node.entity, err = starlark.AsInt32(value)
+			bindChanged = true
+		case "format":
+			node.format, err = toString(key, value)
+			bindChanged = true
+
+		case "skin":
+			var name string
+			if name, err = toString(key, value); err == nil {
+				err = setUISkin(node, name)
+			}
+
+		case "on_click", "on_select", "on_cancel", "on_change", "on_submit", "on_focus", "on_blur", "on_enter", "on_leave":
+			err = e.subscribeUI(node, key[len("on_"):], value)
+
+		default:
+			err = e.applyUIComponentProperty(node, key, value)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	if fontChanged {
+		e.applyUIFont(node)
+	}
+	if bindChanged {
+		e.applyUIBinding(node)
+	}
+	if node.component.GetBounds().Size() != bounds.Size() {
+		e.resizeUI(node)
+		layoutChanged = true
+	}
+	if layoutChanged {
+		switch {
+		case node.parent != nil:
+			if container, ok := node.parent.component.(*ui.Container); ok {
+				container.SetParams(node.component, node.params)
+			}
+		case node.topLevel && hasScreenLayout(node.params):
+			e.uiManager.SetLayoutParams(node.id, node.params)
+		}
+	}
+	if container, ok := node.component.(*ui.Container); ok {
+		container.Relayout()
+	}
+	return nil
+}
+
+// テーマのスキンを変える（ウィンドウは"window"の代わりにこのスキンを使う）
+func setUISkin(node *uiNode, name string) error {
+	switch c := node.component.(type) {
+	case *ui.Container:
+		c.SkinName = name
+	case *ui.MenuWindow:
+		c.SkinName = name
+	case *ui.Button:
+		c.SkinName = name
+	case *ui.ListView:
+		c.SkinName = name
+	case *ui.TextInput:
+		c.SkinName = name
+	case *ui.Slider:
+		c.SkinName = name
+	case *ui.Toggle:
+		c.SkinName = name
+	case *ui.TabContainer:
+		c.SkinName = name
+	default:
+		return fmt.Errorf("%s does not have skin", node.kind)
+	}
+	node.component.(ui.Themeable).ApplyTheme(ui.DefaultThemes.Current())
+	return nil
+}
+
+// 大きさを指定したら内容に合わせるのをやめる
+func (e *ScriptEngine) setUISize(node *uiNode, key string, size float64) {
+	bounds := node.component.GetBounds()
+	width, height := float64(bounds.Dx()), float64(bounds.Dy())
+	if key == "width" {
+		width = size
+	} else {
+		height = size
+	}
+	switch c := node.component.(type) {
+	case *ui.Container:
+		c.AutoSize = false
+	case *ui.Label:
+		c.AutoSize = false
+	}
+	node.component.SetSize(width, height)
+}
+
+// 親のコンテナに大きさが変わったことを伝える（タブの中身は見出しの下に広げるので伝えない）
+func (e *ScriptEngine) resizeUI(node *uiNode) {
+	if node.parent == nil {
+		return
+	}
+	container, ok := node.parent.component.(*ui.Container)
+	if !ok {
+		return
+	}
+	for _, child := range container.Children() {
+		if child.Component == node.component {
+			child.ResetSize()
+		}
+	}
+}
+
+// コンポーネントの種類ごとのプロパティ
+func (e *ScriptEngine) applyUIComponentProperty(node *uiNode, key string, value starlark.Value) error {
+	var err error
+	switch c := node.component.(type) {
+	case *ui.Container:
+		switch key {
+		case "layout":
+			var name string
+			if name, err = toString(key, value); err == nil {
+				c.Layout, err = newUILayout(name)
+			}
+		case "spacing":
+			var spacing float64
+			if spacing, err = toFloat(key, value); err == nil {
+				switch l := c.Layout.(type) {
+				case *ui.FlexLayout:
+					l.Spacing = spacing
+				case *ui.GridLayout:
+					l.SpacingX, l.SpacingY = spacing, spacing
+				}
+			}
+		case "columns":
+			var columns int
+			if columns, err = starlark.AsInt32(value); err == nil {
+				if l, ok := c.Layout.(*ui.GridLayout); ok {
+					l.Columns = columns
+				} else {
+					err = fmt.Errorf("columns requires grid layout")
+				}
+			}
+		case "justify":
+			var name string
+			if name, err = toString(key, value); err == nil {
+				if l, ok := c.Layout.(*ui.FlexLayout); ok {
+					l.Justify, err = parseJustify(name)
+				} else {
+					err = fmt.Errorf("justify requires vertical or horizontal layout")
+				}
+			}
+		case "align_items":
+			if l, ok := c.Layout.(*ui.FlexLayout); ok {
+				l.AlignItems, err = toAlignment(key, value)
+			} else {
+				err = fmt.Errorf("align_items requires vertical or horizontal layout")
+			}
+		case "padding":
+			c.Padding, err = toInsets(key, value)
+		case "background_color":
+			c.BackgroundColor, err = toOptionalColor(value)
+		default:
+			err = fmt.Errorf("unknown %s property: %s", node.kind, key)
+		}
+
+	case *ui.Label:
+		switch key {
+		case "text":
+			var text string
+			if text, err = toString(key, value); err == nil {
+				c.Key = ""
+				c.SetText(text)
+			}
+		case "key":
+			if c.Key, err = toString(key, value); err == nil {
+				c.Localize(e.localizer)
+			}
+		case "args":
+			if c.Args, err = toArgs(key, value); err == nil {
+				c.Localize(e.localizer)
+			}
+		case "color":
+			c.Color, err = parseColor(value)
+		case "align":
+			var name string
+			if name, err = toString(key, value); err == nil {
+				var ok bool
+				if c.Align, ok = textlayout.ParseAlign(name); !ok {
+					err = fmt.Errorf("invalid align: %s", name)
+				}
+			}
+		default:
+			err = fmt.Errorf("unknown %s property: %s", node.kind, key)
+		}
+
+	case *ui.Button:
+		switch key {
+		case "text":
+			var text string
+			if text, err = toString(key, value); err == nil {
+				c.Key = ""
+				c.SetText(text)
+				c.Fit()
+			}
+		case "key":
+			if c.Key, err = toString(key, value); err == nil {
+				c.Localize(e.localizer)
+				c.Fit()
+			}
+		case "args":
+			if c.Args, err = toArgs(key, value); err == nil {
+				c.Localize(e.localizer)
+				c.Fit()
+			}
+		case "enabled":
+			c.Enabled = bool(value.Truth())
+		case "color", "text_color":
+			c.TextColor, err = parseColor(value)
+		case "background_color":
+			c.BackgroundColor, err = parseColor(value)
+		default:
+			err = fmt.Errorf("unknown %s property: %s", node.kind, key)
+		}
+
+	case *ui.Gauge:
+		switch key {
+		case "value":
+			c.Value, err = toFloat(key, value)
+		case "max":
+			c.Max, err = toFloat(key, value)
+		case "color":
+			c.Color, err = parseColor(value)
+		case "background_color":
+			c.BackgroundColor, err = parseColor(value)
+		case "border_color":
+			c.BorderColor, err = toOptionalColor(value)
+		case "low_color":
+			c.LowColor, err = toOptionalColor(value)
+		case "low_threshold":
+			c.LowThreshold, err = toFloat(key, value)
+		case "animate":
+			c.Animated = bool(value.Truth())
+		case "speed":
+			c.Speed, err = toFloat(key, value)
+		case "drain_color":
+			c.DrainColor, err = toOptionalColor(value)
+		case "drain_delay":
+			c.DrainDelay, err = starlark.AsInt32(value)
+		default:
+			err = fmt.Errorf("unknown %s property: %s", node.kind, key)
+		}
+
+	case *ui.MenuWindow:
+		switch key {
+		case "items":
+			err = e.setMenuItems(node, c, value)
+		case "selected":
+			var index int
+			if index, err = starlark.AsInt32(value); err == nil {
+				c.SetSelectedIndex(index)
+			}
+		case "visible_rows":
+			if c.VisibleRows, err = starlark.AsInt32(value); err == nil {
+				c.FitToItems()
+			}
+		case "line_height":
+			var height float64
+			if height, err = toFloat(key, value); err == nil {
+				c.SetLineHeight(height)
+			}
+		case "padding":
+			var padding float64
+			if padding, err = toFloat(key, value); err == nil {
+				c.SetPadding(padding)
+			}
+		case "color", "text_color":
+			var col color.Color
+			if col, err = parseColor(value); err == nil {
+				c.SetTextColor(col)
+			}
+		case "selected_color":
+			var col color.Color
+			if col, err = parseColor(value); err == nil {
+				c.SetSelectedColor(col)
+			}
+		case "background_color":
+			var col color.Color
+			if col, err = parseColor(value); err == nil {
+				c.SetBackgroundColor(col)
+			}
+		default:
+			err = fmt.Errorf("unknown %s property: %s", node.kind, key)
+		}
+
+	case *ui.ListView:
+		switch key {
+		case "items":
+			err = e.setListItems(c, value)
+		case "selected":
+			var index int
+			if index, err = starlark.AsInt32(value); err == nil {
+				c.SetSelectedIndex(index)
+			}
+		case "row_height":
+			if c.RowHeight, err = toFloat(key, value); err == nil {
+				c.ScrollTo(c.SelectedIndex)
+			}
+		case "padding":
+			c.Padding, err = toFloat(key, value)
+		case "color", "text_color":
+			c.TextColor, err = parseColor(value)
+		case "selected_color":
+			c.SelectedColor, err = parseColor(value)
+		case "highlight_color":
+			c.HighlightColor, err = toOptionalColor(value)
+		case "background_color":
+			c.BackgroundColor, err = parseColor(value)
+		default:
+			err = fmt.Errorf("unknown %s property: %s", node.kind, key)
+		}
+
+	case *ui.TextInput:
+		switch key {
+		case "text":
+			var text string
+			if text, err = toString(key, value); err == nil {
+				c.SetText(text)
+			}
+		case "placeholder":
+			c.Placeholder, err = toString(key, value)
+		case "max_length":
+			c.MaxLength, err = starlark.AsInt32(value)
+		case "filter":
+			var name string
+			if name, err = toString(key, value); err == nil {
+				c.Filter, err = parseTextFilter(name)
+			}
+		case "padding":
+			var padding float64
+			if padding, err = toFloat(key, value); err == nil {
+				c.SetPadding(padding)
+			}
+		case "color", "text_color":
+			var col color.Color
+			if col, err = parseColor(value); err == nil {
+				c.SetTextColor(col)
+			}
+		case "background_color":
+			var col color.Color
+			if col, err = toOptionalColor(value); err == nil {
+				c.SetBackgroundColor(col)
+			}
+		default:
+			err = fmt.Errorf("unknown %s property: %s", node.kind, key)
+		}
+
+	case *ui.Slider:
+		switch key {
+		case "min":
+			c.Min, err = toFloat(key, value)
+		case "max":
+			c.Max, err = toFloat(key, value)
+		case "step":
+			c.Step, err = toFloat(key, value)
+		case "value":
+			var v float64
+			if v, err = toFloat(key, value); err == nil {
+				c.SetValue(v)
+			}
+		case "color", "fill_color":
+			c.FillColor, err = parseColor(value)
+		case "track_color":
+			c.TrackColor, err = parseColor(value)
+		case "knob_color":
+			c.KnobColor, err = parseColor(value)
+		default:
+			err = fmt.Errorf("unknown %s property: %s", node.kind, key)
+		}
+
+	case *ui.Toggle:
+		switch key {
+		case "text":
+			var text string
+			if text, err = toString(key, value); err == nil {
+				c.Key = ""
+				c.SetText(text)
+			}
+		case "key":
+			if c.Key, err = toString(key, value); err == nil {
+				c.Localize(e.localizer)
+			}
+		case "args":
+			if c.Args, err = toArgs(key, value); err == nil {
+				c.Localize(e.localizer)
+			}
+		case "checked":
+			c.SetChecked(bool(value.Truth()))
+		case "color", "text_color":
+			c.TextColor, err = parseColor(value)
+		case "check_color":
+			c.CheckColor, err = parseColor(value)
+		default:
+			err = fmt.Errorf("unknown %s property: %s", node.kind, key)
+		}
+
+	case *ui.TabContainer:
+		switch key {
+		case "tabs":
+			err = e.setUITabs(node, value)
+		case "selected":
+			var index int
+			if index, err = starlark.AsInt32(value); err == nil {
+				c.SetSelectedIndex(index)
+			}
+		case "padding":
+			if c.Padding, err = toInsets(key, value); err == nil {
+				c.Relayout()
+			}
+		case "color", "text_color":
+			c.TextColor, err = parseColor(value)
+		case "selected_color":
+			c.SelectedTextColor, err = parseColor(value)
+		case "background_color":
+			c.BackgroundColor, err = toOptionalColor(value)
+		default:
+			err = fmt.Errorf("unknown %s property: %s", node.kind, key)
+		}
+
+	default:
+		err = fmt.Errorf("unknown %s property: %s", node.kind, key)
+	}
+	return err
+}
+
+// ["はい", {"text": "いいえ", "enabled": False}, {"key": "menu.exit"}]
+func (e *ScriptEngine) setMenuItems(node *uiNode, menu *ui.MenuWindow, v starlark.Value) error {
+	choices, err := toChoices(v)
+	if err != nil {
+		return err
+	}
+	menu.ClearItems()
+	for i, choice := range choices {
+		item := &ui.MenuItem{Text: choice.Text, Key: choice.Key, Enabled: choice.Enabled}
+		if choice.Key != "" {
+			item.Text = e.localizer.Tr(choice.Key)
+		}
+		index := i
+		item.OnSelect = func() {
+			e.fireUI(node, "select", starlark.MakeInt(index), starlark.String(item.Text))
+		}
+		menu.Items = append(menu.Items, item)
+	}
+	menu.FitToItems()
+	return nil
+}
+
+// ["薬草", {"text": "エーテル", "detail": "x3"}, {"key": "item.key", "enabled": False}]
+func (e *ScriptEngine) setListItems(list *ui.ListView, v starlark.Value) error {
+	choices, err := toChoices(v)
+	if err != nil {
+		return fmt.Errorf("items: %v", err)
+	}
+	items := make([]*ui.ListItem, len(choices))
+	for i, choice := range choices {
+		item := &ui.ListItem{Text: choice.Text, Key: choice.Key, Enabled: choice.Enabled}
+		if choice.Key != "" {
+			item.Text = e.localizer.Tr(choice.Key)
+		}
+		if dict, ok := v.(*starlark.List).Index(i).(*starlark.Dict); ok {
+			if detail, found, _ := dict.Get(starlark.String("detail")); found {
+				if item.Detail, err = toString("detail", detail); err != nil {
+					return fmt.Errorf("items[%d]: %v", i, err)
+				}
+			}
+		}
+		items[i] = item
+	}
+	list.SetItems(items)
+	return nil
+}
+
+// [{"title": "道具", "content": ui_list(...)}, {"key": "tab.equip", "content": panel}]
+// 前のタブの中身は外す
+func (e *ScriptEngine) setUITabs(node *uiNode, v starlark.Value) error {
+	list, ok := v.(*starlark.List)
+	if !ok {
+		return fmt.Errorf("tabs must be list, got %s", v.Type())
+	}
+	for len(node.children) > 0 {
+		e.detachUI(node.children[0])
+	}
+	for i := 0; i < list.Len(); i++ {
+		dict, ok := list.Index(i).(*starlark.Dict)
+		if !ok {
+			return fmt.Errorf("tabs[%d] must be dict, got %s", i, list.Index(i).Type())
+		}
+		var title, key string
+		var err error
+		if value, found, _ := dict.Get(starlark.String("title")); found {
+			if title, err = toString("title", value); err != nil {
+				return fmt.Errorf("tabs[%d]: %v", i, err)
+			}
+		}
+		if value, found, _ := dict.Get(starlark.String("key")); found {
+			if key, err = toString("key", value); err != nil {
+				return fmt.Errorf("tabs[%d]: %v", i, err)
+			}
+		}
+		value, found, _ := dict.Get(starlark.String("content"))
+		if !found {
+			return fmt.Errorf("tabs[%d] must have content", i)
+		}
+		content, ok := value.(*uiValue)
+		if !ok {
+			return fmt.Errorf("tabs[%d] content must be ui, got %s", i, value.Type())
+		}
+		if err := e.attachUI(node, content.node, title, key); err != nil {
+			return fmt.Errorf("tabs[%d]: %v", i, err)
+		}
+	}
+	return nil
+}
+
+// 入力できる文字の種類
+func parseTextFilter(name string) (func(rune) bool, error) {
+	switch name {
+	case "":
+		return nil, nil
+	case "digits":
+		return func(r rune) bool { return r >= '0' && r <= '9' }, nil
+	case "alphanumeric":
+		return func(r rune) bool {
+			return r >= '0' && r <= '9' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z'
+		}, nil
+	case "ascii":
+		return func(r rune) bool { return r >= 0x20 && r < 0x7f }, nil
+	}
+	return nil, fmt.Errorf("invalid filter: %s", name)
+}
+
+// フォントの指定をフェイスにして反映する
+func (e *ScriptEngine) applyUIFont(node *uiNode) {
+	face := e.uiFace(node)
+	switch c := node.component.(type) {
+	case *ui.Label:
+		c.Font = face
+		if c.AutoSize {
+			c.Fit()
+		}
+	case *ui.Button:
+		c.Font = face
+		c.Fit()
+	case *ui.MenuWindow:
+		c.Font = face
+		c.LineHeight = node.fontSize * 1.5
+		c.FitToItems()
+	case *ui.ListView:
+		c.Font = face
+		c.RowHeight = node.fontSize * 1.5
+		c.ScrollTo(c.SelectedIndex)
+	case *ui.TextInput:
+		c.Font = face
+		c.Fit()
+	case *ui.Toggle:
+		c.Font = face
+		c.Fit()
+	case *ui.TabContainer:
+		c.Font = face
+		c.Relayout()
+	}
+}
+
+// bindの値をUpdateのたびに取り直す
+func (e *ScriptEngine) applyUIBinding(node *uiNode) {
+	switch c := node.component.(type) {
+	case *ui.Label:
+		if node.bind == "" {
+			c.Source = nil
+			return
+		}
+		c.Source = func() string {
+			value, _ := e.boundValue(node, node.bind)
+			args := i18n.Args{"value": value}
+			for k, v := range c.Args {
+				args[k] = v
+			}
+			if c.Key != "" {
+				return e.localizer.Tr(c.Key, args)
+			}
+			return i18n.Format(node.format, args)
+		}
+	case *ui.Gauge:
+		if node.bind == "" {
+			c.Source = nil
+			return
+		}
+		c.Source = func() (float64, float64) {
+			value, _ := e.boundValue(node, node.bind)
+			max := c.Max
+			if node.bindMax != "" {
+				if v, ok := e.boundValue(node, node.bindMax); ok {
+					max = toNumber(v)
+				}
+			}
+			return toNumber(value), max
+		}
+	}
+}
+
+// entityの指定があればエンティティの状態、なければGameStateの変数
+func (e *ScriptEngine) boundValue(node *uiNode, path string) (interface{}, bool) {
+	if node.entity != 0 {
+		value := e.stateManager.GetState(core.EntityID(node.entity), path)
+		return value, value != nil
+	}
+	if e.saveManager == nil {
+		return nil, false
+	}
+	return e.saveManager.Lookup(path)
+}
+
+func toNumber(v interface{}) float64 {
+	switch v := v.(type) {
+	case int:
+		return float64(v)
+	case int64:
+		return float64(v)
+	case float64:
+		return v
+	}
+	return 0
+}
+
+// UIのIDまたはノード
+func (e *ScriptEngine) uiNodeOf(v starlark.Value) (*uiNode, error) {
+	if node, ok := v.(*uiValue); ok {
+		return node.node, nil
+	}
+	id, ok := starlark.AsString(v)
+	if !ok {
+		return nil, fmt.Errorf("ui must be id or ui, got %s", v.Type())
+	}
+	node, exists := e.uiNodes[id]
+	if !exists {
+		return nil, fmt.Errorf("ui not found: %s", id)
+	}
+	return node, nil
+}
+
+// プロパティを変更する
+func (e *ScriptEngine) uiSet(thread *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var target starlark.Value
+	if err := starlark.UnpackPositionalArgs(b.Name(), args, nil, 1, &target); err != nil {
+		return nil, err
+	}
+	node, err := e.uiNodeOf(target)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", b.Name(), err)
+	}
+	if err := e.applyUIProperties(node, append([]starlark.Tuple(nil), kwargs...)); err != nil {
+		return nil, fmt.Errorf("%s: %v", b.Name(), err)
+	}
+	return starlark.None, nil
+}
+
+// 現在の状態を辞書で返す
+func (e *ScriptEngine) uiGet(thread *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var target starlark.Value
+	if err := starlark.UnpackPositionalArgs(b.Name(), args, kwargs, 1, &target); err != nil {
+		return nil, err
+	}
+	node, err := e.uiNodeOf(target)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", b.Name(), err)
+	}
+
+	base := baseOf(node.component)
+	dict := starlark.NewDict(10)
+	dict.SetKey(starlark.String("id"), starlark.String(node.id))
+	dict.SetKey(starlark.String("type"), starlark.String(node.kind))
+	dict.SetKey(starlark.String("x"), starlark.Float(base.X))
+	dict.SetKey(starlark.String("y"), starlark.Float(base.Y))
+	dict.SetKey(starlark.String("width"), starlark.Float(base.Width))
+	dict.SetKey(starlark.String("height"), starlark.Float(base.Height))
+	dict.SetKey(starlark.String("visible"), starlark.Bool(base.Visible))
+	dict.SetKey(starlark.String("focused"), starlark.Bool(base.IsFocused()))
+	switch c := node.component.(type) {
+	case *ui.Label:
+		dict.SetKey(starlark.String("text"), starlark.String(c.Text))
+	case *ui.Button:
+		dict.SetKey(starlark.String("text"), starlark.String(c.Text))
+		dict.SetKey(starlark.String("enabled"), starlark.Bool(c.Enabled))
+	case *ui.Gauge:
+		dict.SetKey(starlark.String("value"), starlark.Float(c.Value))
+		dict.SetKey(starlark.String("max"), starlark.Float(c.Max))
+	case *ui.MenuWindow:
+		dict.SetKey(starlark.String("selected"), starlark.MakeInt(c.SelectedIndex))
+	case *ui.ListView:
+		dict.SetKey(starlark.String("selected"), starlark.MakeInt(c.SelectedIndex))
+		if item := c.Selected(); item != nil {
+			dict.SetKey(starlark.String("text"), starlark.String(item.Text))
+		}
+	case *ui.TextInput:
+		dict.SetKey(starlark.String("text"), starlark.String(c.Text))
+	case *ui.Slider:
+		dict.SetKey(starlark.String("value"), starlark.Float(c.Value))
+	case *ui.Toggle:
+		dict.SetKey(starlark.String("checked"), starlark.Bool(c.Checked))
+	case *ui.TabContainer:
+		dict.SetKey(starlark.String("selected"), starlark.MakeInt(c.SelectedIndex))
+	}
+	return dict, nil
+}
+
+func (e *ScriptEngine) uiShow(thread *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var target starlark.Value
+	if err := starlark.UnpackPositionalArgs(b.Name(), args, kwargs, 1, &target); err != nil {
+		return nil, err
+	}
+	node, err := e.uiNodeOf(target)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", b.Name(), err)
+	}
+	e.showUI(node)
+	return starlark.None, nil
+}
+
+func (e *ScriptEngine) uiHide(thread *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var target starlark.Value
+	if err := starlark.UnpackPositionalArgs(b.Name(), args, kwargs, 1, &target); err != nil {
+		return nil, err
+	}
+	node, err := e.uiNodeOf(target)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", b.Name(), err)
+	}
+	node.component.SetVisible(false)
+	return starlark.None, nil
+}
+
+// 画面・親から外し、子も含めて使えなくする
+func (e *ScriptEngine) uiRemove(thread *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var target starlark.Value
+	if err := starlark.UnpackPositionalArgs(b.Name(), args, kwargs, 1, &target); err != nil {
+		return nil, err
+	}
+	node, err := e.uiNodeOf(target)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", b.Name(), err)
+	}
+	e.detachUI(node)
+	var forget func(n *uiNode)
+	forget = func(n *uiNode) {
+		delete(e.uiNodes, n.id)
+		for _, child := range n.children {
+			forget(child)
+		}
+	}
+	forget(node)
+	return starlark.None, nil
+}
+
+// ui_add_child(parent, child, title=None, key=None)（title・keyはタブの見出し）
+func (e *ScriptEngine) uiAddChild(thread *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var parentValue, childValue starlark.Value
+	var title, key string
+	if err := starlark.UnpackArgs(b.Name(), args, kwargs, "parent", &parentValue, "child", &childValue, "title?", &title, "key?", &key); err != nil {
+		return nil, err
+	}
+	parent, err := e.uiNodeOf(parentValue)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", b.Name(), err)
+	}
+	child, err := e.uiNodeOf(childValue)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", b.Name(), err)
+	}
+	if err := e.attachUI(parent, child, title, key); err != nil {
+		return nil, fmt.Errorf("%s: %v", b.Name(), err)
+	}
+	return starlark.None, nil
+}
+
+// ui_on(id, "click", fn)
+func (e *ScriptEngine) uiOn(thread *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var target, fn starlark.Value
+	var event string
+	if err := starlark.UnpackPositionalArgs(b.Name(), args, kwargs, 3, &target, &event, &fn); err != nil {
+		return nil, err
+	}
+	node, err := e.uiNodeOf(target)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", b.Name(), err)
+	}
+	if err := e.subscribeUI(node, event, fn); err != nil {
+		return nil, fmt.Errorf("%s: %v", b.Name(), err)
+	}
+	return starlark.None, nil
+}
+
+func (e *ScriptEngine) uiFocus(thread *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var target starlark.Value
+	if err := starlark.UnpackPositionalArgs(b.Name(), args, kwargs, 1, &target); err != nil {
+		return nil, err
+	}
+	node, err := e.uiNodeOf(target)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", b.Name(), err)
+	}
+	e.uiManager.FocusComponent(node.component)
+	return starlark.None, nil
+}
+
+func newUILayout(name string) (ui.Layout, error) {
+	switch name {
+	case "vertical":
+		return &ui.FlexLayout{Direction: ui.Vertical, Spacing: 4, AlignItems: ui.AlignStart}, nil
+	case "horizontal":
+		return &ui.FlexLayout{Direction: ui.Horizontal, Spacing: 4, AlignItems: ui.AlignStart}, nil
+	case "grid":
+		return &ui.GridLayout{Columns: 2, SpacingX: 4, SpacingY: 4}, nil
+	case "anchor":
+		return &ui.AnchorLayout{}, nil
+	}
+	return nil, fmt.Errorf("invalid layout: %s", name)
+}
+
+var alignmentNames = map[string]ui.Alignment{
+	"auto":    ui.AlignAuto,
+	"start":   ui.AlignStart,
+	"left":    ui.AlignStart,
+	"top":     ui.AlignStart,
+	"center":  ui.AlignCenter,
+	"middle":  ui.AlignCenter,
+	"end":     ui.AlignEnd,
+	"right":   ui.AlignEnd,
+	"bottom":  ui.AlignEnd,
+	"stretch": ui.AlignStretch,
+}
+
+func toAlignment(key string, v starlark.Value) (ui.Alignment, error) {
+	name, err := toString(key, v)
+	if err != nil {
+		return ui.AlignAuto, err
+	}
+	align, ok := alignmentNames[name]
+	if !ok {
+		return ui.AlignAuto, fmt.Errorf("invalid %s: %s", key, name)
+	}
+	return align, nil
+}
+
+func parseJustify(name string) (ui.Justify, error) {
+	switch name {
+	case "start":
+		return ui.JustifyStart, nil
+	case "center":
+		return ui.JustifyCenter, nil
+	case "end":
+		return ui.JustifyEnd, nil
+	case "space_between":
+		return ui.JustifySpaceBetween, nil
+	case "space_around":
+		return ui.JustifySpaceAround, nil
+	}
+	return ui.JustifyStart, fmt.Errorf("invalid justify: %s", name)
+}
+
+// 10、[上下, 左右]、[上, 右, 下, 左]
+func toInsets(key string, v starlark.Value) (ui.Insets, error) {
+	if f, ok := starlark.AsFloat(v); ok {
+		return ui.UniformInsets(f), nil
+	}
+	seq, ok := v.(starlark.Indexable)
+	if !ok || (seq.Len() != 2 && seq.Len() != 4) {
+		return ui.Insets{}, fmt.Errorf("%s must be number, [vertical, horizontal] or [top, right, bottom, left]", key)
+	}
+	values := make([]float64, seq.Len())
+	for i := range values {
+		f, err := toFloat(key, seq.Index(i))
+		if err != nil {
+			return ui.Insets{}, err
+		}
+		values[i] = f
+	}
+	if len(values) == 2 {
+		return ui.Insets{Top: values[0], Right: values[1], Bottom: values[0], Left: values[1]}, nil
+	}
+	return ui.Insets{Top: values[0], Right: values[1], Bottom: values[2], Left: values[3]}, nil
+}
+
+// Noneなら色なし
+func toOptionalColor(v starlark.Value) (color.Color, error) {
+	if v == starlark.None {
+		return nil, nil
+	}
+	return parseColor(v)
+}
+
+// テーマを切り替える（UIとメッセージウィンドウの見た目がすぐに変わる）
+func (e *ScriptEngine) setTheme(thread *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var id string
+	if err := starlark.UnpackPositionalArgs(b.Name(), args, kwargs, 1, &id); err != nil {
+		return nil, err
+	}
+	if err := ui.DefaultThemes.Use(id); err != nil {
+		return nil, fmt.Errorf("%s: %v", b.Name(), err)
+	}
+	return starlark.None, nil
+}
+
+func (e *ScriptEngine) getTheme(thread *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	if err := starlark.UnpackPositionalArgs(b.Name(), args, kwargs, 0); err != nil {
+		return nil, err
+	}
+	return starlark.String(ui.DefaultThemes.CurrentID()), nil
+}
+
+func (e *ScriptEngine) getThemes(thread *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	if err := starlark.UnpackPositionalArgs(b.Name(), args, kwargs, 0); err != nil {
+		return nil, err
+	}
+	ids := ui.DefaultThemes.IDs()
+	values := make([]starlark.Value, len(ids))
+	for i, id := range ids {
+		values[i] = starlark.String(id)
+	}
+	return starlark.NewList(values), nil
+}
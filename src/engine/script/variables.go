@@ -0,0 +1,39 @@
+package script
+
+import (
+	"fmt"
+
+	"go.starlark.net/starlark"
+)
+
+// get_variable("player.hp", 0)
+// ドットで区切ると辞書の中の値を参照する。なければdefaultを返す
+func (e *ScriptEngine) getVariable(thread *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var path string
+	var defaultValue starlark.Value = starlark.None
+	if err := starlark.UnpackArgs(b.Name(), args, kwargs, "path", &path, "default?", &defaultValue); err != nil {
+		return nil, err
+	}
+	if e.saveManager == nil {
+		return nil, fmt.Errorf("%s: game state is not available", b.Name())
+	}
+	if value, exists := e.saveManager.LookupForStarlark(path); exists {
+		return value, nil
+	}
+	return defaultValue, nil
+}
+
+// set_variable("player", {"hp": 30, "max_hp": 50})
+// 値はセーブデータに保存される
+func (e *ScriptEngine) setVariable(thread *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var name string
+	var value starlark.Value
+	if err := starlark.UnpackPositionalArgs(b.Name(), args, kwargs, 2, &name, &value); err != nil {
+		return nil, err
+	}
+	if e.saveManager == nil {
+		return nil, fmt.Errorf("%s: game state is not available", b.Name())
+	}
+	e.saveManager.SetStateFromStarlark(name, value)
+	return starlark.None, nil
+}
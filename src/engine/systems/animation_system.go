@@ -0,0 +1,48 @@
+package systems
+
+import (
+	"gameengine/src/engine/ecs"
+	"gameengine/src/engine/ecs/components"
+	"gameengine/src/engine/ecs/core"
+)
+
+type AnimationSystem struct {
+	*ecs.BaseSystem
+}
+
+func NewAnimationSystem() *AnimationSystem {
+	return &AnimationSystem{
+		BaseSystem: ecs.NewBaseSystem(ecs.PriorityUpdate, []core.ComponentID{2, 6}), // Sprite と Animation
+	}
+}
+
+func (s *AnimationSystem) Update(dt float64) error {
+	for _, entity := range s.BaseSystem.Entities() {
+		if !entity.IsActive() {
+			continue
+		}
+
+		anim := entity.GetComponent(6).(*components.AnimationComponent)
+		sprite := entity.GetComponent(2).(*components.SpriteComponent)
+
+		wasPlaying := anim.Player.IsPlaying()
+		clipName := anim.Current
+		anim.Player.Update(dt, func(event string) {
+			if anim.OnEvent != nil {
+				anim.OnEvent(clipName, event)
+			}
+		})
+		if wasPlaying && anim.Player.IsFinished() && anim.OnFinish != nil {
+			anim.OnFinish(clipName)
+		}
+
+		// 現在のフレーム画像をスプライトに反映
+		if frame := anim.Player.CurrentFrame(); frame != nil && frame.Image != nil {
+			sprite.Sprite = frame.Image
+			bounds := frame.Image.Bounds()
+			sprite.Width = bounds.Dx()
+			sprite.Height = bounds.Dy()
+		}
+	}
+	return nil
+}
@@ -0,0 +1,48 @@
+package systems
+
+import (
+	"fmt"
+	enginefont "gameengine/src/engine/font"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/basicfont"
+)
+
+// フォントIDと大きさからフェイスを解決する
+// FontManagerが使えない場合は組み込みのビットマップフォントを返す
+type faceResolver struct {
+	fontManager *enginefont.FontManager
+	fallback    font.Face
+	warned      map[string]bool
+}
+
+func newFaceResolver(fontManager *enginefont.FontManager) *faceResolver {
+	return &faceResolver{
+		fontManager: fontManager,
+		fallback:    basicfont.Face7x13,
+		warned:      make(map[string]bool),
+	}
+}
+
+// フォントIDが空ならデフォルトフォント
+func (r *faceResolver) face(fontID string, size float64, style enginefont.FontStyle) font.Face {
+	if r.fontManager == nil {
+		return r.fallback
+	}
+
+	var face font.Face
+	var err error
+	if fontID == "" {
+		face, err = r.fontManager.GetFace(size, style)
+	} else {
+		face, err = r.fontManager.GetFontFace(fontID, size, style)
+	}
+	if err != nil {
+		if !r.warned[fontID] {
+			fmt.Printf("Failed to get font face %q: %v\n", fontID, err)
+			r.warned[fontID] = true
+		}
+		return r.fallback
+	}
+	return face
+}
@@ -3,18 +3,26 @@ package systems
 import (
 	"gameengine/src/engine/ecs"
 	"gameengine/src/engine/ecs/core"
+	"gameengine/src/engine/input"
 )
 
 type InputSystem struct {
 	*ecs.BaseSystem
+	mouse *input.MouseState
 }
 
 func NewInputSystem() *InputSystem {
 	return &InputSystem{
 		BaseSystem: ecs.NewBaseSystem(ecs.PriorityUpdate, []core.ComponentID{}), // 必要なコンポーネントなし
+		mouse:      input.NewMouseState(),
 	}
 }
 
 func (s *InputSystem) Update(dt float64) error {
+	s.mouse.Update()
 	return nil
 }
+
+func (s *InputSystem) Mouse() *input.MouseState {
+	return s.mouse
+}
@@ -0,0 +1,421 @@
+package systems
+
+import (
+	"gameengine/src/engine/ecs"
+	"gameengine/src/engine/ecs/components"
+	"gameengine/src/engine/ecs/core"
+	enginefont "gameengine/src/engine/font"
+	"gameengine/src/engine/i18n"
+	"gameengine/src/engine/input"
+	"gameengine/src/engine/render"
+	"gameengine/src/engine/richtext"
+	"gameengine/src/engine/ui"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+)
+
+// 早送り中に入力待ちで止まる時間（秒）
+const fastForwardWait = 0.1
+
+// InputComponentで指定がない場合のキー
+var defaultMessageKeys = map[string][]ebiten.Key{
+	"next":   {ebiten.KeySpace, ebiten.KeyEnter, ebiten.KeyZ},
+	"select": {ebiten.KeyEnter, ebiten.KeyZ},
+	"up":     {ebiten.KeyArrowUp},
+	"down":   {ebiten.KeyArrowDown},
+	"fast":   {ebiten.KeyControl},
+}
+
+// メッセージウィンドウの位置の更新と描画
+type MessageWindowSystem struct {
+	*ecs.BaseSystem
+	faces *faceResolver
+	icons richtext.IconFunc
+}
+
+func NewMessageWindowSystem(fontManager *enginefont.FontManager) *MessageWindowSystem {
+	return &MessageWindowSystem{
+		BaseSystem: ecs.NewBaseSystem(ecs.PriorityUpdate, []core.ComponentID{8}), // MessageWindow
+		faces:      newFaceResolver(fontManager),
+	}
+}
+
+// インラインアイコン（[icon=id]）の画像の取得方法を設定
+func (s *MessageWindowSystem) SetIconSource(icons richtext.IconFunc) {
+	s.icons = icons
+}
+
+// 言語が切り替わったらキーで設定された本文を訳し直す
+func (s *MessageWindowSystem) SetLocalizer(loc *i18n.Localizer) {
+	loc.OnLanguageChange(func(string) {
+		for _, entity := range s.BaseSystem.Entities() {
+			entity.GetComponent(8).(*components.MessageWindowComponent).Window.Localize(loc)
+		}
+	})
+}
+
+// テーマが切り替わったらウィンドウの見た目を変える
+func (s *MessageWindowSystem) SetThemes(themes *ui.ThemeSet) {
+	themes.OnThemeChange(func(t *ui.Theme) {
+		for _, entity := range s.BaseSystem.Entities() {
+			entity.GetComponent(8).(*components.MessageWindowComponent).Window.ApplyTheme(t)
+		}
+	})
+}
+
+func (s *MessageWindowSystem) Update(dt float64) error {
+	for _, entity := range s.BaseSystem.Entities() {
+		if !entity.IsActive() {
+			continue
+		}
+		window := entity.GetComponent(8).(*components.MessageWindowComponent).Window
+		s.prepare(window)
+		if transform, ok := entity.GetComponent(1).(*components.TransformComponent); ok {
+			window.SetPosition(transform.X, transform.Y)
+		}
+		window.Update()
+	}
+	return nil
+}
+
+// フォントが未設定のウィンドウにFontManagerのフェイスを割り当てる
+func (s *MessageWindowSystem) prepare(window *ui.MessageWindow) {
+	if window.Faces != nil {
+		return
+	}
+	window.Faces = s.faces.face
+	window.Font = s.faces.face(window.FontID, window.FontSize, enginefont.StyleRegular)
+	window.Icons = s.icons
+	window.Relayout()
+}
+
+// ウィンドウを描画キューのUIレイヤーに積む
+func (s *MessageWindowSystem) Collect(q *render.RenderQueue) {
+	for _, entity := range s.BaseSystem.Entities() {
+		if !entity.IsActive() {
+			continue
+		}
+		window := entity.GetComponent(8).(*components.MessageWindowComponent).Window
+		if window.Visible {
+			q.PushFunc(render.SortKey{Layer: render.LayerUI, Z: window.ZIndex, Y: window.Y}, window.Draw)
+		}
+	}
+}
+
+// メッセージの文字送り、入力待ち、選択肢
+type MessageSystem struct {
+	*ecs.BaseSystem
+	windows   *MessageWindowSystem
+	localizer *i18n.Localizer
+	input     *input.InputManager
+	dialogue  *input.InputContext // メッセージを表示している間に積む
+}
+
+func NewMessageSystem(windows *MessageWindowSystem) *MessageSystem {
+	return &MessageSystem{
+		BaseSystem: ecs.NewBaseSystem(ecs.PriorityUpdate, []core.ComponentID{9}), // MessageSystem
+		windows:    windows,
+		localizer:  i18n.Default,
+	}
+}
+
+// キーで指定されたメッセージと選択肢の訳し方を設定する
+// 言語が切り替わったら表示中の選択肢も訳し直す
+func (s *MessageSystem) SetLocalizer(loc *i18n.Localizer) {
+	s.localizer = loc
+	loc.OnLanguageChange(func(string) {
+		for _, entity := range s.BaseSystem.Entities() {
+			ms := entity.GetComponent(9).(*components.MessageSystemComponent)
+			if ms.Menu == nil {
+				continue
+			}
+			right, bottom := ms.Menu.X+ms.Menu.Width, ms.Menu.Y+ms.Menu.Height
+			ms.Menu.Localize(loc)
+			ms.Menu.SetPosition(right-ms.Menu.Width, bottom-ms.Menu.Height)
+		}
+	})
+}
+
+// メッセージを表示している間に"dialogue"の入力コンテキストを積むマネージャーを設定する
+// 会話中はゲームプレイのアクションが押されていないとみなされる
+func (s *MessageSystem) SetInputManager(im *input.InputManager) {
+	if s.input != nil {
+		s.input.RemoveContext(s.dialogue)
+	}
+	s.input = im
+	s.dialogue = input.NewDialogueContext()
+}
+
+func (s *MessageSystem) Update(dt float64) error {
+	talking := false
+	for _, entity := range s.BaseSystem.Entities() {
+		if !entity.IsActive() {
+			continue
+		}
+		ms := entity.GetComponent(9).(*components.MessageSystemComponent)
+		window := s.window(entity, ms)
+		if window == nil {
+			continue
+		}
+		keys, _ := entity.GetComponent(10).(*components.InputComponent)
+		s.step(ms, window, keys, dt)
+		if ms.State == components.MessageRevealing || ms.State == components.MessageWaiting || ms.State == components.MessageChoosing {
+			talking = true
+		}
+	}
+	if s.input != nil {
+		s.input.SetContextActive(s.dialogue, talking)
+	}
+	return nil
+}
+
+// 表示先のウィンドウ
+func (s *MessageSystem) window(entity *core.Entity, ms *components.MessageSystemComponent) *ui.MessageWindow {
+	target := entity.World.GetEntity(ms.WindowID)
+	if target == nil {
+		return nil
+	}
+	component, ok := target.GetComponent(8).(*components.MessageWindowComponent)
+	if !ok {
+		return nil
+	}
+	s.windows.prepare(component.Window)
+	return component.Window
+}
+
+func (s *MessageSystem) step(ms *components.MessageSystemComponent, window *ui.MessageWindow, keys *components.InputComponent, dt float64) {
+	proceed := s.justPressed(keys, "next") || s.justPressed(keys, "select")
+	fast := s.pressed(keys, "fast")
+	skip := ms.SkipRequested
+	ms.SkipRequested = false
+
+	switch ms.State {
+	case components.MessageIdle:
+		if ms.Current < len(ms.Messages) {
+			s.begin(ms, window)
+		}
+
+	case components.MessageRevealing:
+		total := window.CharCount()
+		switch {
+		case fast || proceed || skip || ms.CharDelay <= 0:
+			ms.Revealed = float64(total) // 早送り・スキップは残りを一度に表示
+		default:
+			ms.Revealed += dt / ms.CharDelay
+		}
+		if int(ms.Revealed) < total {
+			window.SetVisibleChars(int(ms.Revealed))
+			return
+		}
+		window.ShowAll()
+		s.revealed(ms, window)
+
+	case components.MessageWaiting:
+		if fast {
+			ms.WaitTime += dt
+		}
+		if proceed || (fast && ms.WaitTime >= fastForwardWait) {
+			s.advance(ms, window)
+		}
+
+	case components.MessageChoosing:
+		if ms.Menu == nil {
+			s.advance(ms, window)
+			return
+		}
+		switch {
+		case s.justPressed(keys, "up"):
+			ms.Menu.MoveSelection(-1)
+		case s.justPressed(keys, "down"):
+			ms.Menu.MoveSelection(1)
+		case proceed:
+			ms.Menu.Select()
+		}
+	}
+}
+
+// 現在のメッセージの表示を始める
+func (s *MessageSystem) begin(ms *components.MessageSystemComponent, window *ui.MessageWindow) {
+	window.Visible = true
+	window.ShowCursor = false
+	msg := ms.Messages[ms.Current]
+	if msg.Key != "" {
+		window.SetTextKey(s.localizer, msg.Key, msg.Args)
+	} else {
+		window.SetText(msg.Text)
+	}
+	window.SetVisibleChars(0)
+	ms.Revealed = 0
+	ms.State = components.MessageRevealing
+}
+
+// 全文を表示し終えたら入力待ちか選択肢へ
+func (s *MessageSystem) revealed(ms *components.MessageSystemComponent, window *ui.MessageWindow) {
+	msg := ms.Messages[ms.Current]
+	if len(msg.Choices) == 0 {
+		window.ShowCursor = true
+		ms.WaitTime = 0
+		ms.State = components.MessageWaiting
+		return
+	}
+
+	menu := ui.NewMenuWindow(window.Faces(window.FontID, window.FontSize, enginefont.StyleRegular))
+	menu.BackgroundColor = window.BackgroundColor
+	menu.TextColor = window.TextColor
+	menu.Padding = window.Padding
+	menu.LineHeight = window.LineHeight
+	menu.ZIndex = window.ZIndex + 1
+	for i, choice := range msg.Choices {
+		item := &ui.MenuItem{Text: choice.Text, Key: choice.Key, Enabled: choice.Enabled}
+		if choice.Key != "" {
+			item.Text = s.localizer.Tr(choice.Key)
+		}
+		index := i
+		item.OnSelect = func() {
+			option := item.Text
+			ms.Choice = index
+			ms.ChoiceText = option
+			if msg.OnChoice != nil {
+				msg.OnChoice(index, option)
+			}
+			if ms.OnChoice != nil {
+				ms.OnChoice(index, option)
+			}
+			s.advance(ms, window)
+		}
+		menu.Items = append(menu.Items, item)
+	}
+	if !msg.Choices[0].Enabled {
+		menu.MoveSelection(1)
+	}
+
+	// ウィンドウの右上に重ならないように出す
+	menu.FitToItems()
+	menu.SetPosition(window.X+window.Width-menu.Width, window.Y-menu.Height-8)
+
+	ms.Menu = menu
+	ms.Choice = -1
+	ms.ChoiceText = ""
+	ms.State = components.MessageChoosing
+}
+
+// 次のメッセージへ進む
+func (s *MessageSystem) advance(ms *components.MessageSystemComponent, window *ui.MessageWindow) {
+	ms.Menu = nil
+	window.ShowCursor = false
+	ms.Current++
+	if ms.Current < len(ms.Messages) {
+		s.begin(ms, window)
+		return
+	}
+
+	ms.State = components.MessageFinished
+	if ms.HideOnFinish {
+		window.Visible = false
+	}
+	if ms.OnFinish != nil {
+		ms.OnFinish()
+	}
+}
+
+// 選択肢のメニューを描画キューのUIレイヤーに積む
+func (s *MessageSystem) Collect(q *render.RenderQueue) {
+	for _, entity := range s.BaseSystem.Entities() {
+		if !entity.IsActive() {
+			continue
+		}
+		ms := entity.GetComponent(9).(*components.MessageSystemComponent)
+		if ms.Menu != nil && ms.Menu.Visible {
+			q.PushFunc(render.SortKey{Layer: render.LayerUI, Z: ms.Menu.ZIndex, Y: ms.Menu.Y}, ms.Menu.Draw)
+		}
+	}
+}
+
+// InputComponentの割り当て（なければ既定のキー）
+func actionKeys(keys *components.InputComponent, action string) []ebiten.Key {
+	if keys != nil && keys.HasAction(action) {
+		return keys.Keys[action]
+	}
+	return defaultMessageKeys[action]
+}
+
+// 入力マネージャーがある時に"dialogue"のコンテキストで調べるアクション（割り当ての変更に従う）
+var messageActions = map[string]input.Action{
+	"next":   input.ActionOK,
+	"select": input.ActionOK,
+	"up":     input.ActionUp,
+	"down":   input.ActionDown,
+}
+
+// アクションのほかに受け付けるキー
+var messageExtraKeys = map[string][]ebiten.Key{
+	"next": {ebiten.KeySpace},
+	"fast": {ebiten.KeyControl},
+}
+
+// 入力マネージャーがあれば"dialogue"のコンテキストに届いた入力だけで判定する
+// （上にメニューやポーズのコンテキストが積まれていれば、会話は進まない）
+func (s *MessageSystem) pressed(keys *components.InputComponent, action string) bool {
+	if s.input == nil {
+		return pressed(keys, action)
+	}
+	if keys != nil && keys.HasAction(action) {
+		for _, key := range keys.Keys[action] {
+			if s.dialogue.IsKeyPressed(key) {
+				return true
+			}
+		}
+		return false
+	}
+	if a, ok := messageActions[action]; ok && s.dialogue.IsPressed(a) {
+		return true
+	}
+	for _, key := range messageExtraKeys[action] {
+		if s.dialogue.IsKeyPressed(key) {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *MessageSystem) justPressed(keys *components.InputComponent, action string) bool {
+	if s.input == nil {
+		return justPressed(keys, action)
+	}
+	if keys != nil && keys.HasAction(action) {
+		for _, key := range keys.Keys[action] {
+			if inpututil.IsKeyJustPressed(key) && s.dialogue.IsKeyPressed(key) {
+				return true
+			}
+		}
+		return false
+	}
+	if a, ok := messageActions[action]; ok && s.dialogue.IsJustPressed(a) {
+		return true
+	}
+	for _, key := range messageExtraKeys[action] {
+		if inpututil.IsKeyJustPressed(key) && s.dialogue.IsKeyPressed(key) {
+			return true
+		}
+	}
+	return false
+}
+
+func pressed(keys *components.InputComponent, action string) bool {
+	for _, key := range actionKeys(keys, action) {
+		if ebiten.IsKeyPressed(key) {
+			return true
+		}
+	}
+	return false
+}
+
+func justPressed(keys *components.InputComponent, action string) bool {
+	for _, key := range actionKeys(keys, action) {
+		if inpututil.IsKeyJustPressed(key) {
+			return true
+		}
+	}
+	return false
+}
@@ -1,6 +1,7 @@
 package systems
 
 import (
+	"gameengine/src/engine/collision"
 	"gameengine/src/engine/ecs"
 	"gameengine/src/engine/ecs/components"
 	"gameengine/src/engine/ecs/core"
@@ -8,6 +9,7 @@ import (
 
 type PhysicsSystem struct {
 	*ecs.BaseSystem
+	tilemaps *TilemapSystem // 通行不可タイルとの衝突判定に使う
 }
 
 func NewPhysicsSystem() *PhysicsSystem {
@@ -16,7 +18,19 @@ func NewPhysicsSystem() *PhysicsSystem {
 	}
 }
 
+// タイルマップの通行不可タイルで止まるようにする
+func (s *PhysicsSystem) SetTilemapSystem(tilemaps *TilemapSystem) {
+	s.tilemaps = tilemaps
+}
+
 func (s *PhysicsSystem) Update(dt float64) error {
+	var colliders []*collision.CollisionObject
+	if s.tilemaps != nil {
+		for _, tm := range s.tilemaps.Tilemaps() {
+			colliders = append(colliders, tm.Colliders()...)
+		}
+	}
+
 	for _, entity := range s.BaseSystem.Entities() {
 		physics := entity.GetComponent(5).(*components.PhysicsComponent)
 		transform := entity.GetComponent(1).(*components.TransformComponent)
@@ -24,9 +38,17 @@ func (s *PhysicsSystem) Update(dt float64) error {
 		// 速度に重力を加える
 		physics.VelocityY += physics.Gravity * dt
 
-		// 位置の更新
-		transform.X += physics.VelocityX * dt
-		transform.Y += physics.VelocityY * dt
+		// 位置の更新（タイルと衝突判定する場合は横、縦の順に動かして押し戻す）
+		if physics.Solid && physics.Width > 0 && physics.Height > 0 && len(colliders) > 0 {
+			physics.OnGround = false
+			transform.X += physics.VelocityX * dt
+			resolveTileCollision(transform, physics, colliders, true)
+			transform.Y += physics.VelocityY * dt
+			resolveTileCollision(transform, physics, colliders, false)
+		} else {
+			transform.X += physics.VelocityX * dt
+			transform.Y += physics.VelocityY * dt
+		}
 
 		// 画面外に出たエンティティの削除
 		if transform.Y < -50 || transform.Y > 650 {
@@ -38,3 +60,33 @@ func (s *PhysicsSystem) Update(dt float64) error {
 	}
 	return nil
 }
+
+// 重なった通行不可タイルの外へ、動いた向きと逆に押し戻して速度を0にする
+func resolveTileCollision(transform *components.TransformComponent, physics *components.PhysicsComponent, colliders []*collision.CollisionObject, horizontal bool) {
+	for _, obj := range colliders {
+		// 接しているだけなら重なりとみなさない（床の上を横に歩けるように）
+		bounds := obj.Shape.GetBounds()
+		if transform.X+physics.Width <= bounds.Min.X || transform.X >= bounds.Max.X ||
+			transform.Y+physics.Height <= bounds.Min.Y || transform.Y >= bounds.Max.Y {
+			continue
+		}
+		switch {
+		case horizontal && physics.VelocityX > 0:
+			transform.X = bounds.Min.X - physics.Width
+		case horizontal && physics.VelocityX < 0:
+			transform.X = bounds.Max.X
+		case !horizontal && physics.VelocityY > 0:
+			transform.Y = bounds.Min.Y - physics.Height
+			physics.OnGround = true
+		case !horizontal && physics.VelocityY < 0:
+			transform.Y = bounds.Max.Y
+		default:
+			continue
+		}
+		if horizontal {
+			physics.VelocityX = 0
+		} else {
+			physics.VelocityY = 0
+		}
+	}
+}
@@ -4,13 +4,13 @@ import (
 	"gameengine/src/engine/ecs"
 	"gameengine/src/engine/ecs/components"
 	"gameengine/src/engine/ecs/core"
+	"gameengine/src/engine/render"
 
 	"github.com/hajimehoshi/ebiten/v2"
 )
 
 type RenderSystem struct {
 	*ecs.BaseSystem
-	screen *ebiten.Image
 }
 
 func NewRenderSystem() *RenderSystem {
@@ -20,38 +20,42 @@ func NewRenderSystem() *RenderSystem {
 }
 
 func (s *RenderSystem) Update(dt float64) error {
-	//	fmt.Printf("RenderSystem Update: screen=%v\n", s.screen != nil)
-	if s.screen == nil {
-		return nil
-	}
-
-	// fmt.Printf("RenderSystem checking required components: %v\n", s.BaseSystem.GetRequiredComponents())
-	// for _, entity := range s.BaseSystem.Entities() {
-	// 	fmt.Printf("Entity %d components: %v\n", entity.ID, entity.Components)
-	// 	if s.BaseSystem.HasRequiredComponents(entity) {
-	// 		fmt.Printf("Entity %d has required components\n", entity.ID)
-	// 	}
-	// }
+	return nil
+}
 
-	// fmt.Printf("RenderSystem entities: %d\n", len(s.BaseSystem.Entities()))
+// スプライトを描画キューに積む（同じ画像のスプライトはまとめて描画される）
+func (s *RenderSystem) Collect(q *render.RenderQueue) {
 	for _, entity := range s.BaseSystem.Entities() {
-		var transform *components.TransformComponent
-		var sprite *components.SpriteComponent
-
-		transform = entity.GetComponent(1).(*components.TransformComponent)
-		sprite = entity.GetComponent(2).(*components.SpriteComponent)
+		if !entity.IsActive() {
+			continue
+		}
 
-		if sprite.Sprite == nil {
+		transform := entity.GetComponent(1).(*components.TransformComponent)
+		sprite := entity.GetComponent(2).(*components.SpriteComponent)
+		if sprite.Sprite == nil || !sprite.Visible {
 			continue
 		}
 
-		op := &ebiten.DrawImageOptions{}
-		op.GeoM.Translate(transform.X, transform.Y)
-		s.screen.DrawImage(sprite.Sprite, op)
-	}
-	return nil
-}
+		scaleX, scaleY := transform.ScaleX, transform.ScaleY
+		if scaleX == 0 && scaleY == 0 {
+			scaleX, scaleY = 1, 1 // 未設定のtransform
+		}
+
+		var geoM ebiten.GeoM
+		geoM.Scale(scaleX, scaleY)
+		geoM.Rotate(transform.Rotation)
+		geoM.Translate(transform.X, transform.Y)
 
-func (s *RenderSystem) SetScreen(screen *ebiten.Image) {
-	s.screen = screen
+		// 同じレイヤー・Zでは足元が下にあるものを手前に描く
+		key := render.SortKey{
+			Layer: render.LayerWorld + sprite.Layer,
+			Z:     sprite.Z,
+			Y:     transform.Y + float64(sprite.Height)*scaleY,
+		}
+		colorScale := sprite.ColorScale
+		if sprite.Color != nil {
+			colorScale.ScaleWithColor(sprite.Color)
+		}
+		q.PushImage(key, sprite.Sprite, geoM, colorScale)
+	}
 }
@@ -5,25 +5,35 @@ import (
 	"gameengine/src/engine/ecs"
 	"gameengine/src/engine/ecs/components"
 	"gameengine/src/engine/ecs/core"
+	"gameengine/src/engine/render"
+	"image/color"
 
 	"github.com/hajimehoshi/ebiten/v2"
 )
 
+// 画面設定の反映先
+type ScreenConfigurable interface {
+	SetScreenSize(width, height int)
+	SetScaleMode(mode render.ScaleMode, letterbox color.Color)
+}
+
 type ScreenConfigSystem struct {
 	*ecs.BaseSystem
-	game interface {
-		SetScreenSize(width, height int)
-	}
-	currentWidth  int
-	currentHeight int
+	game             ScreenConfigurable
+	currentWidth     int
+	currentHeight    int
+	currentMode      render.ScaleMode
+	currentLetterbox color.RGBA
 }
 
-func NewScreenConfigSystem(game interface{ SetScreenSize(width, height int) }) *ScreenConfigSystem {
+func NewScreenConfigSystem(game ScreenConfigurable) *ScreenConfigSystem {
 	return &ScreenConfigSystem{
-		BaseSystem:    ecs.NewBaseSystem(ecs.PriorityUpdate, []core.ComponentID{4}),
-		game:          game,
-		currentWidth:  1280,
-		currentHeight: 720,
+		BaseSystem:       ecs.NewBaseSystem(ecs.PriorityUpdate, []core.ComponentID{4}),
+		game:             game,
+		currentWidth:     1280,
+		currentHeight:    720,
+		currentMode:      render.ScaleFit,
+		currentLetterbox: color.RGBA{0, 0, 0, 255},
 	}
 }
 
@@ -50,6 +60,12 @@ func (s *ScreenConfigSystem) Update(dt float64) error {
 
 			fmt.Printf("Window size set to: %dx%d\n", config.Width, config.Height)
 		}
+
+		if s.currentMode != config.ScaleMode || s.currentLetterbox != config.LetterboxColor {
+			s.game.SetScaleMode(config.ScaleMode, config.LetterboxColor)
+			s.currentMode = config.ScaleMode
+			s.currentLetterbox = config.LetterboxColor
+		}
 	}
 	return nil
 }
@@ -0,0 +1,88 @@
+package systems
+
+import (
+	"container/list"
+)
+
+// テキスト画像のキャッシュの上限
+const (
+	textCacheMaxEntries = 256
+	textCacheMaxPixels  = 8 * 1024 * 1024 // RGBAで32MB
+)
+
+// 使われていない順に捨てるテキスト画像のキャッシュ
+// 捨てた画像は描画キューがまだ参照しているかもしれないので、次のフレームの始めに破棄する
+type textCache struct {
+	entries    map[textKey]*list.Element
+	order      *list.List // 先頭ほど最近使った
+	pixels     int
+	maxEntries int
+	maxPixels  int
+	disposing  []*textImage
+
+	hits, misses, evictions int
+}
+
+type textCacheEntry struct {
+	key textKey
+	img *textImage
+}
+
+func newTextCache(maxEntries, maxPixels int) *textCache {
+	return &textCache{
+		entries:    make(map[textKey]*list.Element),
+		order:      list.New(),
+		maxEntries: maxEntries,
+		maxPixels:  maxPixels,
+	}
+}
+
+func (c *textCache) get(key textKey) (*textImage, bool) {
+	elem, ok := c.entries[key]
+	if !ok {
+		c.misses++
+		return nil, false
+	}
+	c.hits++
+	c.order.MoveToFront(elem)
+	return elem.Value.(*textCacheEntry).img, true
+}
+
+// 描画できないテキスト（空など）もnilとして覚えておく
+func (c *textCache) put(key textKey, img *textImage) {
+	if elem, ok := c.entries[key]; ok {
+		c.remove(elem)
+	}
+	c.entries[key] = c.order.PushFront(&textCacheEntry{key: key, img: img})
+	c.pixels += img.pixels()
+
+	for c.order.Len() > 1 && (c.order.Len() > c.maxEntries || c.pixels > c.maxPixels) {
+		c.remove(c.order.Back())
+		c.evictions++
+	}
+}
+
+func (c *textCache) remove(elem *list.Element) {
+	entry := c.order.Remove(elem).(*textCacheEntry)
+	delete(c.entries, entry.key)
+	c.pixels -= entry.img.pixels()
+	if entry.img != nil {
+		c.disposing = append(c.disposing, entry.img)
+	}
+}
+
+// 前のフレームで捨てた画像を破棄する（フレームの始めに呼ぶ）
+func (c *textCache) disposeEvicted() {
+	for _, img := range c.disposing {
+		img.image.Dispose()
+	}
+	c.disposing = c.disposing[:0]
+}
+
+func (img *textImage) pixels() int {
+	if img == nil {
+		return 0
+	}
+	b := img.image.Bounds()
+	return b.Dx() * b.Dy()
+}
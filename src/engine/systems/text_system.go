@@ -4,86 +4,338 @@ import (
 	"gameengine/src/engine/ecs"
 	"gameengine/src/engine/ecs/components"
 	"gameengine/src/engine/ecs/core"
+	enginefont "gameengine/src/engine/font"
+	"gameengine/src/engine/i18n"
+	"gameengine/src/engine/render"
+	"gameengine/src/engine/richtext"
+	"gameengine/src/engine/textlayout"
 	"image/color"
-	"os"
+	"math"
 
 	"github.com/hajimehoshi/ebiten/v2"
-	"github.com/hajimehoshi/ebiten/v2/text"
-	"golang.org/x/image/font"
-	"golang.org/x/image/font/basicfont"
-	"golang.org/x/image/font/opentype"
 )
 
+// 描画結果が変わる設定をすべて含むキャッシュキー
+type textKey struct {
+	text         string
+	fontID       string
+	size         float64
+	style        enginefont.FontStyle
+	color        color.RGBA
+	outlineColor color.RGBA
+	outlineWidth float64
+	shadowColor  color.RGBA
+	shadowX      float64
+	shadowY      float64
+	align        textlayout.Align
+	maxWidth     float64
+	lineSpacing  float64
+}
+
+// 描画済みのテキスト画像
+type textImage struct {
+	image   *ebiten.Image
+	originX float64 // 画像内のテキストブロック左上
+	originY float64
+	width   float64 // テキストブロックの大きさ
+	height  float64
+}
+
+// この回数続けて文字列が変わったテキストはアトラスで描く
+const (
+	volatileChanges = 3
+	volatileWindow  = 60 // 変化の間隔がこのフレーム数以内なら続けて変わったとみなす
+)
+
+// 文字列が変わった履歴
+type textHistory struct {
+	text    string
+	changes int // 続けて変わった回数
+	changed int // 最後に変わったフレーム
+	seen    int // 最後に描画したフレーム
+}
+
 type TextSystem struct {
 	*ecs.BaseSystem
-	screen    *ebiten.Image
-	font      font.Face
-	textCache map[string]*ebiten.Image
+	faces      *faceResolver
+	icons      richtext.IconFunc
+	textCache  *textCache
+	atlas      *richtext.GlyphAtlas
+	history    map[*components.TextComponent]*textHistory
+	viewport   *render.Viewport
+	frame      int
+	atlasTexts int
 }
 
-func NewTextSystem() *TextSystem {
-	fontData, err := os.ReadFile("assets/fonts/NotoSansJP-Regular.ttf")
-	if err != nil {
-		return &TextSystem{
-			BaseSystem: ecs.NewBaseSystem(ecs.PriorityRender+1, []core.ComponentID{3}),
-			font:       basicfont.Face7x13,
+// フォントはFontManagerから解決し、使えない場合は組み込みのビットマップフォントで描画する
+func NewTextSystem(fontManager *enginefont.FontManager) *TextSystem {
+	return &TextSystem{
+		BaseSystem: ecs.NewBaseSystem(ecs.PriorityRender+1, []core.ComponentID{3}),
+		faces:      newFaceResolver(fontManager),
+		textCache:  newTextCache(textCacheMaxEntries, textCacheMaxPixels),
+		atlas:      richtext.NewGlyphAtlas(),
+		history:    make(map[*components.TextComponent]*textHistory),
+	}
+}
+
+func (s *TextSystem) Update(dt float64) error {
+	return nil
+}
+
+// テキストを描画キューのテキストレイヤーに積む
+// 頻繁に変わるテキストは文字列ごとの画像を作らず、アトラスから1文字ずつ描く
+func (s *TextSystem) Collect(q *render.RenderQueue) {
+	s.frame++
+	s.textCache.disposeEvicted()
+	s.atlasTexts = 0
+
+	for _, entity := range s.BaseSystem.Entities() {
+		textComp := entity.GetComponent(3).(*components.TextComponent)
+		if !textComp.Visible || textComp.Text == "" {
+			continue
 		}
+
+		if s.isVolatile(textComp) {
+			s.collectGlyphs(q, textComp)
+			continue
+		}
+
+		img := s.textImage(textComp)
+		if img == nil {
+			continue
+		}
+
+		x, y := s.blockOrigin(textComp, img.width, img.height)
+		var geoM ebiten.GeoM
+		geoM.Translate(math.Round(x-img.originX), math.Round(y-img.originY))
+		q.PushImage(render.SortKey{Layer: render.LayerText, Y: textComp.Y}, img.image, geoM, ebiten.ColorScale{})
 	}
 
-	tt, err := opentype.Parse(fontData)
-	if err != nil {
-		return &TextSystem{
-			BaseSystem: ecs.NewBaseSystem(ecs.PriorityRender+1, []core.ComponentID{3}),
-			font:       basicfont.Face7x13,
+	// 描画されなくなったテキストの履歴を捨てる
+	for c, h := range s.history {
+		if h.seen != s.frame {
+			delete(s.history, c)
 		}
 	}
+}
 
-	const dpi = 72
-	face, err := opentype.NewFace(tt, &opentype.FaceOptions{
-		Size:    16,
-		DPI:     dpi,
-		Hinting: font.HintingFull,
+// 言語が切り替わったらキーを持つテキストを訳し直す
+func (s *TextSystem) SetLocalizer(loc *i18n.Localizer) {
+	loc.OnLanguageChange(func(string) {
+		for _, entity := range s.BaseSystem.Entities() {
+			entity.GetComponent(3).(*components.TextComponent).Localize(loc)
+		}
 	})
-	if err != nil {
-		return &TextSystem{
-			BaseSystem: ecs.NewBaseSystem(ecs.PriorityRender+1, []core.ComponentID{3}),
-			font:       basicfont.Face7x13,
+}
+
+// アンカーの基準にする画面（論理解像度）
+func (s *TextSystem) SetViewport(v *render.Viewport) {
+	s.viewport = v
+}
+
+// 揃えに応じてX, Yをブロックのどこに合わせるかを決め、ブロック左上を返す
+// アンカーがあれば画面の基準点からずらし、ブロックの同じ位置を合わせる
+func (s *TextSystem) blockOrigin(c *components.TextComponent, width, height float64) (float64, float64) {
+	x, y := c.X, c.Y
+	h, v := c.Align, c.VAlign
+	if c.Anchor.Enabled {
+		if s.viewport != nil {
+			sw, sh := s.viewport.Size()
+			ax, ay := c.Anchor.Point(float64(sw), float64(sh))
+			x, y = x+ax, y+ay
 		}
+		h, v = c.Anchor.H, c.Anchor.V
 	}
+	return x - textlayout.AlignOffset(h, width), y - textlayout.VerticalAlignOffset(v, height)
+}
 
-	return &TextSystem{
-		BaseSystem: ecs.NewBaseSystem(ecs.PriorityRender+1, []core.ComponentID{3}),
-		font:       face,
-		textCache:  make(map[string]*ebiten.Image),
+// TextComponentの設定で描いた場合の大きさと行数（描画と同じレイアウトで測る）
+func (s *TextSystem) Measure(c *components.TextComponent) (width, height float64, lines int) {
+	layout := s.layout(s.key(c))
+	return layout.Width, layout.Height, len(layout.Lines)
+}
+
+// テキストと同じ方法でフォントを解決する（UIのフォント指定用）
+func (s *TextSystem) Faces() richtext.FaceFunc {
+	return s.faces.face
+}
+
+// 文字列が続けて変わっているか（Dynamicなら常に）
+func (s *TextSystem) isVolatile(c *components.TextComponent) bool {
+	h, ok := s.history[c]
+	if !ok {
+		h = &textHistory{text: c.Text, changed: s.frame}
+		s.history[c] = h
 	}
+	h.seen = s.frame
+
+	if h.text != c.Text {
+		if s.frame-h.changed <= volatileWindow {
+			h.changes++
+		} else {
+			h.changes = 1
+		}
+		h.text = c.Text
+		h.changed = s.frame
+	} else if s.frame-h.changed > volatileWindow {
+		h.changes = 0 // しばらく変わらなければ画像に戻す
+	}
+	return c.Dynamic || h.changes >= volatileChanges
 }
 
-func (s *TextSystem) Update(dt float64) error {
-	if s.screen == nil {
-		return nil
+// 影 → 縁取り → 本体の順にアトラスの文字を積む
+func (s *TextSystem) collectGlyphs(q *render.RenderQueue, c *components.TextComponent) {
+	key := s.key(c)
+	layout := s.layout(key)
+	if layout.Width <= 0 || layout.Height <= 0 {
+		return
 	}
+	s.atlasTexts++
 
-	for _, entity := range s.BaseSystem.Entities() {
-		textComp := entity.GetComponent(3).(*components.TextComponent)
-		if !textComp.Visible {
-			continue
+	x, y := s.blockOrigin(c, layout.Width, layout.Height)
+	x, y = math.Round(x), math.Round(y)
+	sortKey := render.SortKey{Layer: render.LayerText, Y: c.Y}
+	emit := func(quad richtext.Quad) {
+		var cs ebiten.ColorScale
+		if quad.Color != nil {
+			cs.ScaleWithColor(quad.Color)
 		}
+		q.PushImageRect(sortKey, quad.Image, quad.Src, quad.GeoM, cs)
+	}
+
+	forEachStroke(key, func(dx, dy float64, clr color.Color) {
+		layout.Quads(s.atlas, x+dx, y+dy, &richtext.DrawOptions{Override: clr, SkipIcons: true}, emit)
+	})
+	layout.Quads(s.atlas, x, y, &richtext.DrawOptions{Color: key.color}, emit)
+}
+
+// キャッシュとアトラスの統計
+func (s *TextSystem) Stats() render.TextStats {
+	atlas := s.atlas.Stats()
+	return render.TextStats{
+		CacheHits:    s.textCache.hits,
+		CacheMisses:  s.textCache.misses,
+		Evictions:    s.textCache.evictions,
+		CachedImages: s.textCache.order.Len(),
+		CachedPixels: s.textCache.pixels,
+		AtlasGlyphs:  atlas.Glyphs,
+		AtlasPages:   atlas.Pages,
+		AtlasHits:    atlas.Hits,
+		AtlasMisses:  atlas.Misses,
+		AtlasTexts:   s.atlasTexts,
+	}
+}
+
+// インラインアイコン（[icon=id]）の画像の取得方法を設定
+func (s *TextSystem) SetIconSource(icons richtext.IconFunc) {
+	s.icons = icons
+}
+
+func (s *TextSystem) textImage(c *components.TextComponent) *textImage {
+	key := s.key(c)
+	if img, exists := s.textCache.get(key); exists {
+		return img
+	}
+
+	img := s.renderText(key)
+	s.textCache.put(key, img)
+	return img
+}
 
-		img, exists := s.textCache[textComp.Text]
-		if !exists {
-			bounds := text.BoundString(s.font, textComp.Text)
-			img = ebiten.NewImage(bounds.Dx(), bounds.Dy())
-			text.Draw(img, textComp.Text, s.font, 0, -bounds.Min.Y, color.White)
-			s.textCache[textComp.Text] = img
+func (s *TextSystem) key(c *components.TextComponent) textKey {
+	return textKey{
+		text:         c.Text,
+		fontID:       c.FontID,
+		size:         c.Size,
+		style:        c.Style,
+		color:        toRGBA(c.Color),
+		outlineColor: toRGBA(c.OutlineColor),
+		outlineWidth: c.OutlineWidth,
+		shadowColor:  toRGBA(c.ShadowColor),
+		shadowX:      c.ShadowOffsetX,
+		shadowY:      c.ShadowOffsetY,
+		align:        c.Align,
+		maxWidth:     c.MaxWidth,
+		lineSpacing:  c.LineSpacing,
+	}
+}
+
+// テキストはマークアップ（[color=red]など）として解釈する
+func (s *TextSystem) layout(key textKey) *richtext.Layout {
+	return richtext.NewFromMarkup(key.text, richtext.Options{
+		FontID:      key.fontID,
+		Size:        key.size,
+		Style:       key.style,
+		MaxWidth:    key.maxWidth,
+		LineSpacing: key.lineSpacing,
+		Align:       key.align,
+		Faces:       s.faces.face,
+		Icons:       s.icons,
+	})
+}
+
+// 影と縁取りを描く位置と色（影の縁取り → 影 → 縁取りの順）
+func forEachStroke(key textKey, fn func(dx, dy float64, clr color.Color)) {
+	outline := math.Ceil(key.outlineWidth)
+	drawOutline := func(dx, dy float64, stroke color.Color) {
+		if outline > 0 {
+			for oy := -outline; oy <= outline; oy++ {
+				for ox := -outline; ox <= outline; ox++ {
+					if (ox != 0 || oy != 0) && ox*ox+oy*oy <= outline*outline+outline {
+						fn(dx+ox, dy+oy, stroke)
+					}
+				}
+			}
 		}
+	}
 
-		op := &ebiten.DrawImageOptions{}
-		op.GeoM.Translate(textComp.X, textComp.Y)
-		s.screen.DrawImage(img, op)
+	if key.shadowX != 0 || key.shadowY != 0 {
+		drawOutline(key.shadowX, key.shadowY, key.shadowColor)
+		fn(key.shadowX, key.shadowY, key.shadowColor)
+	}
+	drawOutline(0, 0, key.outlineColor)
+}
+
+// 影 → 縁取り → 本体の順に1枚の画像へ描画する
+func (s *TextSystem) renderText(key textKey) *textImage {
+	layout := s.layout(key)
+
+	outline := math.Ceil(key.outlineWidth)
+	hasShadow := key.shadowX != 0 || key.shadowY != 0
+	padLeft, padRight := outline, outline
+	padTop, padBottom := outline, outline
+	if hasShadow {
+		padLeft += math.Max(0, -key.shadowX)
+		padRight += math.Max(0, key.shadowX)
+		padTop += math.Max(0, -key.shadowY)
+		padBottom += math.Max(0, key.shadowY)
+	}
+
+	w := int(math.Ceil(layout.Width + padLeft + padRight))
+	h := int(math.Ceil(layout.Height + padTop + padBottom))
+	if w <= 0 || h <= 0 {
+		return nil
+	}
+	img := ebiten.NewImage(w, h)
+
+	// 影と縁取りは単色で描き、アイコンは本体にだけ描く
+	forEachStroke(key, func(dx, dy float64, clr color.Color) {
+		layout.Draw(img, padLeft+dx, padTop+dy, &richtext.DrawOptions{Override: clr, SkipIcons: true})
+	})
+	layout.Draw(img, padLeft, padTop, &richtext.DrawOptions{Color: key.color})
+
+	return &textImage{
+		image:   img,
+		originX: padLeft,
+		originY: padTop,
+		width:   layout.Width,
+		height:  layout.Height,
 	}
-	return nil
 }
 
-func (s *TextSystem) SetScreen(screen *ebiten.Image) {
-	s.screen = screen
+func toRGBA(c color.Color) color.RGBA {
+	if c == nil {
+		return color.RGBA{}
+	}
+	return color.RGBAModel.Convert(c).(color.RGBA)
 }
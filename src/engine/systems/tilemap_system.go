@@ -0,0 +1,62 @@
+package systems
+
+import (
+	"gameengine/src/engine/ecs"
+	"gameengine/src/engine/ecs/components"
+	"gameengine/src/engine/ecs/core"
+	"gameengine/src/engine/render"
+	"image"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+type TilemapSystem struct {
+	*ecs.BaseSystem
+}
+
+func NewTilemapSystem() *TilemapSystem {
+	return &TilemapSystem{
+		BaseSystem: ecs.NewBaseSystem(ecs.PriorityRender, []core.ComponentID{7}), // Tilemap
+	}
+}
+
+func (s *TilemapSystem) Update(dt float64) error {
+	return nil
+}
+
+// 有効なタイルマップ
+func (s *TilemapSystem) Tilemaps() []*components.TilemapComponent {
+	var tilemaps []*components.TilemapComponent
+	for _, entity := range s.BaseSystem.Entities() {
+		if !entity.IsActive() {
+			continue
+		}
+		if tm, ok := entity.GetComponent(7).(*components.TilemapComponent); ok && tm.Map != nil {
+			tilemaps = append(tilemaps, tm)
+		}
+	}
+	return tilemaps
+}
+
+// 表示範囲のチャンクを描画キューに積む
+// レイヤーは背景に並べ、プロパティ render_layer があればその描画レイヤーに置く
+func (s *TilemapSystem) Collect(q *render.RenderQueue, view image.Rectangle) {
+	for _, entity := range s.BaseSystem.Entities() {
+		if !entity.IsActive() {
+			continue
+		}
+		tm := entity.GetComponent(7).(*components.TilemapComponent)
+		if !tm.Visible || tm.Map == nil {
+			continue
+		}
+
+		tm.Renderer.Collect(view, tm.X, tm.Y, func(index int, img *ebiten.Image, op *ebiten.DrawImageOptions) {
+			layer := tm.Map.Layers[index]
+			key := render.SortKey{Layer: render.LayerBackground, Z: index}
+			if _, exists := layer.Properties["render_layer"]; exists {
+				key.Layer = int(layer.Properties.Float("render_layer"))
+			}
+			q.PushImage(key, img, op.GeoM, op.ColorScale)
+		})
+	}
+}
@@ -0,0 +1,58 @@
+package textlayout
+
+import "unicode/utf8"
+
+// 行頭禁則文字（行の先頭に来てはいけない）
+var noLineStart = runeSet(
+	"、。，．,.)]}）］｝〕〉》」』】〙〗〟’”｠»" +
+		"ゝゞヽヾ々〻ー－‐゠〜～" +
+		"ぁぃぅぇぉっゃゅょゎゕゖァィゥェォッャュョヮヵヶㇰㇱㇲㇳㇴㇵㇶㇷㇸㇹㇺㇻㇼㇽㇾㇿ" +
+		"ｧｨｩｪｫｬｭｮｯｰ" +
+		"・：；？！!?‼⁇⁈⁉…‥" +
+		"｡､｣",
+)
+
+// 行末禁則文字（行の末尾に来てはいけない）
+var noLineEnd = runeSet("([{（［｛〔〈《「『【〘〖〝‘“｟«｢")
+
+// ぶら下げできる文字（行末からはみ出してよい）
+var hangable = runeSet("、。，．,.､｡")
+
+func runeSet(s string) map[rune]bool {
+	set := make(map[rune]bool)
+	for _, r := range s {
+		set[r] = true
+	}
+	return set
+}
+
+// 行頭禁則文字か
+func IsNoLineStart(r rune) bool {
+	return noLineStart[r]
+}
+
+// 行末禁則文字か
+func IsNoLineEnd(r rune) bool {
+	return noLineEnd[r]
+}
+
+// ぶら下げできる文字か
+func IsHangable(r rune) bool {
+	return hangable[r]
+}
+
+// posの位置で改行しても禁則に触れないか
+func kinsokuAllows(text string, pos int) bool {
+	next, _ := utf8.DecodeRuneInString(text[pos:])
+	prev, _ := utf8.DecodeLastRuneInString(text[:pos])
+	return !noLineStart[next] && !noLineEnd[prev]
+}
+
+// 行末のぶら下げ文字（1文字まで）を除いた部分のバイト数
+func trimHanging(s string) int {
+	r, size := utf8.DecodeLastRuneInString(s)
+	if size == 0 || !hangable[r] {
+		return len(s)
+	}
+	return len(s) - size
+}
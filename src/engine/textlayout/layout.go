@@ -0,0 +1,273 @@
+package textlayout
+
+import (
+	"math"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/math/fixed"
+)
+
+// 横方向の揃え
+type Align int
+
+const (
+	AlignLeft Align = iota
+	AlignCenter
+	AlignRight
+)
+
+// 縦方向の揃え
+type VerticalAlign int
+
+const (
+	AlignTop VerticalAlign = iota
+	AlignMiddle
+	AlignBottom
+)
+
+func ParseAlign(s string) (Align, bool) {
+	switch s {
+	case "left":
+		return AlignLeft, true
+	case "center", "centre":
+		return AlignCenter, true
+	case "right":
+		return AlignRight, true
+	}
+	return AlignLeft, false
+}
+
+func ParseVerticalAlign(s string) (VerticalAlign, bool) {
+	switch s {
+	case "top":
+		return AlignTop, true
+	case "middle", "center", "centre":
+		return AlignMiddle, true
+	case "bottom":
+		return AlignBottom, true
+	}
+	return AlignTop, false
+}
+
+// 画面のどこを基準に置くか
+// 指定するとX, Yは基準点からのずれになり、テキストの同じ位置（右下なら右下の角）を合わせる
+type Anchor struct {
+	Enabled bool
+	H       Align
+	V       VerticalAlign
+}
+
+var anchorNames = map[string]Anchor{
+	"top_left":     {true, AlignLeft, AlignTop},
+	"top":          {true, AlignCenter, AlignTop},
+	"top_right":    {true, AlignRight, AlignTop},
+	"left":         {true, AlignLeft, AlignMiddle},
+	"center":       {true, AlignCenter, AlignMiddle},
+	"right":        {true, AlignRight, AlignMiddle},
+	"bottom_left":  {true, AlignLeft, AlignBottom},
+	"bottom":       {true, AlignCenter, AlignBottom},
+	"bottom_right": {true, AlignRight, AlignBottom},
+}
+
+// "none"または空なら基準なし
+func ParseAnchor(s string) (Anchor, bool) {
+	if s == "" || s == "none" {
+		return Anchor{}, true
+	}
+	a, ok := anchorNames[s]
+	return a, ok
+}
+
+func (a Anchor) String() string {
+	for name, anchor := range anchorNames {
+		if anchor == a {
+			return name
+		}
+	}
+	return "none"
+}
+
+// w×hの範囲での基準点
+func (a Anchor) Point(w, h float64) (float64, float64) {
+	if !a.Enabled {
+		return 0, 0
+	}
+	return AlignOffset(a.H, w), VerticalAlignOffset(a.V, h)
+}
+
+// 揃えに応じた幅wの中の位置
+func AlignOffset(align Align, w float64) float64 {
+	switch align {
+	case AlignCenter:
+		return w / 2
+	case AlignRight:
+		return w
+	}
+	return 0
+}
+
+func VerticalAlignOffset(align VerticalAlign, h float64) float64 {
+	switch align {
+	case AlignMiddle:
+		return h / 2
+	case AlignBottom:
+		return h
+	}
+	return 0
+}
+
+// レイアウトの設定
+type Options struct {
+	MaxWidth    float64 // 0以下なら折り返さない
+	LineSpacing float64 // 行の高さの倍率（0なら1.0）
+	Align       Align
+}
+
+type Line struct {
+	Text  string
+	Width float64
+	X     float64 // ブロック左端からの位置（揃えを反映）
+}
+
+// 複数行テキストの配置結果
+type Layout struct {
+	Lines      []Line
+	Width      float64 // 一番長い行の幅
+	Height     float64
+	LineHeight float64
+	Ascent     float64
+	Descent    float64
+}
+
+// 改行と最大幅で行に分けて配置する
+func New(face font.Face, text string, opts Options) *Layout {
+	metrics := face.Metrics()
+	spacing := opts.LineSpacing
+	if spacing <= 0 {
+		spacing = 1
+	}
+
+	l := &Layout{
+		LineHeight: fixedToFloat(metrics.Height) * spacing,
+		Ascent:     fixedToFloat(metrics.Ascent),
+		Descent:    fixedToFloat(metrics.Descent),
+	}
+
+	for _, paragraph := range strings.Split(text, "\n") {
+		for _, line := range Wrap(face, paragraph, opts.MaxWidth) {
+			width := Measure(face, line)
+			l.Lines = append(l.Lines, Line{Text: line, Width: width})
+			l.Width = math.Max(l.Width, width)
+		}
+	}
+
+	for i := range l.Lines {
+		switch opts.Align {
+		case AlignCenter:
+			l.Lines[i].X = (l.Width - l.Lines[i].Width) / 2
+		case AlignRight:
+			l.Lines[i].X = l.Width - l.Lines[i].Width
+		}
+	}
+
+	l.Height = l.LineHeight*float64(len(l.Lines)-1) + l.Ascent + l.Descent
+	return l
+}
+
+// i行目のベースライン（ブロック上端から）
+func (l *Layout) Baseline(i int) float64 {
+	return l.Ascent + float64(i)*l.LineHeight
+}
+
+// 文字列の描画幅
+func Measure(face font.Face, s string) float64 {
+	return fixedToFloat(font.MeasureString(face, s))
+}
+
+// 最大幅に収まるように1段落を行に分ける
+// UAX #14 の改行位置と日本語の禁則に従い、句読点は行末にぶら下げる
+// 改行位置の間が1行に収まらない場合は文字単位で分ける
+func Wrap(face font.Face, text string, maxWidth float64) []string {
+	if maxWidth <= 0 || Measure(face, text) <= maxWidth {
+		return []string{text}
+	}
+
+	measure := func(start, end int) float64 {
+		return Measure(face, text[start:end])
+	}
+	var lines []string
+	for _, r := range WrapRanges(text, BreakOpportunities(text), maxWidth, measure) {
+		lines = append(lines, text[r.Start:r.End])
+	}
+	return lines
+}
+
+// 行の範囲（textのバイト位置、行末の空白は含まない）
+type Range struct {
+	Start, End int
+}
+
+// 改行位置の一覧から行を決める
+// measureはtext[start:end]の幅を返す（文字ごとに書体が違う場合にも使えるように）
+// maxWidthが0以下なら強制改行でのみ分ける
+func WrapRanges(text string, breaks []Break, maxWidth float64, measure func(start, end int) float64) []Range {
+	// 行末の空白を除いた幅が収まるか（ぶら下げ文字ははみ出してよい）
+	fits := func(start, end int) bool {
+		end = start + len(trimLineEnd(text[start:end]))
+		if maxWidth <= 0 || measure(start, end) <= maxWidth {
+			return true
+		}
+		n := trimHanging(text[start:end])
+		return n < end-start && measure(start, start+n) <= maxWidth
+	}
+
+	var lines []Range
+	emit := func(start, end int) {
+		lines = append(lines, Range{start, start + len(trimLineEnd(text[start:end]))})
+	}
+
+	start, last := 0, 0 // lastは今の行に収まる最後の改行位置
+	for _, b := range breaks {
+		for !fits(start, b.Pos) {
+			if last > start {
+				emit(start, last)
+				start = last
+				continue
+			}
+			n := fitRunes(text, start, b.Pos, maxWidth, measure)
+			lines = append(lines, Range{start, n})
+			start = n
+		}
+		last = b.Pos
+
+		if b.Mandatory {
+			emit(start, b.Pos)
+			start = b.Pos
+		}
+	}
+	return lines
+}
+
+// 行末の空白と改行文字を除く
+func trimLineEnd(s string) string {
+	return strings.TrimRight(s, " \t\r\n\v\f\u3000\u2028\u2029\u0085")
+}
+
+// text[start:end]のうち最大幅に収まる先頭部分の終わりの位置（最低1文字）
+func fitRunes(text string, start, end int, maxWidth float64, measure func(start, end int) float64) int {
+	fit := start
+	for i, r := range text[start:end] {
+		next := start + i + utf8.RuneLen(r)
+		if fit > start && measure(start, next) > maxWidth {
+			break
+		}
+		fit = next
+	}
+	return fit
+}
+
+func fixedToFloat(v fixed.Int26_6) float64 {
+	return float64(v) / 64
+}
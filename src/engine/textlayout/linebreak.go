@@ -0,0 +1,312 @@
+package textlayout
+
+import "unicode"
+
+// UAX #14 の改行クラス（ゲーム内テキストで使う範囲に絞ったもの）
+type BreakClass int
+
+const (
+	ClassAL  BreakClass = iota // 英字・記号
+	ClassBK                    // 強制改行
+	ClassCR                    // 復帰
+	ClassLF                    // 改行
+	ClassNL                    // 次行
+	ClassSP                    // 空白
+	ClassZW                    // ゼロ幅空白
+	ClassZWJ                   // ゼロ幅接合子
+	ClassCM                    // 結合文字
+	ClassWJ                    // 単語結合子
+	ClassGL                    // ノーブレークスペースなど
+	ClassOP                    // 開き括弧
+	ClassCL                    // 閉じ括弧・句読点
+	ClassCP                    // 閉じ丸括弧
+	ClassQU                    // 引用符
+	ClassNS                    // 行頭禁則（小書き仮名・長音など）
+	ClassEX                    // ！？
+	ClassSY                    // スラッシュ
+	ClassIS                    // 数字の区切り
+	ClassPR                    // 前置記号（$など）
+	ClassPO                    // 後置記号（%など）
+	ClassNU                    // 数字
+	ClassID                    // 漢字・仮名などの表意文字
+	ClassIN                    // リーダー（…）
+	ClassHY                    // ハイフン
+	ClassBA                    // 後で改行可（タブなど）
+	ClassBB                    // 前で改行可
+	ClassB2                    // ダッシュ
+	ClassRI                    // 地域指示記号
+	ClassEB                    // 絵文字の基底
+	ClassEM                    // 絵文字修飾子
+)
+
+// 改行位置
+type Break struct {
+	Pos       int  // 次の行の先頭のバイト位置
+	Mandatory bool // 改行文字による強制改行
+}
+
+// ASCIIの改行クラス
+var asciiClasses = map[rune]BreakClass{
+	'\t': ClassBA, '\n': ClassLF, '\v': ClassBK, '\f': ClassBK, '\r': ClassCR,
+	' ': ClassSP, '!': ClassEX, '"': ClassQU, '$': ClassPR, '%': ClassPO,
+	'\'': ClassQU, '(': ClassOP, ')': ClassCP, '+': ClassPR, ',': ClassIS,
+	'-': ClassHY, '.': ClassIS, '/': ClassSY, ':': ClassIS, ';': ClassIS,
+	'?': ClassEX, '[': ClassOP, '\\': ClassPR, ']': ClassCP, '{': ClassOP,
+	'|': ClassBA, '}': ClassCL,
+}
+
+// ASCII以外で個別に指定する文字
+var runeClasses = map[rune]BreakClass{
+	0x0085: ClassNL, 0x00A0: ClassGL, 0x00AB: ClassQU, 0x00BB: ClassQU,
+	0x00A2: ClassPO, 0x00A3: ClassPR, 0x00A5: ClassPR, 0x00B0: ClassPO,
+	0x00AD: ClassBA, 0x00B4: ClassBB,
+
+	0x200B: ClassZW, 0x200D: ClassZWJ, 0x2060: ClassWJ, 0xFEFF: ClassWJ,
+	0x2007: ClassGL, 0x202F: ClassGL, 0x2010: ClassBA, 0x2012: ClassBA,
+	0x2013: ClassBA, 0x2014: ClassB2, 0x2018: ClassQU, 0x2019: ClassQU,
+	0x201C: ClassQU, 0x201D: ClassQU, 0x2024: ClassIN, 0x2025: ClassIN,
+	0x2026: ClassIN, 0x2028: ClassBK, 0x2029: ClassBK, 0x2030: ClassPO,
+	0x203C: ClassNS, 0x2047: ClassNS, 0x2048: ClassNS, 0x2049: ClassNS,
+	0x20AC: ClassPR, 0x2103: ClassPO, 0x3000: ClassBA, 0x30FB: ClassNS,
+
+	// 和文の約物
+	0x3001: ClassCL, 0x3002: ClassCL, 0x3005: ClassNS, 0x3008: ClassOP,
+	0x3009: ClassCL, 0x300A: ClassOP, 0x300B: ClassCL, 0x300C: ClassOP,
+	0x300D: ClassCL, 0x300E: ClassOP, 0x300F: ClassCL, 0x3010: ClassOP,
+	0x3011: ClassCL, 0x3014: ClassOP, 0x3015: ClassCL, 0x3016: ClassOP,
+	0x3017: ClassCL, 0x3018: ClassOP, 0x3019: ClassCL, 0x301A: ClassOP,
+	0x301B: ClassCL, 0x301C: ClassNS, 0x301D: ClassOP, 0x301E: ClassCL,
+	0x301F: ClassCL, 0x303B: ClassNS, 0x309B: ClassNS, 0x309C: ClassNS,
+	0x309D: ClassNS, 0x309E: ClassNS, 0x30A0: ClassNS, 0x30FC: ClassNS,
+	0x30FD: ClassNS, 0x30FE: ClassNS,
+
+	// 全角形
+	0xFF01: ClassEX, 0xFF04: ClassPR, 0xFF05: ClassPO, 0xFF08: ClassOP,
+	0xFF09: ClassCP, 0xFF0C: ClassCL, 0xFF0E: ClassCL, 0xFF1A: ClassNS,
+	0xFF1B: ClassNS, 0xFF1F: ClassEX, 0xFF3B: ClassOP, 0xFF3D: ClassCP,
+	0xFF5B: ClassOP, 0xFF5D: ClassCL, 0xFF5F: ClassOP, 0xFF60: ClassCL,
+	0xFF61: ClassCL, 0xFF62: ClassOP, 0xFF63: ClassCL, 0xFF64: ClassCL,
+	0xFF65: ClassNS, 0xFF70: ClassNS, 0xFF9E: ClassNS, 0xFF9F: ClassNS,
+	0xFFE0: ClassPO, 0xFFE1: ClassPR, 0xFFE5: ClassPR, 0xFFE6: ClassPR,
+
+	// オブジェクト置換文字（インラインアイコン）は前後で改行できる
+	0xFFFC: ClassID,
+}
+
+// 小書き仮名（UAX #14 ではCJ、日本語の厳格な禁則ではNSとして扱う）
+var smallKana = map[rune]bool{
+	'ぁ': true, 'ぃ': true, 'ぅ': true, 'ぇ': true, 'ぉ': true, 'っ': true,
+	'ゃ': true, 'ゅ': true, 'ょ': true, 'ゎ': true, 'ゕ': true, 'ゖ': true,
+	'ァ': true, 'ィ': true, 'ゥ': true, 'ェ': true, 'ォ': true, 'ッ': true,
+	'ャ': true, 'ュ': true, 'ョ': true, 'ヮ': true, 'ヵ': true, 'ヶ': true,
+	'ｧ': true, 'ｨ': true, 'ｩ': true, 'ｪ': true, 'ｫ': true, 'ｬ': true,
+	'ｭ': true, 'ｮ': true, 'ｯ': true,
+}
+
+// 文字の改行クラス
+func LineBreakClass(r rune) BreakClass {
+	if r < 0x80 {
+		if c, exists := asciiClasses[r]; exists {
+			return c
+		}
+		if r >= '0' && r <= '9' {
+			return ClassNU
+		}
+		if r < 0x20 || r == 0x7F {
+			return ClassCM
+		}
+		return ClassAL
+	}
+	if c, exists := runeClasses[r]; exists {
+		return c
+	}
+	if smallKana[r] || (r >= 0x31F0 && r <= 0x31FF) {
+		return ClassNS
+	}
+
+	switch {
+	case r >= 0x1F1E6 && r <= 0x1F1FF:
+		return ClassRI
+	case r >= 0x1F3FB && r <= 0x1F3FF:
+		return ClassEM
+	case r >= 0x1F466 && r <= 0x1F469, r >= 0x1F44A && r <= 0x1F450, r == 0x1F44D, r == 0x1F44E:
+		return ClassEB
+	case r >= 0x3099 && r <= 0x309A, r >= 0xFE00 && r <= 0xFE0F:
+		return ClassCM
+	case r >= 0x2E80 && r <= 0x2FFF, // 部首
+		r >= 0x3000 && r <= 0x303F,   // 和文の約物（個別指定以外）
+		r >= 0x3040 && r <= 0x30FF,   // 仮名
+		r >= 0x3130 && r <= 0x318F,   // ハングル字母
+		r >= 0x3190 && r <= 0x4DBF,   // 拡張A・漢文
+		r >= 0x4E00 && r <= 0x9FFF,   // CJK統合漢字
+		r >= 0xAC00 && r <= 0xD7A3,   // ハングル
+		r >= 0xF900 && r <= 0xFAFF,   // 互換漢字
+		r >= 0xFE30 && r <= 0xFE4F,   // 縦書き形
+		r >= 0xFF01 && r <= 0xFF60,   // 全角形
+		r >= 0xFF66 && r <= 0xFF9D,   // 半角カナ
+		r >= 0x1F000 && r <= 0x1FAFF, // 絵文字
+		r >= 0x20000 && r <= 0x3FFFD: // 拡張B以降
+		return ClassID
+	}
+
+	switch {
+	case unicode.Is(unicode.Mn, r) || unicode.Is(unicode.Me, r):
+		return ClassCM
+	case unicode.IsDigit(r):
+		return ClassNU
+	case unicode.IsSpace(r):
+		return ClassBA
+	}
+	return ClassAL
+}
+
+// 改行できる位置の一覧（先頭は含まず、末尾は必ず含む）
+func BreakOpportunities(text string) []Break {
+	var breaks []Break
+
+	prevRaw := ClassSP // 直前の文字
+	before := ClassSP  // 空白と結合文字を除いた直前の文字
+	spaces := false    // beforeの後に空白があるか
+	regional := 0      // 連続する地域指示記号の数
+	first := true
+
+	for i, r := range text {
+		cur := LineBreakClass(r)
+		if first {
+			first = false
+			prevRaw = cur
+			before = resolveStart(cur)
+			if cur == ClassRI {
+				regional = 1
+			}
+			continue
+		}
+
+		canBreak, mandatory := false, false
+		switch {
+		case prevRaw == ClassBK || prevRaw == ClassLF || prevRaw == ClassNL ||
+			(prevRaw == ClassCR && cur != ClassLF):
+			canBreak, mandatory = true, true // LB4, LB5
+		case cur == ClassBK || cur == ClassCR || cur == ClassLF || cur == ClassNL:
+			// LB6
+		case cur == ClassSP || cur == ClassZW:
+			// LB7
+		case before == ClassZW:
+			canBreak = true // LB8
+		case prevRaw == ClassZWJ:
+			// LB8a
+		case (cur == ClassCM || cur == ClassZWJ) && prevRaw != ClassSP:
+			// LB9 結合文字は直前の文字に付く
+		default:
+			after := cur
+			if after == ClassCM || after == ClassZWJ {
+				after = ClassAL // LB10
+			}
+			canBreak = pairBreak(before, after, spaces, regional)
+		}
+
+		// 日本語の禁則は UAX #14 の規則より優先する
+		if canBreak && !mandatory && !kinsokuAllows(text, i) {
+			canBreak = false
+		}
+		if canBreak {
+			breaks = append(breaks, Break{Pos: i, Mandatory: mandatory})
+		}
+
+		// 状態の更新
+		switch {
+		case mandatory:
+			before, spaces, regional = resolveStart(cur), false, 0
+		case cur == ClassSP:
+			spaces = true
+		case (cur == ClassCM || cur == ClassZWJ) && prevRaw != ClassSP && !canBreak:
+			// 直前の文字のクラスを引き継ぐ
+		default:
+			before = resolveStart(cur)
+			spaces = false
+		}
+		if cur == ClassRI {
+			regional++
+		} else if cur != ClassCM && cur != ClassZWJ {
+			regional = 0
+		}
+		prevRaw = cur
+	}
+
+	if len(text) > 0 {
+		breaks = append(breaks, Break{Pos: len(text), Mandatory: true})
+	}
+	return breaks
+}
+
+// 行頭・空白の直後に来た結合文字は英字として扱う
+func resolveStart(c BreakClass) BreakClass {
+	if c == ClassCM || c == ClassZWJ {
+		return ClassAL
+	}
+	return c
+}
+
+// 2文字の間で改行できるか（LB11〜LB31）
+func pairBreak(before, after BreakClass, spaces bool, regional int) bool {
+	switch after {
+	case ClassCL, ClassCP, ClassEX, ClassIS, ClassSY:
+		return false // LB13
+	case ClassWJ:
+		return false // LB11
+	}
+	if !spaces && (before == ClassWJ || before == ClassGL) {
+		return false // LB11, LB12
+	}
+	if after == ClassGL && !spaces && before != ClassBA && before != ClassHY {
+		return false // LB12a
+	}
+	if before == ClassOP {
+		return false // LB14
+	}
+	if before == ClassQU && after == ClassOP {
+		return false // LB15
+	}
+	if (before == ClassCL || before == ClassCP) && after == ClassNS {
+		return false // LB16
+	}
+	if before == ClassB2 && after == ClassB2 {
+		return false // LB17
+	}
+	if spaces {
+		return true // LB18
+	}
+
+	switch {
+	case before == ClassQU || after == ClassQU: // LB19
+		return false
+	case after == ClassBA || after == ClassHY || after == ClassNS || before == ClassBB: // LB21
+		return false
+	case after == ClassIN: // LB22
+		return false
+	case before == ClassAL && after == ClassNU, before == ClassNU && after == ClassAL: // LB23
+		return false
+	case before == ClassPR && (after == ClassID || after == ClassEB || after == ClassEM),
+		(before == ClassID || before == ClassEB || before == ClassEM) && after == ClassPO: // LB23a
+		return false
+	case (before == ClassPR || before == ClassPO) && after == ClassAL,
+		before == ClassAL && (after == ClassPR || after == ClassPO): // LB24
+		return false
+	case after == ClassNU && (before == ClassPR || before == ClassPO || before == ClassHY ||
+		before == ClassIS || before == ClassSY || before == ClassNU),
+		before == ClassNU && (after == ClassPO || after == ClassPR): // LB25
+		return false
+	case before == ClassAL && after == ClassAL: // LB28
+		return false
+	case before == ClassIS && after == ClassAL: // LB29
+		return false
+	case (before == ClassAL || before == ClassNU) && after == ClassOP,
+		before == ClassCP && (after == ClassAL || after == ClassNU): // LB30
+		return false
+	case before == ClassRI && after == ClassRI && regional%2 == 1: // LB30a
+		return false
+	case before == ClassEB && after == ClassEM: // LB30b
+		return false
+	}
+	return true // LB31
+}
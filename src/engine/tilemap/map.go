@@ -0,0 +1,222 @@
+package tilemap
+
+import (
+	"image"
+	"math"
+
+	"gameengine/src/engine/collision"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// GIDの上位ビットは反転フラグ
+const (
+	FlagFlipHorizontal uint32 = 0x80000000
+	FlagFlipVertical   uint32 = 0x40000000
+	FlagFlipDiagonal   uint32 = 0x20000000
+	FlagRotatedHex     uint32 = 0x10000000
+	GIDMask                   = ^(FlagFlipHorizontal | FlagFlipVertical | FlagFlipDiagonal | FlagRotatedHex)
+)
+
+// レイヤーの種類
+type LayerType int
+
+const (
+	TileLayer LayerType = iota
+	ObjectLayer
+)
+
+// タイルマップ
+type Map struct {
+	Width      int // タイル数
+	Height     int
+	TileWidth  int // ピクセル
+	TileHeight int
+	Layers     []*Layer
+	Tilesets   []*Tileset
+	Properties Properties
+}
+
+type Layer struct {
+	Name       string
+	Type       LayerType
+	Width      int
+	Height     int
+	Tiles      []uint32 // フラグ付きGID（0は空）
+	Objects    []*Object
+	Visible    bool
+	Opacity    float64
+	OffsetX    float64
+	OffsetY    float64
+	Properties Properties
+}
+
+type Tileset struct {
+	FirstGID   uint32
+	Name       string
+	TileWidth  int
+	TileHeight int
+	Columns    int
+	TileCount  int
+	Spacing    int
+	Margin     int
+	Image      *ebiten.Image
+	Tiles      map[uint32]Properties // ローカルID → タイルのプロパティ
+}
+
+// オブジェクトレイヤーのオブジェクト（スポーン地点など）
+type Object struct {
+	ID         int
+	Name       string
+	Type       string
+	X, Y       float64
+	Width      float64
+	Height     float64
+	GID        uint32
+	Properties Properties
+}
+
+// レイヤーのタイルを複製したマップ（タイルを書き換えても元のマップに影響しない）
+// タイルセットとオブジェクトは共有する
+func (m *Map) Clone() *Map {
+	clone := *m
+	clone.Layers = make([]*Layer, len(m.Layers))
+	for i, l := range m.Layers {
+		layer := *l
+		layer.Tiles = append([]uint32(nil), l.Tiles...)
+		clone.Layers[i] = &layer
+	}
+	return &clone
+}
+
+// 名前でレイヤーを取得
+func (m *Map) Layer(name string) *Layer {
+	for _, l := range m.Layers {
+		if l.Name == name {
+			return l
+		}
+	}
+	return nil
+}
+
+// オブジェクトレイヤーの全オブジェクト
+func (m *Map) Objects() []*Object {
+	var objects []*Object
+	for _, l := range m.Layers {
+		if l.Type == ObjectLayer {
+			objects = append(objects, l.Objects...)
+		}
+	}
+	return objects
+}
+
+// ピクセル座標をタイル座標に変換
+func (m *Map) WorldToTile(x, y float64) (int, int) {
+	return int(math.Floor(x / float64(m.TileWidth))), int(math.Floor(y / float64(m.TileHeight)))
+}
+
+func (m *Map) InBounds(tx, ty int) bool {
+	return tx >= 0 && ty >= 0 && tx < m.Width && ty < m.Height
+}
+
+// GIDに対応するタイルセット
+func (m *Map) TilesetFor(gid uint32) *Tileset {
+	gid &= GIDMask
+	if gid == 0 {
+		return nil
+	}
+	var found *Tileset
+	for _, ts := range m.Tilesets {
+		if ts.FirstGID <= gid && (found == nil || ts.FirstGID > found.FirstGID) {
+			found = ts
+		}
+	}
+	return found
+}
+
+// タイルのプロパティ（無い場合はnil）
+func (m *Map) TileProperties(gid uint32) Properties {
+	ts := m.TilesetFor(gid)
+	if ts == nil {
+		return nil
+	}
+	return ts.Tiles[(gid&GIDMask)-ts.FirstGID]
+}
+
+// タイルの画像
+func (m *Map) TileImage(gid uint32) *ebiten.Image {
+	ts := m.TilesetFor(gid)
+	if ts == nil || ts.Image == nil || ts.Columns <= 0 {
+		return nil
+	}
+	local := int((gid & GIDMask) - ts.FirstGID)
+	col := local % ts.Columns
+	row := local / ts.Columns
+	x := ts.Margin + col*(ts.TileWidth+ts.Spacing)
+	y := ts.Margin + row*(ts.TileHeight+ts.Spacing)
+	return ts.Image.SubImage(image.Rect(x, y, x+ts.TileWidth, y+ts.TileHeight)).(*ebiten.Image)
+}
+
+// タイル座標のGID（フラグは除去）
+func (l *Layer) TileAt(tx, ty int) uint32 {
+	if l.Type != TileLayer || tx < 0 || ty < 0 || tx >= l.Width || ty >= l.Height {
+		return 0
+	}
+	return l.Tiles[ty*l.Width+tx] & GIDMask
+}
+
+func (l *Layer) SetTile(tx, ty int, gid uint32) {
+	if l.Type != TileLayer || tx < 0 || ty < 0 || tx >= l.Width || ty >= l.Height {
+		return
+	}
+	l.Tiles[ty*l.Width+tx] = gid
+}
+
+// 通行不可のタイルか
+// レイヤーの"collision"プロパティ、またはタイルの"solid"/"collision"プロパティで判定
+func (m *Map) IsSolid(tx, ty int) bool {
+	for _, l := range m.Layers {
+		if l.Type != TileLayer {
+			continue
+		}
+		gid := l.TileAt(tx, ty)
+		if gid == 0 {
+			continue
+		}
+		if l.Properties.Bool("collision") {
+			return true
+		}
+		props := m.TileProperties(gid)
+		if props.Bool("solid") || props.Bool("collision") {
+			return true
+		}
+	}
+	return false
+}
+
+// 通行不可タイルを横方向に連結した衝突オブジェクトを作成
+func (m *Map) CollisionObjects(idPrefix string, layer, mask uint32) []*collision.CollisionObject {
+	var objects []*collision.CollisionObject
+	tw, th := float64(m.TileWidth), float64(m.TileHeight)
+
+	for ty := 0; ty < m.Height; ty++ {
+		start := -1
+		for tx := 0; tx <= m.Width; tx++ {
+			solid := tx < m.Width && m.IsSolid(tx, ty)
+			if solid && start < 0 {
+				start = tx
+			}
+			if !solid && start >= 0 {
+				objects = append(objects, &collision.CollisionObject{
+					ID:    idPrefix + "_" + itoa(start) + "_" + itoa(ty),
+					Shape: collision.NewBoxShape(float64(start)*tw, float64(ty)*th, float64(tx-start)*tw, th),
+					Layer: layer,
+					Mask:  mask,
+				})
+				start = -1
+			}
+		}
+	}
+
+	return objects
+}
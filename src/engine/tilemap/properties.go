@@ -0,0 +1,50 @@
+package tilemap
+
+import (
+	"strconv"
+)
+
+// Tiledのカスタムプロパティ
+type Properties map[string]interface{}
+
+func (p Properties) Bool(name string) bool {
+	v, ok := p[name].(bool)
+	return ok && v
+}
+
+func (p Properties) String(name string) string {
+	v, _ := p[name].(string)
+	return v
+}
+
+func (p Properties) Float(name string) float64 {
+	switch v := p[name].(type) {
+	case float64:
+		return v
+	case int:
+		return float64(v)
+	default:
+		return 0
+	}
+}
+
+// プロパティの型に応じて文字列値を変換
+func parsePropertyValue(typ, value string) interface{} {
+	switch typ {
+	case "bool":
+		b, _ := strconv.ParseBool(value)
+		return b
+	case "int":
+		i, _ := strconv.Atoi(value)
+		return i
+	case "float":
+		f, _ := strconv.ParseFloat(value, 64)
+		return f
+	default:
+		return value
+	}
+}
+
+func itoa(i int) string {
+	return strconv.Itoa(i)
+}
@@ -0,0 +1,176 @@
+package tilemap
+
+import (
+	"image"
+	"math"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// チャンク1辺のタイル数
+const ChunkSize = 16
+
+type chunkKey struct {
+	layer  int
+	cx, cy int
+}
+
+// タイルレイヤーをチャンク単位でキャッシュして描画する
+type Renderer struct {
+	tilemap *Map
+	chunks  map[chunkKey]*ebiten.Image
+}
+
+func NewRenderer(m *Map) *Renderer {
+	return &Renderer{
+		tilemap: m,
+		chunks:  make(map[chunkKey]*ebiten.Image),
+	}
+}
+
+// タイル変更時に該当チャンクを破棄
+func (r *Renderer) Invalidate(layer, tx, ty int) {
+	key := chunkKey{layer, tx / ChunkSize, ty / ChunkSize}
+	if img, exists := r.chunks[key]; exists {
+		if img != nil {
+			img.Dispose()
+		}
+		delete(r.chunks, key)
+	}
+}
+
+func (r *Renderer) InvalidateAll() {
+	for key, img := range r.chunks {
+		if img != nil {
+			img.Dispose()
+		}
+		delete(r.chunks, key)
+	}
+}
+
+// 表示中のタイルレイヤーを描画（dstの範囲外のチャンクは描画しない）
+func (r *Renderer) Draw(dst *ebiten.Image, offsetX, offsetY float64) {
+	r.Collect(dst.Bounds(), offsetX, offsetY, func(layer int, img *ebiten.Image, op *ebiten.DrawImageOptions) {
+		dst.DrawImage(img, op)
+	})
+}
+
+func (r *Renderer) DrawLayer(dst *ebiten.Image, index int, offsetX, offsetY float64) {
+	r.collectLayer(dst.Bounds(), index, offsetX, offsetY, func(layer int, img *ebiten.Image, op *ebiten.DrawImageOptions) {
+		dst.DrawImage(img, op)
+	})
+}
+
+// 表示範囲に入るチャンクをレイヤー順に列挙する（描画キューに積む用）
+func (r *Renderer) Collect(view image.Rectangle, offsetX, offsetY float64, fn func(layer int, img *ebiten.Image, op *ebiten.DrawImageOptions)) {
+	for i, layer := range r.tilemap.Layers {
+		if layer.Type == TileLayer && layer.Visible {
+			r.collectLayer(view, i, offsetX, offsetY, fn)
+		}
+	}
+}
+
+func (r *Renderer) collectLayer(view image.Rectangle, index int, offsetX, offsetY float64, fn func(layer int, img *ebiten.Image, op *ebiten.DrawImageOptions)) {
+	m := r.tilemap
+	layer := m.Layers[index]
+	ox := offsetX + layer.OffsetX
+	oy := offsetY + layer.OffsetY
+
+	chunkW := float64(ChunkSize * m.TileWidth)
+	chunkH := float64(ChunkSize * m.TileHeight)
+
+	// 画面に入るチャンクの範囲
+	minCX := int(math.Floor((float64(view.Min.X) - ox) / chunkW))
+	minCY := int(math.Floor((float64(view.Min.Y) - oy) / chunkH))
+	maxCX := int(math.Floor((float64(view.Max.X) - ox) / chunkW))
+	maxCY := int(math.Floor((float64(view.Max.Y) - oy) / chunkH))
+	lastCX := (layer.Width - 1) / ChunkSize
+	lastCY := (layer.Height - 1) / ChunkSize
+
+	for cy := maxInt(minCY, 0); cy <= minInt(maxCY, lastCY); cy++ {
+		for cx := maxInt(minCX, 0); cx <= minInt(maxCX, lastCX); cx++ {
+			img := r.chunk(index, cx, cy)
+			if img == nil {
+				continue
+			}
+			op := &ebiten.DrawImageOptions{}
+			op.GeoM.Translate(ox+float64(cx)*chunkW, oy+float64(cy)*chunkH)
+			op.ColorScale.ScaleAlpha(float32(layer.Opacity))
+			fn(index, img, op)
+		}
+	}
+}
+
+// チャンク画像の取得（未作成なら作成）
+func (r *Renderer) chunk(index, cx, cy int) *ebiten.Image {
+	key := chunkKey{index, cx, cy}
+	if img, exists := r.chunks[key]; exists {
+		return img
+	}
+
+	m := r.tilemap
+	layer := m.Layers[index]
+
+	var img *ebiten.Image
+	for ty := cy * ChunkSize; ty < minInt((cy+1)*ChunkSize, layer.Height); ty++ {
+		for tx := cx * ChunkSize; tx < minInt((cx+1)*ChunkSize, layer.Width); tx++ {
+			gid := layer.Tiles[ty*layer.Width+tx]
+			if gid&GIDMask == 0 {
+				continue
+			}
+			tile := m.TileImage(gid)
+			if tile == nil {
+				continue
+			}
+			if img == nil {
+				img = ebiten.NewImage(ChunkSize*m.TileWidth, ChunkSize*m.TileHeight)
+			}
+			x := float64((tx - cx*ChunkSize) * m.TileWidth)
+			y := float64((ty-cy*ChunkSize)*m.TileHeight + m.TileHeight)
+			img.DrawImage(tile, tileDrawOptions(gid, tile.Bounds(), x, y))
+		}
+	}
+
+	// 空のチャンクもnilとしてキャッシュして再走査を避ける
+	r.chunks[key] = img
+	return img
+}
+
+// 反転フラグを反映した描画オプション（タイルの左下をセルの左下に合わせる）
+func tileDrawOptions(gid uint32, bounds image.Rectangle, x, bottom float64) *ebiten.DrawImageOptions {
+	w, h := float64(bounds.Dx()), float64(bounds.Dy())
+	op := &ebiten.DrawImageOptions{}
+
+	if gid&FlagFlipDiagonal != 0 {
+		op.GeoM.SetElement(0, 0, 0)
+		op.GeoM.SetElement(0, 1, 1)
+		op.GeoM.SetElement(1, 0, 1)
+		op.GeoM.SetElement(1, 1, 0)
+		w, h = h, w
+	}
+	if gid&FlagFlipHorizontal != 0 {
+		op.GeoM.Scale(-1, 1)
+		op.GeoM.Translate(w, 0)
+	}
+	if gid&FlagFlipVertical != 0 {
+		op.GeoM.Scale(1, -1)
+		op.GeoM.Translate(0, h)
+	}
+
+	op.GeoM.Translate(x, bottom-h)
+	return op
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,107 @@
+package tilemap
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// マップ・タイルセット・画像の読み込み元（アセットシステムが実装する）
+type Loader interface {
+	ReadFile(path string) ([]byte, error)
+	LoadImage(path string) (*ebiten.Image, error)
+}
+
+// 拡張子に応じてTiledのマップを読み込む（.tmx / .json / .tmj）
+func Load(loader Loader, mapPath string) (*Map, error) {
+	data, err := loader.ReadFile(mapPath)
+	if err != nil {
+		return nil, err
+	}
+
+	switch strings.ToLower(path.Ext(mapPath)) {
+	case ".tmx":
+		return parseTMX(loader, mapPath, data)
+	case ".json", ".tmj":
+		return parseTiledJSON(loader, mapPath, data)
+	default:
+		return nil, fmt.Errorf("unsupported tilemap format: %s", mapPath)
+	}
+}
+
+// base64のタイルデータを展開
+func decodeTileData(encoded, compression string, count int) ([]uint32, error) {
+	raw, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode tile data: %v", err)
+	}
+
+	var r io.Reader = bytes.NewReader(raw)
+	switch compression {
+	case "":
+	case "zlib":
+		zr, err := zlib.NewReader(r)
+		if err != nil {
+			return nil, err
+		}
+		defer zr.Close()
+		r = zr
+	case "gzip":
+		gr, err := gzip.NewReader(r)
+		if err != nil {
+			return nil, err
+		}
+		defer gr.Close()
+		r = gr
+	default:
+		return nil, fmt.Errorf("unsupported tile data compression: %s", compression)
+	}
+
+	tiles := make([]uint32, count)
+	if err := binary.Read(r, binary.LittleEndian, tiles); err != nil {
+		return nil, fmt.Errorf("failed to read tile data: %v", err)
+	}
+	return tiles, nil
+}
+
+// タイルセットのタイル数・列数を画像サイズから補完
+func (ts *Tileset) complete() {
+	if ts.Image == nil || ts.TileWidth <= 0 || ts.TileHeight <= 0 {
+		return
+	}
+	w, h := ts.Image.Bounds().Dx(), ts.Image.Bounds().Dy()
+	if ts.Columns <= 0 {
+		ts.Columns = (w - ts.Margin*2 + ts.Spacing) / (ts.TileWidth + ts.Spacing)
+	}
+	if ts.TileCount <= 0 {
+		rows := (h - ts.Margin*2 + ts.Spacing) / (ts.TileHeight + ts.Spacing)
+		ts.TileCount = ts.Columns * rows
+	}
+}
+
+func resolvePath(base, rel string) string {
+	if path.IsAbs(rel) {
+		return rel
+	}
+	return path.Join(path.Dir(base), rel)
+}
+
+func validateMap(m *Map) error {
+	if m.TileWidth <= 0 || m.TileHeight <= 0 {
+		return fmt.Errorf("invalid tile size: %dx%d", m.TileWidth, m.TileHeight)
+	}
+	for _, l := range m.Layers {
+		if l.Type == TileLayer && len(l.Tiles) != l.Width*l.Height {
+			return fmt.Errorf("layer %s has %d tiles, expected %d", l.Name, len(l.Tiles), l.Width*l.Height)
+		}
+	}
+	return nil
+}
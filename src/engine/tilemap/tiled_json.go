@@ -0,0 +1,276 @@
+package tilemap
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// TiledのJSON形式（.json / .tmj / .tsj）
+type jsonMap struct {
+	Width      int            `json:"width"`
+	Height     int            `json:"height"`
+	TileWidth  int            `json:"tilewidth"`
+	TileHeight int            `json:"tileheight"`
+	Infinite   bool           `json:"infinite"`
+	Layers     []jsonLayer    `json:"layers"`
+	Tilesets   []jsonTileset  `json:"tilesets"`
+	Properties []jsonProperty `json:"properties"`
+}
+
+type jsonLayer struct {
+	Name        string          `json:"name"`
+	Type        string          `json:"type"` // tilelayer, objectgroup, group, imagelayer
+	Width       int             `json:"width"`
+	Height      int             `json:"height"`
+	Data        json.RawMessage `json:"data"`
+	Encoding    string          `json:"encoding"`
+	Compression string          `json:"compression"`
+	Objects     []jsonObject    `json:"objects"`
+	Layers      []jsonLayer     `json:"layers"`
+	Visible     *bool           `json:"visible"`
+	Opacity     *float64        `json:"opacity"`
+	OffsetX     float64         `json:"offsetx"`
+	OffsetY     float64         `json:"offsety"`
+	Properties  []jsonProperty  `json:"properties"`
+}
+
+type jsonTileset struct {
+	FirstGID   uint32         `json:"firstgid"`
+	Source     string         `json:"source"`
+	Name       string         `json:"name"`
+	TileWidth  int            `json:"tilewidth"`
+	TileHeight int            `json:"tileheight"`
+	Columns    int            `json:"columns"`
+	TileCount  int            `json:"tilecount"`
+	Spacing    int            `json:"spacing"`
+	Margin     int            `json:"margin"`
+	Image      string         `json:"image"`
+	Tiles      []jsonTileInfo `json:"tiles"`
+}
+
+type jsonTileInfo struct {
+	ID         uint32         `json:"id"`
+	Type       string         `json:"type"`
+	Class      string         `json:"class"`
+	Properties []jsonProperty `json:"properties"`
+}
+
+type jsonObject struct {
+	ID         int            `json:"id"`
+	Name       string         `json:"name"`
+	Type       string         `json:"type"`
+	Class      string         `json:"class"`
+	X          float64        `json:"x"`
+	Y          float64        `json:"y"`
+	Width      float64        `json:"width"`
+	Height     float64        `json:"height"`
+	GID        uint32         `json:"gid"`
+	Properties []jsonProperty `json:"properties"`
+}
+
+type jsonProperty struct {
+	Name  string      `json:"name"`
+	Type  string      `json:"type"`
+	Value interface{} `json:"value"`
+}
+
+func parseTiledJSON(loader Loader, mapPath string, data []byte) (*Map, error) {
+	var src jsonMap
+	if err := json.Unmarshal(data, &src); err != nil {
+		return nil, fmt.Errorf("failed to parse tilemap %s: %v", mapPath, err)
+	}
+	if src.Infinite {
+		return nil, fmt.Errorf("infinite maps are not supported: %s", mapPath)
+	}
+
+	m := &Map{
+		Width:      src.Width,
+		Height:     src.Height,
+		TileWidth:  src.TileWidth,
+		TileHeight: src.TileHeight,
+		Properties: jsonProperties(src.Properties),
+	}
+
+	for _, t := range src.Tilesets {
+		ts, err := loadJSONTileset(loader, mapPath, t)
+		if err != nil {
+			return nil, err
+		}
+		m.Tilesets = append(m.Tilesets, ts)
+	}
+
+	if err := appendJSONLayers(m, src.Layers, 0, 0); err != nil {
+		return nil, err
+	}
+
+	if err := validateMap(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// グループレイヤーは展開してオフセットを加算する
+func appendJSONLayers(m *Map, layers []jsonLayer, offsetX, offsetY float64) error {
+	for _, src := range layers {
+		visible := src.Visible == nil || *src.Visible
+		opacity := 1.0
+		if src.Opacity != nil {
+			opacity = *src.Opacity
+		}
+
+		layer := &Layer{
+			Name:       src.Name,
+			Width:      src.Width,
+			Height:     src.Height,
+			Visible:    visible,
+			Opacity:    opacity,
+			OffsetX:    offsetX + src.OffsetX,
+			OffsetY:    offsetY + src.OffsetY,
+			Properties: jsonProperties(src.Properties),
+		}
+
+		switch src.Type {
+		case "tilelayer":
+			layer.Type = TileLayer
+			tiles, err := jsonTileData(src)
+			if err != nil {
+				return fmt.Errorf("layer %s: %v", src.Name, err)
+			}
+			layer.Tiles = tiles
+
+		case "objectgroup":
+			layer.Type = ObjectLayer
+			for _, o := range src.Objects {
+				typ := o.Type
+				if typ == "" {
+					typ = o.Class
+				}
+				layer.Objects = append(layer.Objects, &Object{
+					ID:         o.ID,
+					Name:       o.Name,
+					Type:       typ,
+					X:          o.X + layer.OffsetX,
+					Y:          o.Y + layer.OffsetY,
+					Width:      o.Width,
+					Height:     o.Height,
+					GID:        o.GID,
+					Properties: jsonProperties(o.Properties),
+				})
+			}
+
+		case "group":
+			if err := appendJSONLayers(m, src.Layers, layer.OffsetX, layer.OffsetY); err != nil {
+				return err
+			}
+			continue
+
+		default:
+			// 画像レイヤーなどは未対応
+			continue
+		}
+
+		m.Layers = append(m.Layers, layer)
+	}
+	return nil
+}
+
+func jsonTileData(src jsonLayer) ([]uint32, error) {
+	if src.Encoding == "base64" {
+		var encoded string
+		if err := json.Unmarshal(src.Data, &encoded); err != nil {
+			return nil, err
+		}
+		return decodeTileData(encoded, src.Compression, src.Width*src.Height)
+	}
+
+	var tiles []uint32
+	if err := json.Unmarshal(src.Data, &tiles); err != nil {
+		return nil, err
+	}
+	return tiles, nil
+}
+
+func loadJSONTileset(loader Loader, mapPath string, src jsonTileset) (*Tileset, error) {
+	base := mapPath
+	firstGID := src.FirstGID
+
+	// 外部タイルセット
+	if src.Source != "" {
+		base = resolvePath(mapPath, src.Source)
+		data, err := loader.ReadFile(base)
+		if err != nil {
+			return nil, err
+		}
+		if isXMLTileset(base) {
+			ts, err := parseTSX(loader, base, data)
+			if err != nil {
+				return nil, err
+			}
+			ts.FirstGID = firstGID
+			return ts, nil
+		}
+		if err := json.Unmarshal(data, &src); err != nil {
+			return nil, fmt.Errorf("failed to parse tileset %s: %v", base, err)
+		}
+	}
+
+	ts := &Tileset{
+		FirstGID:   firstGID,
+		Name:       src.Name,
+		TileWidth:  src.TileWidth,
+		TileHeight: src.TileHeight,
+		Columns:    src.Columns,
+		TileCount:  src.TileCount,
+		Spacing:    src.Spacing,
+		Margin:     src.Margin,
+		Tiles:      make(map[uint32]Properties),
+	}
+
+	for _, t := range src.Tiles {
+		props := jsonProperties(t.Properties)
+		typ := t.Type
+		if typ == "" {
+			typ = t.Class
+		}
+		if typ != "" {
+			if props == nil {
+				props = make(Properties)
+			}
+			props["type"] = typ
+		}
+		if props != nil {
+			ts.Tiles[t.ID] = props
+		}
+	}
+
+	if src.Image != "" {
+		img, err := loader.LoadImage(resolvePath(base, src.Image))
+		if err != nil {
+			return nil, fmt.Errorf("failed to load tileset image %s: %v", src.Image, err)
+		}
+		ts.Image = img
+		ts.complete()
+	}
+
+	return ts, nil
+}
+
+func jsonProperties(src []jsonProperty) Properties {
+	if len(src) == 0 {
+		return nil
+	}
+	props := make(Properties, len(src))
+	for _, p := range src {
+		switch v := p.Value.(type) {
+		case float64:
+			if p.Type == "int" {
+				props[p.Name] = int(v)
+			} else {
+				props[p.Name] = v
+			}
+		default:
+			props[p.Name] = v
+		}
+	}
+	return props
+}
@@ -0,0 +1,317 @@
+package tilemap
+
+import (
+	"encoding/xml"
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// TiledのTMX形式（.tmx / .tsx）
+type tmxMap struct {
+	Width      int           `xml:"width,attr"`
+	Height     int           `xml:"height,attr"`
+	TileWidth  int           `xml:"tilewidth,attr"`
+	TileHeight int           `xml:"tileheight,attr"`
+	Infinite   int           `xml:"infinite,attr"`
+	Properties tmxProperties `xml:"properties"`
+	Children   []tmxElement  `xml:",any"` // tileset, layer, objectgroup, group を出現順に保持
+}
+
+// layer / objectgroup / group / tileset の共通要素
+type tmxElement struct {
+	XMLName    xml.Name
+	Name       string        `xml:"name,attr"`
+	Width      int           `xml:"width,attr"`
+	Height     int           `xml:"height,attr"`
+	Visible    *int          `xml:"visible,attr"`
+	Opacity    *float64      `xml:"opacity,attr"`
+	OffsetX    float64       `xml:"offsetx,attr"`
+	OffsetY    float64       `xml:"offsety,attr"`
+	Properties tmxProperties `xml:"properties"`
+	Data       *tmxData      `xml:"data"`
+	Objects    []tmxObject   `xml:"object"`
+	Children   []tmxElement  `xml:",any"`
+
+	FirstGID   uint32    `xml:"firstgid,attr"`
+	Source     string    `xml:"source,attr"`
+	TileWidth  int       `xml:"tilewidth,attr"`
+	TileHeight int       `xml:"tileheight,attr"`
+	TileCount  int       `xml:"tilecount,attr"`
+	Columns    int       `xml:"columns,attr"`
+	Spacing    int       `xml:"spacing,attr"`
+	Margin     int       `xml:"margin,attr"`
+	Image      *tmxImage `xml:"image"`
+	Tiles      []tmxTile `xml:"tile"`
+}
+
+type tmxData struct {
+	Encoding    string `xml:"encoding,attr"`
+	Compression string `xml:"compression,attr"`
+	Content     string `xml:",chardata"`
+	Tiles       []struct {
+		GID uint32 `xml:"gid,attr"`
+	} `xml:"tile"`
+}
+
+type tmxImage struct {
+	Source string `xml:"source,attr"`
+}
+
+type tmxTile struct {
+	ID         uint32        `xml:"id,attr"`
+	Type       string        `xml:"type,attr"`
+	Class      string        `xml:"class,attr"`
+	Properties tmxProperties `xml:"properties"`
+}
+
+type tmxObject struct {
+	ID         int           `xml:"id,attr"`
+	Name       string        `xml:"name,attr"`
+	Type       string        `xml:"type,attr"`
+	Class      string        `xml:"class,attr"`
+	X          float64       `xml:"x,attr"`
+	Y          float64       `xml:"y,attr"`
+	Width      float64       `xml:"width,attr"`
+	Height     float64       `xml:"height,attr"`
+	GID        uint32        `xml:"gid,attr"`
+	Properties tmxProperties `xml:"properties"`
+}
+
+type tmxProperties struct {
+	Property []struct {
+		Name    string `xml:"name,attr"`
+		Type    string `xml:"type,attr"`
+		Value   string `xml:"value,attr"`
+		Content string `xml:",chardata"` // 複数行の文字列
+	} `xml:"property"`
+}
+
+func (p tmxProperties) toProperties() Properties {
+	if len(p.Property) == 0 {
+		return nil
+	}
+	props := make(Properties, len(p.Property))
+	for _, prop := range p.Property {
+		value := prop.Value
+		if value == "" {
+			value = prop.Content
+		}
+		props[prop.Name] = parsePropertyValue(prop.Type, value)
+	}
+	return props
+}
+
+func isXMLTileset(p string) bool {
+	return strings.ToLower(path.Ext(p)) == ".tsx"
+}
+
+func parseTMX(loader Loader, mapPath string, data []byte) (*Map, error) {
+	var src tmxMap
+	if err := xml.Unmarshal(data, &src); err != nil {
+		return nil, fmt.Errorf("failed to parse tilemap %s: %v", mapPath, err)
+	}
+	if src.Infinite != 0 {
+		return nil, fmt.Errorf("infinite maps are not supported: %s", mapPath)
+	}
+
+	m := &Map{
+		Width:      src.Width,
+		Height:     src.Height,
+		TileWidth:  src.TileWidth,
+		TileHeight: src.TileHeight,
+		Properties: src.Properties.toProperties(),
+	}
+
+	if err := appendTMXElements(loader, mapPath, m, src.Children, 0, 0); err != nil {
+		return nil, err
+	}
+
+	if err := validateMap(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func appendTMXElements(loader Loader, mapPath string, m *Map, elements []tmxElement, offsetX, offsetY float64) error {
+	for _, el := range elements {
+		if el.XMLName.Local == "tileset" {
+			ts, err := loadTMXTileset(loader, mapPath, el)
+			if err != nil {
+				return err
+			}
+			m.Tilesets = append(m.Tilesets, ts)
+			continue
+		}
+
+		visible := el.Visible == nil || *el.Visible != 0
+		opacity := 1.0
+		if el.Opacity != nil {
+			opacity = *el.Opacity
+		}
+
+		layer := &Layer{
+			Name:       el.Name,
+			Width:      el.Width,
+			Height:     el.Height,
+			Visible:    visible,
+			Opacity:    opacity,
+			OffsetX:    offsetX + el.OffsetX,
+			OffsetY:    offsetY + el.OffsetY,
+			Properties: el.Properties.toProperties(),
+		}
+
+		switch el.XMLName.Local {
+		case "layer":
+			layer.Type = TileLayer
+			tiles, err := tmxTileData(el)
+			if err != nil {
+				return fmt.Errorf("layer %s: %v", el.Name, err)
+			}
+			layer.Tiles = tiles
+
+		case "objectgroup":
+			layer.Type = ObjectLayer
+			for _, o := range el.Objects {
+				typ := o.Type
+				if typ == "" {
+					typ = o.Class
+				}
+				layer.Objects = append(layer.Objects, &Object{
+					ID:         o.ID,
+					Name:       o.Name,
+					Type:       typ,
+					X:          o.X + layer.OffsetX,
+					Y:          o.Y + layer.OffsetY,
+					Width:      o.Width,
+					Height:     o.Height,
+					GID:        o.GID,
+					Properties: o.Properties.toProperties(),
+				})
+			}
+
+		case "group":
+			if err := appendTMXElements(loader, mapPath, m, el.Children, layer.OffsetX, layer.OffsetY); err != nil {
+				return err
+			}
+			continue
+
+		default:
+			continue
+		}
+
+		m.Layers = append(m.Layers, layer)
+	}
+	return nil
+}
+
+func tmxTileData(el tmxElement) ([]uint32, error) {
+	if el.Data == nil {
+		return nil, fmt.Errorf("layer has no data")
+	}
+	count := el.Width * el.Height
+
+	switch el.Data.Encoding {
+	case "base64":
+		return decodeTileData(el.Data.Content, el.Data.Compression, count)
+
+	case "csv":
+		fields := strings.FieldsFunc(el.Data.Content, func(r rune) bool {
+			return r == ',' || r == '\n' || r == '\r' || r == ' ' || r == '\t'
+		})
+		tiles := make([]uint32, 0, count)
+		for _, f := range fields {
+			gid, err := strconv.ParseUint(f, 10, 32)
+			if err != nil {
+				return nil, fmt.Errorf("invalid csv tile: %s", f)
+			}
+			tiles = append(tiles, uint32(gid))
+		}
+		return tiles, nil
+
+	case "":
+		tiles := make([]uint32, 0, count)
+		for _, t := range el.Data.Tiles {
+			tiles = append(tiles, t.GID)
+		}
+		return tiles, nil
+
+	default:
+		return nil, fmt.Errorf("unsupported tile data encoding: %s", el.Data.Encoding)
+	}
+}
+
+func loadTMXTileset(loader Loader, mapPath string, el tmxElement) (*Tileset, error) {
+	if el.Source == "" {
+		return buildTMXTileset(loader, mapPath, el)
+	}
+
+	source := resolvePath(mapPath, el.Source)
+	data, err := loader.ReadFile(source)
+	if err != nil {
+		return nil, err
+	}
+	var ts *Tileset
+	if isXMLTileset(source) {
+		ts, err = parseTSX(loader, source, data)
+	} else {
+		ts, err = loadJSONTileset(loader, mapPath, jsonTileset{Source: el.Source})
+	}
+	if err != nil {
+		return nil, err
+	}
+	ts.FirstGID = el.FirstGID
+	return ts, nil
+}
+
+// 外部タイルセット（.tsx）
+func parseTSX(loader Loader, tsxPath string, data []byte) (*Tileset, error) {
+	var el tmxElement
+	if err := xml.Unmarshal(data, &el); err != nil {
+		return nil, fmt.Errorf("failed to parse tileset %s: %v", tsxPath, err)
+	}
+	return buildTMXTileset(loader, tsxPath, el)
+}
+
+func buildTMXTileset(loader Loader, base string, el tmxElement) (*Tileset, error) {
+	ts := &Tileset{
+		FirstGID:   el.FirstGID,
+		Name:       el.Name,
+		TileWidth:  el.TileWidth,
+		TileHeight: el.TileHeight,
+		Columns:    el.Columns,
+		TileCount:  el.TileCount,
+		Spacing:    el.Spacing,
+		Margin:     el.Margin,
+		Tiles:      make(map[uint32]Properties),
+	}
+
+	for _, t := range el.Tiles {
+		props := t.Properties.toProperties()
+		typ := t.Type
+		if typ == "" {
+			typ = t.Class
+		}
+		if typ != "" {
+			if props == nil {
+				props = make(Properties)
+			}
+			props["type"] = typ
+		}
+		if props != nil {
+			ts.Tiles[t.ID] = props
+		}
+	}
+
+	if el.Image != nil && el.Image.Source != "" {
+		img, err := loader.LoadImage(resolvePath(base, el.Image.Source))
+		if err != nil {
+			return nil, fmt.Errorf("failed to load tileset image %s: %v", el.Image.Source, err)
+		}
+		ts.Image = img
+		ts.complete()
+	}
+
+	return ts, nil
+}
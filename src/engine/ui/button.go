@@ -0,0 +1,186 @@
+package ui
+
+import (
+	"image/color"
+
+	"gameengine/src/engine/i18n"
+	"gameengine/src/engine/textlayout"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/basicfont"
+)
+
+// クリック、またはフォーカスがある時のEnter・Spaceで押せるボタン
+type Button struct {
+	BaseComponent
+	Text            string
+	Key             string    // 文字列テーブルのキー（指定すると言語の切り替えで訳し直す）
+	Args            i18n.Args // キーのプレースホルダーの値
+	Font            font.Face
+	Padding         float64
+	Enabled         bool
+	TextColor       color.Color
+	DisabledColor   color.Color
+	BackgroundColor color.Color
+	HoverColor      color.Color // カーソルが乗っている時の背景
+	PressedColor    color.Color // 押している間の背景
+	FocusColor      color.Color // フォーカスがある時の枠
+	Frame           *NineSlice  // 枠の画像（背景色の代わりに描き、カーソルや押下で明るさを変える）
+	SkinName        string      // テーマの"button"に重ねるスキンの名前
+	Skin            *Skin       // このボタンだけの上書き
+
+	OnClick func()
+
+	hovered bool
+	pressed bool
+	skin    *Skin
+}
+
+func NewButton(face font.Face, text string, onClick func()) *Button {
+	b := &Button{
+		BaseComponent: BaseComponent{
+			Visible:   true,
+			ZIndex:    100,
+			Focusable: true,
+		},
+		Text:            text,
+		Font:            face,
+		Padding:         8,
+		Enabled:         true,
+		TextColor:       color.White,
+		DisabledColor:   color.RGBA{128, 128, 128, 255},
+		BackgroundColor: color.RGBA{40, 40, 60, 220},
+		HoverColor:      color.RGBA{70, 70, 100, 230},
+		PressedColor:    color.RGBA{25, 25, 40, 230},
+		FocusColor:      color.RGBA{255, 255, 0, 255},
+		OnClick:         onClick,
+	}
+	b.ApplyTheme(DefaultThemes.Current())
+	b.Fit()
+
+	b.On(EventPointerEnter, func(*Event) { b.hovered = true })
+	b.On(EventPointerLeave, func(*Event) { b.hovered = false })
+	b.On(EventPointerDown, func(e *Event) {
+		if e.Button == ebiten.MouseButtonLeft {
+			b.pressed = true
+		}
+	})
+	b.On(EventPointerUp, func(*Event) { b.pressed = false })
+	b.On(EventClick, func(e *Event) {
+		if e.Button == ebiten.MouseButtonLeft {
+			b.Click()
+		}
+	})
+	b.On(EventKeyDown, func(e *Event) {
+		if e.Key == ebiten.KeyEnter || e.Key == ebiten.KeySpace {
+			b.Click()
+		}
+	})
+	return b
+}
+
+// 押せる状態ならOnClickを呼ぶ
+func (b *Button) Click() {
+	if !b.Enabled {
+		b.skin.play("buzzer")
+		return
+	}
+	if b.AcceptsPointer() && b.OnClick != nil {
+		b.skin.play("click")
+		b.OnClick()
+	}
+}
+
+// テーマの"button"、SkinName、Skinの順に重ねた見た目にする
+// 選択色はフォーカスの枠の色になる。フォントや余白が変わった場合は大きさを合わせ直す
+func (b *Button) ApplyTheme(t *Theme) {
+	skin := t.Skin("button", b.SkinName).merge(b.Skin)
+	font, padding := b.Font, b.Padding
+	b.skin = skin
+	b.Frame = skin.Frame
+	if skin.BackgroundColor != nil {
+		b.BackgroundColor = skin.BackgroundColor
+	}
+	if skin.TextColor != nil {
+		b.TextColor = skin.TextColor
+	}
+	if skin.DisabledColor != nil {
+		b.DisabledColor = skin.DisabledColor
+	}
+	if skin.SelectedColor != nil {
+		b.FocusColor = skin.SelectedColor
+	}
+	if skin.Font != nil {
+		b.Font = skin.Font
+	}
+	if skin.Padding > 0 {
+		b.Padding = skin.Padding
+	}
+	if b.Font != font || b.Padding != padding {
+		b.Fit()
+	}
+}
+
+func (b *Button) SetText(text string) {
+	b.Text = text
+}
+
+func (b *Button) Localize(loc *i18n.Localizer) {
+	if b.Key != "" {
+		b.Text = loc.Tr(b.Key, b.Args)
+	}
+}
+
+func (b *Button) face() font.Face {
+	if b.Font != nil {
+		return b.Font
+	}
+	return basicfont.Face7x13
+}
+
+// 文字列と余白がちょうど収まる大きさにする
+func (b *Button) Fit() {
+	width, height := measureLines(b.face(), b.Text)
+	b.SetSize(width+b.Padding*2, height+b.Padding*2)
+}
+
+func (b *Button) Draw(screen *ebiten.Image) {
+	if !b.Visible {
+		return
+	}
+	x, y, w, h := float32(b.X), float32(b.Y), float32(b.Width), float32(b.Height)
+	if b.Frame != nil {
+		var cs ebiten.ColorScale
+		switch {
+		case !b.Enabled:
+			cs.Scale(0.5, 0.5, 0.5, 1)
+		case b.pressed && b.hovered:
+			cs.Scale(0.75, 0.75, 0.75, 1)
+		case b.hovered:
+			cs.Scale(1.25, 1.25, 1.25, 1)
+		}
+		b.Frame.Draw(screen, b.X, b.Y, b.Width, b.Height, cs)
+	} else {
+		background := b.BackgroundColor
+		switch {
+		case !b.Enabled:
+		case b.pressed && b.hovered:
+			background = b.PressedColor
+		case b.hovered:
+			background = b.HoverColor
+		}
+		vector.DrawFilledRect(screen, x, y, w, h, background, false)
+	}
+	if b.focused {
+		vector.StrokeRect(screen, x+1, y+1, w-2, h-2, 2, b.FocusColor, false)
+	}
+
+	textColor := b.TextColor
+	if !b.Enabled {
+		textColor = b.DisabledColor
+	}
+	_, textHeight := measureLines(b.face(), b.Text)
+	drawLines(screen, b.face(), b.Text, b.X+b.Padding, b.Y+(b.Height-textHeight)/2, b.Width-b.Padding*2, textlayout.AlignCenter, textColor)
+}
@@ -3,6 +3,8 @@ package ui
 import (
 	"image"
 
+	"gameengine/src/engine/i18n"
+
 	"github.com/hajimehoshi/ebiten/v2"
 )
 
@@ -15,6 +17,22 @@ type Component interface {
 	GetBounds() image.Rectangle
 	IsVisible() bool
 	SetVisible(bool)
+	GetZIndex() int
+}
+
+// 子コンポーネントを持つコンポーネント（描画順に並ぶ）
+type Parent interface {
+	ChildComponents() []Component
+}
+
+// 埋め込まれたBaseComponentを取り出す（フォーカスやイベントの状態はここに持つ）
+type baseAccessor interface {
+	base() *BaseComponent
+}
+
+// 言語の切り替えで文字列を訳し直すコンポーネント
+type Localizable interface {
+	Localize(loc *i18n.Localizer)
 }
 
 // 基本的なコンポーネントの実装
@@ -23,6 +41,36 @@ type BaseComponent struct {
 	Width, Height float64
 	Visible       bool
 	ZIndex        int
+
+	Modal     bool // 表示中はこれより奥のコンポーネントに入力を渡さない
+	Focusable bool // Tabやクリックでフォーカスを受け取る
+	TabOrder  int  // Tabで移る順（同じなら木の順）
+
+	Tooltip string // カーソルを止めると表示する説明（空なら表示しない）
+
+	focused   bool
+	blocked   bool // 手前のモーダルに入力を遮られている
+	managed   bool // UIManagerに登録されている（フォーカスで入力を受け取る）
+	listeners []eventListener
+}
+
+func (b *BaseComponent) base() *BaseComponent {
+	return b
+}
+
+func (b *BaseComponent) IsFocused() bool {
+	return b.focused
+}
+
+// マウスの入力を受け付けるか（手前のモーダルに遮られていない）
+func (b *BaseComponent) AcceptsPointer() bool {
+	return !b.blocked
+}
+
+// キーの入力を受け付けるか
+// UIManagerに登録されている場合はフォーカスがある時だけ
+func (b *BaseComponent) AcceptsKeys() bool {
+	return !b.managed || (!b.blocked && b.focused)
 }
 
 func (b *BaseComponent) SetPosition(x, y float64) {
@@ -42,6 +90,10 @@ func (b *BaseComponent) GetBounds() image.Rectangle {
 	)
 }
 
+func (b *BaseComponent) GetZIndex() int {
+	return b.ZIndex
+}
+
 func (b *BaseComponent) IsVisible() bool {
 	return b.Visible
 }
@@ -0,0 +1,211 @@
+package ui
+
+import (
+	"image/color"
+
+	"gameengine/src/engine/i18n"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+// 子コンポーネントをLayoutに従って並べるコンポーネント
+// 位置・大きさが変わるたびに子を並べ直す。子の描画と更新もコンテナが行う
+type Container struct {
+	BaseComponent
+	Layout          Layout
+	Padding         Insets
+	BackgroundColor color.Color // nilなら背景なし
+	AutoSize        bool        // trueなら子がちょうど収まる大きさになる
+	Frame           *NineSlice  // 枠の画像（BackgroundColorの代わりに描く）
+	SkinName        string      // テーマのスキンの名前（空ならテーマで見た目を変えない）
+	Skin            *Skin       // このコンテナだけの上書き
+
+	children []*LayoutChild
+}
+
+func NewContainer(layout Layout) *Container {
+	return &Container{
+		BaseComponent: BaseComponent{
+			Visible: true,
+			ZIndex:  100,
+		},
+		Layout: layout,
+	}
+}
+
+// 子を上から順に並べる
+func NewVStack(spacing float64) *Container {
+	return NewContainer(&FlexLayout{Direction: Vertical, Spacing: spacing, AlignItems: AlignStart})
+}
+
+// 子を左から順に並べる
+func NewHStack(spacing float64) *Container {
+	return NewContainer(&FlexLayout{Direction: Horizontal, Spacing: spacing, AlignItems: AlignStart})
+}
+
+// 子を指定した列数のセルに並べる
+func NewGrid(columns int, spacingX, spacingY float64) *Container {
+	return NewContainer(&GridLayout{Columns: columns, SpacingX: spacingX, SpacingY: spacingY})
+}
+
+// 子を親の端・中央からのずれで置く
+func NewAnchorPanel() *Container {
+	return NewContainer(&AnchorLayout{})
+}
+
+// 子を追加して並べ直す
+func (c *Container) AddChild(component Component, params LayoutParams) *LayoutChild {
+	child := newLayoutChild(component, params)
+	c.children = append(c.children, child)
+	c.Relayout()
+	return child
+}
+
+func (c *Container) RemoveChild(component Component) {
+	for i, child := range c.children {
+		if child.Component == component {
+			c.children = append(c.children[:i], c.children[i+1:]...)
+			c.Relayout()
+			return
+		}
+	}
+}
+
+func (c *Container) Children() []*LayoutChild {
+	return c.children
+}
+
+func (c *Container) ChildComponents() []Component {
+	components := make([]Component, len(c.children))
+	for i, child := range c.children {
+		components[i] = child.Component
+	}
+	return components
+}
+
+// 子の置き方を変更して並べ直す
+func (c *Container) SetParams(component Component, params LayoutParams) {
+	for _, child := range c.children {
+		if child.Component == component {
+			child.Params = params
+			c.Relayout()
+			return
+		}
+	}
+}
+
+// 子がすべて収まる大きさ（余白込み）
+// AutoSizeでなければ現在の大きさ
+func (c *Container) PreferredSize() (float64, float64) {
+	if !c.AutoSize || c.Layout == nil {
+		return c.Width, c.Height
+	}
+	w, h := c.Layout.Measure(c.children)
+	return w + c.Padding.Horizontal(), h + c.Padding.Vertical()
+}
+
+func (c *Container) SetPosition(x, y float64) {
+	c.BaseComponent.SetPosition(x, y)
+	c.Relayout()
+}
+
+func (c *Container) SetSize(width, height float64) {
+	c.BaseComponent.SetSize(width, height)
+	c.Relayout()
+}
+
+// 親のレイアウトから位置と大きさを受け取る
+func (c *Container) place(r Rect) {
+	c.X, c.Y, c.Width, c.Height = r.X, r.Y, r.Width, r.Height
+	c.arrange()
+}
+
+// 子を並べ直す（子の表示・非表示や大きさを変えた後に呼ぶ）
+// 入れ子のコンテナの大きさが変わる場合は一番外側で呼ぶ
+func (c *Container) Relayout() {
+	if c.AutoSize {
+		c.Width, c.Height = c.PreferredSize()
+	}
+	c.arrange()
+}
+
+func (c *Container) arrange() {
+	if c.Layout == nil {
+		return
+	}
+	c.Layout.Arrange(Rect{c.X, c.Y, c.Width, c.Height}.Inset(c.Padding), c.children)
+	for _, child := range c.children {
+		child.arranged = child.state()
+	}
+}
+
+// 子を更新し、表示・非表示や文字列の長さで大きさが変わった子があれば並べ直す
+func (c *Container) Update() error {
+	if !c.Visible {
+		return nil
+	}
+	changed := false
+	for _, child := range c.children {
+		if err := child.Update(); err != nil {
+			return err
+		}
+		if child.arranged != child.state() {
+			changed = true
+		}
+	}
+	if changed {
+		c.Relayout()
+	}
+	return nil
+}
+
+func (c *Container) Draw(screen *ebiten.Image) {
+	if !c.Visible {
+		return
+	}
+	if c.Frame != nil {
+		c.Frame.Draw(screen, c.X, c.Y, c.Width, c.Height, ebiten.ColorScale{})
+	} else if c.BackgroundColor != nil {
+		vector.DrawFilledRect(screen, float32(c.X), float32(c.Y), float32(c.Width), float32(c.Height), c.BackgroundColor, false)
+	}
+	for _, child := range c.children {
+		if child.IsVisible() {
+			child.Draw(screen)
+		}
+	}
+}
+
+// 子を訳し直し、文字列の長さが変わるので並べ直す
+func (c *Container) Localize(loc *i18n.Localizer) {
+	for _, child := range c.children {
+		if l, ok := child.Component.(Localizable); ok {
+			l.Localize(loc)
+		}
+	}
+	c.Relayout()
+}
+
+// SkinNameがあればそのスキンの枠・背景・余白にし、子にもテーマを適用して並べ直す
+func (c *Container) ApplyTheme(t *Theme) {
+	if c.SkinName != "" {
+		skin := t.Skin(c.SkinName).merge(c.Skin)
+		c.Frame = skin.Frame
+		if skin.BackgroundColor != nil {
+			c.BackgroundColor = skin.BackgroundColor
+		}
+		if skin.Padding > 0 {
+			c.Padding = UniformInsets(skin.Padding)
+		}
+	}
+	for _, child := range c.children {
+		if themeable, ok := child.Component.(Themeable); ok {
+			size := child.Component.GetBounds().Size()
+			themeable.ApplyTheme(t)
+			if child.Component.GetBounds().Size() != size {
+				child.ResetSize()
+			}
+		}
+	}
+	c.Relayout()
+}
@@ -0,0 +1,127 @@
+package ui
+
+import (
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// UIイベントの種類
+type EventType int
+
+const (
+	EventPointerDown  EventType = iota // マウスボタンを押した
+	EventPointerUp                     // マウスボタンを離した（押した時のコンポーネントに届く）
+	EventPointerMove                   // カーソルが動いた（押している間は押した時のコンポーネントに届く）
+	EventPointerEnter                  // カーソルが入った（伝播しない）
+	EventPointerLeave                  // カーソルが出た（伝播しない）
+	EventClick                         // 同じコンポーネントの上で押して離した
+	EventWheel                         // ホイールを回した
+	EventKeyDown                       // キーを押した（フォーカスのあるコンポーネントに届く）
+	EventKeyUp                         // キーを離した
+	EventFocus                         // フォーカスを得た（伝播しない）
+	EventBlur                          // フォーカスを失った（伝播しない）
+)
+
+// イベントが今どの段階で届いているか
+type EventPhase int
+
+const (
+	PhaseCapture EventPhase = iota // 外側から対象へ向かう途中
+	PhaseTarget                    // 対象そのもの
+	PhaseBubble                    // 対象から外側へ戻る途中
+)
+
+// コンポーネントの木を通って届くイベント
+type Event struct {
+	Type    EventType
+	Phase   EventPhase
+	Target  Component // イベントの対象（一番内側のコンポーネント）
+	Current Component // 今リスナーが呼ばれているコンポーネント
+
+	X, Y           int // カーソルのゲーム内座標
+	Button         ebiten.MouseButton
+	WheelX, WheelY float64
+	Key            ebiten.Key
+
+	stopped bool
+}
+
+// これより先（外側・内側）のコンポーネントに伝えない
+func (e *Event) StopPropagation() {
+	e.stopped = true
+}
+
+func (e *Event) Stopped() bool {
+	return e.stopped
+}
+
+// 伝播するイベントか
+func (e *Event) bubbles() bool {
+	switch e.Type {
+	case EventPointerEnter, EventPointerLeave, EventFocus, EventBlur:
+		return false
+	}
+	return true
+}
+
+type eventListener struct {
+	eventType EventType
+	capture   bool
+	fn        func(*Event)
+}
+
+// イベントのリスナーを登録する
+// captureがtrueなら外側から対象へ向かう途中で、falseなら対象から外側へ戻る途中で呼ばれる
+func (b *BaseComponent) AddEventListener(eventType EventType, capture bool, fn func(*Event)) {
+	b.listeners = append(b.listeners, eventListener{eventType, capture, fn})
+}
+
+// 対象から外側へ戻る途中のリスナーを登録する
+func (b *BaseComponent) On(eventType EventType, fn func(*Event)) {
+	b.AddEventListener(eventType, false, fn)
+}
+
+func (b *BaseComponent) fire(e *Event, capture bool) {
+	listeners := append([]eventListener(nil), b.listeners...) // リスナーの中で登録が変わってもよいように
+	for _, l := range listeners {
+		if l.eventType == e.Type && l.capture == capture {
+			l.fn(e)
+		}
+	}
+}
+
+// 外側から対象までのpathにイベントを届ける
+// 外側→対象（キャプチャ）、対象、対象→外側（バブル）の順に呼び、StopPropagationで止まる
+func dispatchEvent(path []Component, e *Event) {
+	if len(path) == 0 {
+		return
+	}
+	target := path[len(path)-1]
+	e.Target = target
+
+	notify := func(c Component, phase EventPhase, capture bool) {
+		if b, ok := c.(baseAccessor); ok {
+			e.Phase = phase
+			e.Current = c
+			b.base().fire(e, capture)
+		}
+	}
+
+	if e.bubbles() {
+		for _, c := range path[:len(path)-1] {
+			if notify(c, PhaseCapture, true); e.stopped {
+				return
+			}
+		}
+	}
+	if notify(target, PhaseTarget, true); e.stopped {
+		return
+	}
+	if notify(target, PhaseTarget, false); e.stopped || !e.bubbles() {
+		return
+	}
+	for i := len(path) - 2; i >= 0; i-- {
+		if notify(path[i], PhaseBubble, false); e.stopped {
+			return
+		}
+	}
+}
@@ -0,0 +1,113 @@
+package ui
+
+import (
+	"sort"
+)
+
+// キー入力を受け取るコンポーネントの管理
+// フォーカスの移動でEventBlur・EventFocusを送る
+type FocusManager struct {
+	focused Component
+	send    func(c Component, e *Event)
+}
+
+func newFocusManager(send func(c Component, e *Event)) *FocusManager {
+	return &FocusManager{send: send}
+}
+
+func (f *FocusManager) Focused() Component {
+	return f.focused
+}
+
+// フォーカスを移す（nilならフォーカスを外す）
+func (f *FocusManager) Focus(c Component) {
+	if c == f.focused {
+		return
+	}
+	if c != nil && !canFocus(c) {
+		return
+	}
+	previous := f.focused
+	f.focused = c
+	if previous != nil {
+		previous.(baseAccessor).base().focused = false
+		f.send(previous, &Event{Type: EventBlur})
+	}
+	if c != nil {
+		c.(baseAccessor).base().focused = true
+		f.send(c, &Event{Type: EventFocus})
+	}
+}
+
+// candidatesの中でTab順に次（dirが負なら前）のコンポーネントへ移す
+func (f *FocusManager) Move(candidates []Component, dir int) {
+	if len(candidates) == 0 {
+		return
+	}
+	current := -1
+	for i, c := range candidates {
+		if c == f.focused {
+			current = i
+			break
+		}
+	}
+	n := len(candidates)
+	next := 0
+	switch {
+	case current >= 0:
+		next = ((current+dir)%n + n) % n
+	case dir < 0:
+		next = n - 1
+	}
+	f.Focus(candidates[next])
+}
+
+// フォーカスを受け取れるか
+func canFocus(c Component) bool {
+	b, ok := c.(baseAccessor)
+	return ok && b.base().Focusable && c.IsVisible() && !b.base().blocked
+}
+
+// rootsの木からフォーカスを受け取れるものをTab順に集める
+func focusOrder(roots []Component) []Component {
+	var order []Component
+	for _, root := range roots {
+		walkVisible(root, func(c Component) {
+			if canFocus(c) {
+				order = append(order, c)
+			}
+		})
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return order[i].(baseAccessor).base().TabOrder < order[j].(baseAccessor).base().TabOrder
+	})
+	return order
+}
+
+// 表示中のコンポーネントを外側から順にたどる
+func walkVisible(c Component, fn func(Component)) {
+	if !c.IsVisible() {
+		return
+	}
+	fn(c)
+	if p, ok := c.(Parent); ok {
+		for _, child := range p.ChildComponents() {
+			walkVisible(child, fn)
+		}
+	}
+}
+
+// rootからcまでのコンポーネント（含まれなければnil）
+func pathTo(root, c Component) []Component {
+	if root == c {
+		return []Component{root}
+	}
+	if p, ok := root.(Parent); ok {
+		for _, child := range p.ChildComponents() {
+			if path := pathTo(child, c); path != nil {
+				return append([]Component{root}, path...)
+			}
+		}
+	}
+	return nil
+}
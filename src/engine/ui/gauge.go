@@ -0,0 +1,150 @@
+package ui
+
+import (
+	"image/color"
+	"math"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+// 値の割合を横棒で表示するゲージ（HPバーなど）
+type Gauge struct {
+	BaseComponent
+	Value, Max      float64
+	Color           color.Color
+	BackgroundColor color.Color
+	BorderColor     color.Color // nilなら枠なし
+
+	LowColor     color.Color // 割合がLowThreshold以下の時の色（nilなら変えない）
+	LowThreshold float64
+
+	Animated   bool        // trueなら表示を値に向けて少しずつ動かす
+	Speed      float64     // 1フレームに動く割合（0〜1）
+	DrainColor color.Color // 減った分を遅れて減らす帯の色（nilなら帯なし）
+	DrainDelay int         // 減ってから帯が減り始めるまでのフレーム数
+
+	Source func() (value, max float64) // 指定するとUpdateのたびに値を取り直す
+
+	shown   float64 // 表示している割合
+	trail   float64 // 減った分の帯の右端の割合
+	delay   int
+	started bool
+}
+
+func NewGauge(value, max float64) *Gauge {
+	return &Gauge{
+		BaseComponent: BaseComponent{
+			Visible: true,
+			ZIndex:  100,
+			Width:   120,
+			Height:  12,
+		},
+		Value:           value,
+		Max:             max,
+		Color:           color.RGBA{80, 200, 80, 255},
+		BackgroundColor: color.RGBA{0, 0, 0, 160},
+		BorderColor:     color.RGBA{255, 255, 255, 200},
+		LowThreshold:    0.25,
+		Speed:           0.02,
+		DrainColor:      color.RGBA{230, 200, 80, 255},
+		DrainDelay:      30,
+	}
+}
+
+// 0〜1の割合
+func (g *Gauge) Ratio() float64 {
+	if g.Max <= 0 {
+		return 0
+	}
+	return math.Max(0, math.Min(1, g.Value/g.Max))
+}
+
+// 表示している割合（Animatedなら値に追いつくまで遅れる）
+func (g *Gauge) DisplayRatio() float64 {
+	if !g.started {
+		return g.Ratio()
+	}
+	return g.shown
+}
+
+// アニメーションを止め、表示を今の値に合わせる
+func (g *Gauge) Snap() {
+	g.shown = g.Ratio()
+	g.trail = g.shown
+	g.delay = 0
+	g.started = true
+}
+
+func (g *Gauge) Update() error {
+	if g.Source != nil {
+		g.Value, g.Max = g.Source()
+	}
+	g.animate()
+	return nil
+}
+
+// 表示を値に近づけ、減った分の帯を遅れて減らす
+func (g *Gauge) animate() {
+	target := g.Ratio()
+	if !g.started {
+		g.Snap()
+		return
+	}
+
+	if target < g.shown && g.trail <= g.shown {
+		// 減り始めたら帯を残して少し待つ
+		g.trail = g.shown
+		g.delay = g.DrainDelay
+	}
+	if g.Animated && g.Speed > 0 {
+		g.shown = approach(g.shown, target, g.Speed)
+	} else {
+		g.shown = target
+	}
+
+	switch {
+	case g.trail <= g.shown:
+		g.trail = g.shown
+	case g.delay > 0:
+		g.delay--
+	default:
+		speed := g.Speed
+		if speed <= 0 {
+			speed = 0.02
+		}
+		g.trail = math.Max(g.shown, g.trail-speed)
+	}
+}
+
+// 現在値をtargetへstepだけ近づける
+func approach(current, target, step float64) float64 {
+	if current < target {
+		return math.Min(target, current+step)
+	}
+	return math.Max(target, current-step)
+}
+
+func (g *Gauge) Draw(screen *ebiten.Image) {
+	if !g.Visible {
+		return
+	}
+	x, y, w, h := float32(g.X), float32(g.Y), float32(g.Width), float32(g.Height)
+	vector.DrawFilledRect(screen, x, y, w, h, g.BackgroundColor, false)
+	shown := float32(g.DisplayRatio())
+	if g.DrainColor != nil && g.started {
+		if trail := float32(g.trail); trail > shown {
+			vector.DrawFilledRect(screen, x+w*shown, y, w*(trail-shown), h, g.DrainColor, false)
+		}
+	}
+	if shown > 0 {
+		fill := g.Color
+		if g.LowColor != nil && g.Ratio() <= g.LowThreshold {
+			fill = g.LowColor
+		}
+		vector.DrawFilledRect(screen, x, y, w*shown, h, fill, false)
+	}
+	if g.BorderColor != nil {
+		vector.StrokeRect(screen, x, y, w, h, 1, g.BorderColor, false)
+	}
+}
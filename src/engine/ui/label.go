@@ -0,0 +1,125 @@
+package ui
+
+import (
+	"image/color"
+	"math"
+	"strings"
+
+	"gameengine/src/engine/i18n"
+	"gameengine/src/engine/textlayout"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/text"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/basicfont"
+)
+
+// 文字列を表示するだけのコンポーネント（"\n"で改行）
+type Label struct {
+	BaseComponent
+	Text     string
+	Key      string    // 文字列テーブルのキー（指定すると言語の切り替えで訳し直す）
+	Args     i18n.Args // キーのプレースホルダーの値
+	Font     font.Face
+	Color    color.Color
+	Align    textlayout.Align // 幅の中での揃え
+	AutoSize bool             // trueなら文字列に合わせて大きさを変える
+
+	Source func() string // 指定するとUpdateのたびに文字列を取り直す（値の表示用）
+}
+
+func NewLabel(face font.Face, text string) *Label {
+	l := &Label{
+		BaseComponent: BaseComponent{
+			Visible: true,
+			ZIndex:  100,
+		},
+		Text:     text,
+		Font:     face,
+		Color:    color.White,
+		AutoSize: true,
+	}
+	l.ApplyTheme(DefaultThemes.Current())
+	l.Fit()
+	return l
+}
+
+// テーマの"label"の文字色とフォントにする
+func (l *Label) ApplyTheme(t *Theme) {
+	skin := t.Skin("label")
+	if skin.TextColor != nil {
+		l.Color = skin.TextColor
+	}
+	if skin.Font != nil && skin.Font != l.Font {
+		l.Font = skin.Font
+		if l.AutoSize {
+			l.Fit()
+		}
+	}
+}
+
+// 文字列を変え、AutoSizeなら大きさを合わせる
+func (l *Label) SetText(text string) {
+	if text == l.Text {
+		return
+	}
+	l.Text = text
+	if l.AutoSize {
+		l.Fit()
+	}
+}
+
+func (l *Label) Localize(loc *i18n.Localizer) {
+	if l.Key != "" {
+		l.SetText(loc.Tr(l.Key, l.Args))
+	}
+}
+
+func (l *Label) face() font.Face {
+	if l.Font != nil {
+		return l.Font
+	}
+	return basicfont.Face7x13
+}
+
+// 文字列がちょうど収まる大きさにする
+func (l *Label) Fit() {
+	width, height := measureLines(l.face(), l.Text)
+	l.SetSize(width, height)
+}
+
+func (l *Label) Update() error {
+	if l.Source != nil {
+		l.SetText(l.Source())
+	}
+	return nil
+}
+
+func (l *Label) Draw(screen *ebiten.Image) {
+	if !l.Visible || l.Text == "" {
+		return
+	}
+	drawLines(screen, l.face(), l.Text, l.X, l.Y, l.Width, l.Align, l.Color)
+}
+
+// 複数行の文字列の幅と高さ
+func measureLines(face font.Face, s string) (float64, float64) {
+	lines := strings.Split(s, "\n")
+	width := 0.0
+	for _, line := range lines {
+		width = math.Max(width, float64(font.MeasureString(face, line))/64)
+	}
+	return math.Ceil(width), float64(face.Metrics().Height.Ceil() * len(lines))
+}
+
+// 左上(x, y)から1行ずつ幅widthの中で揃えて描く
+func drawLines(screen *ebiten.Image, face font.Face, s string, x, y, width float64, align textlayout.Align, clr color.Color) {
+	metrics := face.Metrics()
+	lineHeight := float64(metrics.Height.Ceil())
+	for i, line := range strings.Split(s, "\n") {
+		lineWidth := float64(font.MeasureString(face, line)) / 64
+		lx := x + textlayout.AlignOffset(align, width-lineWidth)
+		baseline := y + float64(i)*lineHeight + float64(metrics.Ascent.Ceil())
+		text.Draw(screen, line, face, int(math.Round(lx)), int(math.Round(baseline)), clr)
+	}
+}
@@ -0,0 +1,407 @@
+package ui
+
+import (
+	"math"
+
+	"gameengine/src/engine/textlayout"
+)
+
+// 上下左右の余白
+type Insets struct {
+	Top, Right, Bottom, Left float64
+}
+
+// 4辺とも同じ余白
+func UniformInsets(v float64) Insets {
+	return Insets{v, v, v, v}
+}
+
+func (i Insets) Horizontal() float64 { return i.Left + i.Right }
+func (i Insets) Vertical() float64   { return i.Top + i.Bottom }
+
+// 小数の矩形（レイアウトの計算用）
+type Rect struct {
+	X, Y, Width, Height float64
+}
+
+// 余白を除いた内側
+func (r Rect) Inset(i Insets) Rect {
+	return Rect{
+		X:      r.X + i.Left,
+		Y:      r.Y + i.Top,
+		Width:  math.Max(0, r.Width-i.Horizontal()),
+		Height: math.Max(0, r.Height-i.Vertical()),
+	}
+}
+
+// 子の並べ方の揃え
+type Alignment int
+
+const (
+	AlignAuto    Alignment = iota // コンテナの指定に従う
+	AlignStart                    // 左・上
+	AlignCenter                   // 中央
+	AlignEnd                      // 右・下
+	AlignStretch                  // 幅・高さいっぱいに広げる
+)
+
+// 主軸方向の余りの配り方
+type Justify int
+
+const (
+	JustifyStart        Justify = iota // 先頭に詰める
+	JustifyCenter                      // 中央に寄せる
+	JustifyEnd                         // 末尾に詰める
+	JustifySpaceBetween                // 両端を揃えて間を均等に空ける
+	JustifySpaceAround                 // 各要素の前後を均等に空ける
+)
+
+// 並べる向き
+type Direction int
+
+const (
+	Vertical Direction = iota
+	Horizontal
+)
+
+// コンテナの中での子の置き方
+type LayoutParams struct {
+	Margin        Insets
+	Width, Height float64   // 基本の大きさ（0なら追加した時の大きさ）
+	HAlign        Alignment // グリッドのセル・アンカーパネル・横並びでの横方向の揃え
+	VAlign        Alignment // グリッドのセル・アンカーパネル・縦並びでの縦方向の揃え
+	Grow          float64   // 主軸方向の余りを分け合う比率（0なら広げない）
+
+	Anchor           textlayout.Anchor // アンカーパネル・画面での基準点
+	OffsetX, OffsetY float64           // 基準点からのずれ
+}
+
+// コンテナに追加された子
+type LayoutChild struct {
+	Component
+	Params LayoutParams
+
+	width, height float64 // 追加した時の大きさ
+	arranged      arrangedState
+}
+
+// 最後に並べた時の基本の大きさと表示状態
+type arrangedState struct {
+	width, height float64
+	visible       bool
+}
+
+func (c *LayoutChild) state() arrangedState {
+	w, h := c.Size()
+	return arrangedState{w, h, c.IsVisible()}
+}
+
+func newLayoutChild(component Component, params LayoutParams) *LayoutChild {
+	bounds := component.GetBounds()
+	return &LayoutChild{
+		Component: component,
+		Params:    params,
+		width:     float64(bounds.Dx()),
+		height:    float64(bounds.Dy()),
+	}
+}
+
+// 基本の大きさ（余白を含まない）
+func (c *LayoutChild) Size() (width, height float64) {
+	width, height = c.width, c.height
+	switch component := c.Component.(type) {
+	case *Container:
+		if component.AutoSize {
+			width, height = component.PreferredSize()
+		}
+	case *Label:
+		if component.AutoSize {
+			width, height = measureLines(component.face(), component.Text)
+		}
+	}
+	if c.Params.Width > 0 {
+		width = c.Params.Width
+	}
+	if c.Params.Height > 0 {
+		height = c.Params.Height
+	}
+	return width, height
+}
+
+// 追加した後に大きさが変わった場合に基本の大きさを取り直す
+func (c *LayoutChild) ResetSize() {
+	bounds := c.Component.GetBounds()
+	c.width, c.height = float64(bounds.Dx()), float64(bounds.Dy())
+}
+
+// 位置と大きさを決める（コンテナなら中も並べ直す）
+func (c *LayoutChild) place(r Rect) {
+	if container, ok := c.Component.(*Container); ok {
+		container.place(r)
+		return
+	}
+	c.Component.SetPosition(math.Round(r.X), math.Round(r.Y))
+	c.Component.SetSize(math.Round(r.Width), math.Round(r.Height))
+}
+
+// 子の並べ方
+type Layout interface {
+	// 子をboundsの中に置く
+	Arrange(bounds Rect, children []*LayoutChild)
+	// 子がすべて収まる大きさ
+	Measure(children []*LayoutChild) (width, height float64)
+}
+
+// 表示中の子だけ
+func visibleChildren(children []*LayoutChild) []*LayoutChild {
+	visible := make([]*LayoutChild, 0, len(children))
+	for _, child := range children {
+		if child.IsVisible() {
+			visible = append(visible, child)
+		}
+	}
+	return visible
+}
+
+// 長さlengthの範囲に大きさsizeのものを揃えて置いた時の位置と大きさ
+func alignIn(align Alignment, start, length, size float64) (float64, float64) {
+	switch align {
+	case AlignCenter:
+		return start + (length-size)/2, size
+	case AlignEnd:
+		return start + length - size, size
+	case AlignStretch:
+		return start, length
+	}
+	return start, size
+}
+
+// 縦・横に一列に並べ、余りをGrowで分け合う（Flexboxの折り返しなしに相当）
+type FlexLayout struct {
+	Direction  Direction
+	Spacing    float64   // 子の間隔
+	Justify    Justify   // 主軸方向の余りの配り方
+	AlignItems Alignment // 交差軸方向の揃え（子のHAlign/VAlignがAlignAutoの場合）
+}
+
+// 主軸・交差軸に分けた大きさ
+func (l *FlexLayout) axes(width, height float64) (main, cross float64) {
+	if l.Direction == Horizontal {
+		return width, height
+	}
+	return height, width
+}
+
+// 子の交差軸方向の揃え
+func (l *FlexLayout) crossAlign(child *LayoutChild) Alignment {
+	align := child.Params.HAlign
+	if l.Direction == Horizontal {
+		align = child.Params.VAlign
+	}
+	if align == AlignAuto {
+		align = l.AlignItems
+	}
+	return align
+}
+
+func (l *FlexLayout) Measure(children []*LayoutChild) (float64, float64) {
+	children = visibleChildren(children)
+	main, cross := 0.0, 0.0
+	for _, child := range children {
+		w, h := child.Size()
+		m, c := l.axes(w+child.Params.Margin.Horizontal(), h+child.Params.Margin.Vertical())
+		main += m
+		cross = math.Max(cross, c)
+	}
+	if len(children) > 1 {
+		main += l.Spacing * float64(len(children)-1)
+	}
+	if l.Direction == Horizontal {
+		return main, cross
+	}
+	return cross, main
+}
+
+func (l *FlexLayout) Arrange(bounds Rect, children []*LayoutChild) {
+	children = visibleChildren(children)
+	if len(children) == 0 {
+		return
+	}
+
+	// 主軸方向の大きさ（余白込み）と余り
+	boundsMain, boundsCross := l.axes(bounds.Width, bounds.Height)
+	sizes := make([]float64, len(children))
+	used, grow := l.Spacing*float64(len(children)-1), 0.0
+	for i, child := range children {
+		w, h := child.Size()
+		sizes[i], _ = l.axes(w+child.Params.Margin.Horizontal(), h+child.Params.Margin.Vertical())
+		used += sizes[i]
+		grow += child.Params.Grow
+	}
+	free := boundsMain - used
+	if free > 0 && grow > 0 {
+		for i, child := range children {
+			sizes[i] += free * child.Params.Grow / grow
+		}
+		free = 0
+	}
+
+	// 余りの配り方
+	pos, gap := 0.0, l.Spacing
+	if free > 0 {
+		switch l.Justify {
+		case JustifyCenter:
+			pos = free / 2
+		case JustifyEnd:
+			pos = free
+		case JustifySpaceBetween:
+			if len(children) > 1 {
+				gap += free / float64(len(children)-1)
+			} else {
+				pos = free / 2
+			}
+		case JustifySpaceAround:
+			extra := free / float64(len(children))
+			pos = extra / 2
+			gap += extra
+		}
+	}
+
+	for i, child := range children {
+		margin := child.Params.Margin
+		w, h := child.Size()
+		var r Rect
+		if l.Direction == Horizontal {
+			r.X = bounds.X + pos + margin.Left
+			r.Width = sizes[i] - margin.Horizontal()
+			r.Y, r.Height = alignIn(l.crossAlign(child), bounds.Y+margin.Top, boundsCross-margin.Vertical(), h)
+		} else {
+			r.Y = bounds.Y + pos + margin.Top
+			r.Height = sizes[i] - margin.Vertical()
+			r.X, r.Width = alignIn(l.crossAlign(child), bounds.X+margin.Left, boundsCross-margin.Horizontal(), w)
+		}
+		child.place(r)
+		pos += sizes[i] + gap
+	}
+}
+
+// 列数を決めて左上から順にセルへ置く
+type GridLayout struct {
+	Columns               int
+	SpacingX, SpacingY    float64
+	CellWidth, CellHeight float64 // 0なら幅は等分、高さは行で一番高い子に合わせる
+}
+
+func (l *GridLayout) columns(n int) int {
+	if l.Columns <= 0 || l.Columns > n {
+		return n
+	}
+	return l.Columns
+}
+
+// 列の幅（0なら子の最大幅）と行ごとの高さ
+func (l *GridLayout) cells(children []*LayoutChild) (width float64, heights []float64) {
+	cols := l.columns(len(children))
+	width = l.CellWidth
+	for i, child := range children {
+		w, h := child.Size()
+		if l.CellWidth <= 0 {
+			width = math.Max(width, w+child.Params.Margin.Horizontal())
+		}
+		row := i / cols
+		if row >= len(heights) {
+			heights = append(heights, l.CellHeight)
+		}
+		if l.CellHeight <= 0 {
+			heights[row] = math.Max(heights[row], h+child.Params.Margin.Vertical())
+		}
+	}
+	return width, heights
+}
+
+func (l *GridLayout) Measure(children []*LayoutChild) (float64, float64) {
+	children = visibleChildren(children)
+	if len(children) == 0 {
+		return 0, 0
+	}
+	cols := l.columns(len(children))
+	cellWidth, heights := l.cells(children)
+	width := cellWidth*float64(cols) + l.SpacingX*float64(cols-1)
+	height := l.SpacingY * float64(len(heights)-1)
+	for _, h := range heights {
+		height += h
+	}
+	return width, height
+}
+
+func (l *GridLayout) Arrange(bounds Rect, children []*LayoutChild) {
+	children = visibleChildren(children)
+	if len(children) == 0 {
+		return
+	}
+	cols := l.columns(len(children))
+	cellWidth, heights := l.cells(children)
+	if l.CellWidth <= 0 {
+		cellWidth = (bounds.Width - l.SpacingX*float64(cols-1)) / float64(cols)
+	}
+
+	y := bounds.Y
+	for i, child := range children {
+		col, row := i%cols, i/cols
+		if col == 0 && row > 0 {
+			y += heights[row-1] + l.SpacingY
+		}
+		cell := Rect{
+			X:      bounds.X + float64(col)*(cellWidth+l.SpacingX),
+			Y:      y,
+			Width:  cellWidth,
+			Height: heights[row],
+		}.Inset(child.Params.Margin)
+		w, h := child.Size()
+		var r Rect
+		r.X, r.Width = alignIn(child.Params.HAlign, cell.X, cell.Width, w)
+		r.Y, r.Height = alignIn(child.Params.VAlign, cell.Y, cell.Height, h)
+		child.place(r)
+	}
+}
+
+// 子ごとに親の基準点（Anchor）からのずれで置く
+// 子の同じ位置を基準点に合わせる（右下なら子の右下の角）。AlignStretchの向きは親いっぱいに広げる
+type AnchorLayout struct{}
+
+func (l *AnchorLayout) Measure(children []*LayoutChild) (float64, float64) {
+	width, height := 0.0, 0.0
+	for _, child := range visibleChildren(children) {
+		w, h := child.Size()
+		width = math.Max(width, w+child.Params.Margin.Horizontal()+math.Abs(child.Params.OffsetX))
+		height = math.Max(height, h+child.Params.Margin.Vertical()+math.Abs(child.Params.OffsetY))
+	}
+	return width, height
+}
+
+func (l *AnchorLayout) Arrange(bounds Rect, children []*LayoutChild) {
+	for _, child := range visibleChildren(children) {
+		child.place(anchorRect(bounds, child))
+	}
+}
+
+// 親の範囲boundsの中での子の位置と大きさ
+func anchorRect(bounds Rect, child *LayoutChild) Rect {
+	p := child.Params
+	area := bounds.Inset(p.Margin)
+	w, h := child.Size()
+	if p.HAlign == AlignStretch {
+		w = area.Width
+	}
+	if p.VAlign == AlignStretch {
+		h = area.Height
+	}
+	ax, ay := p.Anchor.Point(area.Width, area.Height)
+	px, py := p.Anchor.Point(w, h)
+	return Rect{
+		X:      area.X + ax - px + p.OffsetX,
+		Y:      area.Y + ay - py + p.OffsetY,
+		Width:  w,
+		Height: h,
+	}
+}
@@ -0,0 +1,534 @@
+package ui
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"math"
+	"strings"
+
+	"gameengine/src/engine/i18n"
+	"gameengine/src/engine/input"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/hajimehoshi/ebiten/v2/text"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/basicfont"
+)
+
+// リストのスクロールバーの幅・つまみの最小の高さ、アイコンと文字列の間
+const (
+	listScrollBarWidth = 6
+	listMinThumbHeight = 8
+	listIconSpacing    = 4
+)
+
+// リストの1行（アイテム一覧など）
+type ListItem struct {
+	Text    string
+	Key     string        // 文字列テーブルのキー（指定すると言語の切り替えで訳し直す）
+	Args    i18n.Args     // キーのプレースホルダーの値
+	Detail  string        // 右端に揃えて表示する文字列（個数など）
+	Icon    *ebiten.Image // 文字列の左に表示する
+	Enabled bool          // falseなら灰色で表示し、決定できない（選択はできる）
+	Value   interface{}   // 行に結びつける任意の値
+}
+
+// カーソルで選び、スクロールバーで送れる項目の一覧
+// 選択は上下キー（Inputを指定するとアクション）・クリック、スクロールはホイール・スクロールバーのドラッグ
+type ListView struct {
+	BaseComponent
+	Items         []*ListItem
+	SelectedIndex int
+	ScrollOffset  int // 先頭に表示している行
+	RowHeight     float64
+	Padding       float64
+	Font          font.Face
+
+	BackgroundColor  color.Color
+	TextColor        color.Color
+	DisabledColor    color.Color
+	SelectedColor    color.Color // 選択中の行の文字色
+	HighlightColor   color.Color // 選択中の行の背景
+	ScrollBarColor   color.Color
+	ScrollTrackColor color.Color
+	Frame            *NineSlice    // 枠の画像（nilならBackgroundColorで塗る）
+	Cursor           *ebiten.Image // 選択中の行の左に表示する
+
+	SkinName string // テーマの"list"に重ねるスキンの名前
+	Skin     *Skin  // このリストだけの上書き
+
+	Input    *input.InputManager // 指定すると上下・決定・キャンセルをアクションで受け付ける
+	OnSelect func(index int)     // 有効な行を決定した時
+	OnChange func(index int)     // 選択位置が変わった時
+	OnCancel func()              // キャンセルされた時
+
+	repeat    actionRepeat
+	dragging  bool    // スクロールバーのつまみをドラッグしている
+	dragStart float64 // ドラッグを始めた時のつまみの中の位置
+	skin      *Skin
+	cache     windowCache
+}
+
+// 描いた時の状態（変わったら描き直す）
+type listDrawState struct {
+	width, height               int
+	selected, scroll, rows      int
+	items                       string
+	face                        font.Face
+	background, text, disabled  color.Color
+	selectedColor, highlight    color.Color
+	scrollBarColor, scrollTrack color.Color
+	padding, rowHeight          float64
+	frame                       *NineSlice
+	cursor                      *ebiten.Image
+}
+
+func NewListView(face font.Face) *ListView {
+	l := &ListView{
+		BaseComponent: BaseComponent{
+			Visible:   true,
+			ZIndex:    110,
+			Focusable: true,
+			Width:     240,
+			Height:    160,
+		},
+		RowHeight:        24,
+		Padding:          8,
+		Font:             face,
+		BackgroundColor:  color.RGBA{0, 0, 0, 200},
+		TextColor:        color.White,
+		DisabledColor:    color.RGBA{128, 128, 128, 255},
+		SelectedColor:    color.RGBA{255, 255, 0, 255},
+		HighlightColor:   color.RGBA{255, 255, 255, 40},
+		ScrollBarColor:   color.RGBA{255, 255, 255, 160},
+		ScrollTrackColor: color.RGBA{255, 255, 255, 40},
+	}
+	l.ApplyTheme(DefaultThemes.Current())
+
+	l.On(EventPointerDown, func(e *Event) {
+		if e.Button != ebiten.MouseButtonLeft || !l.AcceptsPointer() {
+			return
+		}
+		if thumbY, thumbHeight, ok := l.thumb(); ok && l.onScrollBar(e.X) {
+			y := float64(e.Y) - l.Y
+			if y >= thumbY && y < thumbY+thumbHeight {
+				l.dragStart = y - thumbY
+			} else {
+				l.dragStart = thumbHeight / 2
+			}
+			l.dragging = true
+			l.dragTo(e.Y)
+			return
+		}
+		if index := l.ItemAt(e.X, e.Y); index >= 0 && index != l.SelectedIndex {
+			l.SetSelectedIndex(index)
+			l.skin.play("move")
+		}
+	})
+	l.On(EventPointerMove, func(e *Event) {
+		if l.dragging {
+			l.dragTo(e.Y)
+		}
+	})
+	l.On(EventPointerUp, func(*Event) { l.dragging = false })
+	l.On(EventClick, func(e *Event) {
+		if e.Button != ebiten.MouseButtonLeft || l.onScrollBar(e.X) {
+			return
+		}
+		if index := l.ItemAt(e.X, e.Y); index >= 0 && index == l.SelectedIndex {
+			l.confirm()
+		}
+	})
+	l.On(EventWheel, func(e *Event) {
+		if e.WheelY > 0 {
+			l.Scroll(-1)
+		} else if e.WheelY < 0 {
+			l.Scroll(1)
+		}
+	})
+	return l
+}
+
+// テーマの"list"、SkinName、Skinの順に重ねた見た目にする
+// 行の高さはスキンのLineHeightになる
+func (l *ListView) ApplyTheme(t *Theme) {
+	skin := t.Skin("list", l.SkinName).merge(l.Skin)
+	l.skin = skin
+	l.Frame = skin.Frame
+	l.Cursor = skin.Cursor
+	if skin.BackgroundColor != nil {
+		l.BackgroundColor = skin.BackgroundColor
+	}
+	if skin.TextColor != nil {
+		l.TextColor = skin.TextColor
+	}
+	if skin.SelectedColor != nil {
+		l.SelectedColor = skin.SelectedColor
+	}
+	if skin.DisabledColor != nil {
+		l.DisabledColor = skin.DisabledColor
+	}
+	if skin.Font != nil {
+		l.Font = skin.Font
+	}
+	if skin.Padding > 0 {
+		l.Padding = skin.Padding
+	}
+	if skin.LineHeight > 0 {
+		l.RowHeight = skin.LineHeight
+	}
+	l.clampScroll()
+	l.cache.invalidate()
+}
+
+// 次のDrawで描き直す（アイコンの画像を描き換えた時など）
+func (l *ListView) Invalidate() {
+	l.cache.invalidate()
+}
+
+func (l *ListView) AddItem(text, detail string, enabled bool) *ListItem {
+	item := &ListItem{Text: text, Detail: detail, Enabled: enabled}
+	l.Items = append(l.Items, item)
+	return item
+}
+
+// 項目を入れ替え、選択位置とスクロールを範囲に収める
+func (l *ListView) SetItems(items []*ListItem) {
+	l.Items = items
+	if l.SelectedIndex >= len(items) {
+		l.SelectedIndex = len(items) - 1
+	}
+	if l.SelectedIndex < 0 {
+		l.SelectedIndex = 0
+	}
+	l.ScrollTo(l.SelectedIndex)
+}
+
+func (l *ListView) ClearItems() {
+	l.Items = nil
+	l.SelectedIndex = 0
+	l.ScrollOffset = 0
+}
+
+// キーを持つ項目を訳し直す
+func (l *ListView) Localize(loc *i18n.Localizer) {
+	for _, item := range l.Items {
+		if item.Key != "" {
+			item.Text = loc.Tr(item.Key, item.Args)
+		}
+	}
+}
+
+// 選択中の項目（なければnil）
+func (l *ListView) Selected() *ListItem {
+	if l.SelectedIndex < 0 || l.SelectedIndex >= len(l.Items) {
+		return nil
+	}
+	return l.Items[l.SelectedIndex]
+}
+
+// 選択位置を設定し、見える位置までスクロールする（範囲外は端に収める）
+func (l *ListView) SetSelectedIndex(index int) {
+	if len(l.Items) == 0 {
+		return
+	}
+	index = int(math.Max(0, math.Min(float64(len(l.Items)-1), float64(index))))
+	changed := index != l.SelectedIndex
+	l.SelectedIndex = index
+	l.ScrollTo(index)
+	if changed && l.OnChange != nil {
+		l.OnChange(index)
+	}
+}
+
+// 選択位置をずらす（端で止まる）
+func (l *ListView) MoveSelection(delta int) {
+	l.SetSelectedIndex(l.SelectedIndex + delta)
+}
+
+// 項目が表示範囲に入るようにスクロールする
+func (l *ListView) ScrollTo(index int) {
+	rows := l.rows()
+	if index < l.ScrollOffset {
+		l.ScrollOffset = index
+	} else if index >= l.ScrollOffset+rows {
+		l.ScrollOffset = index - rows + 1
+	}
+	l.clampScroll()
+}
+
+// 表示範囲を行単位でずらす（選択位置は変えない）
+func (l *ListView) Scroll(delta int) {
+	l.ScrollOffset += delta
+	l.clampScroll()
+}
+
+// 一度に表示できる行数
+func (l *ListView) rows() int {
+	if l.RowHeight <= 0 {
+		return len(l.Items)
+	}
+	rows := int((l.Height - l.Padding*2) / l.RowHeight)
+	if rows < 1 {
+		rows = 1
+	}
+	return rows
+}
+
+func (l *ListView) clampScroll() {
+	if max := len(l.Items) - l.rows(); l.ScrollOffset > max {
+		l.ScrollOffset = max
+	}
+	if l.ScrollOffset < 0 {
+		l.ScrollOffset = 0
+	}
+}
+
+// スクロールバーを表示するか（収まらない項目がある）
+func (l *ListView) scrollable() bool {
+	return len(l.Items) > l.rows()
+}
+
+// 行の右端（スクロールバーがあればその手前）
+func (l *ListView) contentRight() float64 {
+	right := l.Width - l.Padding
+	if l.scrollable() {
+		right -= listScrollBarWidth + l.Padding/2
+	}
+	return right
+}
+
+// ゲーム内座標のxがスクロールバーの上か
+func (l *ListView) onScrollBar(x int) bool {
+	return l.scrollable() && float64(x)-l.X >= l.contentRight()
+}
+
+// つまみの位置と高さ（リストの左上から）
+func (l *ListView) thumb() (y, height float64, ok bool) {
+	if !l.scrollable() {
+		return 0, 0, false
+	}
+	track := l.Height - l.Padding*2
+	height = math.Max(listMinThumbHeight, track*float64(l.rows())/float64(len(l.Items)))
+	maxScroll := len(l.Items) - l.rows()
+	y = l.Padding + (track-height)*float64(l.ScrollOffset)/float64(maxScroll)
+	return y, height, true
+}
+
+// つまみをゲーム内座標のyまでドラッグした位置にスクロールする
+func (l *ListView) dragTo(y int) {
+	_, height, ok := l.thumb()
+	if !ok {
+		return
+	}
+	track := l.Height - l.Padding*2 - height
+	if track <= 0 {
+		return
+	}
+	ratio := (float64(y) - l.Y - l.Padding - l.dragStart) / track
+	l.ScrollOffset = int(math.Round(ratio * float64(len(l.Items)-l.rows())))
+	l.clampScroll()
+}
+
+// ゲーム内座標にある行（なければ-1）
+func (l *ListView) ItemAt(x, y int) int {
+	if !image.Pt(x, y).In(l.GetBounds()) || l.RowHeight <= 0 {
+		return -1
+	}
+	row := int(math.Floor((float64(y) - l.Y - l.Padding) / l.RowHeight))
+	if row < 0 || row >= l.rows() {
+		return -1
+	}
+	if index := l.ScrollOffset + row; index < len(l.Items) {
+		return index
+	}
+	return -1
+}
+
+func (l *ListView) face() font.Face {
+	if l.Font != nil {
+		return l.Font
+	}
+	return basicfont.Face7x13
+}
+
+// 使ったアクションをほかで扱わないようにする
+func (l *ListView) consume(action input.Action) {
+	if l.Input != nil {
+		l.Input.Consume(action)
+	}
+}
+
+// 上下で選択（押し続けると繰り返す）、PageUp・PageDown・Home・Endで大きく移動、決定、キャンセル
+func (l *ListView) Update() error {
+	if !l.Visible || len(l.Items) == 0 || !l.AcceptsKeys() {
+		l.repeat.reset()
+		return nil
+	}
+
+	selected := l.SelectedIndex
+	var up, down, ok, cancel bool
+	if l.Input != nil {
+		up = l.repeat.repeated(l.Input, input.ActionUp)
+		down = l.repeat.repeated(l.Input, input.ActionDown)
+		// マウスの左クリックもActionOKに割り当てられているので、クリックはEventClickで受ける
+		ok = l.Input.IsJustPressed(input.ActionOK) && !inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonLeft)
+		cancel = l.Input.IsJustPressed(input.ActionCancel)
+	} else {
+		up = keyRepeated(ebiten.KeyArrowUp)
+		down = keyRepeated(ebiten.KeyArrowDown)
+		ok = inpututil.IsKeyJustPressed(ebiten.KeyEnter) || inpututil.IsKeyJustPressed(ebiten.KeySpace)
+		cancel = inpututil.IsKeyJustPressed(ebiten.KeyEscape)
+	}
+
+	switch {
+	case up:
+		l.MoveSelection(-1)
+	case down:
+		l.MoveSelection(1)
+	case keyRepeated(ebiten.KeyPageUp):
+		l.MoveSelection(-l.rows())
+	case keyRepeated(ebiten.KeyPageDown):
+		l.MoveSelection(l.rows())
+	case inpututil.IsKeyJustPressed(ebiten.KeyHome):
+		l.SetSelectedIndex(0)
+	case inpututil.IsKeyJustPressed(ebiten.KeyEnd):
+		l.SetSelectedIndex(len(l.Items) - 1)
+	case ok:
+		l.consume(input.ActionOK)
+		l.confirm()
+	case cancel:
+		l.consume(input.ActionCancel)
+		if l.OnCancel != nil {
+			l.skin.play("cancel")
+		}
+		l.Cancel()
+	}
+	if l.SelectedIndex != selected {
+		l.skin.play("move")
+	}
+	return nil
+}
+
+// 選択中の行を決定する（無効な行なら"buzzer"を鳴らすだけ）
+func (l *ListView) confirm() {
+	item := l.Selected()
+	if item == nil {
+		return
+	}
+	if !item.Enabled {
+		l.skin.play("buzzer")
+		return
+	}
+	l.skin.play("select")
+	if l.OnSelect != nil {
+		l.OnSelect(l.SelectedIndex)
+	}
+}
+
+// キャンセル（OnCancelがなければ何もしない）
+func (l *ListView) Cancel() {
+	if l.OnCancel != nil {
+		l.OnCancel()
+	}
+}
+
+func (l *ListView) Draw(screen *ebiten.Image) {
+	if !l.Visible {
+		return
+	}
+	bounds := l.GetBounds()
+	if bounds.Dx() <= 0 || bounds.Dy() <= 0 {
+		return
+	}
+
+	// 前回と同じ状態なら描いておいた画像をそのまま使う
+	rows := l.rows()
+	state := listDrawState{
+		width: bounds.Dx(), height: bounds.Dy(),
+		selected: l.SelectedIndex, scroll: l.ScrollOffset, rows: rows,
+		items: l.itemsKey(), face: l.face(),
+		background: l.BackgroundColor, text: l.TextColor, disabled: l.DisabledColor,
+		selectedColor: l.SelectedColor, highlight: l.HighlightColor,
+		scrollBarColor: l.ScrollBarColor, scrollTrack: l.ScrollTrackColor,
+		padding: l.Padding, rowHeight: l.RowHeight,
+		frame: l.Frame, cursor: l.Cursor,
+	}
+	if listImage := l.cache.begin(bounds.Dx(), bounds.Dy(), state); listImage != nil {
+		l.render(listImage, rows)
+	}
+	l.cache.draw(screen, l.X, l.Y)
+}
+
+// 項目の文字列・アイコン・有効無効（描き直しの判定用）
+func (l *ListView) itemsKey() string {
+	var b strings.Builder
+	for _, item := range l.Items {
+		fmt.Fprintf(&b, "%s\x00%s\x00%p\x00%t\x00", item.Text, item.Detail, item.Icon, item.Enabled)
+	}
+	return b.String()
+}
+
+func (l *ListView) render(dst *ebiten.Image, rows int) {
+	drawWindowBackground(dst, l.Frame, l.BackgroundColor)
+
+	face := l.face()
+	metrics := face.Metrics()
+	right := l.contentRight()
+	cursorWidth := 0.0
+	if l.Cursor != nil {
+		cursorWidth = float64(l.Cursor.Bounds().Dx()) + menuCursorSpacing
+	}
+
+	for row := 0; row < rows && l.ScrollOffset+row < len(l.Items); row++ {
+		i := l.ScrollOffset + row
+		item := l.Items[i]
+		y := l.Padding + float64(row)*l.RowHeight
+		textColor := l.TextColor
+		if !item.Enabled {
+			textColor = l.DisabledColor
+		}
+		if i == l.SelectedIndex {
+			if l.HighlightColor != nil {
+				vector.DrawFilledRect(dst, float32(l.Padding), float32(y), float32(right-l.Padding), float32(l.RowHeight), l.HighlightColor, false)
+			}
+			if item.Enabled {
+				textColor = l.SelectedColor
+			}
+			if l.Cursor != nil {
+				drawCentered(dst, l.Cursor, l.Padding, y, l.RowHeight)
+			}
+		}
+
+		x := l.Padding + cursorWidth
+		if item.Icon != nil {
+			drawCentered(dst, item.Icon, x, y, l.RowHeight)
+			x += float64(item.Icon.Bounds().Dx()) + listIconSpacing
+		}
+		baseline := int(math.Round(y + (l.RowHeight-float64(metrics.Height.Ceil()))/2 + float64(metrics.Ascent.Ceil())))
+		text.Draw(dst, item.Text, face, int(x), baseline, textColor)
+		if item.Detail != "" {
+			width := float64(font.MeasureString(face, item.Detail)) / 64
+			text.Draw(dst, item.Detail, face, int(math.Round(right-width)), baseline, textColor)
+		}
+	}
+
+	// 収まらない項目があればスクロールバー
+	if thumbY, thumbHeight, ok := l.thumb(); ok {
+		x := float32(l.Width - l.Padding - listScrollBarWidth)
+		if l.ScrollTrackColor != nil {
+			vector.DrawFilledRect(dst, x, float32(l.Padding), listScrollBarWidth, float32(l.Height-l.Padding*2), l.ScrollTrackColor, false)
+		}
+		vector.DrawFilledRect(dst, x, float32(thumbY), listScrollBarWidth, float32(thumbHeight), l.ScrollBarColor, false)
+	}
+}
+
+// 画像を(x, y)から高さheightの行の縦の中央に描く
+func drawCentered(dst, img *ebiten.Image, x, y, height float64) {
+	op := &ebiten.DrawImageOptions{}
+	op.GeoM.Translate(math.Round(x), math.Round(y+(height-float64(img.Bounds().Dy()))/2))
+	dst.DrawImage(img, op)
+}
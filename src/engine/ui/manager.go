@@ -1,28 +1,128 @@
 package ui
 
 import (
+	"image"
+	"math"
 	"sort"
 	"sync"
 
+	"gameengine/src/engine/i18n"
+	"gameengine/src/engine/input"
+	"gameengine/src/engine/render"
+
 	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
 )
 
+// 登録されたコンポーネントを重なり順に描画・更新し、入力をイベントとして届ける
+// イベントの配送とフォーカスの移動はUpdateの中で行う
 type UIManager struct {
 	mutex      sync.RWMutex
 	components map[string]Component
+	added      map[string]int // 追加した順（重なり順が同じ場合に使う）
+	nextAdded  int
 	zOrder     []string
+	converter  input.CoordinateConverter
+
+	screen  Rect                    // 論理解像度の画面
+	layouts map[string]*LayoutChild // 画面を基準に置くコンポーネント
+
+	focus   *FocusManager
+	pointer pointerState
+	tooltip *Tooltip
+
+	input       *input.InputManager
+	menuContext *input.InputContext // モーダルやメニューにフォーカスがある間に積む
+	textContext *input.InputContext // 文字入力にフォーカスがある間に積む
 }
 
 func NewUIManager() *UIManager {
-	return &UIManager{
+	m := &UIManager{
 		components: make(map[string]Component),
+		added:      make(map[string]int),
+		layouts:    make(map[string]*LayoutChild),
+		pointer:    newPointerState(),
+		tooltip:    NewTooltip(nil),
+	}
+	m.focus = newFocusManager(func(c Component, e *Event) {
+		dispatchEvent(m.pathTo(c), e)
+	})
+	return m
+}
+
+// 入力コンテキストを積むマネージャーを設定する
+// モーダルが開いている間かメニュー・リストにフォーカスがある間は"menu"、文字入力にフォーカスがある間は"text"のコンテキストを積む
+func (m *UIManager) SetInputManager(im *input.InputManager) {
+	if m.input != nil {
+		m.input.RemoveContext(m.menuContext)
+		m.input.RemoveContext(m.textContext)
+	}
+	m.input = im
+	m.menuContext = input.NewMenuContext()
+	m.textContext = input.NewTextContext()
+}
+
+// フォーカスに合わせて入力コンテキストを積む・外す
+func (m *UIManager) updateInputContexts(modal bool) {
+	if m.input == nil {
+		return
+	}
+	var menu, typing bool
+	switch m.focus.Focused().(type) {
+	case *TextInput:
+		typing = true
+	case *MenuWindow, *ListView:
+		menu = true
+	}
+	m.input.SetContextActive(m.menuContext, menu || modal)
+	m.input.SetContextActive(m.textContext, typing)
+}
+
+// 画面の大きさを設定し、画面を基準に置いたコンポーネントを置き直す
+func (m *UIManager) SetScreenSize(width, height int) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.screen = Rect{Width: float64(width), Height: float64(height)}
+	for _, child := range m.layouts {
+		m.placeOnScreen(child)
+	}
+}
+
+// コンポーネントを画面の基準点（Anchor）からのずれで置く
+// 画面の大きさが変わると置き直す。AlignStretchの向きは画面いっぱいに広げる
+func (m *UIManager) SetLayoutParams(name string, params LayoutParams) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	component := m.components[name]
+	if component == nil {
+		return
+	}
+	child, exists := m.layouts[name]
+	if !exists || child.Component != component {
+		child = newLayoutChild(component, params)
+		m.layouts[name] = child
+	}
+	child.Params = params
+	if m.screen.Width > 0 && m.screen.Height > 0 {
+		m.placeOnScreen(child)
 	}
 }
 
+func (m *UIManager) placeOnScreen(child *LayoutChild) {
+	child.place(anchorRect(m.screen, child))
+	child.arranged = child.state()
+}
+
 func (m *UIManager) AddComponent(name string, component Component) {
 	m.mutex.Lock()
 	defer m.mutex.Unlock()
 
+	if _, exists := m.components[name]; !exists {
+		m.added[name] = m.nextAdded
+		m.nextAdded++
+	}
 	m.components[name] = component
 	m.updateZOrder()
 }
@@ -38,8 +138,27 @@ func (m *UIManager) RemoveComponent(name string) {
 	m.mutex.Lock()
 	defer m.mutex.Unlock()
 
+	component := m.components[name]
+	if component == nil {
+		return
+	}
 	delete(m.components, name)
+	delete(m.added, name)
+	delete(m.layouts, name)
 	m.updateZOrder()
+
+	// 外したコンポーネントの中にあったフォーカスとマウスの状態を捨てる
+	walkVisible(component, func(c Component) {
+		if b, ok := c.(baseAccessor); ok {
+			b.base().managed = false
+			b.base().blocked = false
+		}
+	})
+	if focused := m.focus.focused; focused != nil && pathTo(component, focused) != nil {
+		focused.(baseAccessor).base().focused = false
+		m.focus.focused = nil
+	}
+	m.pointer.forget(component)
 }
 
 func (m *UIManager) Draw(screen *ebiten.Image) {
@@ -51,44 +170,271 @@ func (m *UIManager) Draw(screen *ebiten.Image) {
 			component.Draw(screen)
 		}
 	}
+	m.tooltip.Draw(screen)
 }
 
+// モーダルとフォーカスを決め、マウス・キーの入力をイベントとして届けてから、奥から順に更新する
+// イベントのリスナーやUpdateの中でコンポーネントを追加・削除してもよい
 func (m *UIManager) Update() error {
+	m.mutex.Lock()
+	m.updateZOrder()
+	roots := m.ordered()
+	converter := m.converter
+	screen := m.screen
+	m.mutex.Unlock()
+
+	scope := markBlocked(roots)
+	m.updateFocus(scope)
+	m.updateInputContexts(topModal(roots) != nil)
+	m.routePointer(scope, converter)
+	m.routeKeys()
+
+	// ボタンを押すとツールチップを消す
+	if len(m.pointer.pressed) > 0 {
+		m.tooltip.Hide()
+	} else {
+		m.tooltip.track(m.pointer.hover, m.pointer.x, m.pointer.y, screen)
+	}
+
+	for _, component := range roots {
+		if err := component.Update(); err != nil {
+			return err
+		}
+	}
+
+	// 大きさが変わったものを画面の基準点に置き直す
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	if m.screen.Width > 0 && m.screen.Height > 0 {
+		for _, child := range m.layouts {
+			if child.arranged != child.state() {
+				m.placeOnScreen(child)
+			}
+		}
+	}
+	return nil
+}
+
+// 重なり順（奥から）に並べた登録済みのコンポーネント
+func (m *UIManager) ordered() []Component {
+	roots := make([]Component, 0, len(m.zOrder))
+	for _, name := range m.zOrder {
+		if component := m.components[name]; component != nil {
+			roots = append(roots, component)
+		}
+	}
+	return roots
+}
+
+// 一番手前の表示中のモーダル（なければnil）
+func topModal(roots []Component) Component {
+	for i := len(roots) - 1; i >= 0; i-- {
+		if b, ok := roots[i].(baseAccessor); ok && b.base().Modal && roots[i].IsVisible() {
+			return roots[i]
+		}
+	}
+	return nil
+}
+
+// 一番手前の表示中のモーダルより奥のコンポーネントの入力を止め、入力を受け付ける範囲を返す
+func markBlocked(roots []Component) []Component {
+	modal := topModal(roots)
+	for _, root := range roots {
+		blocked := modal != nil && root != modal
+		walkVisible(root, func(c Component) {
+			if b, ok := c.(baseAccessor); ok {
+				b.base().managed = true
+				b.base().blocked = blocked
+			}
+		})
+	}
+	if modal != nil {
+		return []Component{modal}
+	}
+	return roots
+}
+
+// 入力を受け付ける範囲の外に出たフォーカスを移し、Tab・Shift+Tabで次・前へ移す
+func (m *UIManager) updateFocus(scope []Component) {
+	order := focusOrder(scope)
+	if focused := m.focus.focused; focused != nil && !containsComponent(order, focused) {
+		m.focus.Focus(nil)
+	}
+	if m.focus.focused == nil && len(order) > 0 {
+		m.focus.Focus(order[0])
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyTab) {
+		if ebiten.IsKeyPressed(ebiten.KeyShift) {
+			m.focus.Move(order, -1)
+		} else {
+			m.focus.Move(order, 1)
+		}
+	}
+}
+
+func containsComponent(list []Component, c Component) bool {
+	for _, item := range list {
+		if item == c {
+			return true
+		}
+	}
+	return false
+}
+
+// 登録済みのコンポーネントの木の中でのcまでの道筋
+func (m *UIManager) pathTo(c Component) []Component {
+	m.mutex.RLock()
+	roots := m.ordered()
+	m.mutex.RUnlock()
+
+	for _, root := range roots {
+		if path := pathTo(root, c); path != nil {
+			return path
+		}
+	}
+	return nil
+}
+
+// フォーカスのあるコンポーネント（なければnil）
+func (m *UIManager) Focused() Component {
+	return m.focus.Focused()
+}
+
+// 名前で登録したコンポーネントにフォーカスを移す
+func (m *UIManager) Focus(name string) {
+	if component := m.GetComponent(name); component != nil {
+		m.focus.Focus(component)
+	}
+}
+
+// コンポーネント（コンテナの中のものでもよい）にフォーカスを移す
+func (m *UIManager) FocusComponent(c Component) {
+	m.focus.Focus(c)
+}
+
+// Tab順で次・前のコンポーネントにフォーカスを移す
+func (m *UIManager) FocusNext() {
+	m.mutex.RLock()
+	roots := m.ordered()
+	m.mutex.RUnlock()
+	m.focus.Move(focusOrder(markBlocked(roots)), 1)
+}
+
+func (m *UIManager) FocusPrevious() {
+	m.mutex.RLock()
+	roots := m.ordered()
+	m.mutex.RUnlock()
+	m.focus.Move(focusOrder(markBlocked(roots)), -1)
+}
+
+// 文字列キーを持つコンポーネントを現在の言語で訳し直す
+func (m *UIManager) Localize(loc *i18n.Localizer) {
 	m.mutex.RLock()
 	defer m.mutex.RUnlock()
 
 	for _, component := range m.components {
-		if err := component.Update(); err != nil {
-			return err
+		if l, ok := component.(Localizable); ok {
+			l.Localize(loc)
+		}
+	}
+}
+
+// テーマを適用し直す（コンテナの中も含む）
+func (m *UIManager) ApplyTheme(t *Theme) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	for _, component := range m.components {
+		if themeable, ok := component.(Themeable); ok {
+			themeable.ApplyTheme(t)
+		}
+	}
+	m.tooltip.ApplyTheme(t)
+}
+
+// コンポーネントのTooltipを表示するウィンドウ（見た目や表示までの時間を変えられる）
+func (m *UIManager) Tooltip() *Tooltip {
+	return m.tooltip
+}
+
+// 表示中のコンポーネントを描画キューに積む（Zは重なり順）
+func (m *UIManager) Collect(q *render.RenderQueue, layer int) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	for i, name := range m.zOrder {
+		component := m.components[name]
+		if component == nil || !component.IsVisible() {
+			continue
+		}
+		q.PushFunc(render.SortKey{Layer: layer, Z: i}, component.Draw)
+	}
+	if m.tooltip.IsVisible() {
+		q.PushFunc(render.SortKey{Layer: layer, Z: len(m.zOrder)}, m.tooltip.Draw)
+	}
+}
+
+// 画面座標からゲーム内座標への変換を設定
+func (m *UIManager) SetCoordinateConverter(converter input.CoordinateConverter) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.converter = converter
+}
+
+// ゲーム内座標にある一番手前のコンポーネント
+func (m *UIManager) HitTest(x, y int) Component {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	pt := image.Pt(x, y)
+	for i := len(m.zOrder) - 1; i >= 0; i-- {
+		component := m.components[m.zOrder[i]]
+		if component != nil && component.IsVisible() && pt.In(component.GetBounds()) {
+			return component
 		}
 	}
 	return nil
 }
 
+// ウィンドウ上の座標（カーソル位置など）で判定
+func (m *UIManager) HitTestScreen(screenX, screenY int) Component {
+	m.mutex.RLock()
+	converter := m.converter
+	m.mutex.RUnlock()
+
+	if converter == nil {
+		return m.HitTest(screenX, screenY)
+	}
+	x, y := converter.ScreenToWorld(float64(screenX), float64(screenY))
+	return m.HitTest(int(math.Floor(x)), int(math.Floor(y)))
+}
+
+// ZIndexの小さい順、同じなら追加した順
 func (m *UIManager) updateZOrder() {
 	type componentWithZ struct {
-		name    string
-		zIndex  int
-		visible bool
+		name   string
+		zIndex int
+		added  int
 	}
 
 	components := make([]componentWithZ, 0, len(m.components))
 	for name, comp := range m.components {
-		if base, ok := comp.(*BaseComponent); ok {
-			components = append(components, componentWithZ{
-				name:    name,
-				zIndex:  base.ZIndex,
-				visible: comp.IsVisible(),
-			})
-		}
+		components = append(components, componentWithZ{
+			name:   name,
+			zIndex: comp.GetZIndex(),
+			added:  m.added[name],
+		})
 	}
 
 	sort.Slice(components, func(i, j int) bool {
-		return components[i].zIndex < components[j].zIndex
+		if components[i].zIndex != components[j].zIndex {
+			return components[i].zIndex < components[j].zIndex
+		}
+		return components[i].added < components[j].added
 	})
 
 	m.zOrder = make([]string, len(components))
 	for i, comp := range components {
 		m.zOrder[i] = comp.name
 	}
-} 
\ No newline at end of file
+}
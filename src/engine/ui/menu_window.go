@@ -1,15 +1,32 @@
 package ui
 
 import (
+	"image"
 	"image/color"
+	"math"
+	"strings"
+
+	"gameengine/src/engine/i18n"
+	"gameengine/src/engine/input"
 
 	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
 	"github.com/hajimehoshi/ebiten/v2/text"
+	"github.com/hajimehoshi/ebiten/v2/vector"
 	"golang.org/x/image/font"
+	"golang.org/x/image/font/basicfont"
 )
 
+// スクロールバーの幅
+const menuScrollBarWidth = 4
+
+// カーソル画像と項目の文字列の間
+const menuCursorSpacing = 4
+
 type MenuItem struct {
 	Text     string
+	Key      string    // 文字列テーブルのキー（指定すると言語の切り替えで訳し直す）
+	Args     i18n.Args // キーのプレースホルダーの値
 	Enabled  bool
 	OnSelect func()
 }
@@ -21,26 +38,131 @@ type MenuWindow struct {
 	TextColor       color.Color
 	DisabledColor   color.Color
 	SelectedColor   color.Color
+	ScrollBarColor  color.Color
 	Padding         float64
 	Items           []*MenuItem
 	SelectedIndex   int
 	LineHeight      float64
+	VisibleRows     int           // 一度に表示する項目数（0なら高さに収まるだけ）
+	ScrollOffset    int           // 先頭に表示している項目
+	Frame           *NineSlice    // 枠の画像（nilならBackgroundColorで塗る）
+	Cursor          *ebiten.Image // 選択中の項目の左に表示する
+
+	SkinName string // テーマの"menu"に重ねるスキンの名前
+	Skin     *Skin  // このウィンドウだけの上書き
+
+	Input    *input.InputManager // 指定するとUpdateでキー・マウスの入力を受け付ける
+	OnCancel func()              // キャンセルされた時（nilならキャンセルできない）
+	OnChange func(index int)     // 選択位置が変わった時
+
+	repeat actionRepeat // 押し続けた時の選択移動
+
+	skin  *Skin
+	cache windowCache
+}
+
+// 描いた時の状態（変わったら描き直す）
+type menuDrawState struct {
+	width, height                 int
+	selected, scroll, rows        int
+	items                         string
+	face                          font.Face
+	background, text, disabled    color.Color
+	selectedColor, scrollBarColor color.Color
+	padding, lineHeight           float64
+	frame                         *NineSlice
+	cursor                        *ebiten.Image
 }
 
 func NewMenuWindow(font font.Face) *MenuWindow {
-	return &MenuWindow{
+	w := &MenuWindow{
 		BaseComponent: BaseComponent{
-			Visible: true,
-			ZIndex:  110,
+			Visible:   true,
+			ZIndex:    110,
+			Focusable: true,
 		},
 		Font:            font,
 		BackgroundColor: color.RGBA{0, 0, 0, 200},
 		TextColor:       color.White,
 		DisabledColor:   color.RGBA{128, 128, 128, 255},
 		SelectedColor:   color.RGBA{255, 255, 0, 255},
+		ScrollBarColor:  color.RGBA{255, 255, 255, 128},
 		Padding:         10,
 		LineHeight:      24,
 	}
+	w.ApplyTheme(DefaultThemes.Current())
+	return w
+}
+
+// テーマの"menu"、SkinName、Skinの順に重ねた見た目にする
+// 項目があり、フォントや余白が変わった場合は大きさを合わせ直す
+func (w *MenuWindow) ApplyTheme(t *Theme) {
+	skin := t.Skin("menu", w.SkinName).merge(w.Skin)
+	font, padding, lineHeight := w.Font, w.Padding, w.LineHeight
+	w.skin = skin
+	w.Frame = skin.Frame
+	w.Cursor = skin.Cursor
+	if skin.BackgroundColor != nil {
+		w.BackgroundColor = skin.BackgroundColor
+	}
+	if skin.TextColor != nil {
+		w.TextColor = skin.TextColor
+	}
+	if skin.SelectedColor != nil {
+		w.SelectedColor = skin.SelectedColor
+	}
+	if skin.DisabledColor != nil {
+		w.DisabledColor = skin.DisabledColor
+	}
+	if skin.Font != nil {
+		w.Font = skin.Font
+	}
+	if skin.Padding > 0 {
+		w.Padding = skin.Padding
+	}
+	if skin.LineHeight > 0 {
+		w.LineHeight = skin.LineHeight
+	}
+	if len(w.Items) > 0 && (w.Font != font || w.Padding != padding || w.LineHeight != lineHeight) {
+		w.FitToItems()
+	}
+	w.cache.invalidate()
+}
+
+// 以下の設定はSkinにも書き込み、テーマを切り替えても残す
+func (w *MenuWindow) SetBackgroundColor(c color.Color) {
+	overrides(&w.Skin).BackgroundColor = c
+	w.BackgroundColor = c
+	w.cache.invalidate()
+}
+
+func (w *MenuWindow) SetTextColor(c color.Color) {
+	overrides(&w.Skin).TextColor = c
+	w.TextColor = c
+	w.cache.invalidate()
+}
+
+func (w *MenuWindow) SetSelectedColor(c color.Color) {
+	overrides(&w.Skin).SelectedColor = c
+	w.SelectedColor = c
+	w.cache.invalidate()
+}
+
+func (w *MenuWindow) SetPadding(padding float64) {
+	overrides(&w.Skin).Padding = padding
+	w.Padding = padding
+	w.FitToItems()
+}
+
+func (w *MenuWindow) SetLineHeight(height float64) {
+	overrides(&w.Skin).LineHeight = height
+	w.LineHeight = height
+	w.FitToItems()
+}
+
+// 次のDrawで描き直す
+func (w *MenuWindow) Invalidate() {
+	w.cache.invalidate()
 }
 
 func (w *MenuWindow) AddItem(text string, enabled bool, onSelect func()) {
@@ -51,51 +173,310 @@ func (w *MenuWindow) AddItem(text string, enabled bool, onSelect func()) {
 	})
 }
 
+// 文字列テーブルのキーで項目を追加する
+func (w *MenuWindow) AddLocalizedItem(key string, enabled bool, onSelect func()) {
+	w.Items = append(w.Items, &MenuItem{
+		Text:     i18n.Tr(key),
+		Key:      key,
+		Enabled:  enabled,
+		OnSelect: onSelect,
+	})
+}
+
+// キーを持つ項目を訳し直す（文字列が変わったら大きさを合わせ直す）
+func (w *MenuWindow) Localize(loc *i18n.Localizer) {
+	changed := false
+	for _, item := range w.Items {
+		if item.Key == "" {
+			continue
+		}
+		if text := loc.Tr(item.Key, item.Args); text != item.Text {
+			item.Text = text
+			changed = true
+		}
+	}
+	if changed {
+		w.FitToItems()
+		w.cache.invalidate()
+	}
+}
+
 func (w *MenuWindow) ClearItems() {
 	w.Items = nil
 	w.SelectedIndex = 0
+	w.ScrollOffset = 0
+}
+
+// 選択位置を移動する（無効な項目は飛ばし、端では反対側に回る）
+func (w *MenuWindow) MoveSelection(delta int) {
+	n := len(w.Items)
+	if n == 0 || delta == 0 {
+		return
+	}
+	index := w.SelectedIndex
+	for i := 0; i < n; i++ {
+		index = ((index+delta)%n + n) % n
+		if w.Items[index].Enabled {
+			w.SetSelectedIndex(index)
+			return
+		}
+	}
+}
+
+// 選択位置を設定し、見える位置までスクロールする
+func (w *MenuWindow) SetSelectedIndex(index int) {
+	if index < 0 || index >= len(w.Items) {
+		return
+	}
+	changed := index != w.SelectedIndex
+	w.SelectedIndex = index
+	w.ScrollTo(index)
+	if changed && w.OnChange != nil {
+		w.OnChange(index)
+	}
+}
+
+// 項目が表示範囲に入るようにスクロールする
+func (w *MenuWindow) ScrollTo(index int) {
+	rows := w.rows()
+	if index < w.ScrollOffset {
+		w.ScrollOffset = index
+	} else if index >= w.ScrollOffset+rows {
+		w.ScrollOffset = index - rows + 1
+	}
+	w.clampScroll()
 }
 
+// 一度に表示できる項目数
+func (w *MenuWindow) rows() int {
+	rows := w.VisibleRows
+	if rows <= 0 && w.LineHeight > 0 {
+		rows = int((w.Height - w.Padding*2) / w.LineHeight)
+	}
+	if rows <= 0 || rows > len(w.Items) {
+		rows = len(w.Items)
+	}
+	return rows
+}
+
+func (w *MenuWindow) clampScroll() {
+	if max := len(w.Items) - w.rows(); w.ScrollOffset > max {
+		w.ScrollOffset = max
+	}
+	if w.ScrollOffset < 0 {
+		w.ScrollOffset = 0
+	}
+}
+
+// ゲーム内座標にある項目（なければ-1）
+func (w *MenuWindow) ItemAt(x, y int) int {
+	if !image.Pt(x, y).In(w.GetBounds()) || w.LineHeight <= 0 {
+		return -1
+	}
+	row := int(math.Floor((float64(y) - w.Y - w.Padding) / w.LineHeight))
+	if row < 0 || row >= w.rows() {
+		return -1
+	}
+	if index := w.ScrollOffset + row; index < len(w.Items) {
+		return index
+	}
+	return -1
+}
+
+// 項目がちょうど収まる大きさにする（VisibleRowsがあればその行数分の高さ）
+func (w *MenuWindow) FitToItems() {
+	width := 0.0
+	for _, item := range w.Items {
+		if iw := float64(font.MeasureString(w.face(), item.Text)) / 64; iw > width {
+			width = iw
+		}
+	}
+	width += w.cursorWidth()
+	rows := len(w.Items)
+	if w.VisibleRows > 0 && w.VisibleRows < rows {
+		rows = w.VisibleRows
+		width += menuScrollBarWidth + w.Padding/2
+	}
+	w.SetSize(width+w.Padding*2, float64(rows)*w.LineHeight+w.Padding*2)
+	w.clampScroll()
+}
+
+// カーソル画像の分だけ項目の文字列を右にずらす
+func (w *MenuWindow) cursorWidth() float64 {
+	if w.Cursor == nil {
+		return 0
+	}
+	return float64(w.Cursor.Bounds().Dx()) + menuCursorSpacing
+}
+
+// フォントが未設定なら組み込みのフォントを使う
+func (w *MenuWindow) face() font.Face {
+	if w.Font != nil {
+		return w.Font
+	}
+	return basicfont.Face7x13
+}
+
+// 上下で選択（押し続けると繰り返す）、決定、キャンセル、マウスでの選択とスクロール
 func (w *MenuWindow) Update() error {
-	// キー入力処理
-	// TODO: 入力システムとの連携
+	if !w.Visible || w.Input == nil || len(w.Items) == 0 {
+		w.repeat.reset()
+		return nil
+	}
+	// Itemsが直接縮められた場合
+	if w.SelectedIndex < 0 || w.SelectedIndex >= len(w.Items) {
+		w.SetSelectedIndex(clampInt(w.SelectedIndex, 0, len(w.Items)-1))
+	}
+	if !w.Items[w.SelectedIndex].Enabled {
+		w.MoveSelection(1)
+	}
+
+	// マウスの左クリックもActionOKに割り当てられているので、クリックは位置で判定する
+	clicked := inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonLeft)
+	if mouse := w.Input.Mouse(); mouse != nil && w.AcceptsPointer() {
+		hovered := w.ItemAt(mouse.X, mouse.Y)
+		moved := mouse.X != mouse.PrevX || mouse.Y != mouse.PrevY
+		if hovered >= 0 && w.Items[hovered].Enabled && (moved || clicked) && hovered != w.SelectedIndex {
+			w.SetSelectedIndex(hovered)
+			w.skin.play("move")
+		}
+		if mouse.ScrollY != 0 && image.Pt(mouse.X, mouse.Y).In(w.GetBounds()) {
+			if mouse.ScrollY > 0 {
+				w.ScrollOffset--
+			} else {
+				w.ScrollOffset++
+			}
+			w.clampScroll()
+		}
+		if clicked && hovered >= 0 {
+			w.confirm(hovered)
+			return nil
+		}
+	}
+
+	if !w.AcceptsKeys() {
+		w.repeat.reset()
+		return nil
+	}
+	selected := w.SelectedIndex
+	switch {
+	case w.repeat.repeated(w.Input, input.ActionUp):
+		w.MoveSelection(-1)
+	case w.repeat.repeated(w.Input, input.ActionDown):
+		w.MoveSelection(1)
+	case w.Input.IsJustPressed(input.ActionOK) && !clicked:
+		w.Input.Consume(input.ActionOK)
+		w.confirm(w.SelectedIndex)
+	case w.Input.IsJustPressed(input.ActionCancel):
+		w.Input.Consume(input.ActionCancel)
+		if w.OnCancel != nil {
+			w.skin.play("cancel")
+		}
+		w.Cancel()
+	}
+	if w.SelectedIndex != selected {
+		w.skin.play("move")
+	}
 	return nil
 }
 
+// 入力で項目を決定する（無効な項目なら"buzzer"を鳴らすだけ）
+func (w *MenuWindow) confirm(index int) {
+	if !w.Items[index].Enabled {
+		w.skin.play("buzzer")
+		return
+	}
+	w.skin.play("select")
+	w.Select()
+}
+
+// キャンセル（OnCancelがなければ何もしない）
+func (w *MenuWindow) Cancel() {
+	if w.OnCancel != nil {
+		w.OnCancel()
+	}
+}
+
 func (w *MenuWindow) Draw(screen *ebiten.Image) {
 	if !w.Visible {
 		return
 	}
-
-	// 背景描画
 	bounds := w.GetBounds()
-	windowImage := ebiten.NewImage(bounds.Dx(), bounds.Dy())
-	windowImage.Fill(w.BackgroundColor)
+	if bounds.Dx() <= 0 || bounds.Dy() <= 0 {
+		return
+	}
+
+	// 前回と同じ状態なら描いておいた画像をそのまま使う
+	rows := w.rows()
+	state := menuDrawState{
+		width: bounds.Dx(), height: bounds.Dy(),
+		selected: w.SelectedIndex, scroll: w.ScrollOffset, rows: rows,
+		items: w.itemsKey(), face: w.face(),
+		background: w.BackgroundColor, text: w.TextColor, disabled: w.DisabledColor,
+		selectedColor: w.SelectedColor, scrollBarColor: w.ScrollBarColor,
+		padding: w.Padding, lineHeight: w.LineHeight,
+		frame: w.Frame, cursor: w.Cursor,
+	}
+	if windowImage := w.cache.begin(bounds.Dx(), bounds.Dy(), state); windowImage != nil {
+		w.render(windowImage, rows)
+	}
+	w.cache.draw(screen, w.X, w.Y)
+}
 
-	// メニュー項目描画
-	for i, item := range w.Items {
-		y := w.Padding + float64(i)*w.LineHeight
+// 項目の文字列と有効・無効（描き直しの判定用）
+func (w *MenuWindow) itemsKey() string {
+	var b strings.Builder
+	for _, item := range w.Items {
+		b.WriteString(item.Text)
+		if item.Enabled {
+			b.WriteByte(1)
+		} else {
+			b.WriteByte(0)
+		}
+	}
+	return b.String()
+}
+
+func (w *MenuWindow) render(windowImage *ebiten.Image, rows int) {
+	drawWindowBackground(windowImage, w.Frame, w.BackgroundColor)
+
+	// メニュー項目描画（表示範囲の項目だけ）
+	textX := w.Padding + w.cursorWidth()
+	for row := 0; row < rows && w.ScrollOffset+row < len(w.Items); row++ {
+		i := w.ScrollOffset + row
+		item := w.Items[i]
+		y := w.Padding + float64(row)*w.LineHeight
 		textColor := w.TextColor
 		if !item.Enabled {
 			textColor = w.DisabledColor
 		}
 		if i == w.SelectedIndex {
 			textColor = w.SelectedColor
+			if w.Cursor != nil {
+				op := &ebiten.DrawImageOptions{}
+				op.GeoM.Translate(w.Padding, math.Round(y+(w.LineHeight-float64(w.Cursor.Bounds().Dy()))/2))
+				windowImage.DrawImage(w.Cursor, op)
+			}
 		}
 
 		text.Draw(
 			windowImage,
 			item.Text,
-			w.Font,
-			int(w.Padding),
+			w.face(),
+			int(textX),
 			int(y+w.LineHeight),
 			textColor,
 		)
 	}
 
-	op := &ebiten.DrawImageOptions{}
-	op.GeoM.Translate(w.X, w.Y)
-	screen.DrawImage(windowImage, op)
+	// 収まらない項目があればスクロールバー
+	if rows < len(w.Items) {
+		trackHeight := w.Height - w.Padding*2
+		thumbHeight := trackHeight * float64(rows) / float64(len(w.Items))
+		thumbY := w.Padding + trackHeight*float64(w.ScrollOffset)/float64(len(w.Items))
+		x := w.Width - w.Padding/2 - menuScrollBarWidth
+		vector.DrawFilledRect(windowImage, float32(x), float32(thumbY), menuScrollBarWidth, float32(thumbHeight), w.ScrollBarColor, false)
+	}
 }
 
 func (w *MenuWindow) Select() {
@@ -2,102 +2,337 @@ package ui
 
 import (
 	"image/color"
-	"strings"
+	"math"
+
+	enginefont "gameengine/src/engine/font"
+	"gameengine/src/engine/i18n"
+	"gameengine/src/engine/richtext"
 
 	"github.com/hajimehoshi/ebiten/v2"
-	"github.com/hajimehoshi/ebiten/v2/text"
+	"github.com/hajimehoshi/ebiten/v2/vector"
 	"golang.org/x/image/font"
-	"golang.org/x/image/math/fixed"
+	"golang.org/x/image/font/basicfont"
 )
 
+// 入力待ちカーソルの点滅間隔（フレーム）
+const cursorBlinkFrames = 30
+
 type MessageWindow struct {
 	BaseComponent
 	Text            string
-	Font            font.Face
+	TextKey         string            // 文字列テーブルのキー（指定すると言語の切り替えで訳し直す）
+	TextArgs        i18n.Args         // キーのプレースホルダーの値
+	Font            font.Face         // Facesがない場合にすべての文字に使うフェイス
+	Faces           richtext.FaceFunc // 指定するとマークアップの大きさ・太字をこのフェイスで描く
+	FontID          string
+	FontSize        float64
+	Icons           richtext.IconFunc
 	BackgroundColor color.Color
 	TextColor       color.Color
+	CursorColor     color.Color
+	ShowCursor      bool // 入力待ちカーソルを表示
 	Padding         float64
-	Lines           []string
 	MaxLines        int
 	LineHeight      float64
+	Frame           *NineSlice    // 枠の画像（nilならBackgroundColorで塗る）
+	Cursor          *ebiten.Image // 入力待ちカーソルの画像（nilなら三角を描く）
+	SkinName        string        // テーマの"message"に重ねるスキンの名前
+	Skin            *Skin         // このウィンドウだけの上書き
+	cursorOverride  color.Color   // SetCursorColorで指定した色（テーマの文字色より優先）
+	layout          *richtext.Layout
+	visibleChars    int // 負なら全部表示
+	frame           int
+	cache           windowCache
+}
+
+// 描いた時の状態（変わったら描き直す）
+type messageDrawState struct {
+	width, height     int
+	layout            *richtext.Layout
+	visibleChars      int
+	cursorShown       bool
+	background, text  color.Color
+	cursorColor       color.Color
+	padding, fontSize float64
+	frame             *NineSlice
+	cursor            *ebiten.Image
 }
 
 func NewMessageWindow(font font.Face) *MessageWindow {
-	return &MessageWindow{
+	w := &MessageWindow{
 		BaseComponent: BaseComponent{
 			Visible: true,
 			ZIndex:  100,
 		},
 		Font:            font,
+		FontSize:        18,
 		BackgroundColor: color.RGBA{0, 0, 0, 200},
 		TextColor:       color.White,
+		CursorColor:     color.White,
 		Padding:         10,
 		MaxLines:        4,
 		LineHeight:      24,
+		visibleChars:    -1,
+	}
+	w.ApplyTheme(DefaultThemes.Current())
+	return w
+}
+
+// テーマの"message"、SkinName、Skinの順に重ねた見た目にする
+func (w *MessageWindow) ApplyTheme(t *Theme) {
+	skin := t.Skin("message", w.SkinName).merge(w.Skin)
+	w.Frame = skin.Frame
+	w.Cursor = skin.Cursor
+	if skin.BackgroundColor != nil {
+		w.BackgroundColor = skin.BackgroundColor
+	}
+	if skin.TextColor != nil {
+		w.TextColor = skin.TextColor
+		w.CursorColor = skin.TextColor
+	}
+	if w.cursorOverride != nil {
+		w.CursorColor = w.cursorOverride
+	}
+	if skin.FontID != "" {
+		w.FontID = skin.FontID
+	}
+	if skin.FontSize > 0 {
+		w.FontSize = skin.FontSize
+	}
+	if skin.Font != nil && w.Faces == nil {
+		w.Font = skin.Font
+	}
+	if skin.Padding > 0 {
+		w.Padding = skin.Padding
+	}
+	if skin.LineHeight > 0 {
+		w.LineHeight = skin.LineHeight
+	}
+	w.layout = nil
+	w.cache.invalidate()
+}
+
+// 以下の設定はSkinにも書き込み、テーマを切り替えても残す
+func (w *MessageWindow) SetBackgroundColor(c color.Color) {
+	overrides(&w.Skin).BackgroundColor = c
+	w.BackgroundColor = c
+	w.cache.invalidate()
+}
+
+func (w *MessageWindow) SetTextColor(c color.Color) {
+	overrides(&w.Skin).TextColor = c
+	w.TextColor = c
+	if w.cursorOverride == nil {
+		w.CursorColor = c
 	}
+	w.cache.invalidate()
+}
+
+func (w *MessageWindow) SetCursorColor(c color.Color) {
+	w.cursorOverride = c
+	w.CursorColor = c
+	w.cache.invalidate()
+}
+
+func (w *MessageWindow) SetPadding(padding float64) {
+	overrides(&w.Skin).Padding = padding
+	w.Padding = padding
+	w.layout = nil
+	w.cache.invalidate()
+}
+
+func (w *MessageWindow) SetLineHeight(height float64) {
+	overrides(&w.Skin).LineHeight = height
+	w.LineHeight = height
+	w.layout = nil
+	w.cache.invalidate()
+}
+
+// 次のDrawで描き直す
+func (w *MessageWindow) Invalidate() {
+	w.cache.invalidate()
 }
 
+// マークアップ（[color=red]など）を含むテキストを設定する
 func (w *MessageWindow) SetText(text string) {
 	w.Text = text
-	w.Lines = w.wrapText(text)
+	w.TextKey = ""
+	w.layout = nil
+	w.visibleChars = -1
 }
 
+// 文字列テーブルのキーで本文を設定する
+func (w *MessageWindow) SetTextKey(loc *i18n.Localizer, key string, args i18n.Args) {
+	w.SetText(loc.Tr(key, args))
+	w.TextKey = key
+	w.TextArgs = args
+}
+
+// キーがあれば訳し直し、文字送りの途中ならその位置から続ける
+func (w *MessageWindow) Localize(loc *i18n.Localizer) {
+	if w.TextKey != "" {
+		w.Text = loc.Tr(w.TextKey, w.TextArgs)
+	}
+	w.layout = nil
+}
+
+// 末尾に追加し、収まらない行は古いものから流す
 func (w *MessageWindow) AppendText(text string) {
 	w.Text += text
-	w.Lines = w.wrapText(w.Text)
-	if len(w.Lines) > w.MaxLines {
-		w.Lines = w.Lines[len(w.Lines)-w.MaxLines:]
+	w.layout = nil
+}
+
+// フォントや大きさを変えた後に配置し直す
+func (w *MessageWindow) Relayout() {
+	w.layout = nil
+}
+
+// 表示する文字数（文字送り用、負なら全部）
+func (w *MessageWindow) SetVisibleChars(n int) {
+	w.visibleChars = n
+}
+
+func (w *MessageWindow) ShowAll() {
+	w.visibleChars = -1
+}
+
+// テキスト全体の文字数（アイコンは1文字）
+func (w *MessageWindow) CharCount() int {
+	return w.textLayout().CharCount()
+}
+
+// すべての文字が表示されているか
+func (w *MessageWindow) IsFullyShown() bool {
+	return w.visibleChars < 0 || w.visibleChars >= w.CharCount()
+}
+
+func (w *MessageWindow) Update() error {
+	w.frame++
+	return nil
+}
+
+func (w *MessageWindow) textLayout() *richtext.Layout {
+	if w.layout == nil {
+		w.layout = w.layoutText(w.Text)
 	}
+	return w.layout
+}
+
+// 表示する行とその前の文字数
+// MaxLinesを超える場合は表示中の文字を含む末尾の行を出す
+func (w *MessageWindow) visibleLayout() (*richtext.Layout, int) {
+	layout := w.textLayout()
+	n := len(layout.Lines)
+	last := n - 1
+	if w.visibleChars >= 0 {
+		count := 0
+		for i, line := range layout.Lines {
+			for _, item := range line.Items {
+				count += item.Chars
+			}
+			if count >= w.visibleChars {
+				last = i
+				break
+			}
+		}
+	}
+	if w.MaxLines <= 0 || last+1 <= w.MaxLines {
+		if last == n-1 {
+			return layout, 0
+		}
+		return layout.Slice(0, last+1), 0
+	}
+
+	from := last + 1 - w.MaxLines
+	skipped := 0
+	for _, line := range layout.Lines[:from] {
+		for _, item := range line.Items {
+			skipped += item.Chars
+		}
+	}
+	return layout.Slice(from, last+1), skipped
 }
 
 func (w *MessageWindow) Draw(screen *ebiten.Image) {
 	if !w.Visible {
 		return
 	}
-
-	// 背景描画
 	bounds := w.GetBounds()
-	windowImage := ebiten.NewImage(bounds.Dx(), bounds.Dy())
-	windowImage.Fill(w.BackgroundColor)
+	if bounds.Dx() <= 0 || bounds.Dy() <= 0 {
+		return
+	}
+
+	// 文字送りやカーソルの点滅で変わった時だけ描き直す
+	state := messageDrawState{
+		width: bounds.Dx(), height: bounds.Dy(),
+		layout: w.textLayout(), visibleChars: w.visibleChars,
+		cursorShown: w.ShowCursor && w.IsFullyShown() && (w.frame/cursorBlinkFrames)%2 == 0,
+		background:  w.BackgroundColor, text: w.TextColor, cursorColor: w.CursorColor,
+		padding: w.Padding, fontSize: w.FontSize,
+		frame: w.Frame, cursor: w.Cursor,
+	}
+	if windowImage := w.cache.begin(bounds.Dx(), bounds.Dy(), state); windowImage != nil {
+		w.render(windowImage, state.cursorShown)
+	}
+	w.cache.draw(screen, w.X, w.Y)
+}
+
+func (w *MessageWindow) render(windowImage *ebiten.Image, cursorShown bool) {
+	drawWindowBackground(windowImage, w.Frame, w.BackgroundColor)
 
 	// テキスト描画
-	for i, line := range w.Lines {
-		y := w.Padding + float64(i)*w.LineHeight
-		text.Draw(windowImage, line, w.Font, int(w.Padding), int(y+w.LineHeight), w.TextColor)
+	layout, skipped := w.visibleLayout()
+	opts := &richtext.DrawOptions{Color: w.TextColor}
+	if w.visibleChars >= 0 {
+		opts.LimitChars = true
+		opts.MaxChars = w.visibleChars - skipped
 	}
+	layout.Draw(windowImage, w.Padding, w.Padding, opts)
 
-	op := &ebiten.DrawImageOptions{}
-	op.GeoM.Translate(w.X, w.Y)
-	screen.DrawImage(windowImage, op)
-}
+	if !cursorShown {
+		return
+	}
 
-func (w *MessageWindow) wrapText(text string) []string {
-	// 簡単な行折り返し処理
-	maxWidth := int(w.Width - w.Padding*2)
-	var lines []string
-	words := strings.Split(text, " ")
-	currentLine := ""
+	// 入力待ちカーソル（画像がなければ右下で点滅する三角）
+	if w.Cursor != nil {
+		cb := w.Cursor.Bounds()
+		op := &ebiten.DrawImageOptions{}
+		op.GeoM.Translate(math.Round(w.Width-w.Padding-float64(cb.Dx())), math.Round(w.Height-w.Padding-float64(cb.Dy())))
+		windowImage.DrawImage(w.Cursor, op)
+		return
+	}
+	size := float32(w.FontSize / 2)
+	x := float32(w.Width-w.Padding) - size
+	y := float32(w.Height-w.Padding) - size
+	for row := float32(0); row < size; row++ {
+		inset := row / 2
+		vector.DrawFilledRect(windowImage, x+inset, y+row, size-inset*2, 1, w.CursorColor, false)
+	}
+}
 
-	for _, word := range words {
-		candidate := currentLine
-		if currentLine != "" {
-			candidate += " "
-		}
-		candidate += word
-
-		bounds, _ := font.BoundString(w.Font, candidate)
-		width := bounds.Max.X - bounds.Min.X
-		if width > fixed.I(maxWidth) && currentLine != "" {
-			lines = append(lines, currentLine)
-			currentLine = word
-		} else {
-			currentLine = candidate
+// 改行と禁則処理を考慮してウィンドウ幅で配置する
+func (w *MessageWindow) layoutText(text string) *richtext.Layout {
+	faces := w.Faces
+	if faces == nil {
+		face := w.Font
+		if face == nil {
+			face = basicfont.Face7x13
 		}
+		faces = func(string, float64, enginefont.FontStyle) font.Face { return face }
 	}
 
-	if currentLine != "" {
-		lines = append(lines, currentLine)
+	// LineHeightは行の高さの最小値として扱う
+	spacing := 1.0
+	if height := faces(w.FontID, w.FontSize, enginefont.StyleRegular).Metrics().Height; height > 0 && w.LineHeight > 0 {
+		spacing = w.LineHeight / (float64(height) / 64)
 	}
 
-	return lines
+	return richtext.NewFromMarkup(text, richtext.Options{
+		FontID:      w.FontID,
+		Size:        w.FontSize,
+		MaxWidth:    w.Width - w.Padding*2,
+		LineSpacing: spacing,
+		Faces:       faces,
+		Icons:       w.Icons,
+	})
 }
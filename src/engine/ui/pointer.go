@@ -0,0 +1,171 @@
+package ui
+
+import (
+	"image"
+	"math"
+
+	"gameengine/src/engine/input"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+)
+
+// イベントを送るマウスボタン
+var pointerButtons = []ebiten.MouseButton{
+	ebiten.MouseButtonLeft,
+	ebiten.MouseButtonRight,
+	ebiten.MouseButtonMiddle,
+}
+
+// 前のフレームのカーソルの位置と、ボタンを押した時のコンポーネント
+type pointerState struct {
+	x, y    int
+	known   bool
+	hover   []Component                        // カーソルの下のコンポーネント（外側から）
+	pressed map[ebiten.MouseButton][]Component // 押している間はこのコンポーネントに届ける
+}
+
+func newPointerState() pointerState {
+	return pointerState{pressed: make(map[ebiten.MouseButton][]Component)}
+}
+
+// 外されたコンポーネントを含む状態を捨てる
+func (p *pointerState) forget(c Component) {
+	if containsComponent(p.hover, c) {
+		p.hover = nil
+	}
+	for button, path := range p.pressed {
+		if containsComponent(path, c) {
+			delete(p.pressed, button)
+		}
+	}
+}
+
+// ゲーム内座標にある一番手前のコンポーネントまでの道筋（外側から）
+func hitPath(roots []Component, x, y int) []Component {
+	pt := image.Pt(x, y)
+	for i := len(roots) - 1; i >= 0; i-- {
+		if path := hitPathIn(roots[i], pt); path != nil {
+			return path
+		}
+	}
+	return nil
+}
+
+func hitPathIn(c Component, pt image.Point) []Component {
+	if !c.IsVisible() || !pt.In(c.GetBounds()) {
+		return nil
+	}
+	if p, ok := c.(Parent); ok {
+		children := p.ChildComponents()
+		for i := len(children) - 1; i >= 0; i-- {
+			if path := hitPathIn(children[i], pt); path != nil {
+				return append([]Component{c}, path...)
+			}
+		}
+	}
+	return []Component{c}
+}
+
+// ゲーム内座標にある一番手前のコンポーネントまでの道筋（コンテナの中まで）
+func (m *UIManager) HitPath(x, y int) []Component {
+	m.mutex.RLock()
+	roots := m.ordered()
+	m.mutex.RUnlock()
+	return hitPath(roots, x, y)
+}
+
+// カーソルの移動・出入り、ボタン、ホイールをイベントとして届ける
+// ボタンを押すと、そのコンポーネント（かその外側）でフォーカスを受け取れるものにフォーカスを移す
+func (m *UIManager) routePointer(scope []Component, converter input.CoordinateConverter) {
+	sx, sy := ebiten.CursorPosition()
+	x, y := sx, sy
+	if converter != nil {
+		fx, fy := converter.ScreenToWorld(float64(sx), float64(sy))
+		x, y = int(math.Floor(fx)), int(math.Floor(fy))
+	}
+	p := &m.pointer
+	moved := !p.known || x != p.x || y != p.y
+	p.x, p.y, p.known = x, y, true
+
+	path := hitPath(scope, x, y)
+	newEvent := func(t EventType) *Event {
+		return &Event{Type: t, X: x, Y: y}
+	}
+
+	// 出入り
+	for i, c := range p.hover {
+		if !containsComponent(path, c) {
+			dispatchEvent(p.hover[:i+1], newEvent(EventPointerLeave))
+		}
+	}
+	for i, c := range path {
+		if !containsComponent(p.hover, c) {
+			dispatchEvent(path[:i+1], newEvent(EventPointerEnter))
+		}
+	}
+	p.hover = path
+
+	// 移動（ボタンを押している間は押した時のコンポーネントへ）
+	if moved {
+		target := path
+		for _, button := range pointerButtons {
+			if pressed, ok := p.pressed[button]; ok {
+				target = pressed
+				break
+			}
+		}
+		dispatchEvent(target, newEvent(EventPointerMove))
+	}
+
+	for _, button := range pointerButtons {
+		if inpututil.IsMouseButtonJustPressed(button) && path != nil {
+			p.pressed[button] = path
+			e := newEvent(EventPointerDown)
+			e.Button = button
+			dispatchEvent(path, e)
+			for i := len(path) - 1; i >= 0; i-- {
+				if canFocus(path[i]) {
+					m.focus.Focus(path[i])
+					break
+				}
+			}
+		}
+		if inpututil.IsMouseButtonJustReleased(button) {
+			pressed, ok := p.pressed[button]
+			if !ok {
+				continue
+			}
+			delete(p.pressed, button)
+			e := newEvent(EventPointerUp)
+			e.Button = button
+			dispatchEvent(pressed, e)
+			if path != nil && path[len(path)-1] == pressed[len(pressed)-1] {
+				e := newEvent(EventClick)
+				e.Button = button
+				dispatchEvent(path, e)
+			}
+		}
+	}
+
+	if wx, wy := ebiten.Wheel(); (wx != 0 || wy != 0) && path != nil {
+		e := newEvent(EventWheel)
+		e.WheelX, e.WheelY = wx, wy
+		dispatchEvent(path, e)
+	}
+}
+
+// 押した・離したキーをフォーカスのあるコンポーネントへ届ける
+func (m *UIManager) routeKeys() {
+	focused := m.focus.Focused()
+	if focused == nil {
+		return
+	}
+	path := m.pathTo(focused)
+	for _, key := range inpututil.AppendJustPressedKeys(nil) {
+		dispatchEvent(path, &Event{Type: EventKeyDown, Key: key, X: m.pointer.x, Y: m.pointer.y})
+	}
+	for _, key := range inpututil.AppendJustReleasedKeys(nil) {
+		dispatchEvent(path, &Event{Type: EventKeyUp, Key: key, X: m.pointer.x, Y: m.pointer.y})
+	}
+}
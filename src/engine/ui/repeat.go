@@ -0,0 +1,56 @@
+package ui
+
+import (
+	"gameengine/src/engine/input"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+)
+
+// 押し続けた時の繰り返し（フレーム数）
+const (
+	repeatDelay    = 24 // 最初の入力から繰り返しが始まるまで
+	repeatInterval = 6  // 繰り返しの間隔
+)
+
+// 押してからのフレーム数が、押した瞬間か繰り返しのタイミングならtrue
+func repeatedAt(frames int) bool {
+	if frames == 1 {
+		return true
+	}
+	frames--
+	return frames >= repeatDelay && (frames-repeatDelay)%repeatInterval == 0
+}
+
+// キーを押した瞬間と、押し続けている間の一定間隔でtrue
+func keyRepeated(key ebiten.Key) bool {
+	return repeatedAt(inpututil.KeyPressDuration(key))
+}
+
+// アクションを押し続けた時の繰り返し（最後に押したアクションだけ繰り返す）
+type actionRepeat struct {
+	action input.Action
+	frames int
+}
+
+// 押した瞬間と、押し続けている間の一定間隔でtrue
+func (r *actionRepeat) repeated(im *input.InputManager, action input.Action) bool {
+	if im.IsJustPressed(action) {
+		r.action = action
+		r.frames = 0
+		return true
+	}
+	if r.action != action {
+		return false
+	}
+	if !im.IsPressed(action) {
+		r.action = ""
+		return false
+	}
+	r.frames++
+	return r.frames >= repeatDelay && (r.frames-repeatDelay)%repeatInterval == 0
+}
+
+func (r *actionRepeat) reset() {
+	r.action = ""
+}
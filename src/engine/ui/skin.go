@@ -0,0 +1,343 @@
+package ui
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"math"
+	"sort"
+	"sync"
+
+	enginefont "gameengine/src/engine/font"
+	"gameengine/src/engine/richtext"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"golang.org/x/image/font"
+)
+
+// 四隅はそのままの大きさで、辺と中央を伸ばして描く画像
+type NineSlice struct {
+	Image  *ebiten.Image
+	Border Insets // 四隅の大きさ（画像のピクセル）
+}
+
+// (x, y)から幅width・高さheightに描く
+// 四隅が収まらない場合は四隅を縮める
+func (n *NineSlice) Draw(dst *ebiten.Image, x, y, width, height float64, cs ebiten.ColorScale) {
+	if n == nil || n.Image == nil || width <= 0 || height <= 0 {
+		return
+	}
+	b := n.Image.Bounds()
+	left, right := clampBorder(n.Border.Left, n.Border.Right, float64(b.Dx()))
+	top, bottom := clampBorder(n.Border.Top, n.Border.Bottom, float64(b.Dy()))
+
+	kx := math.Min(1, width/math.Max(left+right, 1))
+	ky := math.Min(1, height/math.Max(top+bottom, 1))
+	src := [2][4]int{
+		{b.Min.X, b.Min.X + int(left), b.Max.X - int(right), b.Max.X},
+		{b.Min.Y, b.Min.Y + int(top), b.Max.Y - int(bottom), b.Max.Y},
+	}
+	dst4 := [2][4]float64{
+		{x, x + left*kx, x + width - right*kx, x + width},
+		{y, y + top*ky, y + height - bottom*ky, y + height},
+	}
+
+	for row := 0; row < 3; row++ {
+		for col := 0; col < 3; col++ {
+			sw, sh := src[0][col+1]-src[0][col], src[1][row+1]-src[1][row]
+			dw, dh := dst4[0][col+1]-dst4[0][col], dst4[1][row+1]-dst4[1][row]
+			if sw <= 0 || sh <= 0 || dw <= 0 || dh <= 0 {
+				continue
+			}
+			part := n.Image.SubImage(image.Rect(src[0][col], src[1][row], src[0][col+1], src[1][row+1])).(*ebiten.Image)
+			op := &ebiten.DrawImageOptions{}
+			op.GeoM.Scale(dw/float64(sw), dh/float64(sh))
+			op.GeoM.Translate(dst4[0][col], dst4[1][row])
+			op.ColorScale = cs
+			dst.DrawImage(part, op)
+		}
+	}
+}
+
+// 両側の幅が画像より大きければ画像の幅に収める
+func clampBorder(a, b, size float64) (float64, float64) {
+	a, b = math.Max(a, 0), math.Max(b, 0)
+	if a+b > size {
+		k := size / (a + b)
+		a, b = math.Floor(a*k), math.Floor(b*k)
+	}
+	return a, b
+}
+
+// ウィンドウの見た目（nil・0・空の項目は変えない）
+type Skin struct {
+	Frame           *NineSlice // 枠と背景（指定するとBackgroundColorでは塗らない）
+	BackgroundColor color.Color
+	TextColor       color.Color
+	SelectedColor   color.Color
+	DisabledColor   color.Color
+	FontID          string
+	FontSize        float64
+	FontStyle       enginefont.FontStyle
+	Font            font.Face // FontID・FontSizeから解決したフェイス
+	Padding         float64
+	LineHeight      float64
+	Cursor          *ebiten.Image     // メニューの選択中の項目、メッセージの入力待ちに表示する
+	Sounds          map[string]string // "move"、"select"、"cancel"、"buzzer"、"click"と効果音のID
+}
+
+// overで指定された項目を上書きしたスキン
+func (s *Skin) merge(over *Skin) *Skin {
+	merged := &Skin{}
+	if s != nil {
+		*merged = *s
+	}
+	if over == nil {
+		return merged
+	}
+	if over.Frame != nil {
+		merged.Frame = over.Frame
+	}
+	if over.BackgroundColor != nil {
+		merged.BackgroundColor = over.BackgroundColor
+	}
+	if over.TextColor != nil {
+		merged.TextColor = over.TextColor
+	}
+	if over.SelectedColor != nil {
+		merged.SelectedColor = over.SelectedColor
+	}
+	if over.DisabledColor != nil {
+		merged.DisabledColor = over.DisabledColor
+	}
+	if over.FontID != "" {
+		merged.FontID = over.FontID
+	}
+	if over.FontSize > 0 {
+		merged.FontSize = over.FontSize
+	}
+	if over.FontStyle != enginefont.StyleRegular {
+		merged.FontStyle = over.FontStyle
+	}
+	if over.Font != nil {
+		merged.Font = over.Font
+	}
+	if over.Padding > 0 {
+		merged.Padding = over.Padding
+	}
+	if over.LineHeight > 0 {
+		merged.LineHeight = over.LineHeight
+	}
+	if over.Cursor != nil {
+		merged.Cursor = over.Cursor
+	}
+	if len(over.Sounds) > 0 {
+		sounds := make(map[string]string, len(merged.Sounds)+len(over.Sounds))
+		for event, id := range merged.Sounds {
+			sounds[event] = id
+		}
+		for event, id := range over.Sounds {
+			sounds[event] = id
+		}
+		merged.Sounds = sounds
+	}
+	return merged
+}
+
+// コンポーネントだけの上書き（なければ作る）
+// スクリプトなどで個別に変えた色や余白をここに書くと、テーマを切り替えても残る
+func overrides(skin **Skin) *Skin {
+	if *skin == nil {
+		*skin = &Skin{}
+	}
+	return *skin
+}
+
+// イベントに割り当てられた効果音を鳴らす
+func (s *Skin) play(event string) {
+	if s == nil {
+		return
+	}
+	if id := s.Sounds[event]; id != "" {
+		soundMutex.RLock()
+		play := soundPlayer
+		soundMutex.RUnlock()
+		if play != nil {
+			play(id)
+		}
+	}
+}
+
+var (
+	soundMutex  sync.RWMutex
+	soundPlayer func(id string)
+)
+
+// スキンの効果音の鳴らし方を設定する
+func SetSoundPlayer(play func(id string)) {
+	soundMutex.Lock()
+	defer soundMutex.Unlock()
+	soundPlayer = play
+}
+
+// ウィジェットの種類（"menu"、"message"、"status"、"button"、"window"）や名前ごとのスキン
+// "default"はすべてに共通で、種類や名前のスキンで上書きする
+type Theme struct {
+	Skins map[string]*Skin
+	Faces richtext.FaceFunc // FontID・FontSizeからフェイスを解決する
+}
+
+func NewTheme() *Theme {
+	return &Theme{Skins: make(map[string]*Skin)}
+}
+
+// "default"に名前の順にスキンを重ねる（空の名前は飛ばす）
+func (t *Theme) Skin(names ...string) *Skin {
+	skin := t.Skins["default"].merge(nil)
+	for _, name := range names {
+		if name != "" {
+			skin = skin.merge(t.Skins[name])
+		}
+	}
+	if skin.Font == nil && t.Faces != nil && (skin.FontID != "" || skin.FontSize > 0) {
+		skin.Font = t.Faces(skin.FontID, skin.FontSize, skin.FontStyle)
+	}
+	return skin
+}
+
+// テーマを切り替えると見た目を変えるコンポーネント
+type Themeable interface {
+	ApplyTheme(t *Theme)
+}
+
+// 読み込んだテーマと現在のテーマ
+type ThemeSet struct {
+	mutex     sync.RWMutex
+	themes    map[string]*Theme
+	current   string
+	empty     *Theme
+	listeners []func(t *Theme)
+}
+
+// 既定のテーマ（ウィンドウを作った時に適用される）
+var DefaultThemes = NewThemeSet()
+
+func NewThemeSet() *ThemeSet {
+	return &ThemeSet{
+		themes: make(map[string]*Theme),
+		empty:  NewTheme(),
+	}
+}
+
+func (s *ThemeSet) Register(id string, t *Theme) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.themes[id] = t
+}
+
+// テーマを切り替え、変更を通知する
+func (s *ThemeSet) Use(id string) error {
+	s.mutex.Lock()
+	t, exists := s.themes[id]
+	if !exists {
+		s.mutex.Unlock()
+		return fmt.Errorf("theme not found: %s", id)
+	}
+	changed := s.current != id
+	s.current = id
+	listeners := append([]func(*Theme){}, s.listeners...)
+	s.mutex.Unlock()
+
+	if changed {
+		for _, fn := range listeners {
+			fn(t)
+		}
+	}
+	return nil
+}
+
+// 現在のテーマ（なければ何も変えない空のテーマ）
+func (s *ThemeSet) Current() *Theme {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	if t, exists := s.themes[s.current]; exists {
+		return t
+	}
+	return s.empty
+}
+
+func (s *ThemeSet) CurrentID() string {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return s.current
+}
+
+func (s *ThemeSet) IDs() []string {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	ids := make([]string, 0, len(s.themes))
+	for id := range s.themes {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// テーマが切り替わった時に呼ばれる関数を登録する
+func (s *ThemeSet) OnThemeChange(fn func(t *Theme)) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.listeners = append(s.listeners, fn)
+}
+
+// ウィンドウの画像（大きさか描く内容が変わった時だけ描き直す）
+type windowCache struct {
+	image *ebiten.Image
+	state interface{} // 描いた時の状態（比較できる値）
+	valid bool
+}
+
+// stateが前回と違えば、描き直すために消した画像を返す（同じならnil）
+func (c *windowCache) begin(width, height int, state interface{}) *ebiten.Image {
+	if c.image != nil {
+		if b := c.image.Bounds(); b.Dx() != width || b.Dy() != height {
+			c.image.Dispose()
+			c.image = nil
+		}
+	}
+	if c.image == nil {
+		c.image = ebiten.NewImage(width, height)
+		c.valid = false
+	}
+	if c.valid && c.state == state {
+		return nil
+	}
+	c.state, c.valid = state, true
+	c.image.Clear()
+	return c.image
+}
+
+func (c *windowCache) invalidate() {
+	c.valid = false
+}
+
+func (c *windowCache) draw(screen *ebiten.Image, x, y float64) {
+	if c.image == nil {
+		return
+	}
+	op := &ebiten.DrawImageOptions{}
+	op.GeoM.Translate(x, y)
+	screen.DrawImage(c.image, op)
+}
+
+// 枠の画像、なければ背景色で塗る
+func drawWindowBackground(dst *ebiten.Image, frame *NineSlice, background color.Color) {
+	b := dst.Bounds()
+	if frame != nil {
+		frame.Draw(dst, float64(b.Min.X), float64(b.Min.Y), float64(b.Dx()), float64(b.Dy()), ebiten.ColorScale{})
+		return
+	}
+	if background != nil {
+		dst.Fill(background)
+	}
+}
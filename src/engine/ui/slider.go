@@ -0,0 +1,171 @@
+package ui
+
+import (
+	"image/color"
+	"math"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+// 範囲の中の値をつまみで選ぶスライダー（音量など）
+// つまみのドラッグ・クリック、フォーカスがある時の←→で値を変える
+type Slider struct {
+	BaseComponent
+	Min, Max float64
+	Step     float64 // 値の刻み（0なら刻まない。←→では範囲の1/20ずつ動かす）
+	Value    float64
+
+	TrackColor color.Color
+	FillColor  color.Color // 最小からつまみまで
+	KnobColor  color.Color
+	FocusColor color.Color // フォーカスがある時のつまみの縁
+	KnobRadius float64
+
+	SkinName string // テーマの"slider"に重ねるスキンの名前
+	Skin     *Skin  // このスライダーだけの上書き
+
+	OnChange func(value float64)
+
+	dragging bool
+	skin     *Skin
+}
+
+func NewSlider(min, max, value float64) *Slider {
+	s := &Slider{
+		BaseComponent: BaseComponent{
+			Visible:   true,
+			ZIndex:    100,
+			Focusable: true,
+			Width:     160,
+			Height:    20,
+		},
+		Min:        min,
+		Max:        max,
+		TrackColor: color.RGBA{255, 255, 255, 60},
+		FillColor:  color.RGBA{80, 160, 255, 255},
+		KnobColor:  color.White,
+		FocusColor: color.RGBA{255, 255, 0, 255},
+		KnobRadius: 7,
+	}
+	s.Value = s.normalize(value)
+	s.ApplyTheme(DefaultThemes.Current())
+
+	s.On(EventPointerDown, func(e *Event) {
+		if e.Button == ebiten.MouseButtonLeft && s.AcceptsPointer() {
+			s.dragging = true
+			s.setFromX(e.X)
+		}
+	})
+	s.On(EventPointerMove, func(e *Event) {
+		if s.dragging {
+			s.setFromX(e.X)
+		}
+	})
+	s.On(EventPointerUp, func(*Event) { s.dragging = false })
+	s.On(EventWheel, func(e *Event) {
+		if e.WheelY > 0 {
+			s.StepBy(1)
+		} else if e.WheelY < 0 {
+			s.StepBy(-1)
+		}
+	})
+	return s
+}
+
+// テーマの"slider"、SkinName、Skinの順に重ねた見た目にする
+// 背景色は溝、選択色は値の範囲、文字色はつまみの色になる
+func (s *Slider) ApplyTheme(t *Theme) {
+	skin := t.Skin("slider", s.SkinName).merge(s.Skin)
+	s.skin = skin
+	if skin.BackgroundColor != nil {
+		s.TrackColor = skin.BackgroundColor
+	}
+	if skin.SelectedColor != nil {
+		s.FillColor = skin.SelectedColor
+	}
+	if skin.TextColor != nil {
+		s.KnobColor = skin.TextColor
+	}
+}
+
+// 範囲に収め、刻みに合わせた値
+func (s *Slider) normalize(v float64) float64 {
+	min, max := math.Min(s.Min, s.Max), math.Max(s.Min, s.Max)
+	if s.Step > 0 {
+		v = s.Min + math.Round((v-s.Min)/s.Step)*s.Step
+	}
+	return math.Max(min, math.Min(max, v))
+}
+
+// 値を設定し、変わっていればOnChangeを呼ぶ
+func (s *Slider) SetValue(v float64) {
+	v = s.normalize(v)
+	if v == s.Value {
+		return
+	}
+	s.Value = v
+	s.skin.play("move")
+	if s.OnChange != nil {
+		s.OnChange(v)
+	}
+}
+
+// 刻みのn個分だけ値を動かす
+func (s *Slider) StepBy(n int) {
+	step := s.Step
+	if step <= 0 {
+		step = (s.Max - s.Min) / 20
+	}
+	s.SetValue(s.Value + step*float64(n))
+}
+
+// 0〜1の割合
+func (s *Slider) Ratio() float64 {
+	if s.Max == s.Min {
+		return 0
+	}
+	return math.Max(0, math.Min(1, (s.Value-s.Min)/(s.Max-s.Min)))
+}
+
+// つまみが動ける範囲の左端と幅
+func (s *Slider) track() (float64, float64) {
+	return s.X + s.KnobRadius, math.Max(0, s.Width-s.KnobRadius*2)
+}
+
+func (s *Slider) setFromX(x int) {
+	left, width := s.track()
+	if width <= 0 {
+		return
+	}
+	ratio := math.Max(0, math.Min(1, (float64(x)-left)/width))
+	s.SetValue(s.Min + ratio*(s.Max-s.Min))
+}
+
+func (s *Slider) Update() error {
+	if !s.Visible || !s.AcceptsKeys() {
+		return nil
+	}
+	switch {
+	case keyRepeated(ebiten.KeyArrowLeft):
+		s.StepBy(-1)
+	case keyRepeated(ebiten.KeyArrowRight):
+		s.StepBy(1)
+	}
+	return nil
+}
+
+func (s *Slider) Draw(screen *ebiten.Image) {
+	if !s.Visible {
+		return
+	}
+	left, width := s.track()
+	cy := s.Y + s.Height/2
+	knobX := left + width*s.Ratio()
+	vector.DrawFilledRect(screen, float32(left), float32(cy-2), float32(width), 4, s.TrackColor, false)
+	vector.DrawFilledRect(screen, float32(left), float32(cy-2), float32(knobX-left), 4, s.FillColor, false)
+	vector.DrawFilledCircle(screen, float32(knobX), float32(cy), float32(s.KnobRadius), s.KnobColor, true)
+	if s.focused {
+		vector.StrokeCircle(screen, float32(knobX), float32(cy), float32(s.KnobRadius+1), 2, s.FocusColor, true)
+	}
+}
@@ -3,6 +3,8 @@ package ui
 import (
 	"fmt"
 	"image/color"
+	"sort"
+	"strings"
 
 	"github.com/hajimehoshi/ebiten/v2"
 	"github.com/hajimehoshi/ebiten/v2/text"
@@ -17,10 +19,24 @@ type StatusWindow struct {
 	Padding         float64
 	Stats           map[string]interface{}
 	LineHeight      float64
+	Frame           *NineSlice // 枠の画像（nilならBackgroundColorで塗る）
+	SkinName        string     // テーマの"status"に重ねるスキンの名前
+	Skin            *Skin      // このウィンドウだけの上書き
+	cache           windowCache
+}
+
+// 描いた時の状態（変わったら描き直す）
+type statusDrawState struct {
+	width, height       int
+	lines               string
+	face                font.Face
+	background, text    color.Color
+	padding, lineHeight float64
+	frame               *NineSlice
 }
 
 func NewStatusWindow(font font.Face) *StatusWindow {
-	return &StatusWindow{
+	w := &StatusWindow{
 		BaseComponent: BaseComponent{
 			Visible: true,
 			ZIndex:  90,
@@ -32,6 +48,35 @@ func NewStatusWindow(font font.Face) *StatusWindow {
 		Stats:           make(map[string]interface{}),
 		LineHeight:      24,
 	}
+	w.ApplyTheme(DefaultThemes.Current())
+	return w
+}
+
+// テーマの"status"、SkinName、Skinの順に重ねた見た目にする
+func (w *StatusWindow) ApplyTheme(t *Theme) {
+	skin := t.Skin("status", w.SkinName).merge(w.Skin)
+	w.Frame = skin.Frame
+	if skin.BackgroundColor != nil {
+		w.BackgroundColor = skin.BackgroundColor
+	}
+	if skin.TextColor != nil {
+		w.TextColor = skin.TextColor
+	}
+	if skin.Font != nil {
+		w.Font = skin.Font
+	}
+	if skin.Padding > 0 {
+		w.Padding = skin.Padding
+	}
+	if skin.LineHeight > 0 {
+		w.LineHeight = skin.LineHeight
+	}
+	w.cache.invalidate()
+}
+
+// 次のDrawで描き直す
+func (w *StatusWindow) Invalidate() {
+	w.cache.invalidate()
 }
 
 func (w *StatusWindow) SetStat(key string, value interface{}) {
@@ -42,28 +87,44 @@ func (w *StatusWindow) Draw(screen *ebiten.Image) {
 	if !w.Visible {
 		return
 	}
-
-	// 背景描画
 	bounds := w.GetBounds()
-	windowImage := ebiten.NewImage(bounds.Dx(), bounds.Dy())
-	windowImage.Fill(w.BackgroundColor)
+	if bounds.Dx() <= 0 || bounds.Dy() <= 0 {
+		return
+	}
 
-	// ステータス描画
-	i := 0
-	for key, value := range w.Stats {
-		y := w.Padding + float64(i)*w.LineHeight
-		text.Draw(
-			windowImage,
-			fmt.Sprintf("%s: %v", key, value),
-			w.Font,
-			int(w.Padding),
-			int(y+w.LineHeight),
-			w.TextColor,
-		)
-		i++
+	// 値が変わった時だけ描き直す（キーの順に並べる）
+	keys := make([]string, 0, len(w.Stats))
+	for key := range w.Stats {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	lines := make([]string, len(keys))
+	for i, key := range keys {
+		lines[i] = fmt.Sprintf("%s: %v", key, w.Stats[key])
 	}
 
-	op := &ebiten.DrawImageOptions{}
-	op.GeoM.Translate(w.X, w.Y)
-	screen.DrawImage(windowImage, op)
-} 
\ No newline at end of file
+	state := statusDrawState{
+		width: bounds.Dx(), height: bounds.Dy(),
+		lines: strings.Join(lines, "\n"), face: w.Font,
+		background: w.BackgroundColor, text: w.TextColor,
+		padding: w.Padding, lineHeight: w.LineHeight,
+		frame: w.Frame,
+	}
+	if windowImage := w.cache.begin(bounds.Dx(), bounds.Dy(), state); windowImage != nil {
+		drawWindowBackground(windowImage, w.Frame, w.BackgroundColor)
+
+		// ステータス描画
+		for i, line := range lines {
+			y := w.Padding + float64(i)*w.LineHeight
+			text.Draw(
+				windowImage,
+				line,
+				w.Font,
+				int(w.Padding),
+				int(y+w.LineHeight),
+				w.TextColor,
+			)
+		}
+	}
+	w.cache.draw(screen, w.X, w.Y)
+}
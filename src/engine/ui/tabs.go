@@ -0,0 +1,311 @@
+package ui
+
+import (
+	"image"
+	"image/color"
+	"math"
+
+	"gameengine/src/engine/i18n"
+	"gameengine/src/engine/textlayout"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/basicfont"
+)
+
+// タブの見出しの間
+const tabSpacing = 2
+
+// タブの見出しと中身
+type Tab struct {
+	Title   string
+	Key     string    // 文字列テーブルのキー（指定すると言語の切り替えで訳し直す）
+	Args    i18n.Args // キーのプレースホルダーの値
+	Content Component
+}
+
+// 見出しで切り替えて1つずつ中身を表示するコンポーネント（装備・アイテム・設定の切り替えなど）
+// 見出しのクリック、フォーカスがある時の←→、Ctrl+PageUp・PageDownで切り替える
+// 中身は見出しの下いっぱいに置き、選ばれていないものは非表示にする
+type TabContainer struct {
+	BaseComponent
+	Tabs          []*Tab
+	SelectedIndex int
+	Font          font.Face
+	TabPadding    float64 // 見出しの文字列の周り
+	Padding       Insets  // 中身の周り
+
+	BackgroundColor   color.Color // 中身の背景
+	TabColor          color.Color // 選ばれていない見出しの背景
+	SelectedTabColor  color.Color
+	TextColor         color.Color
+	SelectedTextColor color.Color
+	FocusColor        color.Color // フォーカスがある時の見出しの枠
+	Frame             *NineSlice  // 中身の枠の画像（BackgroundColorの代わりに描く）
+
+	SkinName string // テーマの"tabs"に重ねるスキンの名前
+	Skin     *Skin  // このコンポーネントだけの上書き
+
+	OnChange func(index int)
+
+	skin *Skin
+}
+
+func NewTabContainer(face font.Face) *TabContainer {
+	t := &TabContainer{
+		BaseComponent: BaseComponent{
+			Visible:   true,
+			ZIndex:    100,
+			Focusable: true,
+			Width:     320,
+			Height:    240,
+		},
+		Font:              face,
+		TabPadding:        6,
+		Padding:           UniformInsets(8),
+		BackgroundColor:   color.RGBA{0, 0, 0, 200},
+		TabColor:          color.RGBA{40, 40, 60, 200},
+		SelectedTabColor:  color.RGBA{0, 0, 0, 200},
+		TextColor:         color.RGBA{160, 160, 160, 255},
+		SelectedTextColor: color.White,
+		FocusColor:        color.RGBA{255, 255, 0, 255},
+	}
+	t.ApplyTheme(DefaultThemes.Current())
+
+	t.On(EventPointerDown, func(e *Event) {
+		if e.Button != ebiten.MouseButtonLeft || e.Target != t || !t.AcceptsPointer() {
+			return
+		}
+		if index := t.TabAt(e.X, e.Y); index >= 0 {
+			t.selectByInput(index)
+		}
+	})
+	t.On(EventKeyDown, func(e *Event) {
+		ctrl := ebiten.IsKeyPressed(ebiten.KeyControl)
+		switch {
+		case ctrl && e.Key == ebiten.KeyPageUp, e.Target == t && e.Key == ebiten.KeyArrowLeft:
+			t.selectByInput(t.SelectedIndex - 1)
+		case ctrl && e.Key == ebiten.KeyPageDown, e.Target == t && e.Key == ebiten.KeyArrowRight:
+			t.selectByInput(t.SelectedIndex + 1)
+		default:
+			return
+		}
+		e.StopPropagation()
+	})
+	return t
+}
+
+// テーマの"tabs"、SkinName、Skinの順に重ねた見た目にし、中身にもテーマを適用する
+// 選択色は選ばれている見出しの文字色、余白は中身の周りになる
+func (t *TabContainer) ApplyTheme(th *Theme) {
+	skin := th.Skin("tabs", t.SkinName).merge(t.Skin)
+	t.skin = skin
+	t.Frame = skin.Frame
+	if skin.BackgroundColor != nil {
+		t.BackgroundColor = skin.BackgroundColor
+		t.SelectedTabColor = skin.BackgroundColor
+	}
+	if skin.TextColor != nil {
+		t.TextColor = skin.TextColor
+	}
+	if skin.SelectedColor != nil {
+		t.SelectedTextColor = skin.SelectedColor
+	}
+	if skin.Font != nil {
+		t.Font = skin.Font
+	}
+	if skin.Padding > 0 {
+		t.Padding = UniformInsets(skin.Padding)
+	}
+	for _, tab := range t.Tabs {
+		if themeable, ok := tab.Content.(Themeable); ok {
+			themeable.ApplyTheme(th)
+		}
+	}
+	t.Relayout()
+}
+
+// タブを追加する
+func (t *TabContainer) AddTab(title string, content Component) *Tab {
+	tab := &Tab{Title: title, Content: content}
+	t.Tabs = append(t.Tabs, tab)
+	t.Relayout()
+	return tab
+}
+
+// 中身がcontentのタブを外す
+func (t *TabContainer) RemoveTab(content Component) {
+	for i, tab := range t.Tabs {
+		if tab.Content == content {
+			t.Tabs = append(t.Tabs[:i], t.Tabs[i+1:]...)
+			if t.SelectedIndex >= len(t.Tabs) && t.SelectedIndex > 0 {
+				t.SelectedIndex = len(t.Tabs) - 1
+			}
+			t.Relayout()
+			return
+		}
+	}
+}
+
+// タブを選び、変わっていればOnChangeを呼ぶ
+func (t *TabContainer) SetSelectedIndex(index int) {
+	if index < 0 || index >= len(t.Tabs) {
+		return
+	}
+	changed := index != t.SelectedIndex
+	t.SelectedIndex = index
+	t.Relayout()
+	if changed && t.OnChange != nil {
+		t.OnChange(index)
+	}
+}
+
+// 入力でタブを選ぶ（端では反対側に回る）
+func (t *TabContainer) selectByInput(index int) {
+	n := len(t.Tabs)
+	if n == 0 {
+		return
+	}
+	index = (index%n + n) % n
+	if index != t.SelectedIndex {
+		t.skin.play("move")
+		t.SetSelectedIndex(index)
+	}
+}
+
+// 選ばれているタブ（なければnil）
+func (t *TabContainer) Selected() *Tab {
+	if t.SelectedIndex < 0 || t.SelectedIndex >= len(t.Tabs) {
+		return nil
+	}
+	return t.Tabs[t.SelectedIndex]
+}
+
+func (t *TabContainer) ChildComponents() []Component {
+	components := make([]Component, 0, len(t.Tabs))
+	for _, tab := range t.Tabs {
+		if tab.Content != nil {
+			components = append(components, tab.Content)
+		}
+	}
+	return components
+}
+
+func (t *TabContainer) SetPosition(x, y float64) {
+	t.BaseComponent.SetPosition(x, y)
+	t.Relayout()
+}
+
+func (t *TabContainer) SetSize(width, height float64) {
+	t.BaseComponent.SetSize(width, height)
+	t.Relayout()
+}
+
+// 選ばれているタブの中身だけ表示し、見出しの下に広げる
+// フォントや余白を変えた後に呼ぶ
+func (t *TabContainer) Relayout() {
+	r := t.contentRect().Inset(t.Padding)
+	for i, tab := range t.Tabs {
+		if tab.Content == nil {
+			continue
+		}
+		tab.Content.SetVisible(i == t.SelectedIndex)
+		(&LayoutChild{Component: tab.Content}).place(r)
+	}
+}
+
+func (t *TabContainer) face() font.Face {
+	if t.Font != nil {
+		return t.Font
+	}
+	return basicfont.Face7x13
+}
+
+func (t *TabContainer) headerHeight() float64 {
+	return float64(t.face().Metrics().Height.Ceil()) + t.TabPadding*2
+}
+
+// 見出しの下の中身の領域
+func (t *TabContainer) contentRect() Rect {
+	h := t.headerHeight()
+	return Rect{X: t.X, Y: t.Y + h, Width: t.Width, Height: math.Max(0, t.Height-h)}
+}
+
+// 見出しの位置と大きさ
+func (t *TabContainer) tabRects() []Rect {
+	rects := make([]Rect, len(t.Tabs))
+	x, h := t.X, t.headerHeight()
+	for i, tab := range t.Tabs {
+		width := float64(font.MeasureString(t.face(), tab.Title))/64 + t.TabPadding*2
+		rects[i] = Rect{X: x, Y: t.Y, Width: math.Ceil(width), Height: h}
+		x += rects[i].Width + tabSpacing
+	}
+	return rects
+}
+
+// ゲーム内座標にある見出し（なければ-1）
+func (t *TabContainer) TabAt(x, y int) int {
+	pt := image.Pt(x, y)
+	for i, r := range t.tabRects() {
+		if pt.In(image.Rect(int(r.X), int(r.Y), int(r.X+r.Width), int(r.Y+r.Height))) {
+			return i
+		}
+	}
+	return -1
+}
+
+// 見出しと中身を訳し直す
+func (t *TabContainer) Localize(loc *i18n.Localizer) {
+	for _, tab := range t.Tabs {
+		if tab.Key != "" {
+			tab.Title = loc.Tr(tab.Key, tab.Args)
+		}
+		if l, ok := tab.Content.(Localizable); ok {
+			l.Localize(loc)
+		}
+	}
+	t.Relayout()
+}
+
+// 選ばれているタブの中身だけ更新する
+func (t *TabContainer) Update() error {
+	if !t.Visible {
+		return nil
+	}
+	if tab := t.Selected(); tab != nil && tab.Content != nil {
+		return tab.Content.Update()
+	}
+	return nil
+}
+
+func (t *TabContainer) Draw(screen *ebiten.Image) {
+	if !t.Visible {
+		return
+	}
+	content := t.contentRect()
+	if t.Frame != nil {
+		t.Frame.Draw(screen, content.X, content.Y, content.Width, content.Height, ebiten.ColorScale{})
+	} else if t.BackgroundColor != nil {
+		vector.DrawFilledRect(screen, float32(content.X), float32(content.Y), float32(content.Width), float32(content.Height), t.BackgroundColor, false)
+	}
+
+	for i, r := range t.tabRects() {
+		background, textColor := t.TabColor, t.TextColor
+		if i == t.SelectedIndex {
+			background, textColor = t.SelectedTabColor, t.SelectedTextColor
+		}
+		x, y, w, h := float32(r.X), float32(r.Y), float32(r.Width), float32(r.Height)
+		if background != nil {
+			vector.DrawFilledRect(screen, x, y, w, h, background, false)
+		}
+		if i == t.SelectedIndex && t.focused {
+			vector.StrokeRect(screen, x+1, y+1, w-2, h-2, 2, t.FocusColor, false)
+		}
+		drawLines(screen, t.face(), t.Tabs[i].Title, r.X+t.TabPadding, r.Y+t.TabPadding, r.Width-t.TabPadding*2, textlayout.AlignCenter, textColor)
+	}
+
+	if tab := t.Selected(); tab != nil && tab.Content != nil && tab.Content.IsVisible() {
+		tab.Content.Draw(screen)
+	}
+}
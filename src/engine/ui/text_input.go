@@ -0,0 +1,337 @@
+package ui
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"unicode"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/hajimehoshi/ebiten/v2/text"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/basicfont"
+)
+
+// キャレットの点滅の周期（フレーム数）
+const textInputBlinkFrames = 30
+
+// 1行の文字入力欄（主人公の名前など）
+// フォーカスがある間、確定した文字の入力と←→・Home・End・BackSpace・Deleteでの編集を受け付ける
+// IMEは確定した文字だけを受け取る（Ebiten 2.5には変換中の文字列を取る手段がないので、変換中の表示はOSに任せる）
+type TextInput struct {
+	BaseComponent
+	Text        string
+	Placeholder string          // 空の時に薄く表示する
+	MaxLength   int             // 文字数の上限（0なら無制限）
+	Filter      func(rune) bool // falseを返す文字は入力しない
+	Font        font.Face
+	Padding     float64
+
+	TextColor        color.Color
+	PlaceholderColor color.Color
+	BackgroundColor  color.Color
+	BorderColor      color.Color
+	FocusColor       color.Color // フォーカスがある時の枠
+	CaretColor       color.Color
+	Frame            *NineSlice // 枠の画像（nilならBackgroundColorで塗る）
+
+	SkinName string // テーマの"text_input"に重ねるスキンの名前
+	Skin     *Skin  // この入力欄だけの上書き
+
+	OnChange func(text string) // 文字列が変わった時
+	OnSubmit func(text string) // Enterを押した時
+	OnCancel func()            // Escを押した時
+
+	caret   int     // キャレットの位置（文字数）
+	scrollX float64 // 収まらない時に左に送った幅
+	blink   int
+	skin    *Skin
+}
+
+func NewTextInput(face font.Face, width float64) *TextInput {
+	t := &TextInput{
+		BaseComponent: BaseComponent{
+			Visible:   true,
+			ZIndex:    100,
+			Focusable: true,
+			Width:     width,
+		},
+		Font:             face,
+		Padding:          6,
+		TextColor:        color.White,
+		PlaceholderColor: color.RGBA{128, 128, 128, 255},
+		BackgroundColor:  color.RGBA{0, 0, 0, 200},
+		BorderColor:      color.RGBA{255, 255, 255, 128},
+		FocusColor:       color.RGBA{255, 255, 0, 255},
+		CaretColor:       color.White,
+	}
+	t.ApplyTheme(DefaultThemes.Current())
+	t.Fit()
+
+	t.On(EventPointerDown, func(e *Event) {
+		if e.Button == ebiten.MouseButtonLeft && t.AcceptsPointer() {
+			t.SetCaret(t.caretAt(e.X))
+		}
+	})
+	return t
+}
+
+// テーマの"text_input"、SkinName、Skinの順に重ねた見た目にする
+// 選択色はフォーカスの枠、無効色はプレースホルダーの色になる
+func (t *TextInput) ApplyTheme(th *Theme) {
+	skin := th.Skin("text_input", t.SkinName).merge(t.Skin)
+	font, padding := t.Font, t.Padding
+	t.skin = skin
+	t.Frame = skin.Frame
+	if skin.BackgroundColor != nil {
+		t.BackgroundColor = skin.BackgroundColor
+	}
+	if skin.TextColor != nil {
+		t.TextColor = skin.TextColor
+		t.CaretColor = skin.TextColor
+	}
+	if skin.SelectedColor != nil {
+		t.FocusColor = skin.SelectedColor
+	}
+	if skin.DisabledColor != nil {
+		t.PlaceholderColor = skin.DisabledColor
+	}
+	if skin.Font != nil {
+		t.Font = skin.Font
+	}
+	if skin.Padding > 0 {
+		t.Padding = skin.Padding
+	}
+	if t.Font != font || t.Padding != padding {
+		t.Fit()
+	}
+}
+
+// 以下の設定はSkinにも書き込み、テーマを切り替えても残す
+// 背景をnilにした場合はテーマを切り替えるとテーマの背景に戻る
+func (t *TextInput) SetBackgroundColor(c color.Color) {
+	overrides(&t.Skin).BackgroundColor = c
+	t.BackgroundColor = c
+}
+
+func (t *TextInput) SetTextColor(c color.Color) {
+	overrides(&t.Skin).TextColor = c
+	t.TextColor = c
+	t.CaretColor = c
+}
+
+func (t *TextInput) SetPadding(padding float64) {
+	overrides(&t.Skin).Padding = padding
+	t.Padding = padding
+	t.Fit()
+}
+
+func (t *TextInput) face() font.Face {
+	if t.Font != nil {
+		return t.Font
+	}
+	return basicfont.Face7x13
+}
+
+// 高さを1行と余白に合わせる（幅は変えない）
+func (t *TextInput) Fit() {
+	t.SetSize(t.Width, float64(t.face().Metrics().Height.Ceil())+t.Padding*2)
+}
+
+// 文字列を置き換え、キャレットを末尾に置く（上限を超える分は切り捨てる）
+func (t *TextInput) SetText(s string) {
+	runes := []rune(s)
+	if t.MaxLength > 0 && len(runes) > t.MaxLength {
+		runes = runes[:t.MaxLength]
+	}
+	t.caret = len(runes)
+	t.change(string(runes))
+}
+
+// キャレットの位置（文字数）
+func (t *TextInput) Caret() int {
+	return t.caret
+}
+
+func (t *TextInput) SetCaret(pos int) {
+	t.caret = clampInt(pos, 0, len([]rune(t.Text)))
+	t.blink = 0
+}
+
+// キャレットの位置に文字を入れる（フィルターと上限を適用する）
+func (t *TextInput) Insert(s string) {
+	runes := []rune(t.Text)
+	t.caret = clampInt(t.caret, 0, len(runes))
+	var inserted []rune
+	for _, r := range s {
+		if unicode.IsControl(r) || (t.Filter != nil && !t.Filter(r)) {
+			continue
+		}
+		if t.MaxLength > 0 && len(runes)+len(inserted) >= t.MaxLength {
+			break
+		}
+		inserted = append(inserted, r)
+	}
+	if len(inserted) == 0 {
+		return
+	}
+	result := append(append(append([]rune{}, runes[:t.caret]...), inserted...), runes[t.caret:]...)
+	t.caret += len(inserted)
+	t.change(string(result))
+}
+
+// キャレットの前（delta<0）・後ろ（delta>0）の文字を消す
+func (t *TextInput) Delete(delta int) {
+	runes := []rune(t.Text)
+	start, end := t.caret+delta, t.caret
+	if delta > 0 {
+		start, end = t.caret, t.caret+delta
+	}
+	start, end = clampInt(start, 0, len(runes)), clampInt(end, 0, len(runes))
+	if start == end {
+		return
+	}
+	t.caret = start
+	t.change(string(append(append([]rune{}, runes[:start]...), runes[end:]...)))
+}
+
+func (t *TextInput) change(s string) {
+	t.blink = 0
+	if s == t.Text {
+		return
+	}
+	t.Text = s
+	if t.OnChange != nil {
+		t.OnChange(s)
+	}
+}
+
+func (t *TextInput) Update() error {
+	if !t.Visible {
+		return nil
+	}
+	t.blink++
+	if !t.AcceptsKeys() {
+		return nil
+	}
+
+	// 確定した文字
+	if chars := ebiten.AppendInputChars(nil); len(chars) > 0 {
+		t.Insert(string(chars))
+	}
+
+	switch {
+	case keyRepeated(ebiten.KeyBackspace):
+		t.Delete(-1)
+	case keyRepeated(ebiten.KeyDelete):
+		t.Delete(1)
+	case keyRepeated(ebiten.KeyArrowLeft):
+		t.SetCaret(t.caret - 1)
+	case keyRepeated(ebiten.KeyArrowRight):
+		t.SetCaret(t.caret + 1)
+	case inpututil.IsKeyJustPressed(ebiten.KeyHome):
+		t.SetCaret(0)
+	case inpututil.IsKeyJustPressed(ebiten.KeyEnd):
+		t.SetCaret(len([]rune(t.Text)))
+	case inpututil.IsKeyJustPressed(ebiten.KeyEnter) || inpututil.IsKeyJustPressed(ebiten.KeyNumpadEnter):
+		if t.OnSubmit != nil {
+			t.OnSubmit(t.Text)
+		}
+	case inpututil.IsKeyJustPressed(ebiten.KeyEscape):
+		if t.OnCancel != nil {
+			t.OnCancel()
+		}
+	}
+	return nil
+}
+
+// 表示する文字列とキャレットの位置
+func (t *TextInput) displayRunes() (runes []rune, caret int) {
+	runes = []rune(t.Text)
+	t.caret = clampInt(t.caret, 0, len(runes)) // Textが直接書き換えられた場合
+	return runes, t.caret
+}
+
+func (t *TextInput) measure(runes []rune) float64 {
+	return float64(font.MeasureString(t.face(), string(runes))) / 64
+}
+
+// ゲーム内座標のxに一番近い文字の境目
+func (t *TextInput) caretAt(x int) int {
+	runes := []rune(t.Text)
+	local := float64(x) - t.X - t.Padding + t.scrollX
+	best, bestDistance := 0, math.Inf(1)
+	for i := 0; i <= len(runes); i++ {
+		if d := math.Abs(t.measure(runes[:i]) - local); d < bestDistance {
+			best, bestDistance = i, d
+		}
+	}
+	return best
+}
+
+func (t *TextInput) Draw(screen *ebiten.Image) {
+	if !t.Visible {
+		return
+	}
+	x, y, w, h := float32(t.X), float32(t.Y), float32(t.Width), float32(t.Height)
+	if t.Frame != nil {
+		t.Frame.Draw(screen, t.X, t.Y, t.Width, t.Height, ebiten.ColorScale{})
+	} else if t.BackgroundColor != nil {
+		vector.DrawFilledRect(screen, x, y, w, h, t.BackgroundColor, false)
+	}
+	border := t.BorderColor
+	if t.focused {
+		border = t.FocusColor
+	}
+	if border != nil {
+		vector.StrokeRect(screen, x, y, w, h, 1, border, false)
+	}
+
+	// 余白の内側だけに描く
+	inner := image.Rect(int(t.X+t.Padding), int(t.Y), int(t.X+t.Width-t.Padding), int(t.Y+t.Height)).Intersect(screen.Bounds())
+	if inner.Empty() {
+		return
+	}
+	clip := screen.SubImage(inner).(*ebiten.Image)
+	face := t.face()
+	metrics := face.Metrics()
+	baseline := int(math.Round(t.Y + (t.Height-float64(metrics.Height.Ceil()))/2 + float64(metrics.Ascent.Ceil())))
+	left := t.X + t.Padding
+
+	runes, caret := t.displayRunes()
+	if len(runes) == 0 {
+		t.scrollX = 0
+		if t.Placeholder != "" {
+			text.Draw(clip, t.Placeholder, face, int(left), baseline, t.PlaceholderColor)
+		}
+	} else {
+		// キャレットが見えるように左右に送る
+		caretX := t.measure(runes[:caret])
+		width := t.Width - t.Padding*2
+		if caretX-t.scrollX > width {
+			t.scrollX = caretX - width
+		} else if caretX < t.scrollX {
+			t.scrollX = caretX
+		}
+		t.scrollX = math.Max(0, math.Min(t.scrollX, t.measure(runes)-width))
+		text.Draw(clip, string(runes), face, int(math.Round(left-t.scrollX)), baseline, t.TextColor)
+	}
+
+	if t.AcceptsKeys() && (t.blink/textInputBlinkFrames)%2 == 0 {
+		cx := float32(math.Round(left - t.scrollX + t.measure(runes[:caret])))
+		top := float32(baseline - metrics.Ascent.Ceil())
+		vector.DrawFilledRect(clip, cx, top, 1, float32(metrics.Height.Ceil()), t.CaretColor, false)
+	}
+}
+
+func clampInt(v, min, max int) int {
+	if v < min {
+		return min
+	}
+	if v > max {
+		return max
+	}
+	return v
+}
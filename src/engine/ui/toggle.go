@@ -0,0 +1,159 @@
+package ui
+
+import (
+	"image/color"
+	"math"
+
+	"gameengine/src/engine/i18n"
+	"gameengine/src/engine/textlayout"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/basicfont"
+)
+
+// チェックボックスと文字列の間
+const toggleSpacing = 6
+
+// オン・オフを切り替えるチェックボックス（設定画面など）
+// クリック、またはフォーカスがある時のEnter・Spaceで切り替える
+type Toggle struct {
+	BaseComponent
+	Checked    bool
+	Text       string
+	Key        string    // 文字列テーブルのキー（指定すると言語の切り替えで訳し直す）
+	Args       i18n.Args // キーのプレースホルダーの値
+	Font       font.Face
+	TextColor  color.Color
+	BoxColor   color.Color
+	CheckColor color.Color
+	FocusColor color.Color
+
+	SkinName string // テーマの"toggle"に重ねるスキンの名前
+	Skin     *Skin  // このトグルだけの上書き
+
+	OnChange func(checked bool)
+
+	skin *Skin
+}
+
+func NewToggle(face font.Face, text string, checked bool) *Toggle {
+	t := &Toggle{
+		BaseComponent: BaseComponent{
+			Visible:   true,
+			ZIndex:    100,
+			Focusable: true,
+		},
+		Checked:    checked,
+		Text:       text,
+		Font:       face,
+		TextColor:  color.White,
+		BoxColor:   color.RGBA{255, 255, 255, 200},
+		CheckColor: color.RGBA{80, 200, 80, 255},
+		FocusColor: color.RGBA{255, 255, 0, 255},
+	}
+	t.ApplyTheme(DefaultThemes.Current())
+	t.Fit()
+
+	t.On(EventClick, func(e *Event) {
+		if e.Button == ebiten.MouseButtonLeft && t.AcceptsPointer() {
+			t.SetChecked(!t.Checked)
+		}
+	})
+	t.On(EventKeyDown, func(e *Event) {
+		if e.Key == ebiten.KeyEnter || e.Key == ebiten.KeySpace {
+			t.SetChecked(!t.Checked)
+		}
+	})
+	return t
+}
+
+// テーマの"toggle"、SkinName、Skinの順に重ねた見た目にする
+// 選択色はチェックの色になる。フォントが変わった場合は大きさを合わせ直す
+func (t *Toggle) ApplyTheme(th *Theme) {
+	skin := th.Skin("toggle", t.SkinName).merge(t.Skin)
+	font := t.Font
+	t.skin = skin
+	if skin.TextColor != nil {
+		t.TextColor = skin.TextColor
+	}
+	if skin.SelectedColor != nil {
+		t.CheckColor = skin.SelectedColor
+	}
+	if skin.Font != nil {
+		t.Font = skin.Font
+	}
+	if t.Font != font {
+		t.Fit()
+	}
+}
+
+// 状態を変え、変わっていればOnChangeを呼ぶ
+func (t *Toggle) SetChecked(checked bool) {
+	if checked == t.Checked {
+		return
+	}
+	t.Checked = checked
+	t.skin.play("click")
+	if t.OnChange != nil {
+		t.OnChange(checked)
+	}
+}
+
+func (t *Toggle) SetText(text string) {
+	t.Text = text
+	t.Fit()
+}
+
+func (t *Toggle) Localize(loc *i18n.Localizer) {
+	if t.Key != "" {
+		t.SetText(loc.Tr(t.Key, t.Args))
+	}
+}
+
+func (t *Toggle) face() font.Face {
+	if t.Font != nil {
+		return t.Font
+	}
+	return basicfont.Face7x13
+}
+
+// チェックボックスの一辺（文字の高さ）
+func (t *Toggle) boxSize() float64 {
+	return float64(t.face().Metrics().Height.Ceil())
+}
+
+// チェックボックスと文字列がちょうど収まる大きさにする
+func (t *Toggle) Fit() {
+	box := t.boxSize()
+	width, height := box, box
+	if t.Text != "" {
+		textWidth, textHeight := measureLines(t.face(), t.Text)
+		width += toggleSpacing + textWidth
+		height = math.Max(height, textHeight)
+	}
+	t.SetSize(width, height)
+}
+
+func (t *Toggle) Draw(screen *ebiten.Image) {
+	if !t.Visible {
+		return
+	}
+	box := t.boxSize()
+	x, y := float32(t.X), float32(t.Y+(t.Height-box)/2)
+	size := float32(box)
+	border := t.BoxColor
+	if t.focused {
+		border = t.FocusColor
+	}
+	vector.StrokeRect(screen, x+1, y+1, size-2, size-2, 2, border, false)
+	if t.Checked {
+		inset := size / 4
+		vector.DrawFilledRect(screen, x+inset, y+inset, size-inset*2, size-inset*2, t.CheckColor, false)
+	}
+	if t.Text != "" {
+		_, textHeight := measureLines(t.face(), t.Text)
+		drawLines(screen, t.face(), t.Text, t.X+box+toggleSpacing, t.Y+(t.Height-textHeight)/2, t.Width-box-toggleSpacing, textlayout.AlignLeft, t.TextColor)
+	}
+}
@@ -0,0 +1,152 @@
+package ui
+
+import (
+	"image/color"
+	"math"
+
+	"gameengine/src/engine/textlayout"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/basicfont"
+)
+
+// カーソルからツールチップまでのずれ
+const (
+	tooltipOffsetX = 12
+	tooltipOffsetY = 16
+)
+
+// カーソルを止めたコンポーネントのTooltipを表示する小さなウィンドウ
+// UIManagerが1つ持ち、一番手前に描く
+type Tooltip struct {
+	BaseComponent
+	Text            string
+	Font            font.Face
+	Padding         float64
+	TextColor       color.Color
+	BackgroundColor color.Color
+	BorderColor     color.Color // nilなら枠なし
+	Frame           *NineSlice  // 枠の画像（BackgroundColorの代わりに描く）
+	Delay           int         // カーソルが乗ってから表示するまでのフレーム数
+
+	SkinName string // テーマの"tooltip"に重ねるスキンの名前
+	Skin     *Skin  // このツールチップだけの上書き
+
+	target Component // Tooltipを表示しているコンポーネント
+	frames int
+}
+
+func NewTooltip(face font.Face) *Tooltip {
+	t := &Tooltip{
+		BaseComponent: BaseComponent{
+			ZIndex: math.MaxInt32,
+		},
+		Font:            face,
+		Padding:         4,
+		TextColor:       color.White,
+		BackgroundColor: color.RGBA{20, 20, 30, 230},
+		BorderColor:     color.RGBA{255, 255, 255, 128},
+		Delay:           30,
+	}
+	t.ApplyTheme(DefaultThemes.Current())
+	return t
+}
+
+// テーマの"tooltip"、SkinName、Skinの順に重ねた見た目にする
+func (t *Tooltip) ApplyTheme(th *Theme) {
+	skin := th.Skin("tooltip", t.SkinName).merge(t.Skin)
+	t.Frame = skin.Frame
+	if skin.BackgroundColor != nil {
+		t.BackgroundColor = skin.BackgroundColor
+	}
+	if skin.TextColor != nil {
+		t.TextColor = skin.TextColor
+	}
+	if skin.Font != nil {
+		t.Font = skin.Font
+	}
+	if skin.Padding > 0 {
+		t.Padding = skin.Padding
+	}
+	if t.Visible {
+		t.fit()
+	}
+}
+
+func (t *Tooltip) face() font.Face {
+	if t.Font != nil {
+		return t.Font
+	}
+	return basicfont.Face7x13
+}
+
+func (t *Tooltip) fit() {
+	width, height := measureLines(t.face(), t.Text)
+	t.SetSize(width+t.Padding*2, height+t.Padding*2)
+}
+
+// カーソルの下のコンポーネントの道筋（外側から）から、一番内側のTooltipを持つものを選ぶ
+// 同じものにDelayフレームの間カーソルが乗っていれば、カーソルの右下（画面からはみ出さない位置）に表示する
+func (t *Tooltip) track(path []Component, x, y int, screen Rect) {
+	var target Component
+	text := ""
+	for i := len(path) - 1; i >= 0; i-- {
+		if b, ok := path[i].(baseAccessor); ok && b.base().Tooltip != "" {
+			target, text = path[i], b.base().Tooltip
+			break
+		}
+	}
+	if target == nil || target != t.target {
+		t.target, t.frames = target, 0
+		t.Visible = false
+		if target == nil {
+			return
+		}
+	}
+	t.frames++
+	if t.frames < t.Delay {
+		return
+	}
+
+	if !t.Visible || text != t.Text {
+		t.Text = text
+		t.fit()
+		px, py := float64(x+tooltipOffsetX), float64(y+tooltipOffsetY)
+		if screen.Width > 0 && screen.Height > 0 {
+			if px+t.Width > screen.Width {
+				px = float64(x) - t.Width
+			}
+			if py+t.Height > screen.Height {
+				py = float64(y) - t.Height
+			}
+			px = math.Max(0, px)
+			py = math.Max(0, py)
+		}
+		t.SetPosition(px, py)
+		t.Visible = true
+	}
+}
+
+// 表示をやめ、カーソルが乗り直すまで出さない
+func (t *Tooltip) Hide() {
+	t.Visible = false
+	t.frames = math.MinInt32
+}
+
+func (t *Tooltip) Draw(screen *ebiten.Image) {
+	if !t.Visible || t.Text == "" {
+		return
+	}
+	x, y, w, h := float32(t.X), float32(t.Y), float32(t.Width), float32(t.Height)
+	if t.Frame != nil {
+		t.Frame.Draw(screen, t.X, t.Y, t.Width, t.Height, ebiten.ColorScale{})
+	} else if t.BackgroundColor != nil {
+		vector.DrawFilledRect(screen, x, y, w, h, t.BackgroundColor, false)
+	}
+	if t.BorderColor != nil && t.Frame == nil {
+		vector.StrokeRect(screen, x, y, w, h, 1, t.BorderColor, false)
+	}
+	drawLines(screen, t.face(), t.Text, t.X+t.Padding, t.Y+t.Padding, t.Width-t.Padding*2, textlayout.AlignLeft, t.TextColor)
+}
@@ -3,19 +3,31 @@ package main
 import (
 	"flag"
 	"fmt"
+	"gameengine/src/engine/asset"
 	"gameengine/src/engine/ecs"
 	"gameengine/src/engine/ecs/components"
 	"gameengine/src/engine/ecs/core"
+	"gameengine/src/engine/font"
+	"gameengine/src/engine/i18n"
+	"gameengine/src/engine/input"
+	"gameengine/src/engine/particle"
+	"gameengine/src/engine/render"
+	"gameengine/src/engine/save"
 	"gameengine/src/engine/script"
 	"gameengine/src/engine/systems"
+	"gameengine/src/engine/ui"
+	"image"
 	"image/color"
 	"log"
+	"os"
 
 	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
 )
 
 var (
 	debugMode = flag.Bool("debug", false, "デバッグモードで実行")
+	language  = flag.String("lang", "", "表示言語（省略時はマニフェストのlanguage）")
 )
 
 type Game struct {
@@ -24,7 +36,18 @@ type Game struct {
 	renderSystem     *systems.RenderSystem
 	inputSystem      *systems.InputSystem
 	textSystem       *systems.TextSystem
+	messageWindows   *systems.MessageWindowSystem
+	messageSystem    *systems.MessageSystem
+	tilemapSystem    *systems.TilemapSystem
 	physicsSystem    *systems.PhysicsSystem
+	assetManager     *asset.AssetManager
+	pipeline         *render.Pipeline
+	renderManager    *render.RenderManager
+	particleManager  *particle.ParticleManager
+	uiManager        *ui.UIManager
+	inputManager     *input.InputManager
+	saveManager      *save.SaveManager
+	viewport         *render.Viewport
 	screenWidth      int
 	screenHeight     int
 	cleanupCounter   int
@@ -38,6 +61,7 @@ func NewGame() *Game {
 	g := &Game{
 		screenWidth:  1280, // HDサイズ
 		screenHeight: 720,
+		viewport:     render.NewViewport(1280, 720),
 	}
 	// ... 他の初期化コード ...
 	return g
@@ -48,6 +72,33 @@ func main() {
 	world := ecs.NewWorld()
 	scriptEngine := script.NewScriptEngine(world, "./scripts")
 
+	// アセットマニフェストの読み込み
+	assetManager := asset.NewAssetManager(nil)
+	assetLoader := asset.NewAssetLoader(os.DirFS("assets"))
+	var manifest *asset.AssetManifest
+	if data, err := os.ReadFile("assets/manifest.json"); err == nil {
+		if manifest, err = asset.LoadManifest(data); err == nil {
+			assetManager.RegisterManifest(manifest, assetLoader)
+		} else {
+			fmt.Printf("Failed to parse asset manifest: %v\n", err)
+		}
+	}
+	scriptEngine.SetAssetManager(assetManager)
+
+	// 文字列テーブルの読み込み（キーで指定した文字列は言語の切り替えで訳し直される）
+	if manifest != nil {
+		if err := asset.RegisterStrings(i18n.Default, manifest, assetLoader); err != nil {
+			fmt.Printf("Failed to load string tables: %v\n", err)
+		}
+	}
+	if *language != "" {
+		i18n.Default.SetLanguage(*language)
+	}
+	if *debugMode {
+		i18n.Default.SetReportMissing(true)
+	}
+	scriptEngine.SetLocalizer(i18n.Default)
+
 	// デフォルトの画面設定エンティティを作成
 	configEntity := world.CreateEntity()
 	configComponent := components.NewScreenConfigComponent()
@@ -57,8 +108,44 @@ func main() {
 	// レンダリングシステムを作成して追加
 	renderSystem := systems.NewRenderSystem()
 	inputSystem := systems.NewInputSystem()
-	textSystem := systems.NewTextSystem()
+	// フォントが読み込めない場合、テキストは組み込みのビットマップフォントで描画する
+	fontManager, err := font.NewFontManager()
+	if err != nil {
+		fmt.Printf("Failed to initialize font manager: %v\n", err)
+	}
+	// マニフェストのフォントを登録（読み込めないものは組み込みフォントで代用）
+	if fontManager != nil && manifest != nil {
+		if err := asset.RegisterFonts(fontManager, manifest, assetLoader); err != nil {
+			fmt.Printf("Failed to register fonts: %v\n", err)
+		}
+	}
+	// テキスト中の [icon=id] は画像アセットを使う
+	iconSource := func(id string) *ebiten.Image {
+		img, err := assetManager.ResolveImage(id)
+		if err != nil {
+			return nil
+		}
+		return img
+	}
+	textSystem := systems.NewTextSystem(fontManager)
+	textSystem.SetIconSource(iconSource)
+	// UIのテーマ（枠の画像、色、フォント、効果音）。ウィンドウを作る前に読み込む
+	if manifest != nil {
+		if err := asset.RegisterThemes(ui.DefaultThemes, manifest, assetLoader, assetManager.ResolveImage, textSystem.Faces()); err != nil {
+			fmt.Printf("Failed to load themes: %v\n", err)
+		}
+	}
+	messageWindows := systems.NewMessageWindowSystem(fontManager)
+	messageWindows.SetIconSource(iconSource)
+	messageSystem := systems.NewMessageSystem(messageWindows)
+	textSystem.SetLocalizer(i18n.Default)
+	messageWindows.SetLocalizer(i18n.Default)
+	messageSystem.SetLocalizer(i18n.Default)
+	messageWindows.SetThemes(ui.DefaultThemes)
 	physicsSystem := systems.NewPhysicsSystem()
+	animationSystem := systems.NewAnimationSystem()
+	tilemapSystem := systems.NewTilemapSystem()
+	physicsSystem.SetTilemapSystem(tilemapSystem)
 
 	// ゲームの初期化
 	game := &Game{
@@ -67,7 +154,17 @@ func main() {
 		renderSystem:     renderSystem,
 		inputSystem:      inputSystem,
 		textSystem:       textSystem,
+		messageWindows:   messageWindows,
+		messageSystem:    messageSystem,
+		tilemapSystem:    tilemapSystem,
 		physicsSystem:    physicsSystem,
+		assetManager:     assetManager,
+		viewport:         render.NewViewport(1280, 720),
+		renderManager:    render.NewRenderManager(),
+		particleManager:  particle.NewParticleManager(),
+		uiManager:        ui.NewUIManager(),
+		inputManager:     input.NewInputManager(),
+		saveManager:      save.NewSaveManager("saves", 10),
 		screenWidth:      1280,
 		screenHeight:     720,
 		scriptSelected:   make(chan string, 1), // バッファ付きチャネル
@@ -87,10 +184,14 @@ func main() {
 	world.AddSystem(screenConfigSystem)
 
 	// 他のシステムを追加
+	world.AddSystem(tilemapSystem)
 	world.AddSystem(renderSystem)
 	world.AddSystem(inputSystem)
 	world.AddSystem(textSystem)
+	world.AddSystem(messageSystem)
+	world.AddSystem(messageWindows)
 	world.AddSystem(physicsSystem)
+	world.AddSystem(animationSystem)
 
 	// FPS表示用のテキストエンティティを作成
 	fpsEntity := game.world.CreateEntity()
@@ -98,9 +199,56 @@ func main() {
 	fpsTextComp.X = 10
 	fpsTextComp.Y = float64(game.screenHeight - 30) // 画面左下
 	fpsTextComp.Text = "FPS: --"
+	fpsTextComp.Dynamic = true // 毎フレーム変わるのでアトラスから描く
 	fpsEntity.AddComponent(fpsTextComp)
 	game.fpsTextID = fpsEntity.GetID()
 
+	// 描画パイプライン（ワールド → UI → デバッグ）
+	game.pipeline = game.newPipeline()
+	scriptEngine.SetRenderPipeline(game.pipeline)
+	scriptEngine.SetRenderManager(game.renderManager)
+	scriptEngine.SetTextStats(textSystem.Stats)
+	scriptEngine.SetTextMeasurer(textSystem.Measure)
+
+	// マウス座標はゲーム内座標に変換して扱う
+	inputSystem.Mouse().Converter = game.viewport
+	game.inputManager.Mouse().Converter = game.viewport
+	game.uiManager.SetCoordinateConverter(game.viewport)
+	game.uiManager.SetScreenSize(game.screenWidth, game.screenHeight)
+	scriptEngine.SetViewport(game.viewport)
+	textSystem.SetViewport(game.viewport)
+	scriptEngine.SetMouseState(inputSystem.Mouse())
+	i18n.Default.OnLanguageChange(func(string) {
+		game.uiManager.Localize(i18n.Default)
+	})
+	ui.DefaultThemes.OnThemeChange(func(t *ui.Theme) {
+		game.uiManager.ApplyTheme(t)
+	})
+
+	// スクリプトから組み立てるUI（ui_windowなど）とbindで表示するゲーム状態
+	scriptEngine.SetUIManager(game.uiManager)
+	scriptEngine.SetInputManager(game.inputManager)
+	scriptEngine.SetFaceSource(textSystem.Faces())
+	scriptEngine.SetSaveManager(game.saveManager)
+
+	// メニュー・文字入力・会話の間は入力コンテキストを積み、ゲームプレイのアクションを止める
+	game.uiManager.SetInputManager(game.inputManager)
+	game.messageSystem.SetInputManager(game.inputManager)
+
+	// 入力の割り当て（保存されたプロファイルがあれば読み込む）
+	game.inputManager.SetProfileDir("config")
+	if err := game.inputManager.LoadProfile("default"); err != nil && !os.IsNotExist(err) {
+		log.Printf("Failed to load input profile: %v", err)
+	}
+
+	// ゲームパッドの抜き差し（つながったパッドは空いているプレイヤーに割り当てられる）
+	game.inputManager.OnGamepadConnected(func(id ebiten.GamepadID, player int) {
+		fmt.Printf("Gamepad connected: %s (player %d)\n", ebiten.GamepadName(id), player)
+	})
+	game.inputManager.OnGamepadDisconnected(func(id ebiten.GamepadID, player int) {
+		fmt.Printf("Gamepad disconnected: %d (player %d)\n", id, player)
+	})
+
 	// ウィンドウ設定
 	ebiten.SetWindowTitle("Game")
 	ebiten.SetWindowResizingMode(ebiten.WindowResizingModeEnabled)
@@ -137,6 +285,16 @@ func (g *Game) Update() error {
 		return nil
 	}
 
+	// 非同期ロード待ちのアセットを処理
+	if err := g.assetManager.Update(); err != nil {
+		return err
+	}
+
+	// アクションの入力状態を更新（メニューなどのUIが使う）
+	if err := g.inputManager.Update(); err != nil {
+		return err
+	}
+
 	// スクリプトエンジンの更新を最初に行う
 	if err := g.scriptEngine.CallUpdate(); err != nil {
 		return err
@@ -147,6 +305,15 @@ func (g *Game) Update() error {
 		return err
 	}
 
+	// パーティクルとUIの更新
+	g.particleManager.Update(1.0 / 60.0)
+	if err := g.uiManager.Update(); err != nil {
+		return err
+	}
+
+	// エフェクトの時間経過
+	g.pipeline.Update(1.0 / 60.0)
+
 	// 非アクティブなエンティティを定期的にクリーンアップ
 	g.cleanupCounter++
 	if g.cleanupCounter >= 60 { // 1秒ごとにクリーンアップ
@@ -164,39 +331,81 @@ func (g *Game) Update() error {
 	return nil
 }
 
+// 標準のパスとエフェクトを登録（エフェクトはスクリプトから有効化する）
+func (g *Game) newPipeline() *render.Pipeline {
+	p := render.NewPipeline()
+
+	// 各パスは描画キューから自分のレイヤー範囲だけを描画する
+	world := p.AddPass(render.PassWorld, func(dst *ebiten.Image) {
+		g.renderManager.DrawLayers(dst, -render.LayerMax, render.LayerUI)
+	})
+	colorGrade := render.NewColorGradeEffect(render.EffectColorGrade)
+	colorGrade.SetEnabled(false)
+	world.AddEffect(colorGrade)
+	vignette := render.NewVignetteEffect(render.EffectVignette)
+	vignette.SetEnabled(false)
+	world.AddEffect(vignette)
+
+	// フェードとフラッシュはUIまで含めて掛ける
+	uiPass := p.AddPass(render.PassUI, func(dst *ebiten.Image) {
+		g.renderManager.DrawLayers(dst, render.LayerUI, render.LayerDebug)
+	})
+	crt := render.NewCRTEffect(render.EffectCRT)
+	crt.SetEnabled(false)
+	uiPass.AddEffect(crt)
+	uiPass.AddEffect(render.NewFadeEffect(render.EffectFade))
+	uiPass.AddEffect(render.NewFlashEffect(render.EffectFlash))
+
+	debug := p.AddPass(render.PassDebug, func(dst *ebiten.Image) {
+		g.renderManager.DrawLayers(dst, render.LayerDebug, render.LayerMax)
+		stats := g.renderManager.Stats()
+		text := g.textSystem.Stats()
+		ebitenutil.DebugPrint(dst, fmt.Sprintf("TPS: %.1f\ncommands: %d  draw calls: %d  batches: %d (%d sprites)"+
+			"\ntext cache: %d hits  %d misses  %d evicted  %d images\nglyph atlas: %d glyphs  %d pages  %d hits  %d misses  %d texts",
+			ebiten.ActualTPS(), stats.Commands, stats.DrawCalls, stats.Batches, stats.Batched,
+			text.CacheHits, text.CacheMisses, text.Evictions, text.CachedImages,
+			text.AtlasGlyphs, text.AtlasPages, text.AtlasHits, text.AtlasMisses, text.AtlasTexts))
+	})
+	debug.Enabled = *debugMode
+
+	return p
+}
+
 func (g *Game) Draw(screen *ebiten.Image) {
-	screen.Fill(color.RGBA{0, 0, 0, 255}) // 背景を黒に
-	g.renderSystem.SetScreen(screen)
-	g.renderSystem.Update(0)
-	g.textSystem.SetScreen(screen) // テキストシステムの描画も追加
-	g.textSystem.Update(0)
+	width, height := g.viewport.Size()
+
+	// 描画コマンドを1つのキューに集める
+	g.renderManager.Begin()
+	queue := g.renderManager.Queue()
+	g.tilemapSystem.Collect(queue, image.Rect(0, 0, width, height))
+	g.renderSystem.Collect(queue)
+	g.particleManager.Collect(queue, render.SortKey{Layer: render.LayerWorld, Z: 100})
+	g.uiManager.Collect(queue, render.LayerUI)
+	g.textSystem.Collect(queue)
+	g.messageWindows.Collect(queue)
+	g.messageSystem.Collect(queue)
+
+	// 論理解像度で描画してからウィンドウに合わせて拡大
+	g.viewport.Draw(screen, g.pipeline.Render(width, height))
 }
 
 func (g *Game) Layout(outsideWidth, outsideHeight int) (screenWidth, screenHeight int) {
-	// デバッグ出力を追加
-	// fmt.Printf("Layout called: outside=%dx%d, screen=%dx%d\n",
-	// 	outsideWidth, outsideHeight, g.screenWidth, g.screenHeight)
-
-	// 画面のアスペクト比を維持
-	targetAspectRatio := float64(g.screenWidth) / float64(g.screenHeight)
-	currentAspectRatio := float64(outsideWidth) / float64(outsideHeight)
-
-	var width, height int
-	if currentAspectRatio > targetAspectRatio {
-		// 高さに合わせる
-		height = g.screenHeight
-		width = g.screenWidth
-	} else {
-		// 幅に合わせる
-		width = g.screenWidth
-		height = g.screenHeight
-	}
-
-	// fmt.Printf("Returning layout: %dx%d\n", width, height)
+	// ウィンドウ全体を描画先にし、拡大縮小と余白はViewportで行う
+	scale := ebiten.DeviceScaleFactor()
+	width := int(float64(outsideWidth) * scale)
+	height := int(float64(outsideHeight) * scale)
+	g.viewport.Layout(width, height)
 	return width, height
 }
 
 func (g *Game) SetScreenSize(width, height int) {
 	g.screenWidth = width
 	g.screenHeight = height
+	g.viewport.SetBaseSize(width, height)
+	g.uiManager.SetScreenSize(width, height)
+}
+
+func (g *Game) SetScaleMode(mode render.ScaleMode, letterbox color.Color) {
+	g.viewport.SetMode(mode)
+	g.viewport.SetLetterboxColor(letterbox)
 }